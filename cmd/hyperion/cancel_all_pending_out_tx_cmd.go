@@ -33,9 +33,16 @@ func cancelAllPendingOutTxCmd(cmd *cli.Cmd) {
 			TendermintRPC:         *cfg.tendermintRPC,
 			HeliosGasPrices:       *cfg.heliosGasPrices,
 			HeliosGas:             *cfg.heliosGas,
+			HeliosGasMultiplier:   *cfg.heliosGasMultiplier,
 			EthGasPriceAdjustment: *cfg.ethGasPriceAdjustment,
 			EthMaxGasPrice:        *cfg.ethMaxGasPrice,
 			PendingTxWaitDuration: *cfg.pendingTxWaitDuration,
+
+			EthRemoteSignerURL:        *cfg.ethRemoteSignerURL,
+			EthRemoteSignerAddress:    *cfg.ethRemoteSignerAddress,
+			EthRemoteSignerCACert:     *cfg.ethRemoteSignerCACert,
+			EthRemoteSignerClientCert: *cfg.ethRemoteSignerClientCert,
+			EthRemoteSignerClientKey:  *cfg.ethRemoteSignerClientKey,
 		})
 		heliosNetwork := global.GetHeliosNetwork()
 		if heliosNetwork == nil {
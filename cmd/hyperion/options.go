@@ -94,6 +94,8 @@ type Config struct {
 	heliosGasPrices *string
 	heliosGas       *string
 
+	heliosGasMultiplier *float64
+
 	// Cosmos Key Management
 	heliosPrivKey *string
 
@@ -101,8 +103,32 @@ type Config struct {
 	ethGasPriceAdjustment *float64
 	ethMaxGasPrice        *string
 
+	// Ethereum remote signer
+	ethRemoteSignerURL        *string
+	ethRemoteSignerAddress    *string
+	ethRemoteSignerCACert     *string
+	ethRemoteSignerClientCert *string
+	ethRemoteSignerClientKey  *string
+
 	// Relayer config
 	pendingTxWaitDuration *string
+
+	// Compliance
+	sanctionsListPath        *string
+	sanctionsRefreshInterval *string
+
+	drainTimeout *string
+
+	// Logs
+	enabledLogs  *string
+	logFormat    *string
+	logDir       *string
+	logMaxSizeMB *int
+	logMaxFiles  *int
+
+	// Tracing
+	otlpEndpoint     *string
+	traceSampleRatio *float64
 }
 
 func initConfig(cmd *cli.Cmd) Config {
@@ -119,32 +145,39 @@ func initConfig(cmd *cli.Cmd) Config {
 
 	cfg.heliosGRPC = cmd.String(cli.StringOpt{
 		Name:   "helios-grpc",
-		Desc:   "Helios GRPC querying endpoint",
+		Desc:   "Helios GRPC querying endpoint, or a comma separated list of endpoints to fail over between",
 		EnvVar: "HYPERION_HELIOS_GRPC",
 		Value:  "tcp://localhost:9090",
 	})
 
 	cfg.tendermintRPC = cmd.String(cli.StringOpt{
 		Name:   "tendermint-rpc",
-		Desc:   "Tendermint RPC endpoint",
+		Desc:   "Tendermint RPC endpoint, or a comma separated list paired by position with --helios-grpc",
 		EnvVar: "HYPERION_TENDERMINT_RPC",
 		Value:  "http://localhost:26657",
 	})
 
 	cfg.heliosGasPrices = cmd.String(cli.StringOpt{
 		Name:   "helios-gas-prices",
-		Desc:   "Specify Helios chain transaction fees as DecCoins gas prices",
+		Desc:   "Fallback Helios gas prices as DecCoins, used when the feemarket min gas price cannot be queried",
 		EnvVar: "HYPERION_HELIOS_GAS_PRICES",
 		Value:  "500000000ahelios", // example: 500000000ahelios
 	})
 
 	cfg.heliosGas = cmd.String(cli.StringOpt{
 		Name:   "helios-gas",
-		Desc:   "Specify Helios chain transaction gas",
+		Desc:   "Maximum gas of a Helios chain transaction",
 		EnvVar: "HYPERION_HELIOS_GAS",
 		Value:  "2000000", // example: 2000000
 	})
 
+	cfg.heliosGasMultiplier = cmd.Float64(cli.Float64Opt{
+		Name:   "helios-gas-multiplier",
+		Desc:   "Multiplier applied to the simulated gas of Helios chain transactions",
+		EnvVar: "HYPERION_HELIOS_GAS_MULTIPLIER",
+		Value:  float64(1.3),
+	})
+
 	cfg.heliosPrivKey = cmd.String(cli.StringOpt{
 		Name:   "helios-pk",
 		Desc:   "Provide a raw Helios account private key of the validator in hex.",
@@ -167,6 +200,41 @@ func initConfig(cmd *cli.Cmd) Config {
 		Value:  "500gwei",
 	})
 
+	cfg.ethRemoteSignerURL = cmd.String(cli.StringOpt{
+		Name:   "eth-remote-signer-url",
+		Desc:   "Web3Signer compatible endpoint holding the Ethereum key. If set, the Ethereum key is not derived from the Helios private key.",
+		EnvVar: "HYPERION_ETH_REMOTE_SIGNER_URL",
+		Value:  "",
+	})
+
+	cfg.ethRemoteSignerAddress = cmd.String(cli.StringOpt{
+		Name:   "eth-remote-signer-address",
+		Desc:   "Ethereum address of the key held by the remote signer",
+		EnvVar: "HYPERION_ETH_REMOTE_SIGNER_ADDRESS",
+		Value:  "",
+	})
+
+	cfg.ethRemoteSignerCACert = cmd.String(cli.StringOpt{
+		Name:   "eth-remote-signer-ca-cert",
+		Desc:   "PEM CA certificate used to verify the remote signer",
+		EnvVar: "HYPERION_ETH_REMOTE_SIGNER_CA_CERT",
+		Value:  "",
+	})
+
+	cfg.ethRemoteSignerClientCert = cmd.String(cli.StringOpt{
+		Name:   "eth-remote-signer-client-cert",
+		Desc:   "PEM client certificate for TLS client auth with the remote signer",
+		EnvVar: "HYPERION_ETH_REMOTE_SIGNER_CLIENT_CERT",
+		Value:  "",
+	})
+
+	cfg.ethRemoteSignerClientKey = cmd.String(cli.StringOpt{
+		Name:   "eth-remote-signer-client-key",
+		Desc:   "PEM client key for TLS client auth with the remote signer",
+		EnvVar: "HYPERION_ETH_REMOTE_SIGNER_CLIENT_KEY",
+		Value:  "",
+	})
+
 	/** Batch Requester **/
 
 	cfg.pendingTxWaitDuration = cmd.String(cli.StringOpt{
@@ -175,5 +243,77 @@ func initConfig(cmd *cli.Cmd) Config {
 		EnvVar: "HYPERION_RELAY_PENDING_TX_WAIT_DURATION",
 		Value:  "20m",
 	})
+
+	/** Compliance **/
+
+	cfg.sanctionsListPath = cmd.String(cli.StringOpt{
+		Name:   "sanctions-list",
+		Desc:   "Path to a JSON array of sanctioned addresses used to screen batch recipients. Defaults to the bundled OFAC list.",
+		EnvVar: "HYPERION_SANCTIONS_LIST",
+		Value:  "",
+	})
+
+	cfg.sanctionsRefreshInterval = cmd.String(cli.StringOpt{
+		Name:   "sanctions-list-refresh",
+		Desc:   "Specify how often the sanctions list file is reloaded from disk",
+		EnvVar: "HYPERION_SANCTIONS_LIST_REFRESH",
+		Value:  "1h",
+	})
+
+	cfg.drainTimeout = cmd.String(cli.StringOpt{
+		Name:   "drain-timeout",
+		Desc:   "Specify how long a stopping runner waits for its relays and Helios msgs in flight",
+		EnvVar: "HYPERION_DRAIN_TIMEOUT",
+		Value:  "2m",
+	})
+
+	cfg.enabledLogs = cmd.String(cli.StringOpt{
+		Name:   "enabled-logs",
+		Desc:   "Comma separated list of the loops writing their verbose logs, the others write them once their level is set to debug",
+		EnvVar: "HYPERION_ENABLED_LOGS",
+		Value:  "signer,relayer,oracle,batch_creator",
+	})
+
+	cfg.logFormat = cmd.String(cli.StringOpt{
+		Name:   "log-format",
+		Desc:   "Format of the console logs (json, text), the log store is always JSON",
+		EnvVar: "HYPERION_LOG_FORMAT",
+		Value:  "json",
+	})
+
+	cfg.logDir = cmd.String(cli.StringOpt{
+		Name:   "log-dir",
+		Desc:   "Directory of the log store queried by get-logs, defaults to ~/.heliades/hyperion/logs. Set to \"off\" to disable it",
+		EnvVar: "HYPERION_LOG_DIR",
+		Value:  "",
+	})
+
+	cfg.logMaxSizeMB = cmd.Int(cli.IntOpt{
+		Name:   "log-max-size",
+		Desc:   "Size in MB after which the log store file is rotated",
+		EnvVar: "HYPERION_LOG_MAX_SIZE",
+		Value:  20,
+	})
+
+	cfg.logMaxFiles = cmd.Int(cli.IntOpt{
+		Name:   "log-max-files",
+		Desc:   "Number of log store files kept, the current one included",
+		EnvVar: "HYPERION_LOG_MAX_FILES",
+		Value:  5,
+	})
+
+	cfg.otlpEndpoint = cmd.String(cli.StringOpt{
+		Name:   "otlp-endpoint",
+		Desc:   "OTLP/HTTP collector receiving the traces, e.g. http://localhost:4318. Tracing is off when it and OTEL_EXPORTER_OTLP_ENDPOINT are empty",
+		EnvVar: "HYPERION_OTLP_ENDPOINT",
+		Value:  "",
+	})
+
+	cfg.traceSampleRatio = cmd.Float64(cli.Float64Opt{
+		Name:   "trace-sample-ratio",
+		Desc:   "Ratio of the traces exported, between 0 and 1",
+		EnvVar: "HYPERION_TRACE_SAMPLE_RATIO",
+		Value:  float64(1),
+	})
 	return cfg
 }
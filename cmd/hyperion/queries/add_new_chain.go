@@ -10,7 +10,7 @@ import (
 )
 
 func AddNewChain(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	hyperionParams, err := network.HyperionParams(ctx)
 	if err != nil {
 		return nil, err
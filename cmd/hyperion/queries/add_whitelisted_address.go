@@ -11,7 +11,7 @@ import (
 )
 
 func AddWhitelistedAddress(ctx context.Context, global *global.Global, chainId uint64, address string) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
 	// check if address is ethereum address
 	if !gethcommon.IsHexAddress(address) {
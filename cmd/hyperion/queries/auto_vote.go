@@ -0,0 +1,55 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/autovote"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// GetAutoVoteRules returns the auto-vote configuration.
+func GetAutoVoteRules(ctx context.Context, global *global.Global) (*autovote.Config, error) {
+	return global.GetAutoVoteConfig()
+}
+
+// SetAutoVoteRules replaces the auto-vote configuration after validating its rules.
+func SetAutoVoteRules(ctx context.Context, global *global.Global, config *autovote.Config) (*autovote.Config, error) {
+	if err := global.SetAutoVoteConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// GetAutoVotePlan returns the votes the engine would cast on the open proposals, without
+// casting them.
+func GetAutoVotePlan(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
+	config, err := global.GetAutoVoteConfig()
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := global.PlanAutoVotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"enabled":   config.Enabled,
+		"decisions": decisions,
+	}, nil
+}
+
+// GetAutoVotes returns the votes cast by the engine, newest first.
+func GetAutoVotes(ctx context.Context, global *global.Global) ([]map[string]interface{}, error) {
+	return storage.GetAutoVotes()
+}
+
+// MarkProposalReviewed leaves the proposal to manual voting.
+func MarkProposalReviewed(ctx context.Context, global *global.Global, proposalId uint64) (map[string]interface{}, error) {
+	if err := global.MarkProposalReviewed(proposalId); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"proposal_id": proposalId,
+		"reviewed":    true,
+	}, nil
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func CancelAllPendingOutTx(ctx context.Context, global *global.Global, chainId uint64) error {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
 	// cancel all pending outgoing txs
 	err := network.SendCancelAllPendingOutTx(ctx, chainId)
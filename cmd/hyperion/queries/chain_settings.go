@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ratelimit"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/utils"
 )
@@ -23,6 +24,9 @@ func UpdateChainSettings(ctx context.Context, global *global.Global, chainId uin
 			return err
 		}
 	}
+	if _, err := ratelimit.ParseLimits(settings["outflow_limits"]); err != nil {
+		return err
+	}
 	err = storage.SetChainSettings(chainId, settings)
 	if err != nil {
 		fmt.Println("Error updating chain settings: ", err)
@@ -44,7 +48,7 @@ func GetChainSettings(ctx context.Context, global *global.Global, chainId uint64
 		}
 	}
 
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	registeredNetworks, err := network.GetValidatorHyperionData(ctx, global.GetAddress())
 	if err != nil {
 		return settings, nil
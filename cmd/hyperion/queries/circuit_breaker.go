@@ -0,0 +1,42 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/breaker"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetCircuitBreaker returns the circuit breaker configuration and the breaker state of
+// the chain.
+func GetCircuitBreaker(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
+	config, err := global.GetCircuitBreakerConfig()
+	if err != nil {
+		return nil, err
+	}
+	state, err := global.GetCircuitBreakerState(chainId)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"config": config,
+		"state":  state,
+		"armed": map[string]bool{
+			breaker.TripwireUnmatchedWithdrawal: config.Armed(breaker.TripwireUnmatchedWithdrawal, chainId),
+			breaker.TripwireValsetMismatch:      config.Armed(breaker.TripwireValsetMismatch, chainId),
+			breaker.TripwireDepositVolume:       config.Armed(breaker.TripwireDepositVolume, chainId),
+		},
+	}, nil
+}
+
+// SetCircuitBreakerConfig replaces the circuit breaker configuration after validating it.
+func SetCircuitBreakerConfig(ctx context.Context, global *global.Global, config *breaker.Config) (*breaker.Config, error) {
+	if err := global.SetCircuitBreakerConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func RearmCircuitBreaker(ctx context.Context, global *global.Global, chainId uint64) (*breaker.State, error) {
+	return global.RearmCircuitBreaker(chainId)
+}
@@ -33,7 +33,14 @@ func ClaimTokensOfOldContract(ctx context.Context, global *global.Global, hyperi
 	}
 	targetNetwork := targetNetworks[0]
 
-	err = (*targetNetwork).SendClaimTokensOfOldContract(ctx, hyperionId, tokenContract, amountInSdkMath.BigInt(), (*targetNetwork).FromAddress(), (*targetNetwork).GetPersonalSignFn())
+	pendingBatchNonce, err := global.PendingBatchNonce(ctx, hyperionId, tokenContract)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	txHash, err := (*targetNetwork).SendClaimTokensOfOldContract(ctx, hyperionId, tokenContract, amountInSdkMath.BigInt(), pendingBatchNonce, (*targetNetwork).FromAddress(), (*targetNetwork).FromAddress(), (*targetNetwork).GetPersonalSignFn())
 	if err != nil {
 		return map[string]interface{}{
 			"error": err.Error(),
@@ -42,5 +49,6 @@ func ClaimTokensOfOldContract(ctx context.Context, global *global.Global, hyperi
 
 	return map[string]interface{}{
 		"success": true,
+		"txHash":  txHash.Hex(),
 	}
 }
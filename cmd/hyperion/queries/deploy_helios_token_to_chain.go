@@ -10,7 +10,7 @@ import (
 )
 
 func DeployHeliosTokenToChain(ctx context.Context, global *global.Global, chainId uint64, denom string, name string, symbol string, decimals uint8) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	counterpartyChainParams, err := network.GetCounterpartyChainParamsByChainId(ctx, chainId)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// RunDeployment starts or resumes the deployment pipeline of the chain. On failure the
+// state is returned with the error so that the caller knows the stage to resume from.
+func RunDeployment(ctx context.Context, global *global.Global, request *global.DeploymentRequest) (*global.DeploymentState, error) {
+	return global.RunDeployment(ctx, request)
+}
+
+func GetDeployment(ctx context.Context, global *global.Global, chainId uint64) (*global.DeploymentState, error) {
+	return global.GetDeployment(chainId)
+}
+
+// ResetDeployment forgets the deployment state of the chain, the next run deploys a new
+// contract.
+func ResetDeployment(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
+	if err := global.ResetDeployment(chainId); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"chainId": chainId,
+		"reset":   true,
+	}, nil
+}
@@ -0,0 +1,18 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/slashingprotection"
+)
+
+// ExportSlashingProtection returns the signing history of the orchestrator in the
+// interchange format, to be imported on the host taking over the validator.
+func ExportSlashingProtection(ctx context.Context, global *global.Global) (*slashingprotection.Interchange, error) {
+	store, err := slashingprotection.DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Export(), nil
+}
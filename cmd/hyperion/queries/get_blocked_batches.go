@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// GetBlockedBatches lists the batches refused by the sanctions screening. With all set,
+// hits that were only flagged are returned too. A chainId of 0 returns every chain.
+func GetBlockedBatches(ctx context.Context, global *global.Global, chainId uint64, all bool) (map[string]interface{}, error) {
+	hits, err := storage.GetSanctionsHits(chainId, !all)
+	if err != nil {
+		return nil, err
+	}
+
+	sanctionsList := global.GetSanctionsList()
+
+	return map[string]interface{}{
+		"batches":        hits,
+		"total":          len(hits),
+		"list_source":    sanctionsList.Source(),
+		"list_size":      sanctionsList.Size(),
+		"list_loaded_at": sanctionsList.LoadedAt().Unix(),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetHeliosEndpoints returns the Helios endpoint in use and the last health check of
+// every configured endpoint.
+func GetHeliosEndpoints(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
+	heliosNetwork := global.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, errors.New("helios network not initialized")
+	}
+
+	return map[string]interface{}{
+		"active":    heliosNetwork.ActiveEndpoint(),
+		"endpoints": heliosNetwork.EndpointsHealth(),
+	}, nil
+}
@@ -0,0 +1,14 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
+)
+
+// GetIndexedEvents returns a page of the Hyperion contract events indexed for the chain,
+// filtered by kind, address, token, nonce and block range.
+func GetIndexedEvents(ctx context.Context, global *global.Global, chainId uint64, query indexer.Query) (indexer.Page, error) {
+	return global.QueryEventIndex(chainId, query)
+}
@@ -11,14 +11,14 @@ import (
 )
 
 func GetListHyperions(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	hyperionParams, err := network.HyperionParams(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	counterpartyChainParams := hyperionParams.CounterpartyChainParams
-	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(*global.GetHeliosNetwork(), global.GetAddress())
+	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(global.GetHeliosNetwork(), global.GetAddress())
 
 	hyperions := map[string]interface{}{}
 	for _, counterpartyChainParam := range counterpartyChainParams {
@@ -57,6 +57,7 @@ func GetListHyperions(ctx context.Context, global *global.Global) (map[string]in
 			"minCallExternalDataGas":           counterpartyChainParam.MinCallExternalDataGas,
 			"registered":                       registered,
 			"running":                          running,
+			"runnerStatus":                     global.GetRunnerStatus(counterpartyChainParam.BridgeChainId),
 			"paused":                           counterpartyChainParam.Paused,
 			"enabled":                          true,
 			"proposed":                         true,
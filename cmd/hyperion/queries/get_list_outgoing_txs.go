@@ -7,7 +7,7 @@ import (
 )
 
 func GetListOutgoingTxs(ctx context.Context, global *global.Global, chainId uint64) (map[string]int, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
 	batches, err := network.QueryGetListOutgoingTxs(ctx, chainId)
 	if err != nil {
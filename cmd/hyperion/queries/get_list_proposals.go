@@ -13,7 +13,7 @@ import (
 
 func GetListProposals(ctx context.Context, global *global.Global, page int, size int) (map[string]interface{}, error) {
 	fmt.Println("GetListProposals", page, size)
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	proposals, total, err := network.GetProposalsByPageAndSize(ctx, page, size)
 	if err != nil {
 		return nil, err
@@ -7,7 +7,7 @@ import (
 )
 
 func GetListTokens(ctx context.Context, global *global.Global, chainId uint64, page uint64, size uint64) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	// _, err := network.GetCounterpartyChainParamsByChainId(ctx, chainId)
 	// if err != nil {
 	// 	return nil, err
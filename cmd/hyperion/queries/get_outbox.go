@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetOutbox returns the depth of the outbox and the age of the messages still waiting
+// to be broadcast to Helios, per chain and loop.
+func GetOutbox(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
+	outbox := global.GetOutbox()
+	if outbox == nil {
+		return map[string]interface{}{
+			"depth":              0,
+			"msgs":               0,
+			"oldest_age_seconds": 0,
+			"origins":            []map[string]interface{}{},
+		}, nil
+	}
+	return outbox.Stats(), nil
+}
@@ -0,0 +1,26 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetParticipation returns the participation report of every running chain, or of a
+// single chain when chainId is not 0.
+func GetParticipation(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
+	reports := make(map[string]interface{})
+	for id, orchestrator := range global.GetOrchestrators() {
+		if chainId != 0 && id != chainId {
+			continue
+		}
+		reports[fmt.Sprintf("%d", id)] = orchestrator.ParticipationReport()
+	}
+
+	if chainId != 0 && len(reports) == 0 {
+		return nil, fmt.Errorf("no orchestrator running for chain %d", chainId)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetProposalDiff returns the field-by-field diff between the counterparty chain params
+// proposed by the proposal and the live ones.
+func GetProposalDiff(ctx context.Context, global *global.Global, proposalId uint64) (map[string]interface{}, error) {
+	diffs, err := global.DiffProposal(ctx, proposalId)
+	if err != nil {
+		return nil, err
+	}
+
+	risky := false
+	for _, diff := range diffs {
+		risky = risky || diff.Risky
+	}
+
+	return map[string]interface{}{
+		"proposalId": proposalId,
+		"risky":      risky,
+		"diffs":      diffs,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package queries
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// GetSignatures returns the archived valset and batch confirmations of a chain, each one
+// re-verified against the encoded message it was produced for.
+func GetSignatures(ctx context.Context, global *global.Global, chainId uint64, kind string, nonce uint64, tokenContract string) (map[string]interface{}, error) {
+	signatures, err := storage.GetSignatures(chainId, kind, nonce, tokenContract)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedCount := 0
+	for _, signature := range signatures {
+		if err := verifyArchivedSignature(signature); err != nil {
+			signature["verified"] = false
+			signature["verification_error"] = err.Error()
+			continue
+		}
+		signature["verified"] = true
+		verifiedCount++
+	}
+
+	return map[string]interface{}{
+		"signatures": signatures,
+		"total":      len(signatures),
+		"verified":   verifiedCount,
+	}, nil
+}
+
+// verifyArchivedSignature checks that the message hashes to the archived hash and that the
+// signature over it recovers to the archived signer.
+func verifyArchivedSignature(signature map[string]interface{}) error {
+	messageHex, _ := signature["message"].(string)
+	messageHashHex, _ := signature["message_hash"].(string)
+	signatureHex, _ := signature["signature"].(string)
+	ethSigner, _ := signature["eth_signer"].(string)
+
+	message := common.FromHex(messageHex)
+	if len(message) == 0 {
+		return errors.New("missing encoded message")
+	}
+
+	messageHash := crypto.Keccak256Hash(message)
+	if messageHash != common.HexToHash(messageHashHex) {
+		return errors.Errorf("encoded message hashes to %s, archived hash is %s", messageHash.Hex(), messageHashHex)
+	}
+
+	sig := common.FromHex(signatureHex)
+	if len(sig) != crypto.SignatureLength {
+		return errors.Errorf("signature has %d bytes", len(sig))
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(accounts.TextHash(messageHash.Bytes()), sig)
+	if err != nil {
+		return errors.Wrap(err, "failed to recover signer")
+	}
+	recovered := crypto.PubkeyToAddress(*pubKey)
+	if !strings.EqualFold(recovered.Hex(), ethSigner) {
+		return errors.Errorf("signature recovers to %s, expected %s", recovered.Hex(), ethSigner)
+	}
+
+	return nil
+}
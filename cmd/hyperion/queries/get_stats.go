@@ -10,7 +10,15 @@ import (
 func GetStats(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
 	orchestrators := global.GetOrchestrators()
 	stats := make(map[string]interface{})
+	heliosEndpoint := ""
+	if heliosNetwork := global.GetHeliosNetwork(); heliosNetwork != nil {
+		heliosEndpoint = heliosNetwork.ActiveEndpoint().GRPC
+	}
 	for chainId, orchestrator := range orchestrators {
+		outboxDepth, outboxOldestAge := 0, int64(0)
+		if outbox := global.GetOutbox(); outbox != nil {
+			outboxDepth, outboxOldestAge = outbox.ChainStats(chainId)
+		}
 		stats[fmt.Sprintf("%d", chainId)] = map[string]interface{}{
 			"totalTxs":             orchestrator.HyperionState.TxCount,
 			"batches":              orchestrator.HyperionState.BatchCount,
@@ -26,6 +34,14 @@ func GetStats(ctx context.Context, global *global.Global) (map[string]interface{
 			"depositPaused":        orchestrator.HyperionState.IsDepositPaused,
 			"withdrawalPaused":     orchestrator.HyperionState.IsWithdrawalPaused,
 			"gasPrice":             orchestrator.HyperionState.GasPrice,
+			"relayerAddress":       orchestrator.GetEthereum().RelayerAddress().Hex(),
+			"sanctionsHitCount":    orchestrator.HyperionState.SanctionsHitCount,
+			"sanctionsPolicy":      orchestrator.GetSanctionsPolicy(),
+			"heldBatchCount":       orchestrator.HyperionState.HeldBatchCount,
+			"heliosWakeupCount":    orchestrator.HyperionState.HeliosWakeupCount,
+			"outboxDepth":          outboxDepth,
+			"outboxOldestAge":      outboxOldestAge,
+			"heliosEndpoint":       heliosEndpoint,
 		}
 	}
 
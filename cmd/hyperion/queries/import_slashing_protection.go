@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/slashingprotection"
+)
+
+// ImportSlashingProtection merges an exported signing history into the local store.
+// Nothing is imported if a record conflicts with a payload already signed here.
+func ImportSlashingProtection(ctx context.Context, global *global.Global, interchange *slashingprotection.Interchange) (map[string]interface{}, error) {
+	store, err := slashingprotection.DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Import(interchange); err != nil {
+		return nil, err
+	}
+
+	imported := 0
+	for _, signer := range interchange.Data {
+		imported += len(signer.SignedValsets) + len(signer.SignedBatches)
+	}
+
+	return map[string]interface{}{
+		"imported_records": imported,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package queries
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+)
+
+// GetLogs returns the most recent lines of the log store matching the query.
+func GetLogs(ctx context.Context, global *global.Global, query logs.Query) ([]logs.Record, error) {
+	store := logs.GetStore()
+	if store == nil {
+		return nil, errors.New("log store is disabled")
+	}
+	return store.Query(query)
+}
+
+// GetLogLevels returns the default log level and the levels overridden per chain and loop.
+func GetLogLevels(ctx context.Context, global *global.Global) (map[string]interface{}, error) {
+	levels := logs.GetLevels()
+	return map[string]interface{}{
+		"default":   levels.Default().String(),
+		"overrides": levels.Overrides(),
+	}, nil
+}
+
+// SetLogLevel sets the log level of a loop of a chain at runtime, a zero chainId applies to
+// every chain and an empty loop to every loop. The "reset" level removes the override, and
+// without chain nor loop the level is the new default.
+func SetLogLevel(ctx context.Context, global *global.Global, chainId uint64, loop string, level string) (map[string]interface{}, error) {
+	levels := logs.GetLevels()
+	if level == "reset" {
+		levels.Reset(chainId, loop)
+		return GetLogLevels(ctx, global)
+	}
+
+	parsed, err := log.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if chainId == 0 && loop == "" {
+		levels.SetDefault(parsed)
+	} else {
+		levels.Set(chainId, loop, parsed)
+	}
+	return GetLogLevels(ctx, global)
+}
@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// RunMigration starts or resumes the migration of the chain to a new Hyperion contract. On
+// failure the state is returned with the error so that the caller knows the stage to
+// resume from.
+func RunMigration(ctx context.Context, global *global.Global, request *global.MigrationRequest) (*global.MigrationState, error) {
+	return global.RunMigration(ctx, request)
+}
+
+// PlanMigration returns the steps left of the migration of the chain without sending any tx.
+func PlanMigration(ctx context.Context, global *global.Global, chainId uint64) (*global.MigrationPlan, error) {
+	return global.PlanMigration(ctx, chainId)
+}
+
+func GetMigration(ctx context.Context, global *global.Global, chainId uint64) (*global.MigrationState, error) {
+	return global.GetMigration(chainId)
+}
+
+// ResetMigration forgets the migration state of the chain, the next run starts over from
+// pausing deposits.
+func ResetMigration(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
+	if err := global.ResetMigration(chainId); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"chainId": chainId,
+		"reset":   true,
+	}, nil
+}
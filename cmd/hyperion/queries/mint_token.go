@@ -9,7 +9,7 @@ import (
 )
 
 func MintToken(ctx context.Context, global *global.Global, chainId uint64, tokenAddress string, decimals uint64, amount float64, receiverAddress string) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
 	amountMath, err := utils.FormatAmount(amount, decimals)
 	if err != nil {
@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+)
+
+// GetOutflowHolds returns the outflow limits of the chain, the batches held by them and
+// the batches counted in the current windows.
+func GetOutflowHolds(ctx context.Context, global *global.Global, chainId uint64) (map[string]interface{}, error) {
+	limits, err := orchestrator.OutflowLimits(chainId)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := orchestrator.OutflowLedger(chainId)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"limits":   limits,
+		"held":     ledger.Pending(),
+		"holds":    ledger.Holds,
+		"outflows": ledger.Outflows,
+	}, nil
+}
+
+// ApproveHeldBatch lets a held batch through the outflow limits on the next signer and
+// relayer runs.
+func ApproveHeldBatch(ctx context.Context, global *global.Global, chainId uint64, tokenContract string, batchNonce uint64) (map[string]interface{}, error) {
+	ledger, err := orchestrator.ApproveHeldBatch(chainId, tokenContract, batchNonce)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"chainId":       chainId,
+		"tokenContract": tokenContract,
+		"batchNonce":    batchNonce,
+		"approved":      true,
+		"held":          ledger.Pending(),
+	}, nil
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func PauseOrUnpauseWithdrawal(ctx context.Context, global *global.Global, chainId uint64, pause bool) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	msg, err := network.PauseOrUnpauseHyperionWithdrawalMsg(ctx, chainId, pause)
 	if err != nil {
 		return nil, err
@@ -30,7 +30,7 @@ func PauseOrUnpauseWithdrawal(ctx context.Context, global *global.Global, chainI
 }
 
 func PauseOrUnpauseDeposit(ctx context.Context, global *global.Global, chainId uint64, pause bool) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	counterpartyChainParams, err := network.GetCounterpartyChainParamsByChainId(ctx, chainId)
 	if err != nil {
 		return nil, err
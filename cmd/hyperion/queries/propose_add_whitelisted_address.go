@@ -9,7 +9,7 @@ import (
 )
 
 func ProposeAddWhitelistedAddress(ctx context.Context, global *global.Global, title string, description string, chainId uint64, address string) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	hyperionParams, err := network.HyperionParams(ctx)
 	if err != nil {
 		return nil, err
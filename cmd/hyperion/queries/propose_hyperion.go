@@ -10,8 +10,8 @@ import (
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 )
 
-func ProposeHyperion(ctx context.Context, global *global.Global, title string, description string, bridgeChainId uint64, bridgeChainName string, averageCounterpartyBlockTime uint64) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+func ProposeHyperion(ctx context.Context, global *global.Global, title string, description string, bridgeChainId uint64, bridgeChainName string, averageCounterpartyBlockTime uint64, dryRun bool) (map[string]interface{}, error) {
+	network := global.GetHeliosNetwork()
 	hyperionParams, err := network.HyperionParams(ctx)
 	if err != nil {
 		return nil, err
@@ -33,7 +33,7 @@ func ProposeHyperion(ctx context.Context, global *global.Global, title string, d
 	hyperionAddress := hyperionContractInfo["hyperionAddress"].(string)
 	startHeight := uint64(hyperionContractInfo["initializedAtBlockNumber"].(float64))
 
-	proposalId, err := global.CreateNewBlockchainProposal(title, description, &hyperiontypes.CounterpartyChainParams{
+	proposedParams := &hyperiontypes.CounterpartyChainParams{
 		HyperionId:                   bridgeChainId,
 		BridgeChainId:                bridgeChainId,
 		BridgeChainName:              bridgeChainName,
@@ -41,7 +41,20 @@ func ProposeHyperion(ctx context.Context, global *global.Global, title string, d
 		AverageCounterpartyBlockTime: averageCounterpartyBlockTime,
 		BridgeCounterpartyAddress:    hyperionAddress,
 		BridgeContractStartHeight:    startHeight + 1, // +1 because the start height is the first block who hyperion will start listening
-	})
+	}
+
+	diff, err := global.PreviewNewBlockchainProposal(ctx, proposedParams)
+	if dryRun {
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"dryRun": true,
+			"diff":   diff,
+		}, nil
+	}
+
+	proposalId, err := global.CreateNewBlockchainProposal(title, description, proposedParams)
 
 	if err != nil {
 		return nil, err
@@ -57,5 +70,6 @@ func ProposeHyperion(ctx context.Context, global *global.Global, title string, d
 
 	return map[string]interface{}{
 		"proposalId": proposalId,
+		"diff":       diff,
 	}, nil
 }
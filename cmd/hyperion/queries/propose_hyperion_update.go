@@ -10,8 +10,8 @@ import (
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 )
 
-func ProposeHyperionUpdate(ctx context.Context, global *global.Global, title string, description string, bridgeChainId uint64, bridgeChainName string, averageCounterpartyBlockTime uint64) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+func ProposeHyperionUpdate(ctx context.Context, global *global.Global, title string, description string, bridgeChainId uint64, bridgeChainName string, averageCounterpartyBlockTime uint64, dryRun bool) (map[string]interface{}, error) {
+	network := global.GetHeliosNetwork()
 	hyperionParams, err := network.HyperionParams(ctx)
 	if err != nil {
 		return nil, err
@@ -43,7 +43,7 @@ func ProposeHyperionUpdate(ctx context.Context, global *global.Global, title str
 	hyperionAddress := hyperionContractInfo["hyperionAddress"].(string)
 	startHeight := uint64(hyperionContractInfo["initializedAtBlockNumber"].(float64))
 
-	proposalId, err := global.ProposeHyperionUpdate(title, description, &hyperiontypes.CounterpartyChainParams{
+	proposedParams := &hyperiontypes.CounterpartyChainParams{
 		HyperionId:                   bridgeChainId,
 		BridgeChainId:                bridgeChainId,
 		BridgeChainName:              bridgeChainName,
@@ -51,7 +51,20 @@ func ProposeHyperionUpdate(ctx context.Context, global *global.Global, title str
 		AverageCounterpartyBlockTime: averageCounterpartyBlockTime,
 		BridgeCounterpartyAddress:    hyperionAddress,
 		BridgeContractStartHeight:    startHeight + 1, // +1 because the start height is the first block who hyperion will start listening
-	})
+	}
+
+	diff, err := global.PreviewHyperionUpdate(ctx, proposedParams)
+	if dryRun {
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"dryRun": true,
+			"diff":   diff,
+		}, nil
+	}
+
+	proposalId, err := global.ProposeHyperionUpdate(title, description, proposedParams)
 
 	if err != nil {
 		return nil, err
@@ -67,5 +80,6 @@ func ProposeHyperionUpdate(ctx context.Context, global *global.Global, title str
 
 	return map[string]interface{}{
 		"proposalId": proposalId,
+		"diff":       diff,
 	}, nil
 }
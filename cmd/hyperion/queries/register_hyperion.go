@@ -11,9 +11,9 @@ import (
 )
 
 func RegisterHyperion(ctx context.Context, global *global.Global, chainId uint64) error {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
-	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(*global.GetHeliosNetwork(), global.GetAddress())
+	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(global.GetHeliosNetwork(), global.GetAddress())
 	if slices.Contains(registeredNetworks, chainId) {
 		return fmt.Errorf("hyperion already registered for chain %d", chainId)
 	}
@@ -11,8 +11,10 @@ import (
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/pricefeed"
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
+	log "github.com/xlab/suplog"
 )
 
 func min(a, b time.Duration) time.Duration {
@@ -23,13 +25,13 @@ func min(a, b time.Duration) time.Duration {
 }
 
 func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) error {
-	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(*global.GetHeliosNetwork(), global.GetAddress())
+	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(global.GetHeliosNetwork(), global.GetAddress())
 
 	if !slices.Contains(registeredNetworks, chainId) {
 		return fmt.Errorf("chainId %d is not registered", chainId)
 	}
 
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	counterpartyChainParams, err := network.GetCounterpartyChainParamsByChainId(ctx, chainId)
 	if err != nil {
 		return err
@@ -50,7 +52,7 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 	}
 
 	orchestratorCfg := orchestrator.Config{
-		EnabledLogs:          "signer,relayer,oracle,batch-creator",
+		EnabledLogs:          global.GetConfig().EnabledLogs,
 		ChainId:              counterpartyChainParams.BridgeChainId,
 		ChainName:            counterpartyChainParams.BridgeChainName,
 		HyperionId:           uint64(counterpartyChainParams.HyperionId),
@@ -68,14 +70,17 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 		ChainParams:          counterpartyChainParams,
 	}
 
-	fmt.Println("run Hyperion FUNC")
+	logger := log.WithFields(log.Fields{
+		logs.FieldChain:   counterpartyChainParams.BridgeChainName,
+		logs.FieldChainId: chainId,
+		logs.FieldLoop:    "runner",
+	})
 
 	// Create a channel to signal when the runner is set
 	runnerSet := make(chan struct{}, 1)
 	startErr := make(chan error, 1)
 
 	go func() {
-		fmt.Println("run orchestrator")
 		baseDelay, _ := time.ParseDuration("10s")
 		maxDelay, _ := time.ParseDuration("5m")
 		currentDelay := baseDelay
@@ -85,24 +90,24 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 			// Create new cancellable context for each iteration
 			ctxCancellable, cancel := context.WithCancelCause(ctx)
 
-			fmt.Println("Starting orchestrator")
+			logger.Infoln("starting orchestrator")
 
 			// Initialize new target network
 			targetNetworks, err := global.InitTargetNetworks(counterpartyChainParams)
 			if err != nil {
-				fmt.Println("Error initializing target network:", err)
+				logger.WithError(err).Errorln("failed to initialize the target network")
 				cancel(fmt.Errorf("error initializing target network"))
 				if !sleepCtx(ctx, currentDelay) {
-					fmt.Println("Cancel during backoff")
+					logger.Infoln("cancelled during backoff")
 					cancel(fmt.Errorf("context cancelled during backoff"))
 					return
 				}
 				currentDelay = min(currentDelay*2, maxDelay)
 				consecutiveErrors++
 				if consecutiveErrors > 5 {
-					fmt.Println("Too many consecutive errors, waiting for max delay")
+					logger.WithField("errors", consecutiveErrors).Warningln("too many consecutive errors, waiting for max delay")
 					if !sleepCtx(ctx, maxDelay) {
-						fmt.Println("Cancel during max delay")
+						logger.Infoln("cancelled during max delay")
 						cancel(fmt.Errorf("context cancelled during max delay"))
 						return
 					}
@@ -125,19 +130,19 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 				global,
 			)
 			if err != nil {
-				fmt.Println("Error creating new orchestrator:", err)
+				logger.WithError(err).Errorln("failed to create the orchestrator")
 				cancel(fmt.Errorf("error creating new orchestrator"))
 				if !sleepCtx(ctx, currentDelay) {
-					fmt.Println("Cancel during backoff")
+					logger.Infoln("cancelled during backoff")
 					cancel(fmt.Errorf("context cancelled during backoff"))
 					return
 				}
 				currentDelay = min(currentDelay*2, maxDelay)
 				consecutiveErrors++
 				if consecutiveErrors > 5 {
-					fmt.Println("Too many consecutive errors, waiting for max delay")
+					logger.WithField("errors", consecutiveErrors).Warningln("too many consecutive errors, waiting for max delay")
 					if !sleepCtx(ctx, maxDelay) {
-						fmt.Println("Cancel during max delay")
+						logger.Infoln("cancelled during max delay")
 						cancel(fmt.Errorf("context cancelled during max delay"))
 						return
 					}
@@ -163,7 +168,7 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 			go func() {
 				defer func() {
 					if r := recover(); r != nil {
-						fmt.Printf("Recovered from panic in hyperion.Run: %v\n", r)
+						logger.WithField("panic", r).Errorln("recovered from panic in hyperion.Run")
 						errChan <- fmt.Errorf("panic: %v", r)
 					}
 				}()
@@ -176,30 +181,30 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 			select {
 			case err := <-errChan:
 				if err != nil {
-					fmt.Printf("Orchestrator error: %v, restarting after delay...\n", err)
+					logger.WithError(err).WithField("delay", currentDelay.String()).Errorln("orchestrator failed, restarting after delay")
 					cancel(fmt.Errorf("error running orchestrator"))
 					if !sleepCtx(ctx, currentDelay) {
-						fmt.Println("Cancel during backoff")
+						logger.Infoln("cancelled during backoff")
 						cancel(fmt.Errorf("context cancelled during backoff"))
 						return
 					}
 					currentDelay = min(currentDelay*2, maxDelay)
 					consecutiveErrors++
 					if consecutiveErrors > 5 {
-						fmt.Println("Too many consecutive errors, waiting for max delay")
+						logger.WithField("errors", consecutiveErrors).Warningln("too many consecutive errors, waiting for max delay")
 						if !sleepCtx(ctx, maxDelay) {
-							fmt.Println("Cancel during max delay")
+							logger.Infoln("cancelled during max delay")
 							cancel(fmt.Errorf("context cancelled during max delay"))
 							return
 						}
 					}
 				} else {
-					fmt.Println("Orchestrator stopped normally, exiting...")
+					logger.Infoln("orchestrator stopped normally")
 					cancel(fmt.Errorf("orchestrator stopped normally"))
 					return
 				}
 			case <-ctx.Done():
-				fmt.Println("Context cancelled, stopping orchestrator, cause:", context.Cause(ctx))
+				logger.WithField("cause", context.Cause(ctx)).Infoln("context cancelled, stopping orchestrator")
 				cancel(fmt.Errorf("context cancelled"))
 				return
 			}
@@ -211,7 +216,7 @@ func RunHyperion(ctx context.Context, global *global.Global, chainId uint64) err
 	case <-runnerSet:
 		return nil
 	case err := <-startErr:
-		fmt.Println("Error starting orchestrator, exiting...", err)
+		logger.WithError(err).Errorln("failed to start orchestrator")
 		return err
 	case <-ctx.Done():
 		return ctx.Err()
@@ -12,6 +12,6 @@ func StopHyperion(ctx context.Context, global *global.Global, chainId uint64) er
 	if runner == nil {
 		return fmt.Errorf("runner for chainId %d not found", chainId)
 	}
-	global.CancelRunner(chainId)
-	return nil
+	// the runner drains its work in flight before it stops
+	return global.StopRunner(chainId)
 }
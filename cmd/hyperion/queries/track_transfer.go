@@ -0,0 +1,14 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/transfers"
+)
+
+// TrackTransfer returns the lifecycle of the transfers of a source tx hash, an event
+// nonce or a Helios tx id, and the stage each of them is at.
+func TrackTransfer(ctx context.Context, global *global.Global, query global.TransferQuery) ([]*transfers.Lifecycle, error) {
+	return global.TrackTransfer(ctx, query)
+}
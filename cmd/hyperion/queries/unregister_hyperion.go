@@ -10,17 +10,20 @@ import (
 )
 
 func UnRegisterHyperion(ctx context.Context, global *global.Global, chainId uint64) error {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
 	// stop hyperion if it is running
 	runner := global.GetRunner(chainId)
 	if runner != nil {
+		if _, err := global.DrainRunner(chainId); err != nil {
+			fmt.Println("Error draining hyperion for chain", chainId, err)
+		}
 		global.CancelRunner(chainId)
 		fmt.Println("Hyperion stopped successfully for chain", chainId)
 	}
 
 	// check if hyperion is registered
-	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(*global.GetHeliosNetwork(), global.GetAddress())
+	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(global.GetHeliosNetwork(), global.GetAddress())
 	if !slices.Contains(registeredNetworks, chainId) {
 		return fmt.Errorf("hyperion not registered for chain %d", chainId)
 	}
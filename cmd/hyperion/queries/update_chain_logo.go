@@ -8,7 +8,7 @@ import (
 )
 
 func UpdateChainLogo(ctx context.Context, global *global.Global, chainId uint64, logo string) map[string]interface{} {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	msg, err := network.UpdateChainLogoMsg(ctx, chainId, logo)
 	if err != nil {
 		return map[string]interface{}{
@@ -13,9 +13,9 @@ import (
 )
 
 func UpdateFeeHyperion(ctx context.Context, global *global.Global, minTxFeeHLS float64, minBatchFeeHLS float64, chainId uint64) error {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 
-	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(*global.GetHeliosNetwork(), global.GetAddress())
+	registeredNetworks, _ := helios.GetListOfNetworksWhereRegistered(global.GetHeliosNetwork(), global.GetAddress())
 	if !slices.Contains(registeredNetworks, chainId) {
 		return nil // no need to update
 	}
@@ -10,7 +10,7 @@ import (
 )
 
 func UploadLogo(ctx context.Context, global *global.Global, logobase64 string) map[string]interface{} {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	msg, err := network.StoreLogoMsg(ctx, logobase64)
 	if err != nil {
 		return map[string]interface{}{
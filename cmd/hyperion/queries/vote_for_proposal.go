@@ -9,7 +9,7 @@ import (
 )
 
 func VoteForProposal(ctx context.Context, global *global.Global, proposalId uint64, voteOption govtypes.VoteOption) (map[string]interface{}, error) {
-	network := *global.GetHeliosNetwork()
+	network := global.GetHeliosNetwork()
 	msg, err := network.VoteOnProposalWithOptionMsg(ctx, proposalId, global.GetCosmosAddress(), voteOption)
 	if err != nil {
 		return map[string]interface{}{
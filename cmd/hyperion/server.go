@@ -6,17 +6,20 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
-	"os/signal"
 	"runtime"
 	"runtime/pprof"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/Helios-Chain-Labs/hyperion/cmd/hyperion/queries"
 	"github.com/Helios-Chain-Labs/hyperion/cmd/hyperion/static"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/autovote"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/breaker"
 	globaltypes "github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/slashingprotection"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/version"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
@@ -90,6 +93,26 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// streamAuthMiddleware also accepts a stream token as the token query parameter, an
+// EventSource cannot set the X-Password header. The password itself is never taken from
+// the URL, which ends up in access logs and browser history.
+func streamAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	auth := authMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Password") == "" {
+			if token := r.URL.Query().Get("token"); token != "" {
+				if !streamTokens.valid(token, time.Now()) {
+					sendError(w, "Invalid or expired stream token", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		auth(w, r)
+	}
+}
+
 func startServer(cmd *cli.Cmd) {
 	cmd.Before = func() {
 		initMetrics(cmd)
@@ -101,6 +124,13 @@ func startServer(cmd *cli.Cmd) {
 		// ensure a clean exit
 		defer closer.Close()
 
+		if err := initLogs(cfg); err != nil {
+			log.WithError(err).Fatalln("failed to set up the logs")
+		}
+		if err := initTracing(cfg); err != nil {
+			log.WithError(err).Fatalln("failed to set up the tracing")
+		}
+
 		router := mux.NewRouter()
 		router.Use(loggingMiddleware)
 
@@ -111,9 +141,21 @@ func startServer(cmd *cli.Cmd) {
 			TendermintRPC:         *cfg.tendermintRPC,
 			HeliosGasPrices:       *cfg.heliosGasPrices,
 			HeliosGas:             *cfg.heliosGas,
+			HeliosGasMultiplier:   *cfg.heliosGasMultiplier,
 			EthGasPriceAdjustment: *cfg.ethGasPriceAdjustment,
 			EthMaxGasPrice:        *cfg.ethMaxGasPrice,
 			PendingTxWaitDuration: *cfg.pendingTxWaitDuration,
+
+			SanctionsListPath:        *cfg.sanctionsListPath,
+			SanctionsRefreshInterval: *cfg.sanctionsRefreshInterval,
+			DrainTimeout:             *cfg.drainTimeout,
+			EnabledLogs:              *cfg.enabledLogs,
+
+			EthRemoteSignerURL:        *cfg.ethRemoteSignerURL,
+			EthRemoteSignerAddress:    *cfg.ethRemoteSignerAddress,
+			EthRemoteSignerCACert:     *cfg.ethRemoteSignerCACert,
+			EthRemoteSignerClientCert: *cfg.ethRemoteSignerClientCert,
+			EthRemoteSignerClientKey:  *cfg.ethRemoteSignerClientKey,
 		})
 		heliosNetwork := global.GetHeliosNetwork()
 		if heliosNetwork == nil {
@@ -121,15 +163,21 @@ func startServer(cmd *cli.Cmd) {
 		}
 
 		// ctx, cancelFn := context.WithCancel(context.Background())
-		rootCtx, _ := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		rootCtx, rootCancel := context.WithCancel(context.Background())
 
-		// never use rootStop, it will cause the server to hang when the context is cancelled
-		// closer.Bind(rootStop)
+		// on SIGTERM closer drains the runners before their context is cancelled and the
+		// process exits, the relays in flight are not interrupted
+		closer.Bind(func() {
+			global.DrainRunners()
+			global.Stop()
+			rootCancel()
+		})
 
 		// API endpoints first
 		apiRouter := router.PathPrefix("/api").Subrouter()
 		apiRouter.HandleFunc("/query", authMiddleware(injectGlobalMiddleware(handleQueryGet, global, rootCtx))).Methods("GET")
 		apiRouter.HandleFunc("/query", authMiddleware(injectGlobalMiddleware(handleQueryPost, global, rootCtx))).Methods("POST")
+		apiRouter.HandleFunc("/stream", streamAuthMiddleware(injectGlobalMiddleware(handleStream, global, rootCtx))).Methods("GET")
 		apiRouter.HandleFunc("/version", handleVersion).Methods("GET")
 		apiRouter.HandleFunc("/debug-goroutines", handleDebugGoroutines).Methods("GET")
 		apiRouter.HandleFunc("/debug-goroutines-stats", handleDebugGoroutinesStats).Methods("GET")
@@ -373,6 +421,272 @@ func handleQueryGet(w http.ResponseWriter, r *http.Request) {
 		}
 		sendSuccess(w, proposals, nil)
 		return
+	case "get-blocked-batches":
+		chainId := uint64(0)
+		if query.Get("chain_id") != "" {
+			parsedChainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+			if err != nil {
+				sendError(w, "Invalid chain_id", http.StatusBadRequest)
+				return
+			}
+			chainId = parsedChainId
+		}
+		blockedBatches, err := queries.GetBlockedBatches(r.Context(), global, chainId, query.Get("all") == "true")
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, blockedBatches, nil)
+		return
+	case "get-signatures":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		nonce := uint64(0)
+		if query.Get("nonce") != "" {
+			parsedNonce, err := strconv.ParseUint(query.Get("nonce"), 10, 64)
+			if err != nil {
+				sendError(w, "Invalid nonce", http.StatusBadRequest)
+				return
+			}
+			nonce = parsedNonce
+		}
+		signatures, err := queries.GetSignatures(r.Context(), global, chainId, query.Get("kind"), nonce, query.Get("token_contract"))
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, signatures, nil)
+		return
+	case "get-outflow-holds":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		holds, err := queries.GetOutflowHolds(r.Context(), global, chainId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, holds, nil)
+		return
+	case "get-participation":
+		chainId := uint64(0)
+		if query.Get("chain_id") != "" {
+			parsedChainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+			if err != nil {
+				sendError(w, "Invalid chain_id", http.StatusBadRequest)
+				return
+			}
+			chainId = parsedChainId
+		}
+		participation, err := queries.GetParticipation(r.Context(), global, chainId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendSuccess(w, participation, nil)
+		return
+	case "track-transfer":
+		transferQuery := globaltypes.TransferQuery{TxHash: query.Get("tx_hash")}
+		for param, value := range map[string]*uint64{"chain_id": &transferQuery.ChainId, "nonce": &transferQuery.EventNonce, "tx_id": &transferQuery.TxId} {
+			if query.Get(param) == "" {
+				continue
+			}
+			parsed, err := strconv.ParseUint(query.Get(param), 10, 64)
+			if err != nil {
+				sendError(w, "Invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*value = parsed
+		}
+		lifecycles, err := queries.TrackTransfer(r.Context(), global, transferQuery)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendSuccess(w, lifecycles, nil)
+		return
+	case "get-indexed-events":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		indexQuery := indexer.Query{Kind: query.Get("kind"), Address: query.Get("address"), Token: query.Get("token")}
+		for param, value := range map[string]*uint64{"nonce": &indexQuery.Nonce, "from_block": &indexQuery.FromBlock, "to_block": &indexQuery.ToBlock} {
+			if query.Get(param) == "" {
+				continue
+			}
+			parsed, err := strconv.ParseUint(query.Get(param), 10, 64)
+			if err != nil {
+				sendError(w, "Invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*value = parsed
+		}
+		for param, value := range map[string]*int{"page": &indexQuery.Page, "size": &indexQuery.Size} {
+			if query.Get(param) == "" {
+				continue
+			}
+			parsed, err := strconv.Atoi(query.Get(param))
+			if err != nil {
+				sendError(w, "Invalid "+param, http.StatusBadRequest)
+				return
+			}
+			*value = parsed
+		}
+		page, err := queries.GetIndexedEvents(r.Context(), global, chainId, indexQuery)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendSuccess(w, page, nil)
+		return
+	case "get-outbox":
+		outbox, err := queries.GetOutbox(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, outbox, nil)
+		return
+	case "get-logs":
+		logQuery := logs.Query{Loop: query.Get("loop"), Level: query.Get("level"), Search: query.Get("search"), Limit: 200}
+		if value := query.Get("chain_id"); value != "" {
+			chainId, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				sendError(w, "Invalid chain_id", http.StatusBadRequest)
+				return
+			}
+			logQuery.ChainId = chainId
+		}
+		if value := query.Get("since"); value != "" {
+			since, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				sendError(w, "Invalid since, expected RFC3339", http.StatusBadRequest)
+				return
+			}
+			logQuery.Since = since
+		}
+		if value := query.Get("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				sendError(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+			logQuery.Limit = limit
+		}
+		records, err := queries.GetLogs(r.Context(), global, logQuery)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, records, nil)
+		return
+	case "get-log-levels":
+		levels, err := queries.GetLogLevels(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, levels, nil)
+		return
+	case "get-helios-endpoints":
+		endpoints, err := queries.GetHeliosEndpoints(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, endpoints, nil)
+		return
+	case "get-deployment":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		deployment, err := queries.GetDeployment(r.Context(), global, chainId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendSuccess(w, deployment, nil)
+		return
+	case "get-migration":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		migration, err := queries.GetMigration(r.Context(), global, chainId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendSuccess(w, migration, nil)
+		return
+	case "get-proposal-diff":
+		proposalId, err := strconv.ParseUint(query.Get("proposal_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid proposal_id", http.StatusBadRequest)
+			return
+		}
+		diff, err := queries.GetProposalDiff(r.Context(), global, proposalId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, diff, nil)
+		return
+	case "get-circuit-breaker":
+		chainId, err := strconv.ParseUint(query.Get("chain_id"), 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chain_id", http.StatusBadRequest)
+			return
+		}
+		circuitBreaker, err := queries.GetCircuitBreaker(r.Context(), global, chainId)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, circuitBreaker, nil)
+		return
+	case "get-auto-vote-rules":
+		config, err := queries.GetAutoVoteRules(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, config, nil)
+		return
+	case "get-auto-vote-plan":
+		plan, err := queries.GetAutoVotePlan(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, plan, nil)
+		return
+	case "get-auto-votes":
+		votes, err := queries.GetAutoVotes(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, votes, nil)
+		return
+	case "export-slashing-protection":
+		interchange, err := queries.ExportSlashingProtection(r.Context(), global)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, interchange, nil)
+		return
 	}
 	sendSuccess(w, "404", nil)
 }
@@ -448,6 +762,86 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 		}
 		sendSuccess(w, hyperionContractInfo, nil)
 		return
+	case "run-deployment":
+		var request globaltypes.DeploymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.ChainId == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		deployment, err := queries.RunDeployment(r.Context(), global, &request)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(Response{
+				Success: false,
+				Data:    deployment,
+				Error:   err.Error(),
+			})
+			return
+		}
+		sendSuccess(w, deployment, nil)
+		return
+	case "reset-deployment":
+		var params struct {
+			ChainID uint64 `json:"chain_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChainID == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.ResetDeployment(r.Context(), global, params.ChainID)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "run-migration":
+		var params struct {
+			globaltypes.MigrationRequest
+			DryRun bool `json:"dry_run"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChainId == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if params.DryRun {
+			plan, err := queries.PlanMigration(r.Context(), global, params.ChainId)
+			if err != nil {
+				sendError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			sendSuccess(w, plan, nil)
+			return
+		}
+		migration, err := queries.RunMigration(r.Context(), global, &params.MigrationRequest)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(Response{
+				Success: false,
+				Data:    migration,
+				Error:   err.Error(),
+			})
+			return
+		}
+		sendSuccess(w, migration, nil)
+		return
+	case "reset-migration":
+		var params struct {
+			ChainID uint64 `json:"chain_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChainID == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.ResetMigration(r.Context(), global, params.ChainID)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
 	case "propose-hyperion":
 		var params struct {
 			Title                        string `json:"title"`
@@ -455,13 +849,14 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 			BridgeChainId                uint64 `json:"bridge_chain_id"`
 			BridgeChainName              string `json:"bridge_chain_name"`
 			AverageCounterpartyBlockTime uint64 `json:"average_counterparty_block_time"`
+			DryRun                       bool   `json:"dry_run"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 			sendError(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
-		response, err := queries.ProposeHyperion(r.Context(), global, params.Title, params.Description, params.BridgeChainId, params.BridgeChainName, params.AverageCounterpartyBlockTime)
+		response, err := queries.ProposeHyperion(r.Context(), global, params.Title, params.Description, params.BridgeChainId, params.BridgeChainName, params.AverageCounterpartyBlockTime, params.DryRun)
 		if err != nil {
 			sendError(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -475,13 +870,14 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 			BridgeChainId                uint64 `json:"bridge_chain_id"`
 			BridgeChainName              string `json:"bridge_chain_name"`
 			AverageCounterpartyBlockTime uint64 `json:"average_counterparty_block_time"`
+			DryRun                       bool   `json:"dry_run"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 			sendError(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
-		response, err := queries.ProposeHyperionUpdate(r.Context(), global, params.Title, params.Description, params.BridgeChainId, params.BridgeChainName, params.AverageCounterpartyBlockTime)
+		response, err := queries.ProposeHyperionUpdate(r.Context(), global, params.Title, params.Description, params.BridgeChainId, params.BridgeChainName, params.AverageCounterpartyBlockTime, params.DryRun)
 		if err != nil {
 			sendError(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -629,7 +1025,7 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 			sendError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		sendSuccess(w, "Hyperion stopped successfully for chain "+strconv.FormatUint(params.ChainID, 10), nil)
+		sendSuccess(w, "Hyperion draining for chain "+strconv.FormatUint(params.ChainID, 10)+", it stops once the work in flight settled", nil)
 		return
 	case "register-hyperion":
 		var params struct {
@@ -813,6 +1209,97 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 		}
 		sendSuccess(w, response, nil)
 		return
+	case "set-auto-vote-rules":
+		var config autovote.Config
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.SetAutoVoteRules(r.Context(), global, &config)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "set-circuit-breaker-config":
+		// omitted fields keep their default value
+		config := breaker.DefaultConfig()
+		if err := json.NewDecoder(r.Body).Decode(config); err != nil {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.SetCircuitBreakerConfig(r.Context(), global, config)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "set-log-level":
+		var params struct {
+			ChainID uint64 `json:"chain_id"`
+			Loop    string `json:"loop"`
+			Level   string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Level == "" {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.SetLogLevel(r.Context(), global, params.ChainID, params.Loop, params.Level)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "approve-held-batch":
+		var params struct {
+			ChainID       uint64 `json:"chain_id"`
+			TokenContract string `json:"token_contract"`
+			BatchNonce    uint64 `json:"batch_nonce"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChainID == 0 || params.TokenContract == "" || params.BatchNonce == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.ApproveHeldBatch(r.Context(), global, params.ChainID, params.TokenContract, params.BatchNonce)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "rearm-circuit-breaker":
+		var params struct {
+			ChainID uint64 `json:"chain_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ChainID == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.RearmCircuitBreaker(r.Context(), global, params.ChainID)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
+	case "mark-proposal-reviewed":
+		var params struct {
+			ProposalID uint64 `json:"proposal_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.ProposalID == 0 {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.MarkProposalReviewed(r.Context(), global, params.ProposalID)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
 	case "mint-token":
 		var params struct {
 			ChainID         uint64  `json:"chain_id"`
@@ -832,6 +1319,28 @@ func handleQueryPost(w http.ResponseWriter, r *http.Request) {
 		}
 		sendSuccess(w, response, nil)
 		return
+	case "stream-token":
+		token, expiresAt, err := streamTokens.issue(time.Now())
+		if err != nil {
+			sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sendSuccess(w, map[string]interface{}{"token": token, "expires_at": expiresAt}, nil)
+		return
+
+	case "import-slashing-protection":
+		var interchange slashingprotection.Interchange
+		if err := json.NewDecoder(r.Body).Decode(&interchange); err != nil {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		response, err := queries.ImportSlashingProtection(r.Context(), global, &interchange)
+		if err != nil {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendSuccess(w, response, nil)
+		return
 	}
 	sendError(w, "Unknown query type", http.StatusBadRequest)
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	globaltypes "github.com/Helios-Chain-Labs/hyperion/orchestrator/global"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
+)
+
+const (
+	// defaultStreamReplay is the number of recent events sent to a client connecting
+	// without a seq to resume from
+	defaultStreamReplay = 100
+	streamKeepAlive     = 15 * time.Second
+	// streamTokenTTL is the time a stream token opens the stream, an EventSource
+	// reconnecting after it needs a new token
+	streamTokenTTL = 5 * time.Minute
+)
+
+// streamTokens are the tokens issued by POST stream-token, they only open /api/stream.
+var streamTokens = &streamTokenSet{tokens: make(map[string]time.Time)}
+
+type streamTokenSet struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+func (s *streamTokenSet) issue(now time.Time) (string, time.Time, error) {
+	bz := make([]byte, 32)
+	if _, err := rand.Read(bz); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(bz)
+	expiresAt := now.Add(streamTokenTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, expiry := range s.tokens {
+		if !now.Before(expiry) {
+			delete(s.tokens, t)
+		}
+	}
+	s.tokens[token] = expiresAt
+	return token, expiresAt, nil
+}
+
+func (s *streamTokenSet) valid(token string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiry, ok := s.tokens[token]
+	return ok && now.Before(expiry)
+}
+
+// handleStream pushes the live status of the chains as Server-Sent Events.
+//
+//	GET /api/stream?chainId=1,11155111&types=status,relay&since=42&replay=100
+//
+// chainId and types filter the events, since resumes after the seq of the last event
+// received, as does the Last-Event-ID header sent by EventSource when it reconnects.
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	global := r.Context().Value(GlobalKey).(*globaltypes.Global)
+	query := r.URL.Query()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendError(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter := stream.Filter{}
+	for _, value := range splitList(query.Get("chainId")) {
+		chainId, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			sendError(w, "Invalid chainId", http.StatusBadRequest)
+			return
+		}
+		filter.ChainIds = append(filter.ChainIds, chainId)
+	}
+	filter.Types = splitList(query.Get("types"))
+
+	since := uint64(0)
+	replay := defaultStreamReplay
+	lastEventId := query.Get("since")
+	if lastEventId == "" {
+		lastEventId = r.Header.Get("Last-Event-ID")
+	}
+	if lastEventId != "" {
+		value, err := strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			sendError(w, "Invalid since", http.StatusBadRequest)
+			return
+		}
+		// resuming, every event missed is replayed
+		since, replay = value, 0
+	}
+	if value := query.Get("replay"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			sendError(w, "Invalid replay", http.StatusBadRequest)
+			return
+		}
+		replay = limit
+		if replay == 0 && since == 0 {
+			// no replay asked, only the events to come
+			since = ^uint64(0)
+		}
+	}
+
+	hub := global.GetStatusStream()
+	sub, events := hub.Subscribe(filter, since, replay)
+	defer hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range events {
+		if err := writeStreamEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				// too slow to follow, the client reconnects with Last-Event-ID
+				return
+			}
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event stream.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+func splitList(value string) []string {
+	values := make([]string, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
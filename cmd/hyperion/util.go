@@ -3,11 +3,15 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
+	"github.com/xlab/closer"
 	log "github.com/xlab/suplog"
 	"google.golang.org/grpc"
 )
@@ -119,3 +123,52 @@ func formatHyperionIds(input string) map[int]int {
 
 	return result
 }
+
+// initLogs writes the logs as structured lines filtered by the runtime levels of their
+// chain and loop, and copies them to the log store.
+func initLogs(cfg Config) error {
+	dir := *cfg.logDir
+	if dir == "" {
+		defaultDir, err := logs.DefaultDir()
+		if err != nil {
+			return err
+		}
+		dir = defaultDir
+	} else if dir == "off" {
+		dir = ""
+	}
+
+	if err := logs.Setup(logs.Config{
+		Level:    logLevel(*appLogLevel),
+		Format:   *cfg.logFormat,
+		Dir:      dir,
+		MaxSize:  int64(*cfg.logMaxSizeMB) * 1024 * 1024,
+		MaxFiles: *cfg.logMaxFiles,
+	}); err != nil {
+		return err
+	}
+	if store := logs.GetStore(); store != nil {
+		closer.Bind(func() { store.Close() })
+	}
+	return nil
+}
+
+// initTracing exports the spans of the orchestrator loops to the OTLP collector, the
+// spans still buffered are flushed on exit.
+func initTracing(cfg Config) error {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    *cfg.otlpEndpoint,
+		SampleRatio: *cfg.traceSampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+	closer.Bind(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.WithError(err).Warningln("failed to flush the traces")
+		}
+	})
+	return nil
+}
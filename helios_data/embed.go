@@ -0,0 +1,10 @@
+package heliosdata
+
+import (
+	_ "embed"
+)
+
+// OFACList is the bundled list of sanctioned addresses (lowercase hex, JSON array).
+//
+//go:embed ofac.json
+var OFACList []byte
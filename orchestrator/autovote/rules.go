@@ -0,0 +1,253 @@
+package autovote
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/pkg/errors"
+)
+
+// Rule casts Option on the proposals matching all of its criteria. An empty criterion
+// matches every proposal.
+type Rule struct {
+	Name string `json:"name"`
+	// MsgTypes matches proposals holding at least one msg of these type urls
+	MsgTypes []string `json:"msg_types,omitempty"`
+	// ChainIds matches proposals whose msgs target one of these counterparty chains
+	ChainIds []uint64 `json:"chain_ids,omitempty"`
+	// Proposers matches proposals submitted by one of these addresses
+	Proposers []string `json:"proposers,omitempty"`
+	// Option is one of yes, no, abstain or no_with_veto
+	Option string `json:"option"`
+	// HoursBeforeDeadline holds the vote until this many hours before the end of the
+	// voting period, and drops it if the operator reviewed the proposal by then
+	HoursBeforeDeadline uint64 `json:"hours_before_deadline,omitempty"`
+}
+
+// Config is the operator-defined auto-vote configuration. Rules are evaluated in order,
+// the first matching rule decides the vote.
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Rules   []Rule `json:"rules"`
+	// Reviewed lists the proposals the operator reviewed, they are left to manual voting
+	// by the rules holding their vote until the deadline
+	Reviewed []uint64 `json:"reviewed"`
+}
+
+// Proposal is the part of a governance proposal the rules are evaluated against.
+type Proposal struct {
+	Id        uint64
+	Title     string
+	Proposer  string
+	MsgTypes  []string
+	ChainIds  []uint64
+	VotingEnd time.Time
+}
+
+// Decision is the vote planned by a rule for a proposal.
+type Decision struct {
+	ProposalId uint64    `json:"proposal_id"`
+	Title      string    `json:"title"`
+	Rule       string    `json:"rule"`
+	Option     string    `json:"option"`
+	CastAfter  time.Time `json:"cast_after"`
+	VotingEnd  time.Time `json:"voting_end"`
+	// Due is true once the vote can be cast
+	Due bool `json:"due"`
+}
+
+func ParseOption(option string) (govtypes.VoteOption, error) {
+	switch strings.ToLower(strings.TrimSpace(option)) {
+	case "yes":
+		return govtypes.OptionYes, nil
+	case "no":
+		return govtypes.OptionNo, nil
+	case "abstain":
+		return govtypes.OptionAbstain, nil
+	case "no_with_veto":
+		return govtypes.OptionNoWithVeto, nil
+	}
+	return govtypes.OptionEmpty, errors.Errorf("unknown vote option %q, expected one of yes, no, abstain, no_with_veto", option)
+}
+
+func (c *Config) Validate() error {
+	for i, rule := range c.Rules {
+		if _, err := ParseOption(rule.Option); err != nil {
+			return errors.Wrapf(err, "rule %d (%s)", i, rule.Name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) IsReviewed(proposalId uint64) bool {
+	for _, reviewed := range c.Reviewed {
+		if reviewed == proposalId {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matches(proposal Proposal) bool {
+	if len(r.MsgTypes) > 0 && !containsAny(r.MsgTypes, proposal.MsgTypes) {
+		return false
+	}
+	if len(r.ChainIds) > 0 {
+		matched := false
+		for _, chainId := range r.ChainIds {
+			for _, proposalChainId := range proposal.ChainIds {
+				matched = matched || chainId == proposalChainId
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Proposers) > 0 && !containsAny(r.Proposers, []string{proposal.Proposer}) {
+		return false
+	}
+	return true
+}
+
+func containsAny(expected []string, values []string) bool {
+	for _, e := range expected {
+		for _, v := range values {
+			if strings.EqualFold(strings.TrimPrefix(e, "/"), strings.TrimPrefix(v, "/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Evaluate returns the vote planned by the first rule matching the proposal, nil when no
+// rule matches or when the matching rule leaves the reviewed proposal to the operator.
+func (c *Config) Evaluate(proposal Proposal, now time.Time) *Decision {
+	for _, rule := range c.Rules {
+		if !rule.matches(proposal) {
+			continue
+		}
+
+		castAfter := now
+		if rule.HoursBeforeDeadline > 0 {
+			if c.IsReviewed(proposal.Id) {
+				return nil
+			}
+			castAfter = proposal.VotingEnd.Add(-time.Duration(rule.HoursBeforeDeadline) * time.Hour)
+		}
+
+		return &Decision{
+			ProposalId: proposal.Id,
+			Title:      proposal.Title,
+			Rule:       rule.Name,
+			Option:     strings.ToLower(rule.Option),
+			CastAfter:  castAfter,
+			VotingEnd:  proposal.VotingEnd,
+			Due:        !now.Before(castAfter),
+		}
+	}
+	return nil
+}
+
+// ChainIdsFromJSON collects the chain_id and bridge_chain_id fields found anywhere in the
+// JSON encoding of a proposal msg.
+func ChainIdsFromJSON(bz []byte) []uint64 {
+	var decoded interface{}
+	if err := json.Unmarshal(bz, &decoded); err != nil {
+		return nil
+	}
+
+	found := make(map[uint64]struct{})
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, field := range v {
+				if key == "chain_id" || key == "bridge_chain_id" || key == "chainId" || key == "bridgeChainId" {
+					if chainId, ok := parseChainId(field); ok {
+						found[chainId] = struct{}{}
+					}
+				}
+				walk(field)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(decoded)
+
+	chainIds := make([]uint64, 0, len(found))
+	for chainId := range found {
+		chainIds = append(chainIds, chainId)
+	}
+	sort.Slice(chainIds, func(i, j int) bool { return chainIds[i] < chainIds[j] })
+	return chainIds
+}
+
+// parseChainId reads a chain id encoded as a JSON number or, for uint64, as a string.
+func parseChainId(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return uint64(v), v > 0
+	case string:
+		chainId, err := strconv.ParseUint(v, 10, 64)
+		return chainId, err == nil && chainId > 0
+	}
+	return 0, false
+}
+
+var configMu sync.Mutex
+
+// DefaultConfigPath returns ~/.heliades/hyperion/autovote.json.
+func DefaultConfigPath() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".heliades", "hyperion", "autovote.json"), nil
+}
+
+// LoadConfig reads the auto-vote configuration, a missing file is a disabled configuration.
+func LoadConfig(path string) (*Config, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	config := &Config{Rules: []Rule{}, Reviewed: []uint64{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read auto-vote config")
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrap(err, "failed to decode auto-vote config")
+	}
+	return config, nil
+}
+
+func SaveConfig(path string, config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create auto-vote config dir")
+	}
+	return os.WriteFile(path, data, 0644)
+}
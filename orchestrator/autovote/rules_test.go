@@ -0,0 +1,44 @@
+package autovote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	config := &Config{
+		Rules: []Rule{
+			{Name: "our chains", MsgTypes: []string{"/helios.hyperion.v1.MsgAddCounterpartyChainParams"}, ChainIds: []uint64{11155111}, Option: "yes"},
+			{Name: "review or abstain", Option: "abstain", HoursBeforeDeadline: 12},
+		},
+	}
+	assert.NoError(t, config.Validate())
+
+	chainAddition := Proposal{Id: 1, MsgTypes: []string{"/helios.hyperion.v1.MsgAddCounterpartyChainParams"}, ChainIds: []uint64{11155111}, VotingEnd: now.Add(48 * time.Hour)}
+	decision := config.Evaluate(chainAddition, now)
+	assert.Equal(t, "our chains", decision.Rule)
+	assert.Equal(t, "yes", decision.Option)
+	assert.True(t, decision.Due)
+
+	// another chain falls through to the deadline rule, not due until 12h before the end
+	other := Proposal{Id: 2, MsgTypes: chainAddition.MsgTypes, ChainIds: []uint64{97}, VotingEnd: now.Add(48 * time.Hour)}
+	decision = config.Evaluate(other, now)
+	assert.Equal(t, "review or abstain", decision.Rule)
+	assert.False(t, decision.Due)
+	assert.True(t, config.Evaluate(other, now.Add(37*time.Hour)).Due)
+
+	// a reviewed proposal is left to the operator
+	config.Reviewed = []uint64{2}
+	assert.Nil(t, config.Evaluate(other, now.Add(37*time.Hour)))
+
+	config.Rules[0].Option = "maybe"
+	assert.Error(t, config.Validate())
+}
+
+func TestChainIdsFromJSON(t *testing.T) {
+	bz := []byte(`{"@type":"/helios.hyperion.v1.MsgAddCounterpartyChainParams","counterparty_chain_params":{"bridge_chain_id":"11155111","hyperion_id":"3"},"chain_id":"11155111","other":[{"chain_id":97}]}`)
+	assert.Equal(t, []uint64{97, 11155111}, ChainIdsFromJSON(bz))
+}
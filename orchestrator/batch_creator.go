@@ -20,19 +20,27 @@ import (
 func (s *Orchestrator) runBatchCreator(ctx context.Context) (err error) {
 	bc := batchCreator{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "batch-creator"),
 	}
 	s.logger.WithField("loop_duration", defaultLoopDur.String()).Debugln("starting BatchCreator...")
 
-	return loops.RunLoop(ctx, s.ethereum, defaultLoopDur, func() error {
+	return loops.RunLoopWithWakeup(ctx, s.ethereum, defaultLoopDur, s.wakeups.batchCreator, func() error {
 		if s.HyperionState.BatchCreatorStatus == "running" {
 			return nil
 		}
+		if s.heliosSyncing(ctx, "batch_creator") {
+			s.setStatus(LoopBatchCreator, HeliosSyncingStatus)
+			return nil
+		}
+		if !s.startWork("batch_creator") {
+			s.setStatus(LoopBatchCreator, DrainingStatus)
+			return nil
+		}
+		defer s.finishWork("batch_creator")
 
 		start := time.Now()
-		s.HyperionState.BatchCreatorStatus = "running"
+		s.setStatus(LoopBatchCreator, "running")
 		err := bc.requestTokenBatches(ctx)
-		s.HyperionState.BatchCreatorStatus = "idle"
+		s.setStatus(LoopBatchCreator, "idle")
 		s.HyperionState.BatchCreatorLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 		s.HyperionState.BatchCreatorNextExecutionTimestamp = uint64(start.Add(defaultLoopDur).Unix())
 		return err
@@ -41,11 +49,10 @@ func (s *Orchestrator) runBatchCreator(ctx context.Context) (err error) {
 
 type batchCreator struct {
 	*Orchestrator
-	logEnabled bool
 }
 
 func (l *batchCreator) Log() log.Logger {
-	return l.logger.WithField("loop", "BatchCreator")
+	return l.logger.WithField("loop", LoopBatchCreator)
 }
 
 func (l *batchCreator) requestTokenBatches(ctx context.Context) error {
@@ -146,19 +153,19 @@ func (l *batchCreator) requestTokenBatch(ctx context.Context, fee *hyperiontypes
 		tokenSymbol, err := l.ethereum.TokenSymbol(ctx, tokenAddress)
 		if err == nil {
 			l.CacheSymbol[tokenAddress] = tokenSymbol
-			l.Orchestrator.HyperionState.BatchCreatorStatus = "batching " + tokenSymbol
+			l.Orchestrator.setStatus(LoopBatchCreator, "batching "+tokenSymbol)
 		} else {
-			l.Orchestrator.HyperionState.BatchCreatorStatus = "batching " + tokenAddress.String()
+			l.Orchestrator.setStatus(LoopBatchCreator, "batching "+tokenAddress.String())
 		}
 	} else {
-		l.Orchestrator.HyperionState.BatchCreatorStatus = "batching " + l.CacheSymbol[tokenAddress]
+		l.Orchestrator.setStatus(LoopBatchCreator, "batching "+l.CacheSymbol[tokenAddress])
 	}
 
 	if fee.TotalFees.LT(minimumBatchFee) {
 		return nil, errors.New("total fees less than minimum batch fee")
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopBatchCreator) {
 		l.Log().WithFields(log.Fields{"token_denom": tokenDenom, "token_addr": tokenAddress.String()}).Infoln("requesting token batch on Helios")
 	}
 
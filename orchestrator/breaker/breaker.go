@@ -0,0 +1,246 @@
+package breaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Tripwires of the circuit breaker.
+const (
+	// TripwireUnmatchedWithdrawal fires on a batch executed on the contract that Helios
+	// does not hold and that we never signed
+	TripwireUnmatchedWithdrawal = "unmatched_withdrawal"
+	// TripwireValsetMismatch fires when the valset of the contract differs from the
+	// Helios valset of the same nonce
+	TripwireValsetMismatch = "valset_mismatch"
+	// TripwireDepositVolume fires when the deposits of a token in the current hour exceed
+	// a multiple of its trailing hourly average
+	TripwireDepositVolume = "deposit_volume"
+)
+
+// Config is the operator-defined circuit breaker configuration. The keys of the
+// orchestrator must be allowed to pause the contract and the withdrawals on Helios.
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// ChainIds restricts the breaker to these chains, empty means every chain
+	ChainIds            []uint64 `json:"chain_ids"`
+	UnmatchedWithdrawal bool     `json:"unmatched_withdrawal"`
+	ValsetMismatch      bool     `json:"valset_mismatch"`
+	DepositVolume       bool     `json:"deposit_volume"`
+	// DepositVolumeMultiplier is the multiple of the trailing hourly average above which
+	// the deposits of the current hour trip the breaker
+	DepositVolumeMultiplier float64 `json:"deposit_volume_multiplier"`
+	// DepositVolumeWindowHours is the number of past hours averaged
+	DepositVolumeWindowHours int `json:"deposit_volume_window_hours"`
+	// AlertWebhookURL receives a JSON POST of the state when the breaker trips
+	AlertWebhookURL string `json:"alert_webhook_url,omitempty"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		ChainIds:                 []uint64{},
+		UnmatchedWithdrawal:      true,
+		ValsetMismatch:           true,
+		DepositVolume:            true,
+		DepositVolumeMultiplier:  10,
+		DepositVolumeWindowHours: 24,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.DepositVolumeMultiplier <= 1 {
+		return errors.Errorf("deposit_volume_multiplier must be greater than 1, got %v", c.DepositVolumeMultiplier)
+	}
+	if c.DepositVolumeWindowHours <= 0 {
+		return errors.Errorf("deposit_volume_window_hours must be positive, got %d", c.DepositVolumeWindowHours)
+	}
+	return nil
+}
+
+// Armed tells whether the tripwire pauses the chain when it fires.
+func (c *Config) Armed(tripwire string, chainId uint64) bool {
+	if !c.Enabled {
+		return false
+	}
+	if len(c.ChainIds) > 0 {
+		found := false
+		for _, id := range c.ChainIds {
+			found = found || id == chainId
+		}
+		if !found {
+			return false
+		}
+	}
+	switch tripwire {
+	case TripwireUnmatchedWithdrawal:
+		return c.UnmatchedWithdrawal
+	case TripwireValsetMismatch:
+		return c.ValsetMismatch
+	case TripwireDepositVolume:
+		return c.DepositVolume
+	}
+	return false
+}
+
+var configMu sync.Mutex
+
+// DefaultConfigPath returns ~/.heliades/hyperion/circuit_breaker.json.
+func DefaultConfigPath() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".heliades", "hyperion", "circuit_breaker.json"), nil
+}
+
+// LoadConfig reads the circuit breaker configuration, a missing file is the disabled
+// default configuration.
+func LoadConfig(path string) (*Config, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	config := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read circuit breaker config")
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrap(err, "failed to decode circuit breaker config")
+	}
+	return config, nil
+}
+
+func SaveConfig(path string, config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create circuit breaker config dir")
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// State is the persisted breaker state of a chain. Once tripped it stays tripped, and
+// the tripwires no longer act, until the operator re-arms it.
+type State struct {
+	ChainId   uint64    `json:"chain_id"`
+	Tripped   bool      `json:"tripped"`
+	Tripwire  string    `json:"tripwire,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	TrippedAt time.Time `json:"tripped_at,omitempty"`
+	// DepositsPauseTx and WithdrawalsPauseTx are empty when the pause was already in
+	// place or failed, see Errors
+	DepositsPauseTx    string    `json:"deposits_pause_tx,omitempty"`
+	WithdrawalsPauseTx string    `json:"withdrawals_pause_tx,omitempty"`
+	Errors             []string  `json:"errors,omitempty"`
+	RearmedAt          time.Time `json:"rearmed_at,omitempty"`
+}
+
+// SendAlert posts the alert as JSON to the webhook.
+func SendAlert(ctx context.Context, url string, alert interface{}) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// VolumeTracker sums the deposits of each token per hour. It is persisted with the last
+// deposit observed, the trailing average is only measured once the tracker ran for a full
+// window.
+type VolumeTracker struct {
+	StartedAt time.Time `json:"started_at"`
+	// Hourly maps a token to its deposited amount per hour, keyed by unix hour
+	Hourly map[string]map[int64]float64 `json:"hourly"`
+	// LastDepositNonce is the event nonce of the last deposit observed
+	LastDepositNonce uint64 `json:"last_deposit_nonce"`
+}
+
+func NewVolumeTracker(startedAt time.Time) *VolumeTracker {
+	return &VolumeTracker{
+		StartedAt: startedAt,
+		Hourly:    make(map[string]map[int64]float64),
+	}
+}
+
+func unixHour(t time.Time) int64 {
+	return t.Unix() / 3600
+}
+
+func (v *VolumeTracker) Observe(token string, amount float64, at time.Time) {
+	if v.Hourly[token] == nil {
+		v.Hourly[token] = make(map[int64]float64)
+	}
+	v.Hourly[token][unixHour(at)] += amount
+}
+
+// Check returns the deposits of the token in the hour of now and the average of the
+// previous windowHours, spiked is true when the current hour exceeds multiplier times the
+// average. A token without deposits in the window has no average and never spikes.
+func (v *VolumeTracker) Check(token string, now time.Time, windowHours int, multiplier float64) (current float64, average float64, spiked bool) {
+	hour := unixHour(now)
+	current = v.Hourly[token][hour]
+
+	if now.Sub(v.StartedAt) < time.Duration(windowHours+1)*time.Hour {
+		return current, 0, false
+	}
+
+	total := float64(0)
+	for h := hour - int64(windowHours); h < hour; h++ {
+		total += v.Hourly[token][h]
+	}
+	average = total / float64(windowHours)
+	return current, average, average > 0 && current > average*multiplier
+}
+
+// Prune drops the hours older than the window.
+func (v *VolumeTracker) Prune(now time.Time, windowHours int) {
+	oldest := unixHour(now) - int64(windowHours)
+	for token, hours := range v.Hourly {
+		for h := range hours {
+			if h < oldest {
+				delete(hours, h)
+			}
+		}
+		if len(hours) == 0 {
+			delete(v.Hourly, token)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeTracker(t *testing.T) {
+	start := time.Unix(3600*1000, 0)
+	tracker := NewVolumeTracker(start)
+
+	// one deposit of 100 per hour for a day
+	for h := 0; h < 24; h++ {
+		tracker.Observe("0xaaaa", 100, start.Add(time.Duration(h)*time.Hour))
+	}
+
+	// the window is not full yet
+	_, _, spiked := tracker.Check("0xaaaa", start.Add(23*time.Hour), 24, 10)
+	assert.False(t, spiked)
+
+	now := start.Add(25 * time.Hour)
+	tracker.Observe("0xaaaa", 900, now)
+	current, average, spiked := tracker.Check("0xaaaa", now, 24, 10)
+	assert.Equal(t, float64(900), current)
+	assert.InDelta(t, float64(2400-100)/24, average, 0.001)
+	assert.False(t, spiked)
+
+	tracker.Observe("0xaaaa", 1000, now)
+	_, _, spiked = tracker.Check("0xaaaa", now, 24, 10)
+	assert.True(t, spiked)
+
+	// a token without history never spikes
+	tracker.Observe("0xbbbb", 1e18, now)
+	_, _, spiked = tracker.Check("0xbbbb", now, 24, 10)
+	assert.False(t, spiked)
+
+	tracker.Prune(now, 24)
+	for h := range tracker.Hourly["0xaaaa"] {
+		assert.GreaterOrEqual(t, h, unixHour(now)-24)
+	}
+}
+
+func TestArmed(t *testing.T) {
+	config := DefaultConfig()
+	assert.NoError(t, config.Validate())
+	assert.False(t, config.Armed(TripwireValsetMismatch, 1))
+
+	config.Enabled = true
+	config.ChainIds = []uint64{11155111}
+	config.DepositVolume = false
+	assert.True(t, config.Armed(TripwireValsetMismatch, 11155111))
+	assert.False(t, config.Armed(TripwireValsetMismatch, 97))
+	assert.False(t, config.Armed(TripwireDepositVolume, 11155111))
+
+	config.DepositVolumeMultiplier = 1
+	assert.Error(t, config.Validate())
+}
@@ -0,0 +1,331 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/breaker"
+	helioshyperion "github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/hyperion"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// circuitBreaker holds the deposit volume history of the chain, persisted with the trip
+// state so that a restart neither re-arms a tripped breaker nor blinds the volume tripwire
+// for a window.
+type circuitBreaker struct {
+	mu sync.Mutex
+	// tripMu serializes the trips of the tripwires, a breaker pauses once
+	tripMu sync.Mutex
+
+	volume *breaker.VolumeTracker
+	// heliosBatchNonces is the highest batch nonce seen on Helios for each token
+	heliosBatchNonces map[string]uint64
+}
+
+func newCircuitBreaker(chainId uint64) *circuitBreaker {
+	volume := breaker.NewVolumeTracker(time.Now())
+	if found, err := storage.GetDepositVolume(chainId, volume); err != nil || !found || volume.Hourly == nil {
+		if err != nil {
+			log.WithError(err).Warningln("failed to load deposit volume history of chain", chainId)
+		}
+		volume = breaker.NewVolumeTracker(time.Now())
+	}
+	return &circuitBreaker{volume: volume, heliosBatchNonces: make(map[string]uint64)}
+}
+
+func (cb *circuitBreaker) observeHeliosBatch(token string, nonce uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if nonce > cb.heliosBatchNonces[token] {
+		cb.heliosBatchNonces[token] = nonce
+	}
+}
+
+func (cb *circuitBreaker) lastHeliosBatchNonce(token string) uint64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.heliosBatchNonces[token]
+}
+
+func (s *Orchestrator) circuitBreakerConfig() (*breaker.Config, error) {
+	path, err := breaker.DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return breaker.LoadConfig(path)
+}
+
+// CircuitBreakerState returns the breaker state of the chain, a breaker that never
+// tripped is returned armed.
+func CircuitBreakerState(chainId uint64) (*breaker.State, error) {
+	state := &breaker.State{ChainId: chainId}
+	if _, err := storage.GetCircuitBreakerState(chainId, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// checkTripwires runs the event tripwires on the events about to be claimed.
+func (l *oracle) checkTripwires(ctx context.Context, events []event) {
+	config, err := l.circuitBreakerConfig()
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to load circuit breaker config")
+		return
+	}
+
+	for _, ev := range events {
+		if e, ok := ev.(*withdrawal); ok {
+			if reason := l.unmatchedWithdrawal(ctx, e); reason != "" {
+				l.tripCircuitBreaker(ctx, config, breaker.TripwireUnmatchedWithdrawal, reason)
+			}
+		}
+	}
+
+	l.checkDepositVolume(ctx, config, events)
+}
+
+// unmatchedWithdrawal returns why an executed batch matches no batch of Helios, empty
+// when it matches or when it cannot be told. The batch of a claimed withdrawal is removed
+// from Helios once observed, so a batch missing from Helios only trips when its nonce is
+// above the last batch nonce seen on Helios for the token and its withdrawal claim is not
+// attested yet.
+func (l *oracle) unmatchedWithdrawal(ctx context.Context, e *withdrawal) string {
+	nonce := e.BatchNonce.Uint64()
+	token := e.Token.Hex()
+	helios := l.GetHelios()
+
+	batch, err := helios.BatchRequestByNonce(ctx, l.cfg.HyperionId, nonce, e.Token)
+	if err == nil && batch != nil {
+		l.breaker.observeHeliosBatch(token, nonce)
+		return ""
+	}
+	if err != nil && !errors.Is(err, helioshyperion.ErrNotFound) {
+		l.Log().WithError(err).Warningln("failed to get batch", nonce, "of executed withdrawal, tripwire skipped")
+		return ""
+	}
+
+	// a batch we signed was a Helios batch, it may have been removed in the meantime
+	signatures, err := storage.GetSignatures(l.cfg.ChainId, signatureTypeBatch, nonce, token)
+	if err != nil || len(signatures) > 0 {
+		return ""
+	}
+
+	if nonce <= l.breaker.lastHeliosBatchNonce(token) {
+		return ""
+	}
+	batches, err := helios.LatestTransactionBatches(ctx, l.cfg.HyperionId)
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to get Helios batches, tripwire skipped")
+		return ""
+	}
+	for _, b := range batches {
+		l.breaker.observeHeliosBatch(gethcommon.HexToAddress(b.TokenContract).Hex(), b.BatchNonce)
+	}
+	if nonce <= l.breaker.lastHeliosBatchNonce(token) {
+		return ""
+	}
+
+	// an attested withdrawal claim was matched with its batch by Helios before the removal
+	lastObservedEventNonce, err := helios.QueryGetLastObservedEventNonce(ctx, l.cfg.HyperionId)
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to get last observed event nonce, tripwire skipped")
+		return ""
+	}
+	if e.EventNonce.Uint64() <= lastObservedEventNonce {
+		l.breaker.observeHeliosBatch(token, nonce)
+		return ""
+	}
+
+	return fmt.Sprintf("batch %d of token %s executed at block %d (event nonce %d) is above the last Helios batch %d of the token and its withdrawal is not attested", nonce, token, e.Raw.BlockNumber, e.EventNonce.Uint64(), l.breaker.lastHeliosBatchNonce(token))
+}
+
+// checkDepositVolume records the deposits at the time of their block, so that deposits
+// claimed late are counted in the hour they were made.
+func (l *oracle) checkDepositVolume(ctx context.Context, config *breaker.Config, events []event) {
+	cb := l.breaker
+	now := time.Now()
+
+	// the block times are read before taking the lock
+	blockTimes := make(map[uint64]time.Time)
+	for _, ev := range events {
+		if e, ok := ev.(*deposit); ok {
+			if _, ok := blockTimes[e.Raw.BlockNumber]; !ok {
+				blockTimes[e.Raw.BlockNumber] = l.blockTime(ctx, e.Raw.BlockNumber, now)
+			}
+		}
+	}
+
+	cb.mu.Lock()
+	spikes := make([]string, 0)
+	observed := make(map[string]time.Time)
+	for _, ev := range events {
+		e, ok := ev.(*deposit)
+		if !ok || e.Nonce() <= cb.volume.LastDepositNonce {
+			continue
+		}
+		cb.volume.LastDepositNonce = e.Nonce()
+		amount, _ := new(big.Float).SetInt(e.Amount).Float64()
+		token := e.TokenContract.Hex()
+		at := blockTimes[e.Raw.BlockNumber]
+		cb.volume.Observe(token, amount, at)
+		if at.After(observed[token]) {
+			observed[token] = at
+		}
+	}
+	for token, at := range observed {
+		current, average, spiked := cb.volume.Check(token, at, config.DepositVolumeWindowHours, config.DepositVolumeMultiplier)
+		if spiked {
+			spikes = append(spikes, fmt.Sprintf("deposits of %s in the last hour are %.0f, %.1fx the trailing average of %.0f", token, current, current/average, average))
+		}
+	}
+	cb.volume.Prune(now, config.DepositVolumeWindowHours)
+	err := storage.SaveDepositVolume(l.cfg.ChainId, cb.volume)
+	cb.mu.Unlock()
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to save deposit volume history")
+	}
+
+	for _, reason := range spikes {
+		l.tripCircuitBreaker(ctx, config, breaker.TripwireDepositVolume, reason)
+	}
+}
+
+// blockTime returns the time of the block, or fallback when the header cannot be read.
+func (l *oracle) blockTime(ctx context.Context, blockNumber uint64, fallback time.Time) time.Time {
+	header, err := l.ethereum.GetHeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil || header == nil {
+		l.Log().WithError(err).Warningln("failed to get header of block", blockNumber, "deposit volume counted at the current time")
+		return fallback
+	}
+	return time.Unix(int64(header.Time), 0)
+}
+
+// tripCircuitBreaker pauses the deposits on the contract and the withdrawals on Helios
+// when the tripwire is armed, and alerts the operator. A disarmed tripwire is only logged.
+func (s *Orchestrator) tripCircuitBreaker(ctx context.Context, config *breaker.Config, tripwire string, reason string) {
+	logger := s.logger.WithFields(log.Fields{"tripwire": tripwire, "reason": reason})
+
+	if !config.Armed(tripwire, s.cfg.ChainId) {
+		logger.Warningln("circuit breaker tripwire fired, not armed for the chain")
+		return
+	}
+
+	// the check and the pauses run under the lock, a concurrent tripwire then finds the
+	// breaker tripped
+	s.breaker.tripMu.Lock()
+	defer s.breaker.tripMu.Unlock()
+
+	state, err := CircuitBreakerState(s.cfg.ChainId)
+	if err != nil {
+		logger.WithError(err).Errorln("failed to load circuit breaker state")
+		return
+	}
+	if state.Tripped {
+		logger.Warningln("circuit breaker tripwire fired, already tripped")
+		return
+	}
+
+	logger.Errorln("circuit breaker tripped, pausing deposits and withdrawals")
+	state.Tripped = true
+	state.Tripwire = tripwire
+	state.Reason = reason
+	state.TrippedAt = time.Now()
+	state.DepositsPauseTx = ""
+	state.WithdrawalsPauseTx = ""
+	state.Errors = []string{}
+	// persisted first so that a restart during the pauses does not pause again
+	if err := storage.SaveCircuitBreakerState(s.cfg.ChainId, state); err != nil {
+		logger.WithError(err).Errorln("failed to save circuit breaker state")
+	}
+
+	if hash, err := s.pauseDeposits(ctx); err != nil {
+		state.Errors = append(state.Errors, "pause deposits: "+err.Error())
+	} else {
+		state.DepositsPauseTx = hash
+	}
+	if hash, err := s.pauseWithdrawals(ctx); err != nil {
+		state.Errors = append(state.Errors, "pause withdrawals: "+err.Error())
+	} else {
+		state.WithdrawalsPauseTx = hash
+	}
+	for _, e := range state.Errors {
+		logger.Errorln("circuit breaker failed to", e)
+	}
+
+	s.setStatus(LoopError, "circuit breaker tripped: "+reason)
+	if err := storage.SaveCircuitBreakerState(s.cfg.ChainId, state); err != nil {
+		logger.WithError(err).Errorln("failed to save circuit breaker state")
+	}
+
+	if config.AlertWebhookURL != "" {
+		if err := breaker.SendAlert(ctx, config.AlertWebhookURL, state); err != nil {
+			logger.WithError(err).Errorln("failed to send circuit breaker alert")
+		}
+	}
+}
+
+func (s *Orchestrator) pauseDeposits(ctx context.Context) (string, error) {
+	paused, err := s.ethereum.IsDepositPaused(ctx)
+	if err != nil {
+		return "", err
+	}
+	if paused {
+		return "", nil
+	}
+	hash, err := s.ethereum.EmergencyPause(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.HyperionState.IsDepositPaused = true
+	return hash.Hex(), nil
+}
+
+func (s *Orchestrator) pauseWithdrawals(ctx context.Context) (string, error) {
+	helios := s.GetHelios()
+	params, err := helios.GetCounterpartyChainParamsByChainId(ctx, s.cfg.ChainId)
+	if err != nil {
+		return "", err
+	}
+	if params.Paused {
+		return "", nil
+	}
+	msg, err := helios.PauseOrUnpauseHyperionWithdrawalMsg(ctx, s.cfg.ChainId, true)
+	if err != nil {
+		return "", err
+	}
+	if err := helios.SyncBroadcastMsgsSimulate(ctx, []sdk.Msg{msg}); err != nil {
+		return "", err
+	}
+	resp, err := s.global.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to broadcast withdrawal pause")
+	}
+	s.HyperionState.IsWithdrawalPaused = true
+	return resp.TxHash, nil
+}
+
+// RearmCircuitBreaker clears the tripped state of the chain so that the tripwires act
+// again. The pauses are left in place, they are lifted separately.
+func RearmCircuitBreaker(chainId uint64) (*breaker.State, error) {
+	state, err := CircuitBreakerState(chainId)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Tripped {
+		return state, nil
+	}
+	state.Tripped = false
+	state.RearmedAt = time.Now()
+	if err := storage.SaveCircuitBreakerState(chainId, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
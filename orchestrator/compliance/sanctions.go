@@ -0,0 +1,218 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	heliosdata "github.com/Helios-Chain-Labs/hyperion/helios_data"
+)
+
+// Policy defines what the orchestrator does when a batch pays out to a sanctioned address.
+type Policy string
+
+const (
+	// PolicyRefuseSign skips the batch confirmation, the batch is neither signed nor relayed.
+	PolicyRefuseSign Policy = "refuse_sign"
+	// PolicyRefuseRelay signs the batch but never submits it to the counterparty chain.
+	PolicyRefuseRelay Policy = "refuse_relay"
+	// PolicyFlag only records the hit, signing and relaying continue as usual.
+	PolicyFlag Policy = "flag"
+
+	DefaultPolicy = PolicyFlag
+
+	BundledSource = "bundled"
+)
+
+func ParsePolicy(str string) (Policy, error) {
+	switch Policy(strings.ToLower(strings.TrimSpace(str))) {
+	case PolicyRefuseSign:
+		return PolicyRefuseSign, nil
+	case PolicyRefuseRelay:
+		return PolicyRefuseRelay, nil
+	case PolicyFlag, "":
+		return PolicyFlag, nil
+	}
+	return "", errors.Errorf("unknown sanctions policy %q, expected one of refuse_sign, refuse_relay, flag", str)
+}
+
+// BlocksSigning returns true if a batch hit by the screening must not be confirmed.
+func (p Policy) BlocksSigning() bool {
+	return p == PolicyRefuseSign
+}
+
+// BlocksRelaying returns true if a batch hit by the screening must not be submitted.
+func (p Policy) BlocksRelaying() bool {
+	return p == PolicyRefuseSign || p == PolicyRefuseRelay
+}
+
+// SanctionsList holds the set of sanctioned addresses. It is loaded either from
+// the list bundled in helios_data/ofac.json or from an operator-provided file,
+// which is reloaded whenever it changes on disk.
+type SanctionsList struct {
+	path string
+
+	mu        sync.RWMutex
+	addresses map[gethcommon.Address]struct{}
+	modTime   time.Time
+	loadedAt  time.Time
+}
+
+// NewSanctionsList loads the list at path, or the bundled list when path is empty.
+func NewSanctionsList(path string) (*SanctionsList, error) {
+	s := &SanctionsList{
+		path:      path,
+		addresses: make(map[gethcommon.Address]struct{}),
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Source returns the file path the list is loaded from, or "bundled".
+func (s *SanctionsList) Source() string {
+	if s.path == "" {
+		return BundledSource
+	}
+	return s.path
+}
+
+// Reload reads the list again. An operator-provided file that did not change since
+// the last load is skipped.
+func (s *SanctionsList) Reload() error {
+	data := heliosdata.OFACList
+	var modTime time.Time
+
+	if s.path != "" {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to stat sanctions list")
+		}
+		modTime = info.ModTime()
+
+		s.mu.RLock()
+		unchanged := !s.loadedAt.IsZero() && modTime.Equal(s.modTime)
+		s.mu.RUnlock()
+		if unchanged {
+			return nil
+		}
+
+		data, err = os.ReadFile(s.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read sanctions list")
+		}
+	}
+
+	addresses, err := parseAddresses(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse sanctions list %s", s.Source())
+	}
+
+	s.mu.Lock()
+	s.addresses = addresses
+	s.modTime = modTime
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RunRefresh reloads the list every interval until ctx is done. A failed reload keeps
+// the previously loaded addresses.
+func (s *SanctionsList) RunRefresh(ctx context.Context, interval time.Duration) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Reload(); err != nil {
+				log.WithError(err).WithField("source", s.Source()).Warningln("failed to refresh sanctions list, keeping previous one")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *SanctionsList) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.addresses)
+}
+
+func (s *SanctionsList) LoadedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadedAt
+}
+
+func (s *SanctionsList) IsSanctioned(address string) bool {
+	if !gethcommon.IsHexAddress(address) {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.addresses[gethcommon.HexToAddress(address)]
+	return ok
+}
+
+// ScreenBatch returns the destination addresses of the batch that are on the list.
+func (s *SanctionsList) ScreenBatch(batch *hyperiontypes.OutgoingTxBatch) []string {
+	if batch == nil {
+		return nil
+	}
+
+	hits := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, tx := range batch.Transactions {
+		if tx == nil || !s.IsSanctioned(tx.DestAddress) {
+			continue
+		}
+		dest := strings.ToLower(tx.DestAddress)
+		if _, ok := seen[dest]; ok {
+			continue
+		}
+		seen[dest] = struct{}{}
+		hits = append(hits, dest)
+	}
+
+	return hits
+}
+
+func parseAddresses(data []byte) (map[gethcommon.Address]struct{}, error) {
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[gethcommon.Address]struct{}, len(list))
+	for _, address := range list {
+		address = strings.TrimSpace(address)
+		if !gethcommon.IsHexAddress(address) {
+			// one bad entry must not disable the whole screening
+			log.WithField("address", address).Warningln("skipping invalid address in sanctions list")
+			continue
+		}
+		addresses[gethcommon.HexToAddress(address)] = struct{}{}
+	}
+
+	return addresses, nil
+}
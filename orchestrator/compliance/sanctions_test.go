@@ -0,0 +1,67 @@
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+func TestBundledListScreensBatch(t *testing.T) {
+	list, err := NewSanctionsList("")
+	assert.NoError(t, err)
+	assert.Equal(t, BundledSource, list.Source())
+	assert.True(t, list.Size() > 0)
+
+	batch := &hyperiontypes.OutgoingTxBatch{
+		BatchNonce: 1,
+		Transactions: []*hyperiontypes.OutgoingTransferTx{
+			{DestAddress: "0x01E2919679362dFBC9ee1644Ba9C6da6D6245BB1"},
+			{DestAddress: "0x0000000000000000000000000000000000000001"},
+			{DestAddress: "0x01e2919679362dfbc9ee1644ba9c6da6d6245bb1"},
+		},
+	}
+	assert.Equal(t, []string{"0x01e2919679362dfbc9ee1644ba9c6da6d6245bb1"}, list.ScreenBatch(batch))
+}
+
+func TestOperatorListReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanctions.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`["0x0000000000000000000000000000000000000001"]`), 0644))
+
+	list, err := NewSanctionsList(path)
+	assert.NoError(t, err)
+	assert.True(t, list.IsSanctioned("0x0000000000000000000000000000000000000001"))
+	assert.False(t, list.IsSanctioned("0x0000000000000000000000000000000000000002"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`["0x0000000000000000000000000000000000000002"]`), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, future, future))
+	assert.NoError(t, list.Reload())
+	assert.False(t, list.IsSanctioned("0x0000000000000000000000000000000000000001"))
+	assert.True(t, list.IsSanctioned("0x0000000000000000000000000000000000000002"))
+
+	// a broken file keeps the previous list
+	assert.NoError(t, os.WriteFile(path, []byte(`["0x00000000000000`), 0644))
+	past := time.Now().Add(2 * time.Minute)
+	assert.NoError(t, os.Chtimes(path, past, past))
+	assert.Error(t, list.Reload())
+	assert.True(t, list.IsSanctioned("0x0000000000000000000000000000000000000002"))
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("REFUSE_RELAY")
+	assert.NoError(t, err)
+	assert.True(t, policy.BlocksRelaying())
+	assert.False(t, policy.BlocksSigning())
+
+	policy, err = ParsePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyFlag, policy)
+
+	_, err = ParsePolicy("drop")
+	assert.Error(t, err)
+}
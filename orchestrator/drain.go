@@ -0,0 +1,211 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// DrainingStatus is shown by the loops that no longer take new work because the
+// orchestrator is stopping.
+const DrainingStatus = "draining"
+
+// drainGate lets the loops finish the iteration in flight when the orchestrator stops,
+// instead of being interrupted between sending a tx and recording its outcome.
+type drainGate struct {
+	mu         sync.Mutex
+	draining   bool
+	busy       map[string]int
+	pendingTxs map[gethcommon.Hash]PendingTx
+}
+
+func newDrainGate() *drainGate {
+	return &drainGate{
+		busy:       make(map[string]int),
+		pendingTxs: make(map[gethcommon.Hash]PendingTx),
+	}
+}
+
+// PendingTx is an EVM tx sent and not mined yet.
+type PendingTx struct {
+	Kind          string    `json:"kind"`
+	Nonce         uint64    `json:"nonce"`
+	TokenContract string    `json:"token_contract,omitempty"`
+	TxHash        string    `json:"tx_hash"`
+	SentAt        time.Time `json:"sent_at"`
+}
+
+// DrainState is persisted when the orchestrator of a chain stopped, it tells what was
+// left in flight when the drain deadline passed.
+type DrainState struct {
+	ChainId    uint64    `json:"chain_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	TimedOut   bool      `json:"timed_out"`
+	// BusyLoops are the loops still in an iteration at the deadline
+	BusyLoops []string `json:"busy_loops"`
+	// PendingTxs were sent and not mined at the deadline, their fees are not recorded
+	PendingTxs []PendingTx `json:"pending_txs"`
+	// OutboxDepth is the number of Helios msgs of the chain left in the outbox
+	OutboxDepth int `json:"outbox_depth"`
+	// OracleCursor is the last Ethereum block observed by the oracle, the next start
+	// resumes from there when it is behind the start height
+	OracleCursor uint64 `json:"oracle_cursor"`
+	// ResumedAt is set by the start that used the cursor, a cursor is used once
+	ResumedAt time.Time `json:"resumed_at,omitempty"`
+}
+
+// startWork registers an iteration of the loop, it returns false once the orchestrator
+// drains and the loop must not take new work.
+func (s *Orchestrator) startWork(loop string) bool {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	if s.drain.draining {
+		return false
+	}
+	s.drain.busy[loop]++
+	return true
+}
+
+func (s *Orchestrator) finishWork(loop string) {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	s.drain.busy[loop]--
+	if s.drain.busy[loop] <= 0 {
+		delete(s.drain.busy, loop)
+	}
+}
+
+// work runs an iteration of the loop, it returns false without running it once the
+// orchestrator drains.
+func (s *Orchestrator) work(loop string, fn func()) bool {
+	if !s.startWork(loop) {
+		return false
+	}
+	defer s.finishWork(loop)
+	fn()
+	return true
+}
+
+// Draining tells whether the orchestrator is stopping.
+func (s *Orchestrator) Draining() bool {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	return s.drain.draining
+}
+
+// trackTx records a tx sent to the chain until untrackTx is called once it is mined or failed.
+func (s *Orchestrator) trackTx(kind string, nonce uint64, tokenContract string, txHash gethcommon.Hash) {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	s.drain.pendingTxs[txHash] = PendingTx{
+		Kind:          kind,
+		Nonce:         nonce,
+		TokenContract: tokenContract,
+		TxHash:        txHash.Hex(),
+		SentAt:        time.Now(),
+	}
+}
+
+func (s *Orchestrator) untrackTx(txHash gethcommon.Hash) {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	delete(s.drain.pendingTxs, txHash)
+}
+
+// PendingTxs are the txs sent to the chain and not mined yet.
+func (s *Orchestrator) PendingTxs() []PendingTx {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	pendingTxs := make([]PendingTx, 0, len(s.drain.pendingTxs))
+	for _, tx := range s.drain.pendingTxs {
+		pendingTxs = append(pendingTxs, tx)
+	}
+	return pendingTxs
+}
+
+// Drain stops the loops from taking new work and waits for the iterations in flight to
+// finish, or for ctx to be done. The context of the loops is left to the caller to cancel.
+func (s *Orchestrator) Drain(ctx context.Context) *DrainState {
+	state := &DrainState{ChainId: s.cfg.ChainId, StartedAt: time.Now()}
+
+	s.drain.mu.Lock()
+	s.drain.draining = true
+	s.drain.mu.Unlock()
+	s.logger.Infoln("draining orchestrator, waiting for the work in flight")
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+wait:
+	for {
+		s.drain.mu.Lock()
+		idle := len(s.drain.busy) == 0
+		s.drain.mu.Unlock()
+		if idle {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			state.TimedOut = true
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	s.drain.mu.Lock()
+	state.BusyLoops = make([]string, 0, len(s.drain.busy))
+	for loop := range s.drain.busy {
+		state.BusyLoops = append(state.BusyLoops, loop)
+	}
+	sort.Strings(state.BusyLoops)
+	s.drain.mu.Unlock()
+	state.PendingTxs = s.PendingTxs()
+
+	if s.Oracle != nil {
+		state.OracleCursor = s.Oracle.lastObservedEthHeight
+	}
+	state.FinishedAt = time.Now()
+
+	if state.TimedOut {
+		s.logger.Warningln("drain deadline passed with loops", state.BusyLoops, "busy and", len(state.PendingTxs), "txs pending")
+	} else {
+		s.logger.Infoln("orchestrator drained")
+	}
+	return state
+}
+
+// resumeOracleCursor returns the oracle cursor of the last drain not resumed yet, zero
+// when there is none, and marks it resumed.
+func (s *Orchestrator) resumeOracleCursor() uint64 {
+	state, err := LastDrainState(s.cfg.ChainId)
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to read the last drain state")
+		return 0
+	}
+	if state == nil || !state.ResumedAt.IsZero() {
+		return 0
+	}
+	state.ResumedAt = time.Now()
+	if err := storage.SaveDrainState(s.cfg.ChainId, state); err != nil {
+		s.logger.WithError(err).Warningln("failed to save the last drain state")
+	}
+	return state.OracleCursor
+}
+
+// LastDrainState returns the state persisted when the orchestrator of the chain last
+// stopped, nil when it never drained.
+func LastDrainState(chainId uint64) (*DrainState, error) {
+	state := &DrainState{}
+	found, err := storage.GetDrainState(chainId, state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	log "github.com/xlab/suplog"
+)
+
+func TestDrainWaitsForWorkInFlight(t *testing.T) {
+	s := &Orchestrator{drain: newDrainGate(), logger: log.WithField("test", "drain")}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go s.work("relayer", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	done := make(chan *DrainState)
+	go func() { done <- s.Drain(context.Background()) }()
+
+	// no new iteration once draining
+	assert.Eventually(t, s.Draining, time.Second, 10*time.Millisecond)
+	assert.False(t, s.work("signer", func() { t.Fatal("iteration ran while draining") }))
+
+	close(release)
+	state := <-done
+	assert.False(t, state.TimedOut)
+	assert.Empty(t, state.BusyLoops)
+}
+
+func TestDrainTimesOut(t *testing.T) {
+	s := &Orchestrator{drain: newDrainGate(), logger: log.WithField("test", "drain")}
+
+	assert.True(t, s.startWork("batch_creator"))
+	s.trackTx("batch", 4, "0xtoken", gethcommon.HexToHash("0x01"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	state := s.Drain(ctx)
+	assert.True(t, state.TimedOut)
+	assert.Equal(t, []string{"batch_creator"}, state.BusyLoops)
+	assert.Len(t, state.PendingTxs, 1)
+	assert.Equal(t, uint64(4), state.PendingTxs[0].Nonce)
+}
@@ -56,6 +56,9 @@ type HyperionContract interface {
 
 	IsDepositPaused(ctx context.Context) (bool, error)
 
+	EmergencyPause(ctx context.Context) (*common.Hash, error)
+	EmergencyUnpause(ctx context.Context) (*common.Hash, error)
+
 	PrepareTransactionBatch(
 		ctx context.Context,
 		currentValset *types.Valset,
@@ -137,7 +140,7 @@ type HyperionContract interface {
 
 	GetTransactionFeesUsedInNetworkNativeCurrency(ctx context.Context, txHash common.Hash) (*big.Int, uint64, error)
 
-	SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, ethFrom common.Address, signerFn keystore.PersonalSignFn) error
+	SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, pendingBatchNonce uint64, destination common.Address, ethFrom common.Address, signerFn keystore.PersonalSignFn) (*common.Hash, error)
 }
 
 func DeployHyperionContract(
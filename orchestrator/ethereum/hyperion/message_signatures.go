@@ -18,6 +18,12 @@ import (
 // set update on the Hyperion Ethereum contract. This value will then be signed before being
 // submitted to Cosmos, verified, and then relayed to Ethereum
 func EncodeValsetConfirm(hyperionID common.Hash, valset *types.Valset) common.Hash {
+	return crypto.Keccak256Hash(EncodeValsetConfirmMessage(hyperionID, valset))
+}
+
+// EncodeValsetConfirmMessage returns the abi encoded checkpoint whose hash is signed by
+// EncodeValsetConfirm callers.
+func EncodeValsetConfirmMessage(hyperionID common.Hash, valset *types.Valset) []byte {
 	// error case here should not occur outside of testing since the above is a constant
 	contractAbi, abiErr := abi.JSON(strings.NewReader(ValsetCheckpointABIJSON))
 	if abiErr != nil {
@@ -62,17 +68,26 @@ func EncodeValsetConfirm(hyperionID common.Hash, valset *types.Valset) common.Ha
 		panic(fmt.Sprintf("Error packing checkpoint! %s/n", packErr))
 	}
 
-	// we hash the resulting encoded bytes discarding the first 4 bytes these 4 bytes are the constant
+	// we discard the first 4 bytes of the encoded bytes, these 4 bytes are the constant
 	// method name 'checkpoint'. If you where to replace the checkpoint constant in this code you would
 	// then need to adjust how many bytes you truncate off the front to get the output of abi.encode()
-	hash := crypto.Keccak256Hash(bytes[4:])
-	return hash
+	return bytes[4:]
 }
 
 // EncodeTxBatchConfirm takes the required input data and produces the required signature to confirm a transaction
 // batch on the Hyperion Ethereum contract. This value will then be signed before being
 // submitted to Cosmos, verified, and then relayed to Ethereum
 func EncodeTxBatchConfirm(hyperionID common.Hash, batch *types.OutgoingTxBatch) common.Hash {
+	message := EncodeTxBatchConfirmMessage(hyperionID, batch)
+	if message == nil {
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(message)
+}
+
+// EncodeTxBatchConfirmMessage returns the abi encoded batch whose hash is signed by
+// EncodeTxBatchConfirm callers.
+func EncodeTxBatchConfirmMessage(hyperionID common.Hash, batch *types.OutgoingTxBatch) []byte {
 	abi, err := abi.JSON(strings.NewReader(OutgoingBatchTxConfirmABIJSON))
 	if err != nil {
 		log.Fatalln("bad ABI constant")
@@ -111,12 +126,11 @@ func EncodeTxBatchConfirm(hyperionID common.Hash, batch *types.OutgoingTxBatch)
 	// should be filtered above.
 	if err != nil {
 		log.WithError(err).Errorln("Error packing transactionBatch!")
-		return common.Hash{}
+		return nil
 
 	}
 
-	hash := crypto.Keccak256Hash(abiEncodedBatch[4:])
-	return common.BytesToHash(hash.Bytes())
+	return abiEncodedBatch[4:]
 }
 
 const (
@@ -8,8 +8,11 @@ import (
 	sdkmath "cosmossdk.io/math"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/keystore"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/slashingprotection"
+	wrappers "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
 	"github.com/Helios-Chain-Labs/metrics"
 	"github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
@@ -33,28 +36,32 @@ func (s *hyperionContract) SendPreparedTxSync(ctx context.Context, txData []byte
 	return &txHash, cost, nil
 }
 
-func (s *hyperionContract) SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, ethFrom common.Address, signerFn keystore.PersonalSignFn) error {
+// maxClaimNonceAttempts bounds the signed nonces skipped by a claim of the old contract.
+const maxClaimNonceAttempts = 16
 
-	batchNonce := uint64(18001)
+// SendClaimTokensOfOldContract withdraws tokens held by the contract to the destination
+// through a batch signed by ethFrom alone, which must hold enough power in the current
+// valset of the contract. It waits for the receipt and fails if the tx reverted.
+//
+// The batch takes a nonce above the last one executed and above pendingBatchNonce, the
+// highest nonce of the batches of the token pending on Helios. Its confirm is recorded in
+// the slashing protection store before it is signed, the nonces already signed with
+// another payload are skipped.
+func (s *hyperionContract) SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, pendingBatchNonce uint64, destination common.Address, ethFrom common.Address, signerFn keystore.PersonalSignFn) (*common.Hash, error) {
 	hyperionIdHash := common.HexToHash(strconv.FormatUint(hyperionId, 16))
 
-	nonce, err := s.GetValsetNonce(ctx, ethFrom)
+	valset, err := s.currentValset(ctx, hyperionId, ethFrom)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	valset := &types.Valset{
-		HyperionId: hyperionId,
-		Nonce:      nonce.Uint64(),
-		Height:     nonce.Uint64(),
-		Members: []*types.BridgeValidator{
-			{
-				Power:           4294967295,
-				EthereumAddress: common.HexToAddress("0x882f8A95409C127f0dE7BA83b4Dfa0096C3D8D79").String(),
-			},
-		},
-		RewardAmount: sdkmath.NewInt(0),
-		RewardToken:  "0x79966E79c6263B928CF722485dd5A55959077253",
+	lastBatchNonce, err := s.GetTxBatchNonce(ctx, common.HexToAddress(tokenContract), ethFrom)
+	if err != nil {
+		return nil, err
+	}
+	batchNonce := lastBatchNonce.Uint64() + 1
+	if pendingBatchNonce >= batchNonce {
+		batchNonce = pendingBatchNonce + 1
 	}
 
 	batch := &types.OutgoingTxBatch{
@@ -66,8 +73,8 @@ func (s *hyperionContract) SendClaimTokensOfOldContract(ctx context.Context, hyp
 			{
 				HyperionId:  hyperionId,
 				Id:          50000000,
-				Sender:      common.HexToAddress("0x882f8A95409C127f0dE7BA83b4Dfa0096C3D8D79").String(),
-				DestAddress: common.HexToAddress("0x882f8A95409C127f0dE7BA83b4Dfa0096C3D8D79").String(),
+				Sender:      ethFrom.String(),
+				DestAddress: destination.String(),
 				Token: &types.Token{
 					Contract: tokenContract,
 					Amount:   sdkmath.NewIntFromBigInt(amountInSdkMath),
@@ -82,11 +89,27 @@ func (s *hyperionContract) SendClaimTokensOfOldContract(ctx context.Context, hyp
 		},
 	}
 
-	confirmHash := EncodeTxBatchConfirm(hyperionIdHash, batch)
+	store, err := slashingprotection.DefaultStore()
+	if err != nil {
+		return nil, errors.Wrap(err, "slashing protection store unavailable, refusing to sign")
+	}
+	var confirmHash common.Hash
+	for attempt := 0; ; attempt++ {
+		batch.BatchNonce = batchNonce
+		confirmHash = EncodeTxBatchConfirm(hyperionIdHash, batch)
+		err := store.CheckAndRecordBatch(hyperionId, ethFrom, common.HexToAddress(tokenContract), batchNonce, confirmHash)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, slashingprotection.ErrConflictingSignature) || attempt >= maxClaimNonceAttempts {
+			return nil, errors.Wrapf(err, "batch nonce %d of token %s", batchNonce, tokenContract)
+		}
+		batchNonce++
+	}
 	signature, err := signerFn(ethFrom, confirmHash.Bytes())
 	if err != nil {
 		metrics.ReportFuncError(s.svcTags)
-		return errors.New("failed to sign validator address")
+		return nil, errors.New("failed to sign validator address")
 	}
 
 	hexSignature := common.Bytes2Hex(signature)
@@ -104,14 +127,60 @@ func (s *hyperionContract) SendClaimTokensOfOldContract(ctx context.Context, hyp
 
 	txData, err := s.PrepareTransactionBatch(ctx, valset, batch, confirms)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	txHash, _, err := s.SendTx(ctx, s.hyperionAddress, txData)
 	if err != nil {
 		metrics.ReportFuncError(s.svcTags)
-		return err
+		return nil, err
 	}
 	log.Info("txHash: ", txHash.Hex())
-	return nil
+
+	if _, _, err := s.WaitForTransaction(ctx, txHash); err != nil {
+		return &txHash, errors.Wrapf(err, "claim tx %s", txHash.Hex())
+	}
+	return &txHash, nil
+}
+
+// currentValset rebuilds the valset the contract checkpoints from its last ValsetUpdatedEvent.
+func (s *hyperionContract) currentValset(ctx context.Context, hyperionId uint64, callerAddress common.Address) (*types.Valset, error) {
+	height, err := s.GetLastValsetUpdatedEventHeight(ctx, callerAddress)
+	if err != nil {
+		return nil, err
+	}
+	end := height.Uint64()
+	iter, err := s.ethHyperion.FilterValsetUpdatedEvent(&bind.FilterOpts{
+		Start:   end,
+		End:     &end,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan the last ValsetUpdatedEvent")
+	}
+	defer iter.Close()
+
+	var event *wrappers.HyperionValsetUpdatedEvent
+	for iter.Next() {
+		event = iter.Event
+	}
+	if event == nil {
+		return nil, errors.Errorf("no ValsetUpdatedEvent found at block %d", end)
+	}
+
+	valset := &types.Valset{
+		HyperionId:   hyperionId,
+		Nonce:        event.NewValsetNonce.Uint64(),
+		Height:       end,
+		Members:      make([]*types.BridgeValidator, len(event.Validators)),
+		RewardAmount: sdkmath.NewIntFromBigInt(event.RewardAmount),
+		RewardToken:  event.RewardToken.Hex(),
+	}
+	for i, validator := range event.Validators {
+		valset.Members[i] = &types.BridgeValidator{
+			Power:           event.Powers[i].Uint64(),
+			EthereumAddress: validator.Hex(),
+		}
+	}
+	return valset, nil
 }
@@ -29,11 +29,22 @@ type NetworkConfig struct {
 	MaxGasPrice           string
 	PendingTxWaitDuration string
 	ChainID               int
+
+	// RelayerWallet pays the gas of relayed transactions, the orchestrator key is used when nil
+	RelayerWallet *RelayerWallet
+}
+
+// RelayerWallet is an EVM account distinct from the orchestrator signing key, used to send
+// batches, valset updates and ERC20 deployments so the signing key can stay cold and unfunded.
+type RelayerWallet struct {
+	FromAddr gethcommon.Address
+	SignerFn bind.SignerFn
 }
 
 // Network is the orchestrator's reference endpoint to the Ethereum network
 type Network interface {
 	FromAddress() gethcommon.Address
+	RelayerAddress() gethcommon.Address
 
 	GetRpc() *rpcs.Rpc
 	TestRpc(ctx context.Context) bool
@@ -44,12 +55,16 @@ type Network interface {
 	GetGasPrice(ctx context.Context) (*big.Int, error)
 
 	GetHyperionContractAddress() gethcommon.Address
+	GetCode(ctx context.Context, address gethcommon.Address) ([]byte, error)
 
 	GetSendToHeliosEvents(startBlock, endBlock uint64) ([]*hyperionevents.HyperionSendToHeliosEvent, error)
 	GetHyperionERC20DeployedEvents(startBlock, endBlock uint64) ([]*hyperionevents.HyperionERC20DeployedEvent, error)
 	GetValsetUpdatedEvents(startBlock, endBlock uint64) ([]*hyperionevents.HyperionValsetUpdatedEvent, error)
 	GetValsetUpdatedEventsAtSpecificBlock(block uint64) ([]*hyperionevents.HyperionValsetUpdatedEvent, error)
 	GetTransactionBatchExecutedEvents(startBlock, endBlock uint64) ([]*hyperionevents.HyperionTransactionBatchExecutedEvent, error)
+	GetTransactionBatchExecutedEventsOfBatch(startBlock, endBlock uint64, batchNonce uint64, tokenContract gethcommon.Address) ([]*hyperionevents.HyperionTransactionBatchExecutedEvent, error)
+	GetSendToHeliosEventsOfTx(ctx context.Context, txHash gethcommon.Hash) ([]*hyperionevents.HyperionSendToHeliosEvent, uint64, error)
+	GetHyperionLogs(ctx context.Context, startBlock, endBlock uint64) ([]gethtypes.Log, error)
 
 	GetValsetNonce(ctx context.Context) (*big.Int, error)
 	SendEthValsetUpdate(ctx context.Context,
@@ -99,16 +114,19 @@ type Network interface {
 
 	TokenDecimals(ctx context.Context, tokenContract gethcommon.Address) (uint8, error)
 	TokenSymbol(ctx context.Context, tokenContract gethcommon.Address) (string, error)
+	TokenBalance(ctx context.Context, tokenContract gethcommon.Address, holder gethcommon.Address) (*big.Int, error)
 	ExecuteExternalDataTx(ctx context.Context, address gethcommon.Address, txAbi []byte, blockNumber *big.Int) ([]byte, []byte, string, error)
 	GetSignerFn() bind.SignerFn
 	GetPersonalSignFn() keystore.PersonalSignFn
 
 	WaitForTransaction(ctx context.Context, txHash gethcommon.Hash) (*gethtypes.Transaction, uint64, error)
 	GetTransactionFeesUsedInNetworkNativeCurrency(ctx context.Context, txHash gethcommon.Hash) (*big.Int, uint64, error)
-	SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, ethFrom common.Address, signerFn keystore.PersonalSignFn) error
+	SendClaimTokensOfOldContract(ctx context.Context, hyperionId uint64, tokenContract string, amountInSdkMath *big.Int, pendingBatchNonce uint64, destination common.Address, ethFrom common.Address, signerFn keystore.PersonalSignFn) (*common.Hash, error)
 
 	PauseOrUnpauseDeposit(ctx context.Context, pause bool) (*gethcommon.Hash, error)
 	IsDepositPaused(ctx context.Context) (bool, error)
+	EmergencyPause(ctx context.Context) (*gethcommon.Hash, error)
+	EmergencyUnpause(ctx context.Context) (*gethcommon.Hash, error)
 }
 
 type cacheHeaderValue struct {
@@ -123,6 +141,9 @@ type network struct {
 	SignerFn       bind.SignerFn
 	PersonalSignFn keystore.PersonalSignFn
 
+	// relayerContract sends the relayed transactions with the relayer wallet, nil if none is configured
+	relayerContract hyperion.HyperionContract
+
 	cachedHeader *cacheHeaderValue
 	CacheTTL     time.Duration
 }
@@ -187,9 +208,74 @@ func NewNetwork(
 		CacheTTL:         500 * time.Millisecond,
 	}
 
+	if cfg.RelayerWallet != nil && cfg.RelayerWallet.FromAddr != fromAddr {
+		relayerCommitter, err := committer.NewEthCommitter(
+			cfg.RelayerWallet.FromAddr,
+			cfg.GasPriceAdjustment,
+			cfg.MaxGasPrice,
+			cfg.RelayerWallet.SignerFn,
+			provider.NewEVMProvider(cfg.EthNodeRPC),
+			options...,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to init relayer wallet committer")
+		}
+
+		n.relayerContract, err = hyperion.NewHyperionContract(context.Background(), relayerCommitter, hyperionContractAddr, hyperion.PendingTxInputList{}, pendingTxDuration, cfg.RelayerWallet.SignerFn)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to init relayer wallet contract")
+		}
+	}
+
 	return n, nil
 }
 
+// relayer returns the contract bound to the account that pays for relayed transactions.
+func (n *network) relayer() hyperion.HyperionContract {
+	if n.relayerContract != nil {
+		return n.relayerContract
+	}
+	return n.HyperionContract
+}
+
+func (n *network) RelayerAddress() gethcommon.Address {
+	if n.relayerContract != nil {
+		return n.relayerContract.FromAddress()
+	}
+	return n.FromAddr
+}
+
+func (n *network) SendPreparedTx(ctx context.Context, txData []byte) (*gethcommon.Hash, *big.Int, error) {
+	return n.relayer().SendPreparedTx(ctx, txData)
+}
+
+func (n *network) SendPreparedTxSync(ctx context.Context, txData []byte) (*gethcommon.Hash, *big.Int, error) {
+	return n.relayer().SendPreparedTxSync(ctx, txData)
+}
+
+func (n *network) SendEthValsetUpdate(
+	ctx context.Context,
+	oldValset *hyperiontypes.Valset,
+	newValset *hyperiontypes.Valset,
+	confirms []*hyperiontypes.MsgValsetConfirm,
+) (*gethcommon.Hash, *big.Int, error) {
+	return n.relayer().SendEthValsetUpdate(ctx, oldValset, newValset, confirms)
+}
+
+func (n *network) DeployERC20(
+	ctx context.Context,
+	callerAddress gethcommon.Address,
+	denom string,
+	name string,
+	symbol string,
+	decimals uint8,
+) (*gethtypes.Transaction, uint64, error) {
+	if n.relayerContract != nil && callerAddress == n.FromAddr {
+		callerAddress = n.relayerContract.FromAddress()
+	}
+	return n.relayer().DeployERC20(ctx, callerAddress, denom, name, symbol, decimals)
+}
+
 func (n *network) GetHyperionContractAddress() gethcommon.Address {
 	return n.HyperionContract.Address()
 }
@@ -225,6 +311,24 @@ func (n *network) TokenSymbol(ctx context.Context, tokenContract gethcommon.Addr
 	return strings.Trim(string(res), "\x00"), nil
 }
 
+func (n *network) TokenBalance(ctx context.Context, tokenContract gethcommon.Address, holder gethcommon.Address) (*big.Int, error) {
+	msg := ethereum.CallMsg{
+		To:   &tokenContract,
+		Data: append(gethcommon.Hex2Bytes("70a08231"), gethcommon.LeftPadBytes(holder.Bytes(), 32)...), // balanceOf(address) method signature
+	}
+
+	res, err := n.Provider().CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errors.Errorf("no balance found for token contract %s", tokenContract.Hex())
+	}
+
+	return big.NewInt(0).SetBytes(res), nil
+}
+
 func (n *network) GetSignerFn() bind.SignerFn {
 	return n.SignerFn
 }
@@ -262,8 +366,13 @@ func (n *network) GetHeaderByNumber(ctx context.Context, number *big.Int) (*geth
 	return n.Provider().HeaderByNumber(ctx, number)
 }
 
+// GetNativeBalance returns the balance of the account paying the relay gas.
 func (n *network) GetNativeBalance(ctx context.Context) (*big.Int, error) {
-	return n.Provider().Balance(ctx, n.FromAddr)
+	return n.Provider().Balance(ctx, n.RelayerAddress())
+}
+
+func (n *network) GetCode(ctx context.Context, address gethcommon.Address) ([]byte, error) {
+	return n.Provider().CodeAt(ctx, address, nil)
 }
 
 func (n *network) GetHyperionID(ctx context.Context) (gethcommon.Hash, error) {
@@ -462,6 +571,79 @@ func (n *network) GetTransactionBatchExecutedEvents(startBlock, endBlock uint64)
 	return transactionBatchExecutedEvents, nil
 }
 
+// GetTransactionBatchExecutedEventsOfBatch scans the blocks for the execution of a single batch,
+// filtering on the indexed batch nonce and token.
+func (n *network) GetTransactionBatchExecutedEventsOfBatch(startBlock, endBlock uint64, batchNonce uint64, tokenContract gethcommon.Address) ([]*hyperionevents.HyperionTransactionBatchExecutedEvent, error) {
+	hyperionFilterer, err := hyperionevents.NewHyperionFilterer(n.Address(), n.Provider())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init Hyperion events filterer")
+	}
+
+	iter, err := hyperionFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
+		Start: startBlock,
+		End:   &endBlock,
+	}, []*big.Int{new(big.Int).SetUint64(batchNonce)}, []gethcommon.Address{tokenContract})
+	if err != nil {
+		if !isUnknownBlockErr(err) {
+			return nil, errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
+		} else if iter == nil {
+			return nil, errors.New("no iterator returned")
+		}
+	}
+
+	defer iter.Close()
+
+	var transactionBatchExecutedEvents []*hyperionevents.HyperionTransactionBatchExecutedEvent
+	for iter.Next() {
+		transactionBatchExecutedEvents = append(transactionBatchExecutedEvents, iter.Event)
+	}
+
+	return transactionBatchExecutedEvents, nil
+}
+
+// GetSendToHeliosEventsOfTx reads the SendToHelios events emitted by the Hyperion contract in the
+// tx, along with the block the tx was mined in.
+func (n *network) GetSendToHeliosEventsOfTx(ctx context.Context, txHash gethcommon.Hash) ([]*hyperionevents.HyperionSendToHeliosEvent, uint64, error) {
+	receipt, err := n.Provider().TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to get the receipt of tx %s", txHash.Hex())
+	}
+
+	hyperionFilterer, err := hyperionevents.NewHyperionFilterer(n.Address(), n.Provider())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to init Hyperion events filterer")
+	}
+
+	var sendToHeliosEvents []*hyperionevents.HyperionSendToHeliosEvent
+	for _, txLog := range receipt.Logs {
+		if txLog == nil || txLog.Address != n.Address() {
+			continue
+		}
+		event, err := hyperionFilterer.ParseSendToHeliosEvent(*txLog)
+		if err != nil {
+			// another event of the contract, the signature does not match
+			continue
+		}
+		sendToHeliosEvents = append(sendToHeliosEvents, event)
+	}
+
+	return sendToHeliosEvents, receipt.BlockNumber.Uint64(), nil
+}
+
+// GetHyperionLogs returns every log emitted by the Hyperion contract in the blocks, whatever
+// the event.
+func (n *network) GetHyperionLogs(ctx context.Context, startBlock, endBlock uint64) ([]gethtypes.Log, error) {
+	logs, err := n.Provider().FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(startBlock),
+		ToBlock:   new(big.Int).SetUint64(endBlock),
+		Addresses: []gethcommon.Address{n.Address()},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan past Hyperion logs from Ethereum")
+	}
+	return logs, nil
+}
+
 func isUnknownBlockErr(err error) bool {
 	// Geth error
 	if strings.Contains(err.Error(), "unknown block") {
@@ -0,0 +1,342 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/keystore"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Config describes how to reach a Web3Signer compatible remote signer.
+type Config struct {
+	// URL of the signer, e.g. https://signer.internal:9000
+	URL string
+	// Address of the EVM account held by the signer
+	Address string
+
+	// CACertFile is a PEM bundle used to verify the signer certificate, the system pool is used if empty
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are the PEM encoded certificate and key used for TLS client auth
+	ClientCertFile string
+	ClientKeyFile  string
+
+	Timeout time.Duration
+}
+
+func (cfg Config) Enabled() bool {
+	return len(cfg.URL) > 0
+}
+
+// Web3Signer signs EVM transactions and personal messages through the
+// Web3Signer eth1 JSON-RPC API (eth_accounts, eth_sign, eth_signTransaction),
+// so the private key never has to be present on the Hyperion host.
+type Web3Signer struct {
+	url     string
+	address common.Address
+	client  *http.Client
+	reqID   atomic.Uint64
+}
+
+func NewWeb3Signer(cfg Config) (*Web3Signer, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("remote signer url is empty")
+	}
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, errors.Errorf("invalid remote signer address %q", cfg.Address)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Web3Signer{
+		url:     strings.TrimSuffix(cfg.URL, "/"),
+		address: common.HexToAddress(cfg.Address),
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(cfg.CACertFile) > 0 {
+		caPEM, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read remote signer CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no certificate found in remote signer CA file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(cfg.ClientCertFile) > 0 || len(cfg.ClientKeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load remote signer client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (s *Web3Signer) Address() common.Address {
+	return s.address
+}
+
+// Upcheck calls the signer health endpoint and makes sure it holds the configured account.
+func (s *Web3Signer) Upcheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/upcheck", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "remote signer is unreachable")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("remote signer upcheck returned status %d", resp.StatusCode)
+	}
+
+	var addresses []string
+	if err := s.call(ctx, "eth_accounts", nil, &addresses); err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if common.HexToAddress(address) == s.address {
+			return nil
+		}
+	}
+
+	return errors.Errorf("remote signer does not hold a key for %s", s.address.Hex())
+}
+
+// PersonalSignFn signs data with the EIP-191 personal message prefix, the same way
+// keystore.PrivateKeyPersonalSignFn does for a local key.
+func (s *Web3Signer) PersonalSignFn() keystore.PersonalSignFn {
+	return func(from common.Address, data []byte) ([]byte, error) {
+		if from != s.address {
+			return nil, errors.New("from address mismatch")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+		defer cancel()
+
+		var sigHex string
+		if err := s.call(ctx, "eth_sign", []interface{}{s.address.Hex(), hexutil.Encode(data)}, &sigHex); err != nil {
+			return nil, err
+		}
+
+		sig, err := hexutil.Decode(sigHex)
+		if err != nil {
+			return nil, errors.Wrap(err, "remote signer returned a malformed signature")
+		}
+		if len(sig) != crypto.SignatureLength {
+			return nil, errors.Errorf("remote signer returned a signature of %d bytes", len(sig))
+		}
+
+		// Web3Signer returns V as 27/28, local keys produce 0/1
+		if sig[crypto.RecoveryIDOffset] >= 27 {
+			sig[crypto.RecoveryIDOffset] -= 27
+		}
+
+		pubKey, err := crypto.SigToPub(accounts.TextHash(data), sig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recover remote signature")
+		}
+		if crypto.PubkeyToAddress(*pubKey) != s.address {
+			return nil, errors.New("remote signature was not made by the configured address")
+		}
+
+		return sig, nil
+	}
+}
+
+// SignerFn returns a bind.SignerFn that has the transaction signed by the remote signer
+// and checks the returned transaction is the one that was requested.
+func (s *Web3Signer) SignerFn(chainID uint64) bind.SignerFn {
+	chainIDBig := new(big.Int).SetUint64(chainID)
+	gethSigner := gethtypes.LatestSignerForChainID(chainIDBig)
+
+	return func(from common.Address, tx *gethtypes.Transaction) (*gethtypes.Transaction, error) {
+		if from != s.address {
+			return nil, bind.ErrNotAuthorized
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout)
+		defer cancel()
+
+		var rawTx string
+		if err := s.call(ctx, "eth_signTransaction", []interface{}{toSignTxArgs(from, chainIDBig, tx)}, &rawTx); err != nil {
+			return nil, err
+		}
+
+		rawBytes, err := hexutil.Decode(rawTx)
+		if err != nil {
+			return nil, errors.Wrap(err, "remote signer returned a malformed transaction")
+		}
+
+		signedTx := new(gethtypes.Transaction)
+		if err := signedTx.UnmarshalBinary(rawBytes); err != nil {
+			return nil, errors.Wrap(err, "failed to decode remotely signed transaction")
+		}
+
+		if signedTx.ChainId().Cmp(chainIDBig) != 0 {
+			return nil, errors.Errorf("remote signer signed for chain %s, expected %d", signedTx.ChainId(), chainID)
+		}
+
+		sender, err := gethtypes.Sender(gethSigner, signedTx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recover remotely signed transaction sender")
+		}
+		if sender != s.address {
+			return nil, errors.Errorf("remotely signed transaction sender %s does not match %s", sender.Hex(), s.address.Hex())
+		}
+
+		if !sameTx(tx, signedTx) {
+			return nil, errors.New("remotely signed transaction does not match the requested one")
+		}
+
+		return signedTx, nil
+	}
+}
+
+func toSignTxArgs(from common.Address, chainID *big.Int, tx *gethtypes.Transaction) map[string]interface{} {
+	args := map[string]interface{}{
+		"from":    from.Hex(),
+		"gas":     hexutil.EncodeUint64(tx.Gas()),
+		"nonce":   hexutil.EncodeUint64(tx.Nonce()),
+		"value":   hexutil.EncodeBig(tx.Value()),
+		"data":    hexutil.Encode(tx.Data()),
+		"chainId": hexutil.EncodeBig(chainID),
+	}
+	if tx.To() != nil {
+		args["to"] = tx.To().Hex()
+	}
+
+	if tx.Type() == gethtypes.DynamicFeeTxType {
+		args["maxFeePerGas"] = hexutil.EncodeBig(tx.GasFeeCap())
+		args["maxPriorityFeePerGas"] = hexutil.EncodeBig(tx.GasTipCap())
+	} else {
+		args["gasPrice"] = hexutil.EncodeBig(tx.GasPrice())
+	}
+
+	return args
+}
+
+func sameTx(requested, signed *gethtypes.Transaction) bool {
+	if requested.Nonce() != signed.Nonce() ||
+		requested.Gas() != signed.Gas() ||
+		requested.Value().Cmp(signed.Value()) != 0 ||
+		!bytes.Equal(requested.Data(), signed.Data()) ||
+		requested.GasFeeCap().Cmp(signed.GasFeeCap()) != 0 ||
+		requested.GasTipCap().Cmp(signed.GasTipCap()) != 0 {
+		return false
+	}
+
+	if requested.To() == nil || signed.To() == nil {
+		return requested.To() == nil && signed.To() == nil
+	}
+
+	return *requested.To() == *signed.To()
+}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *Web3Signer) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      s.reqID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "remote signer %s request failed", method)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read remote signer %s response", method)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("remote signer %s returned status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return errors.Wrapf(err, "failed to decode remote signer %s response", method)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("remote signer %s failed: %s (code %d)", method, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
@@ -0,0 +1,249 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInSigner is a minimal Web3Signer eth1 stand-in holding a single key.
+type standInSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+}
+
+func (s *standInSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/upcheck" {
+		w.Write([]byte("OK"))
+		return
+	}
+
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "eth_accounts":
+		result = []string{crypto.PubkeyToAddress(s.key.PublicKey).Hex()}
+	case "eth_sign":
+		var data string
+		json.Unmarshal(req.Params[1], &data)
+		sig, _ := crypto.Sign(accounts.TextHash(hexutil.MustDecode(data)), s.key)
+		sig[crypto.RecoveryIDOffset] += 27
+		result = hexutil.Encode(sig)
+	case "eth_signTransaction":
+		var args struct {
+			To       *common.Address `json:"to"`
+			Gas      hexutil.Uint64  `json:"gas"`
+			GasPrice *hexutil.Big    `json:"gasPrice"`
+			Nonce    hexutil.Uint64  `json:"nonce"`
+			Value    *hexutil.Big    `json:"value"`
+			Data     hexutil.Bytes   `json:"data"`
+		}
+		json.Unmarshal(req.Params[0], &args)
+		tx := gethtypes.NewTx(&gethtypes.LegacyTx{
+			Nonce:    uint64(args.Nonce),
+			To:       args.To,
+			Gas:      uint64(args.Gas),
+			GasPrice: args.GasPrice.ToInt(),
+			Value:    args.Value.ToInt(),
+			Data:     args.Data,
+		})
+		signed, _ := gethtypes.SignTx(tx, gethtypes.LatestSignerForChainID(s.chainID), s.key)
+		raw, _ := signed.MarshalBinary()
+		result = hexutil.Encode(raw)
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"}})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+type testPKI struct {
+	caFile, certFile, keyFile string
+	serverTLS                 *tls.Config
+}
+
+// newTestPKI creates a CA, a server certificate for 127.0.0.1 and a client certificate.
+func newTestPKI(t *testing.T) testPKI {
+	dir := t.TempDir()
+
+	caKey := newTLSKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) (tls.Certificate, []byte, []byte) {
+		key := newTLSKey(t)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		return pair, certPEM, keyPEM
+	}
+
+	serverPair, _, _ := issue(2, x509.ExtKeyUsageServerAuth)
+	_, clientCertPEM, clientKeyPEM := issue(3, x509.ExtKeyUsageClientAuth)
+
+	pki := testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+	require.NoError(t, os.WriteFile(pki.caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600))
+	require.NoError(t, os.WriteFile(pki.certFile, clientCertPEM, 0600))
+	require.NoError(t, os.WriteFile(pki.keyFile, clientKeyPEM, 0600))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	pki.serverTLS = &tls.Config{
+		Certificates: []tls.Certificate{serverPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+
+	return pki
+}
+
+func newTLSKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func startStandIn(t *testing.T, key *ecdsa.PrivateKey, chainID int64) (*httptest.Server, testPKI) {
+	pki := newTestPKI(t)
+	server := httptest.NewUnstartedServer(&standInSigner{key: key, chainID: big.NewInt(chainID)})
+	server.TLS = pki.serverTLS
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, pki
+}
+
+func TestWeb3SignerSigning(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	server, pki := startStandIn(t, key, 11155111)
+
+	signer, err := NewWeb3Signer(Config{
+		URL:            server.URL,
+		Address:        address.Hex(),
+		CACertFile:     pki.caFile,
+		ClientCertFile: pki.certFile,
+		ClientKeyFile:  pki.keyFile,
+	})
+	require.NoError(t, err)
+	require.NoError(t, signer.Upcheck(context.Background()))
+
+	// personal signatures must match the ones of a local key
+	data := crypto.Keccak256([]byte("valset confirm"))
+	sig, err := signer.PersonalSignFn()(address, data)
+	require.NoError(t, err)
+	localSig, err := crypto.Sign(accounts.TextHash(data), key)
+	require.NoError(t, err)
+	assert.Equal(t, localSig, sig)
+
+	_, err = signer.PersonalSignFn()(common.HexToAddress("0x01"), data)
+	assert.Error(t, err)
+
+	to := common.HexToAddress("0x000000000000000000000000000000000000dead")
+	tx := gethtypes.NewTx(&gethtypes.LegacyTx{Nonce: 7, To: &to, Gas: 21000, GasPrice: big.NewInt(1e9), Value: big.NewInt(1), Data: []byte{0x01}})
+	signedTx, err := signer.SignerFn(11155111)(address, tx)
+	require.NoError(t, err)
+	sender, err := gethtypes.Sender(gethtypes.LatestSignerForChainID(big.NewInt(11155111)), signedTx)
+	require.NoError(t, err)
+	assert.Equal(t, address, sender)
+	assert.Equal(t, uint64(7), signedTx.Nonce())
+
+	// the signer is configured for another chain
+	_, err = signer.SignerFn(1)(address, tx)
+	assert.Error(t, err)
+}
+
+func TestWeb3SignerRequiresClientCert(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	server, pki := startStandIn(t, key, 1)
+
+	signer, err := NewWeb3Signer(Config{
+		URL:        server.URL,
+		Address:    address.Hex(),
+		CACertFile: pki.caFile,
+	})
+	require.NoError(t, err)
+	assert.Error(t, signer.Upcheck(context.Background()))
+}
+
+func TestWeb3SignerUnknownAccount(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	server, pki := startStandIn(t, key, 1)
+
+	signer, err := NewWeb3Signer(Config{
+		URL:            server.URL,
+		Address:        "0x000000000000000000000000000000000000beef",
+		CACertFile:     pki.caFile,
+		ClientCertFile: pki.certFile,
+		ClientKeyFile:  pki.keyFile,
+	})
+	require.NoError(t, err)
+	assert.Error(t, signer.Upcheck(context.Background()))
+}
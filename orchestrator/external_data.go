@@ -24,19 +24,27 @@ const (
 func (s *Orchestrator) runExternalData(ctx context.Context) error {
 	externalData := externalData{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "external_data"),
 	}
 	s.logger.WithField("loop_duration", defaultExternalDataLoopDur.String()).Debugln("starting ExternalData...")
 
-	return loops.RunLoop(ctx, s.ethereum, defaultExternalDataLoopDur, func() error {
+	return loops.RunLoopWithWakeup(ctx, s.ethereum, defaultExternalDataLoopDur, s.wakeups.externalData, func() error {
 		if s.HyperionState.ExternalDataStatus == "running" {
 			return nil
 		}
+		if s.heliosSyncing(ctx, "external_data") {
+			s.setStatus(LoopExternalData, HeliosSyncingStatus)
+			return nil
+		}
+		if !s.startWork("external_data") {
+			s.setStatus(LoopExternalData, DrainingStatus)
+			return nil
+		}
+		defer s.finishWork("external_data")
 
 		start := time.Now()
-		s.HyperionState.ExternalDataStatus = "running"
+		s.setStatus(LoopExternalData, "running")
 		err := externalData.Process(ctx)
-		s.HyperionState.ExternalDataStatus = "idle"
+		s.setStatus(LoopExternalData, "idle")
 		s.HyperionState.ExternalDataLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 		s.HyperionState.ExternalDataNextExecutionTimestamp = uint64(start.Add(defaultExternalDataLoopDur).Unix())
 		return err
@@ -45,11 +53,10 @@ func (s *Orchestrator) runExternalData(ctx context.Context) error {
 
 type externalData struct {
 	*Orchestrator
-	logEnabled bool
 }
 
 func (l *externalData) Log() log.Logger {
-	return l.logger.WithField("loop", "ExternalData")
+	return l.logger.WithField("loop", LoopExternalData)
 }
 
 func (l *externalData) Process(ctx context.Context) error {
@@ -78,7 +85,7 @@ func (l *externalData) relayExternalData(ctx context.Context) error {
 	defer doneFn()
 
 	txs, err := l.GetHelios().LatestTransactionExternalCallDataTxs(ctx, l.cfg.HyperionId)
-	if l.logEnabled {
+	if l.logEnabled(LoopExternalData) {
 		l.Log().Info("txs: ", txs)
 	}
 	if err != nil {
@@ -0,0 +1,197 @@
+package global
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/autovote"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/gov"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+const (
+	autoVoteLoopInterval = 5 * time.Minute
+	// autoVoteProposalsPageSize is the number of latest proposals checked for an open vote
+	autoVoteProposalsPageSize = 100
+)
+
+func (g *Global) autoVoteConfigPath() (string, error) {
+	return autovote.DefaultConfigPath()
+}
+
+func (g *Global) GetAutoVoteConfig() (*autovote.Config, error) {
+	path, err := g.autoVoteConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return autovote.LoadConfig(path)
+}
+
+func (g *Global) SetAutoVoteConfig(config *autovote.Config) error {
+	path, err := g.autoVoteConfigPath()
+	if err != nil {
+		return err
+	}
+	if config.Rules == nil {
+		config.Rules = []autovote.Rule{}
+	}
+	if config.Reviewed == nil {
+		config.Reviewed = []uint64{}
+	}
+	return autovote.SaveConfig(path, config)
+}
+
+// MarkProposalReviewed leaves the proposal to the operator, the rules holding their vote
+// until the deadline no longer vote on it.
+func (g *Global) MarkProposalReviewed(proposalId uint64) error {
+	config, err := g.GetAutoVoteConfig()
+	if err != nil {
+		return err
+	}
+	if config.IsReviewed(proposalId) {
+		return nil
+	}
+	config.Reviewed = append(config.Reviewed, proposalId)
+	return g.SetAutoVoteConfig(config)
+}
+
+// PlanAutoVotes evaluates the rules against the proposals in voting period that we did
+// not vote on yet. Nothing is cast, it is the dry-run preview of the engine.
+func (g *Global) PlanAutoVotes(ctx context.Context) ([]*autovote.Decision, error) {
+	config, err := g.GetAutoVoteConfig()
+	if err != nil {
+		return nil, err
+	}
+	return g.planAutoVotes(ctx, config)
+}
+
+func (g *Global) planAutoVotes(ctx context.Context, config *autovote.Config) ([]*autovote.Decision, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, errors.New("helios network not initialized")
+	}
+
+	proposals, _, err := heliosNetwork.GetProposalsByPageAndSize(ctx, 1, autoVoteProposalsPageSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get proposals")
+	}
+
+	now := time.Now()
+	decisions := make([]*autovote.Decision, 0)
+	for _, proposal := range proposals {
+		if proposal.Status != govtypes.StatusVotingPeriod {
+			continue
+		}
+		voted, err := heliosNetwork.HasVoted(ctx, proposal.Id, g.accAddress.String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get our vote on proposal %d", proposal.Id)
+		}
+		if voted {
+			continue
+		}
+		if decision := config.Evaluate(proposalFacts(heliosNetwork.Codec(), proposal), now); decision != nil {
+			decisions = append(decisions, decision)
+		}
+	}
+
+	return decisions, nil
+}
+
+// runAutoVote casts the due votes of the auto-vote engine. The configuration is read on
+// every run so that enabling the engine or changing its rules needs no restart.
+func (g *Global) runAutoVote(ctx context.Context) {
+	ticker := time.NewTicker(autoVoteLoopInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		config, err := g.GetAutoVoteConfig()
+		if err != nil {
+			loopLogger("auto_vote").WithError(err).Warningln("failed to load config")
+			continue
+		}
+		if !config.Enabled {
+			continue
+		}
+
+		decisions, err := g.planAutoVotes(ctx, config)
+		if err != nil {
+			loopLogger("auto_vote").WithError(err).Warningln("failed to plan votes")
+			continue
+		}
+		for _, decision := range decisions {
+			if decision.Due {
+				g.castAutoVote(ctx, decision)
+			}
+		}
+	}
+}
+
+func (g *Global) castAutoVote(ctx context.Context, decision *autovote.Decision) {
+	logger := loopLogger("auto_vote").WithFields(log.Fields{
+		"proposal_id": decision.ProposalId,
+		"option":      decision.Option,
+		"rule":        decision.Rule,
+	})
+	txHash := ""
+	err := func() error {
+		option, err := autovote.ParseOption(decision.Option)
+		if err != nil {
+			return err
+		}
+		msg, err := g.heliosNetwork.VoteOnProposalWithOptionMsg(ctx, decision.ProposalId, g.accAddress, option)
+		if err != nil {
+			return err
+		}
+		resp, err := g.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
+		if err != nil {
+			return err
+		}
+		txHash = resp.TxHash
+		return nil
+	}()
+
+	voteErr := ""
+	if err != nil {
+		voteErr = err.Error()
+		logger.WithError(err).Errorln("failed to vote")
+	} else {
+		logger.WithField(logs.FieldTxHash, txHash).Infoln("voted")
+	}
+	if err := storage.RecordAutoVote(decision.ProposalId, decision.Title, decision.Rule, decision.Option, txHash, voteErr); err != nil {
+		logger.WithError(err).Warningln("failed to log vote")
+	}
+}
+
+// proposalFacts extracts the msg types, target chains and proposer the rules match on.
+func proposalFacts(cdc codec.Codec, proposal *govtypes.Proposal) autovote.Proposal {
+	facts := autovote.Proposal{
+		Id:       proposal.Id,
+		Title:    proposal.Title,
+		Proposer: proposal.Proposer,
+		MsgTypes: make([]string, 0),
+		ChainIds: make([]uint64, 0),
+	}
+	if proposal.VotingEndTime != nil {
+		facts.VotingEnd = *proposal.VotingEndTime
+	}
+
+	for _, msg := range gov.DecodeProposalMsgs(cdc, proposal) {
+		facts.MsgTypes = append(facts.MsgTypes, msg.TypeUrl)
+		facts.ChainIds = append(facts.ChainIds, autovote.ChainIdsFromJSON(msg.JSON)...)
+	}
+
+	return facts
+}
@@ -0,0 +1,341 @@
+package global
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/outbox"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
+)
+
+const (
+	// maxBroadcastInterval is the flush interval of an almost empty queue, it shrinks
+	// down to minBroadcastInterval as the queue fills up
+	maxBroadcastInterval = 15 * time.Second
+	minBroadcastInterval = 2 * time.Second
+	// confirmBroadcastInterval caps the wait of a queue holding valset or batch confirms
+	confirmBroadcastInterval = 5 * time.Second
+	broadcastLoopResolution  = 1 * time.Second
+
+	// maxMsgsPerBundle is the largest number of msgs merged into a single Helios tx
+	maxMsgsPerBundle = 50
+)
+
+// broadcastLane orders the queued messages, lower lanes are broadcast first.
+type broadcastLane int
+
+const (
+	laneConfirms broadcastLane = iota
+	laneClaims
+	laneBatchRequests
+	laneOthers
+)
+
+func (l broadcastLane) String() string {
+	switch l {
+	case laneConfirms:
+		return "confirms"
+	case laneClaims:
+		return "claims"
+	case laneBatchRequests:
+		return "batch_requests"
+	}
+	return "others"
+}
+
+func laneOf(msg sdk.Msg) broadcastLane {
+	switch msg.(type) {
+	case *hyperiontypes.MsgValsetConfirm, *hyperiontypes.MsgConfirmBatch, *hyperiontypes.MsgConfirmMultipleBatches:
+		return laneConfirms
+	case *hyperiontypes.MsgDepositClaim, *hyperiontypes.MsgWithdrawClaim, *hyperiontypes.MsgValsetUpdatedClaim,
+		*hyperiontypes.MsgERC20DeployedClaim, *hyperiontypes.MsgExternalDataClaim:
+		return laneClaims
+	case *hyperiontypes.MsgRequestBatch, *hyperiontypes.MsgRequestBatchWithMinimumFee:
+		return laneBatchRequests
+	}
+	return laneOthers
+}
+
+type queuedMessage struct {
+	msgs     []sdk.Msg
+	respChan chan<- *sdk.TxResponse
+	errChan  chan<- error
+	// outboxID is the journal entry of the msgs, empty if they could not be journaled
+	outboxID string
+	// spanCtx is the span of the caller waiting for the broadcast, the bundle links to it
+	spanCtx trace.SpanContext
+}
+
+// lane of a queued message is the highest priority lane of its msgs
+func (q queuedMessage) lane() broadcastLane {
+	lane := laneOthers
+	for _, msg := range q.msgs {
+		if msgLane := laneOf(msg); msgLane < lane {
+			lane = msgLane
+		}
+	}
+	return lane
+}
+
+// HeliosBroadcastManager merges the msgs queued by every chain into Helios txs.
+// Each lane is broadcast in its own txs, and a rejected tx is bisected until the
+// offending queued message is isolated, so that one bad msg only fails its own caller.
+type HeliosBroadcastManager struct {
+	messageQueue chan queuedMessage // Unbuffered channel for messages to be broadcast
+	outbox       *outbox.Outbox
+}
+
+func (h *HeliosBroadcastManager) runBroadcastLoop(g *Global) {
+	ticker := time.NewTicker(broadcastLoopResolution)
+	defer ticker.Stop()
+
+	queuedMessages := make([]queuedMessage, 0)
+	lastFlush := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+			if len(queuedMessages) == 0 {
+				lastFlush = time.Now()
+				continue
+			}
+			if time.Since(lastFlush) < nextBroadcastInterval(queuedMessages) {
+				continue
+			}
+			loopLogger("broadcast").WithField("queued", len(queuedMessages)).Debugln("processing the broadcast queue")
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						loopLogger("broadcast").WithField("panic", r).Errorln("recovered from panic during processBatch")
+					}
+				}()
+				h.processBatch(g, queuedMessages)
+			}()
+			queuedMessages = make([]queuedMessage, 0)
+			lastFlush = time.Now()
+		case qMsg := <-h.messageQueue:
+			loopLogger("broadcast").WithField("lane", qMsg.lane()).WithField("msgs", len(qMsg.msgs)).Debugln("message received, adding to queue")
+			queuedMessages = append(queuedMessages, qMsg)
+		}
+	}
+}
+
+// nextBroadcastInterval shortens the flush interval as the queue pressure grows.
+func nextBroadcastInterval(queued []queuedMessage) time.Duration {
+	pending := 0
+	hasConfirms := false
+	for _, qMsg := range queued {
+		pending += len(qMsg.msgs)
+		if qMsg.lane() == laneConfirms {
+			hasConfirms = true
+		}
+	}
+
+	interval := minBroadcastInterval
+	if pending < maxMsgsPerBundle {
+		interval = maxBroadcastInterval - (maxBroadcastInterval-minBroadcastInterval)*time.Duration(pending)/maxMsgsPerBundle
+	}
+	if hasConfirms && interval > confirmBroadcastInterval {
+		interval = confirmBroadcastInterval
+	}
+
+	return interval
+}
+
+func (h *HeliosBroadcastManager) processBatch(g *Global, batch []queuedMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	if g.heliosNetwork == nil {
+		loopLogger("broadcast").Warningln("helios network not initialized, retrying in 1s")
+		// wait for 1 second and try again
+		time.Sleep(1 * time.Second)
+		if g.heliosNetwork == nil {
+			loopLogger("broadcast").Errorln("helios network not initialized after 1s")
+			for _, qMsg := range batch {
+				qMsg.errChan <- errors.New("helios network not initialized")
+			}
+			return
+		}
+	}
+
+	links := make([]trace.SpanContext, 0, len(batch))
+	for _, qMsg := range batch {
+		links = append(links, qMsg.spanCtx)
+	}
+	ctx, span := tracing.StartLinked(context.Background(), "broadcast.process", links, attribute.Int("hyperion.queued", len(batch)))
+	defer span.End()
+
+	sorted := make([]queuedMessage, len(batch))
+	copy(sorted, batch)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].lane() < sorted[j].lane()
+	})
+
+	heliosNetwork := g.heliosNetwork
+	send := func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+		resp, err := heliosNetwork.SyncBroadcastMsg(msgs...)
+		if err != nil && isConnectionError(err) {
+			// let the next bundles go to another endpoint if this one is down
+			heliosNetwork.FailoverIfUnhealthy()
+		}
+		return resp, err
+	}
+
+	for _, bundle := range splitBundles(sorted) {
+		broadcastBundle(ctx, send, h.settle, bundle)
+	}
+}
+
+// settle removes from the outbox the msgs that were included in a tx or rejected by Helios.
+func (h *HeliosBroadcastManager) settle(qMsg queuedMessage) {
+	if h.outbox == nil || qMsg.outboxID == "" {
+		return
+	}
+	if err := h.outbox.Remove(qMsg.outboxID); err != nil {
+		loopLogger("broadcast").WithError(err).Warningln("failed to remove message from outbox")
+	}
+}
+
+// splitBundles groups the queued messages of the same lane into bundles of at most
+// maxMsgsPerBundle msgs. A queued message is never split.
+func splitBundles(sorted []queuedMessage) [][]queuedMessage {
+	bundles := make([][]queuedMessage, 0)
+	current := make([]queuedMessage, 0)
+	currentMsgs := 0
+	for _, qMsg := range sorted {
+		if len(current) > 0 && (current[0].lane() != qMsg.lane() || currentMsgs+len(qMsg.msgs) > maxMsgsPerBundle) {
+			bundles = append(bundles, current)
+			current = make([]queuedMessage, 0)
+			currentMsgs = 0
+		}
+		current = append(current, qMsg)
+		currentMsgs += len(qMsg.msgs)
+	}
+	if len(current) > 0 {
+		bundles = append(bundles, current)
+	}
+	return bundles
+}
+
+// broadcastBundle sends the bundle as one tx. When Helios rejects it, the bundle is
+// bisected and both halves are retried until the rejected queued message is isolated.
+func broadcastBundle(ctx context.Context, send func(msgs ...sdk.Msg) (*sdk.TxResponse, error), settle func(queuedMessage), bundle []queuedMessage) {
+	allMsgs := make([]sdk.Msg, 0)
+	for _, qMsg := range bundle {
+		allMsgs = append(allMsgs, qMsg.msgs...)
+	}
+
+	ctx, span := tracing.Start(ctx, "broadcast.bundle",
+		attribute.String("hyperion.lane", bundle[0].lane().String()),
+		attribute.Int("hyperion.queued", len(bundle)),
+		tracing.AttrCount.Int(len(allMsgs)),
+	)
+
+	start := time.Now()
+	_, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "SyncBroadcastMsg")
+	resp, err := send(allMsgs...)
+	if err == nil && resp == nil {
+		err = errors.New("empty broadcast response")
+	} else if err == nil && resp.Code != 0 {
+		err = errors.Errorf("tx %s failed with code %d: %s", resp.TxHash, resp.Code, resp.RawLog)
+	}
+	if resp != nil {
+		rpcSpan.SetAttributes(tracing.AttrTxHash.String(resp.TxHash))
+	}
+	tracing.End(rpcSpan, err)
+	tracing.End(span, err)
+
+	if err == nil {
+		for _, qMsg := range bundle {
+			settle(qMsg)
+			qMsg.respChan <- resp
+		}
+		loopLogger("broadcast").WithFields(log.Fields{
+			logs.FieldTxHash: resp.TxHash,
+			"code":           resp.Code,
+			"lane":           bundle[0].lane(),
+			"duration":       time.Since(start).String(),
+			"msgs":           len(allMsgs),
+		}).Infoln("messages broadcast")
+		return
+	}
+
+	if isTransientBroadcastError(err) {
+		loopLogger("broadcast").WithError(err).WithField("lane", bundle[0].lane()).Warningln("batched messages failed")
+		for _, qMsg := range bundle {
+			qMsg.errChan <- errors.Wrap(err, "runBroadcastLoop batched Msgs failed")
+		}
+		return
+	}
+
+	if len(bundle) == 1 {
+		loopLogger("broadcast").WithError(err).WithField("lane", bundle[0].lane()).WithField("msg_types", msgTypeURLs(bundle[0].msgs)).Warningln("isolated rejected message")
+		settle(bundle[0])
+		bundle[0].errChan <- errors.Wrapf(err, "Helios rejected %s", strings.Join(msgTypeURLs(bundle[0].msgs), ", "))
+		return
+	}
+
+	loopLogger("broadcast").WithError(err).WithField("queued", len(bundle)).Warningln("bundle rejected, bisecting")
+	middle := len(bundle) / 2
+	broadcastBundle(ctx, send, settle, bundle[:middle])
+	broadcastBundle(ctx, send, settle, bundle[middle:])
+}
+
+// isTransientBroadcastError tells apart failures of the connection to Helios, which say
+// nothing about the msgs, from rejections of the msgs themselves.
+func isTransientBroadcastError(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"connection refused",
+		"connection reset",
+		"unavailable",
+		"deadline exceeded",
+		"timed out",
+		"unexpected eof",
+		"account sequence mismatch",
+		"mempool is full",
+		"tx already exists in cache",
+	} {
+		if strings.Contains(errStr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectionError tells whether the broadcast failed to reach the Helios endpoint.
+func isConnectionError(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"connection refused",
+		"connection reset",
+		"unavailable",
+		"deadline exceeded",
+		"unexpected eof",
+	} {
+		if strings.Contains(errStr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func msgTypeURLs(msgs []sdk.Msg) []string {
+	typeURLs := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		typeURLs = append(typeURLs, sdk.MsgTypeURL(msg))
+	}
+	return typeURLs
+}
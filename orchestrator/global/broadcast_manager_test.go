@@ -0,0 +1,98 @@
+package global
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+type testCaller struct {
+	resp chan *sdk.TxResponse
+	err  chan error
+}
+
+func newQueued(msgs ...sdk.Msg) (queuedMessage, testCaller) {
+	caller := testCaller{resp: make(chan *sdk.TxResponse, 1), err: make(chan error, 1)}
+	return queuedMessage{msgs: msgs, respChan: caller.resp, errChan: caller.err}, caller
+}
+
+func TestBroadcastBundleIsolatesRejectedMessage(t *testing.T) {
+	bad := &hyperiontypes.MsgDepositClaim{EventNonce: 666}
+
+	bundle := make([]queuedMessage, 0)
+	callers := make([]testCaller, 0)
+	for i := uint64(1); i <= 5; i++ {
+		var msg sdk.Msg = &hyperiontypes.MsgDepositClaim{EventNonce: i}
+		if i == 4 {
+			msg = bad
+		}
+		qMsg, caller := newQueued(msg)
+		bundle = append(bundle, qMsg)
+		callers = append(callers, caller)
+	}
+
+	broadcasts := 0
+	send := func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+		broadcasts++
+		for _, msg := range msgs {
+			if msg == bad {
+				return &sdk.TxResponse{TxHash: "BAD", Code: 18, RawLog: "invalid claim"}, nil
+			}
+		}
+		return &sdk.TxResponse{TxHash: "OK"}, nil
+	}
+
+	broadcastBundle(context.Background(), send, func(queuedMessage) {}, bundle)
+
+	for i, caller := range callers {
+		if i == 3 {
+			assert.Error(t, <-caller.err)
+			continue
+		}
+		resp := <-caller.resp
+		assert.Equal(t, "OK", resp.TxHash)
+	}
+	assert.True(t, broadcasts < 2*len(bundle))
+}
+
+func TestBroadcastBundleTransientErrorFailsAll(t *testing.T) {
+	first, firstCaller := newQueued(&hyperiontypes.MsgValsetConfirm{Nonce: 1})
+	second, secondCaller := newQueued(&hyperiontypes.MsgValsetConfirm{Nonce: 2})
+
+	broadcasts := 0
+	broadcastBundle(context.Background(), func(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+		broadcasts++
+		return nil, errors.New("rpc error: code = Unavailable desc = connection refused")
+	}, func(queuedMessage) {}, []queuedMessage{first, second})
+
+	assert.Equal(t, 1, broadcasts)
+	assert.Error(t, <-firstCaller.err)
+	assert.Error(t, <-secondCaller.err)
+}
+
+func TestSplitBundlesByLane(t *testing.T) {
+	confirm, _ := newQueued(&hyperiontypes.MsgConfirmBatch{Nonce: 1})
+	claim, _ := newQueued(&hyperiontypes.MsgWithdrawClaim{EventNonce: 1})
+	request, _ := newQueued(&hyperiontypes.MsgRequestBatch{Denom: "ahelios"})
+
+	bundles := splitBundles([]queuedMessage{confirm, claim, request})
+	assert.Len(t, bundles, 3)
+	assert.Equal(t, laneConfirms, bundles[0][0].lane())
+	assert.Equal(t, laneBatchRequests, bundles[2][0].lane())
+
+	assert.Equal(t, maxBroadcastInterval, nextBroadcastInterval([]queuedMessage{}))
+	assert.Equal(t, confirmBroadcastInterval, nextBroadcastInterval([]queuedMessage{confirm}))
+	assert.True(t, nextBroadcastInterval([]queuedMessage{claim}) > confirmBroadcastInterval)
+
+	many := make([]sdk.Msg, maxMsgsPerBundle)
+	for i := range many {
+		many[i] = &hyperiontypes.MsgWithdrawClaim{EventNonce: uint64(i)}
+	}
+	full, _ := newQueued(many...)
+	assert.Equal(t, minBroadcastInterval, nextBroadcastInterval([]queuedMessage{full}))
+}
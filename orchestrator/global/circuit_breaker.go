@@ -0,0 +1,38 @@
+package global
+
+import (
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/breaker"
+)
+
+func (g *Global) GetCircuitBreakerConfig() (*breaker.Config, error) {
+	path, err := breaker.DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return breaker.LoadConfig(path)
+}
+
+// SetCircuitBreakerConfig replaces the circuit breaker configuration, the running
+// orchestrators read it on every tripwire check.
+func (g *Global) SetCircuitBreakerConfig(config *breaker.Config) error {
+	path, err := breaker.DefaultConfigPath()
+	if err != nil {
+		return err
+	}
+	if config.ChainIds == nil {
+		config.ChainIds = []uint64{}
+	}
+	return breaker.SaveConfig(path, config)
+}
+
+func (g *Global) GetCircuitBreakerState(chainId uint64) (*breaker.State, error) {
+	return orchestrator.CircuitBreakerState(chainId)
+}
+
+// RearmCircuitBreaker lets the tripwires of the chain act again. Deposits and withdrawals
+// stay paused until they are resumed with pause-or-unpause-deposit and
+// pause-or-unpause-withdrawal.
+func (g *Global) RearmCircuitBreaker(chainId uint64) (*breaker.State, error) {
+	return orchestrator.RearmCircuitBreaker(chainId)
+}
@@ -0,0 +1,406 @@
+package global
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	wrappers "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+// Stages of the deployment pipeline of a Hyperion contract, in order. The stage of a
+// deployment is the last step completed.
+const (
+	DeploymentStageNone              = ""
+	DeploymentStageDeployed          = "deployed"
+	DeploymentStageCodeVerified      = "code_verified"
+	DeploymentStageInitialized       = "initialized"
+	DeploymentStageErc20sDeployed    = "erc20s_deployed"
+	DeploymentStageProposalSubmitted = "proposal_submitted"
+)
+
+// DeploymentToken is an ERC20 deployed through the Hyperion contract before the proposal.
+type DeploymentToken struct {
+	Denom    string `json:"denom"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// DeploymentRequest holds the inputs of the pipeline, it is persisted with the state so
+// that a deployment can be resumed with the chain id only.
+type DeploymentRequest struct {
+	ChainId                      uint64            `json:"chain_id"`
+	Title                        string            `json:"title"`
+	Description                  string            `json:"description"`
+	BridgeChainName              string            `json:"bridge_chain_name"`
+	AverageCounterpartyBlockTime uint64            `json:"average_counterparty_block_time"`
+	Tokens                       []DeploymentToken `json:"tokens"`
+}
+
+// DeploymentState is the persisted state of the deployment pipeline of a chain.
+type DeploymentState struct {
+	Request DeploymentRequest `json:"request"`
+	Stage   string            `json:"stage"`
+	// ContractType is "new" for a chain not registered on Helios yet, "update" otherwise
+	ContractType       string            `json:"contract_type"`
+	HyperionAddress    string            `json:"hyperion_address"`
+	DeployedAtBlock    uint64            `json:"deployed_at_block"`
+	InitializedAtBlock uint64            `json:"initialized_at_block"`
+	CodeHash           string            `json:"code_hash"`
+	ContractSourceHash string            `json:"contract_source_hash"`
+	Erc20s             map[string]string `json:"erc20s"`
+	ProposalId         uint64            `json:"proposal_id"`
+	LastError          string            `json:"last_error,omitempty"`
+	LastVerifiedAt     time.Time         `json:"last_verified_at"`
+	StartedAt          time.Time         `json:"started_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	CompletedStagesAt  map[string]string `json:"completed_stages_at"`
+}
+
+func (s *DeploymentState) Done() bool {
+	return s.Stage == DeploymentStageProposalSubmitted
+}
+
+func (g *Global) GetDeployment(chainId uint64) (*DeploymentState, error) {
+	state := &DeploymentState{}
+	found, err := storage.GetDeploymentState(chainId, state)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no deployment started for chain %d", chainId)
+	}
+	return state, nil
+}
+
+func (g *Global) ResetDeployment(chainId uint64) error {
+	if !g.claimDeployment(chainId) {
+		return fmt.Errorf("deployment of chain %d is running", chainId)
+	}
+	defer g.releaseDeployment(chainId)
+	return storage.RemoveDeploymentState(chainId)
+}
+
+// claimDeployment marks the deployment of the chain as running, it returns false when
+// another call already runs it so that no step is sent twice.
+func (g *Global) claimDeployment(chainId uint64) bool {
+	g.deployingMu.Lock()
+	defer g.deployingMu.Unlock()
+	if g.deploying[chainId] {
+		return false
+	}
+	g.deploying[chainId] = true
+	return true
+}
+
+func (g *Global) releaseDeployment(chainId uint64) {
+	g.deployingMu.Lock()
+	delete(g.deploying, chainId)
+	g.deployingMu.Unlock()
+}
+
+// RunDeployment starts the deployment pipeline of the chain, or resumes it from the last
+// completed stage. The request is only used to start a deployment, a resumed deployment
+// keeps the request it was started with. The deployed bytecode is verified against the
+// bundled artifact before every step following the deployment.
+func (g *Global) RunDeployment(ctx context.Context, request *DeploymentRequest) (*DeploymentState, error) {
+	if g.GetHeliosNetwork() == nil {
+		return nil, fmt.Errorf("helios network not initialized")
+	}
+
+	if !g.claimDeployment(request.ChainId) {
+		return nil, fmt.Errorf("deployment of chain %d is already running", request.ChainId)
+	}
+	defer g.releaseDeployment(request.ChainId)
+
+	state := &DeploymentState{}
+	found, err := storage.GetDeploymentState(request.ChainId, state)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		state = &DeploymentState{
+			Request:           *request,
+			StartedAt:         time.Now(),
+			Erc20s:            map[string]string{},
+			CompletedStagesAt: map[string]string{},
+		}
+		if err := g.saveDeployment(state); err != nil {
+			return nil, err
+		}
+	}
+
+	for !state.Done() {
+		if err := g.runDeploymentStep(ctx, state); err != nil {
+			state.LastError = err.Error()
+			g.saveDeployment(state)
+			return state, errors.Wrapf(err, "deployment of chain %d failed after stage %q", state.Request.ChainId, state.Stage)
+		}
+		state.LastError = ""
+		state.CompletedStagesAt[state.Stage] = time.Now().Format(time.RFC3339)
+		if err := g.saveDeployment(state); err != nil {
+			return state, err
+		}
+		loopLogger("deployment").WithField(logs.FieldChainId, state.Request.ChainId).WithField("stage", state.Stage).Infoln("deployment reached stage")
+	}
+
+	return state, nil
+}
+
+func (g *Global) saveDeployment(state *DeploymentState) error {
+	state.UpdatedAt = time.Now()
+	return storage.SaveDeploymentState(state.Request.ChainId, state)
+}
+
+// runDeploymentStep completes the step following the current stage.
+func (g *Global) runDeploymentStep(ctx context.Context, state *DeploymentState) error {
+	chainId := state.Request.ChainId
+
+	if state.Stage != DeploymentStageNone {
+		if err := g.verifyDeployment(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	switch state.Stage {
+	case DeploymentStageNone:
+		contractType, err := g.deploymentContractType(ctx, chainId)
+		if err != nil {
+			return err
+		}
+		hyperionAddress, atBlockNumber, success := g.DeployNewHyperionContract(chainId)
+		if !success {
+			return fmt.Errorf("failed to deploy hyperion contract")
+		}
+		state.ContractType = contractType
+		state.HyperionAddress = hyperionAddress.Hex()
+		state.DeployedAtBlock = atBlockNumber
+
+		// the contract info is read by the initialization and proposal steps
+		if err := storage.UpdateHyperionContractInfo(chainId, map[string]interface{}{
+			"hyperionAddress":          hyperionAddress.Hex(),
+			"chainId":                  chainId,
+			"createdAt":                time.Now().Format(time.RFC3339),
+			"atBlockNumber":            atBlockNumber,
+			"initializedAtBlockNumber": atBlockNumber + 1000,
+			"type":                     contractType,
+			"proposed":                 false,
+		}); err != nil {
+			return err
+		}
+		state.Stage = DeploymentStageDeployed
+
+	case DeploymentStageDeployed:
+		// verified above
+		state.Stage = DeploymentStageCodeVerified
+
+	case DeploymentStageCodeVerified:
+		// a previous run may have initialized the contract before failing to save the stage
+		blockNumber, initialized, err := g.deploymentInitializedAtBlock(ctx, state)
+		if err != nil {
+			return err
+		}
+		if initialized {
+			storage.UpdateHyperionContractInfo(chainId, map[string]interface{}{
+				"initializedAtBlockNumber": blockNumber,
+			})
+		} else {
+			blockNumber, err = g.InitializeHyperionContractWithDefaultValset(chainId)
+			if err != nil {
+				return errors.Wrap(err, "failed to initialize hyperion contract")
+			}
+		}
+		state.InitializedAtBlock = blockNumber
+		state.Stage = DeploymentStageInitialized
+
+	case DeploymentStageInitialized:
+		if err := g.deployDeploymentTokens(ctx, state); err != nil {
+			return err
+		}
+		state.Stage = DeploymentStageErc20sDeployed
+
+	case DeploymentStageErc20sDeployed:
+		proposedParams := &hyperiontypes.CounterpartyChainParams{
+			HyperionId:                   chainId,
+			BridgeChainId:                chainId,
+			BridgeChainName:              state.Request.BridgeChainName,
+			AverageCounterpartyBlockTime: state.Request.AverageCounterpartyBlockTime,
+			BridgeCounterpartyAddress:    state.HyperionAddress,
+			BridgeContractStartHeight:    state.InitializedAtBlock + 1, // +1 because the start height is the first block who hyperion will start listening
+		}
+
+		var proposalId uint64
+		var err error
+		if state.ContractType == "update" {
+			proposalId, err = g.ProposeHyperionUpdate(state.Request.Title, state.Request.Description, proposedParams)
+		} else {
+			proposalId, err = g.CreateNewBlockchainProposal(state.Request.Title, state.Request.Description, proposedParams)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to submit proposal")
+		}
+		state.ProposalId = proposalId
+		storage.UpdateHyperionContractInfo(chainId, map[string]interface{}{
+			"proposed": true,
+		})
+
+		time.Sleep(4 * time.Second)
+		if err := g.VoteOnProposal(proposalId); err != nil {
+			loopLogger("deployment").WithError(err).WithField(logs.FieldChainId, state.Request.ChainId).WithField("proposal_id", proposalId).Warningln("failed to vote on proposal")
+		}
+		state.Stage = DeploymentStageProposalSubmitted
+
+	default:
+		return fmt.Errorf("unknown deployment stage %q", state.Stage)
+	}
+
+	return nil
+}
+
+func (g *Global) deploymentContractType(ctx context.Context, chainId uint64) (string, error) {
+	hyperionParams, err := (*g.GetHeliosNetwork()).HyperionParams(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, counterpartyChainParam := range hyperionParams.CounterpartyChainParams {
+		if counterpartyChainParam.BridgeChainId == chainId {
+			return "update", nil
+		}
+	}
+	return "new", nil
+}
+
+// verifyDeployment checks that the code at the contract address is the runtime bytecode
+// of the bundled artifact, whose creation bytecode hashes to the contract source hash we
+// propose to Helios.
+func (g *Global) verifyDeployment(ctx context.Context, state *DeploymentState) error {
+	expectedCode, err := wrappers.HyperionRuntimeBin()
+	if err != nil {
+		return err
+	}
+
+	targetNetworks, err := g.InitTargetNetworks(&hyperiontypes.CounterpartyChainParams{
+		BridgeChainId:             state.Request.ChainId,
+		BridgeCounterpartyAddress: state.HyperionAddress,
+	})
+	if err != nil {
+		return err
+	}
+	if len(targetNetworks) == 0 {
+		return fmt.Errorf("no target networks found for chainId: %d", state.Request.ChainId)
+	}
+
+	code, err := (*targetNetworks[0]).GetCode(ctx, gethcommon.HexToAddress(state.HyperionAddress))
+	if err != nil {
+		return errors.Wrap(err, "failed to get deployed bytecode")
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract deployed at %s", state.HyperionAddress)
+	}
+
+	codeHash := crypto.Keccak256Hash(code).Hex()
+	if !bytes.Equal(code, expectedCode) {
+		return fmt.Errorf("bytecode at %s (hash %s) does not match the bundled Hyperion artifact (hash %s)", state.HyperionAddress, codeHash, crypto.Keccak256Hash(expectedCode).Hex())
+	}
+
+	state.CodeHash = codeHash
+	state.ContractSourceHash = wrappers.HyperionSourceHash()
+	state.LastVerifiedAt = time.Now()
+	return nil
+}
+
+// deploymentInitializedAtBlock returns the block the contract was initialized at, the
+// contract is initialized once its valset checkpoint is set.
+func (g *Global) deploymentInitializedAtBlock(ctx context.Context, state *DeploymentState) (uint64, bool, error) {
+	targetNetworks, err := g.InitTargetNetworks(&hyperiontypes.CounterpartyChainParams{
+		BridgeChainId:             state.Request.ChainId,
+		BridgeCounterpartyAddress: state.HyperionAddress,
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(targetNetworks) == 0 {
+		return 0, false, fmt.Errorf("no target networks found for chainId: %d", state.Request.ChainId)
+	}
+	targetNetwork := targetNetworks[0]
+
+	checkpoint, err := (*targetNetwork).GetLastValsetCheckpoint(ctx)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to get valset checkpoint")
+	}
+	if checkpoint == nil || *checkpoint == (gethcommon.Hash{}) {
+		return 0, false, nil
+	}
+
+	// the initialization emits the ValsetUpdated event of nonce 0
+	latestHeader, err := (*targetNetwork).GetHeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to get latest header")
+	}
+	events, err := (*targetNetwork).GetValsetUpdatedEventsWithIndexedNonce(0, state.DeployedAtBlock, latestHeader.Number.Uint64())
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to get ValsetUpdated events")
+	}
+	if len(events) == 0 {
+		return 0, false, fmt.Errorf("contract %s is initialized but its ValsetUpdated event of nonce 0 was not found", state.HyperionAddress)
+	}
+	return events[0].Raw.BlockNumber, true, nil
+}
+
+// deployDeploymentTokens deploys the ERC20s of the request, the tokens already deployed
+// by a previous run are skipped.
+func (g *Global) deployDeploymentTokens(ctx context.Context, state *DeploymentState) error {
+	if len(state.Request.Tokens) == 0 {
+		return nil
+	}
+
+	targetNetworks, err := g.InitTargetNetworks(&hyperiontypes.CounterpartyChainParams{
+		BridgeChainId:             state.Request.ChainId,
+		BridgeCounterpartyAddress: state.HyperionAddress,
+	})
+	if err != nil {
+		return err
+	}
+	if len(targetNetworks) == 0 {
+		return fmt.Errorf("no target networks found for chainId: %d", state.Request.ChainId)
+	}
+	targetNetwork := targetNetworks[0]
+
+	for _, token := range state.Request.Tokens {
+		if _, ok := state.Erc20s[token.Denom]; ok {
+			continue
+		}
+
+		_, blockNumber, err := (*targetNetwork).DeployERC20(ctx, g.GetAddress(), token.Denom, token.Name, token.Symbol, token.Decimals)
+		if err != nil {
+			return errors.Wrapf(err, "failed to deploy ERC20 %s", token.Denom)
+		}
+		events, err := (*targetNetwork).GetHyperionERC20DeployedEvents(blockNumber, blockNumber)
+		if err != nil {
+			return errors.Wrap(err, "failed to get ERC20Deployed events")
+		}
+		for _, event := range events {
+			if event.HeliosDenom == token.Denom {
+				state.Erc20s[token.Denom] = event.TokenContract.Hex()
+			}
+		}
+		if _, ok := state.Erc20s[token.Denom]; !ok {
+			return fmt.Errorf("ERC20Deployed event of %s not found at block %d", token.Denom, blockNumber)
+		}
+
+		// persist each token so that a failure on the next one does not deploy it again
+		if err := g.saveDeployment(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
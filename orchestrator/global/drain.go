@@ -0,0 +1,150 @@
+package global
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+// Statuses of the runner of a chain.
+const (
+	RunnerRunning  = "running"
+	RunnerDraining = "draining"
+	RunnerStopped  = "stopped"
+)
+
+const defaultDrainTimeout = 2 * time.Minute
+
+func (g *Global) drainTimeout() time.Duration {
+	timeout, err := time.ParseDuration(g.cfg.DrainTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultDrainTimeout
+	}
+	return timeout
+}
+
+// GetRunnerStatus returns whether the runner of the chain runs, drains or is stopped.
+func (g *Global) GetRunnerStatus(chainId uint64) string {
+	g.drainingMu.Lock()
+	draining := g.draining[chainId]
+	g.drainingMu.Unlock()
+
+	if draining {
+		return RunnerDraining
+	}
+	if g.GetRunner(chainId) != nil {
+		return RunnerRunning
+	}
+	return RunnerStopped
+}
+
+// StopRunner drains the runner of the chain in the background and removes it once
+// stopped, see DrainRunner.
+func (g *Global) StopRunner(chainId uint64) error {
+	if g.GetRunner(chainId) == nil {
+		return fmt.Errorf("runner for chainId %d not found", chainId)
+	}
+	if g.GetRunnerStatus(chainId) == RunnerDraining {
+		return fmt.Errorf("runner for chainId %d is already draining", chainId)
+	}
+
+	go func() {
+		if _, err := g.DrainRunner(chainId); err != nil {
+			loopLogger("drain").WithError(err).WithField(logs.FieldChainId, chainId).Errorln("failed to drain runner")
+		}
+		g.CancelRunner(chainId)
+	}()
+	return nil
+}
+
+// DrainRunner stops the loops of the chain from taking new work and waits, until the drain
+// timeout, for the iterations in flight to finish and for the Helios msgs of the chain to
+// leave the outbox. The state left is persisted, then the context of the runner is
+// cancelled. The runner is kept in the runners file so that it starts again.
+func (g *Global) DrainRunner(chainId uint64) (*orchestrator.DrainState, error) {
+	cancel := g.GetRunner(chainId)
+	if cancel == nil {
+		return nil, fmt.Errorf("runner for chainId %d not found", chainId)
+	}
+
+	g.drainingMu.Lock()
+	if g.draining[chainId] {
+		g.drainingMu.Unlock()
+		return nil, fmt.Errorf("runner for chainId %d is already draining", chainId)
+	}
+	g.draining[chainId] = true
+	g.drainingMu.Unlock()
+	defer func() {
+		g.drainingMu.Lock()
+		delete(g.draining, chainId)
+		g.drainingMu.Unlock()
+	}()
+
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), g.drainTimeout())
+	defer cancelTimeout()
+
+	state := &orchestrator.DrainState{ChainId: chainId, StartedAt: time.Now()}
+	if o := g.GetOrchestrator(chainId); o != nil {
+		state = o.Drain(ctx)
+	}
+	state.OutboxDepth = g.waitOutboxSettled(ctx, chainId)
+	if state.OutboxDepth > 0 {
+		state.TimedOut = true
+	}
+	state.FinishedAt = time.Now()
+
+	cancel(errors.New("runner drained"))
+
+	if err := storage.SaveDrainState(chainId, state); err != nil {
+		return state, errors.Wrap(err, "failed to save drain state")
+	}
+	return state, nil
+}
+
+// waitOutboxSettled waits for the Helios msgs of the chain to be broadcast, it returns the
+// number of msgs left when ctx is done.
+func (g *Global) waitOutboxSettled(ctx context.Context, chainId uint64) int {
+	box := g.GetOutbox()
+	if box == nil {
+		return 0
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		depth, _ := box.ChainStats(chainId)
+		if depth == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return depth
+		case <-ticker.C:
+		}
+	}
+}
+
+// DrainRunners drains the runners of every chain side by side, it is called on shutdown.
+func (g *Global) DrainRunners() {
+	var wg sync.WaitGroup
+	for chainId := range g.GetRunners() {
+		wg.Add(1)
+		go func(chainId uint64) {
+			defer wg.Done()
+			state, err := g.DrainRunner(chainId)
+			if err != nil {
+				loopLogger("drain").WithError(err).WithField(logs.FieldChainId, chainId).Errorln("failed to drain runner")
+				return
+			}
+			loopLogger("drain").WithField(logs.FieldChainId, chainId).WithField("timed_out", state.TimedOut).Infoln("runner drained")
+		}(chainId)
+	}
+	wg.Wait()
+}
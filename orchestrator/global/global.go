@@ -2,27 +2,34 @@ package global
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"cosmossdk.io/math"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/compliance"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/committer"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/keystore"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/remotesigner"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/rpcs"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
 	wrappers "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
@@ -37,20 +44,26 @@ type Config struct {
 	HeliosGasPrices string
 	HeliosGas       string
 
+	HeliosGasMultiplier float64
+
 	EthGasPriceAdjustment float64
 	EthMaxGasPrice        string
 	PendingTxWaitDuration string
-}
 
-type queuedMessage struct {
-	msgs     []sdk.Msg
-	respChan chan<- *sdk.TxResponse
-	errChan  chan<- error
-}
+	SanctionsListPath        string
+	SanctionsRefreshInterval string
+
+	// DrainTimeout bounds the wait for the work in flight when a runner stops
+	DrainTimeout string
 
-type HeliosBroadcastManager struct {
-	messageQueue chan queuedMessage // Unbuffered channel for messages to be broadcast
-	ticker       *time.Ticker
+	// EnabledLogs lists the loops writing their verbose logs
+	EnabledLogs string
+
+	EthRemoteSignerURL        string
+	EthRemoteSignerAddress    string
+	EthRemoteSignerCACert     string
+	EthRemoteSignerClientCert string
+	EthRemoteSignerClientKey  string
 }
 
 type Global struct {
@@ -61,9 +74,24 @@ type Global struct {
 	accAddress        cosmostypes.AccAddress
 
 	runners                   map[uint64]context.CancelCauseFunc
+	draining                  map[uint64]bool
+	drainingMu                sync.Mutex
+	deploying                 map[uint64]bool
+	deployingMu               sync.Mutex
+	migrating                 map[uint64]bool
+	migratingMu               sync.Mutex
 	orchestrators             map[uint64]*orchestrator.Orchestrator
 	lastTimeResetHeliosClient time.Time
 	heliosBroadcastManager    *HeliosBroadcastManager
+	sanctionsList             *compliance.SanctionsList
+	stopSanctionsRefresh      context.CancelFunc
+	// runCtx bounds the background loops of the global, cancelled by Stop on shutdown
+	runCtx  context.Context
+	stopRun context.CancelFunc
+	remoteSigner              *remotesigner.Web3Signer
+	remoteSignerMu            sync.Mutex
+	relayerWallets            map[string]*ethereum.RelayerWallet
+	statusStream              *stream.Hub
 
 	LastTryAuthTime time.Time
 
@@ -71,7 +99,8 @@ type Global struct {
 }
 
 func NewGlobal(cfg *Config) *Global {
-	return &Global{cfg: cfg, runners: make(map[uint64]context.CancelCauseFunc, 0), orchestrators: make(map[uint64]*orchestrator.Orchestrator, 0), lastTimeResetHeliosClient: time.Now(), LastTryAuthTime: time.Now(), mu: sync.Mutex{}}
+	runCtx, stopRun := context.WithCancel(context.Background())
+	return &Global{cfg: cfg, runCtx: runCtx, stopRun: stopRun, runners: make(map[uint64]context.CancelCauseFunc, 0), draining: make(map[uint64]bool), deploying: make(map[uint64]bool), migrating: make(map[uint64]bool), orchestrators: make(map[uint64]*orchestrator.Orchestrator, 0), relayerWallets: make(map[string]*ethereum.RelayerWallet, 0), statusStream: stream.NewHub(stream.DefaultHistory), lastTimeResetHeliosClient: time.Now(), LastTryAuthTime: time.Now(), mu: sync.Mutex{}}
 }
 
 func (g *Global) GetConfig() *Config {
@@ -86,92 +115,61 @@ func (g *Global) GetHeliosNetwork() *helios.Network {
 		}
 		g.heliosBroadcastManager = &HeliosBroadcastManager{
 			messageQueue: make(chan queuedMessage),
+			outbox:       g.loadOutbox(),
 		}
 		go g.heliosBroadcastManager.runBroadcastLoop(g)
+		go g.replayOutbox(g.runCtx)
+		go g.runAutoVote(g.runCtx)
+		go g.runHeliosEvents(g.runCtx)
+		go g.runStatusStream(g.runCtx)
 	}
 	return g.heliosNetwork
 }
 
-func (h *HeliosBroadcastManager) runBroadcastLoop(g *Global) {
-	h.ticker = time.NewTicker(15 * time.Second)
-	defer h.ticker.Stop()
-
-	queuedMessages := make([]queuedMessage, 0)
-	for {
-		select {
-		case <-h.ticker.C:
-			if len(queuedMessages) > 0 {
-				fmt.Println("runBroadcastLoop: processing queue (ticker)", len(queuedMessages))
-				func() {
-					defer func() {
-						if r := recover(); r != nil {
-							fmt.Println("runBroadcastLoop: recovered from panic during processBatch", r)
-						}
-					}()
-					h.processBatch(g, queuedMessages)
-				}()
-				queuedMessages = make([]queuedMessage, 0)
-			} else {
-				fmt.Println("runBroadcastLoop: no messages to process (ticker)")
-			}
-		case qMsg := <-h.messageQueue:
-			fmt.Println("runBroadcastLoop: message received, adding to queue", qMsg)
-			queuedMessages = append(queuedMessages, qMsg)
-		}
-	}
-}
-
-func (c *HeliosBroadcastManager) processBatch(g *Global, batch []queuedMessage) {
-	if len(batch) == 0 {
-		return
-	}
-
-	// Collect all messages from the batch
-	allMsgs := make([]sdk.Msg, 0)
-	allRespChans := make([]chan<- *sdk.TxResponse, 0)
-	allErrChans := make([]chan<- error, 0)
-
-	for _, qMsg := range batch {
-		allMsgs = append(allMsgs, qMsg.msgs...)
-		allRespChans = append(allRespChans, qMsg.respChan)
-		allErrChans = append(allErrChans, qMsg.errChan)
-	}
-
-	fmt.Println("processBatch: broadcasting messages", len(allMsgs), "messages")
-
-	start := time.Now()
+// GetSanctionsList returns the sanctions list used to screen batch recipients. The operator
+// file is loaded on first use and refreshed in the background, if it cannot be loaded the
+// bundled list is used instead.
+func (g *Global) GetSanctionsList() *compliance.SanctionsList {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	if g.heliosNetwork == nil {
-		fmt.Println("processBatch: helios network not initialized")
-		// wait for 1 second and try again
-		time.Sleep(1 * time.Second)
-		if g.heliosNetwork == nil {
-			fmt.Println("processBatch: helios network not initialized after 1 second")
-			return
-		}
+	if g.sanctionsList != nil {
+		return g.sanctionsList
 	}
 
-	// Broadcast the collected messages
-	resp, err := g.heliosNetwork.SyncBroadcastMsg(allMsgs...)
-
-	// Send responses back to all waiting callers
-	for i := 0; i < len(allRespChans); i++ {
+	sanctionsList, err := compliance.NewSanctionsList(g.cfg.SanctionsListPath)
+	if err != nil {
+		loopLogger("sanctions").WithError(err).WithField("path", g.cfg.SanctionsListPath).Warningln("failed to load the sanctions list, falling back to the bundled list")
+		sanctionsList, _ = compliance.NewSanctionsList("")
+	} else if g.cfg.SanctionsListPath != "" {
+		refreshInterval, err := time.ParseDuration(g.cfg.SanctionsRefreshInterval)
 		if err != nil {
-			allErrChans[i] <- errors.Wrap(err, "runBroadcastLoop batched Msgs failed")
-		} else {
-			allRespChans[i] <- resp
+			refreshInterval = time.Hour
 		}
-		close(allRespChans[i])
-		close(allErrChans[i])
+		refreshCtx, cancel := context.WithCancel(context.Background())
+		g.stopSanctionsRefresh = cancel
+		go sanctionsList.RunRefresh(refreshCtx, refreshInterval)
 	}
+	g.sanctionsList = sanctionsList
 
-	if err != nil {
-		fmt.Println("runBroadcastLoop batched messages failed", err)
-		return
+	return g.sanctionsList
+}
+
+// StopSanctionsRefresh stops reloading the sanctions list file, it is called on shutdown.
+func (g *Global) StopSanctionsRefresh() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopSanctionsRefresh != nil {
+		g.stopSanctionsRefresh()
+		g.stopSanctionsRefresh = nil
 	}
-	duration := time.Since(start)
+}
 
-	fmt.Println("runBroadcastLoop messages broadcasted successfully tx_hash:", resp.TxHash, "code:", resp.Code, "duration:", duration, "num_msgs:", len(allMsgs))
+// Stop ends the background loops of the global, the Helios endpoint health checks among
+// them. It is called on shutdown once the runners are drained.
+func (g *Global) Stop() {
+	g.StopSanctionsRefresh()
+	g.stopRun()
 }
 
 func (g *Global) ResetHeliosClient() {
@@ -179,7 +177,7 @@ func (g *Global) ResetHeliosClient() {
 	defer g.mu.Unlock()
 	if g.heliosNetwork != nil {
 		if time.Since(g.lastTimeResetHeliosClient) < 30*time.Second {
-			fmt.Println("Helios client already reset in the last 30 seconds")
+			loopLogger("global").Debugln("helios client already reset in the last 30 seconds")
 			return
 		}
 		g.heliosNetwork.Reconnect()
@@ -187,7 +185,17 @@ func (g *Global) ResetHeliosClient() {
 	}
 }
 
-func (g *Global) SyncBroadcastMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, error) {
+func (g *Global) SyncBroadcastMsgs(ctx context.Context, msgs []sdk.Msg) (resp *sdk.TxResponse, err error) {
+	// the span lasts while the msgs wait in the queue and are broadcast with the msgs
+	// of the other callers, the broadcast.process span links to it
+	ctx, span := tracing.Start(ctx, "broadcast.wait", tracing.AttrCount.Int(len(msgs)))
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(tracing.AttrTxHash.String(resp.TxHash))
+		}
+		tracing.End(span, err)
+	}()
+
 	respChan := make(chan *sdk.TxResponse, 1)
 	errChan := make(chan error, 1)
 
@@ -195,8 +203,10 @@ func (g *Global) SyncBroadcastMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.Tx
 		msgs:     msgs,
 		respChan: respChan,
 		errChan:  errChan,
+		outboxID: g.journalMsgs(ctx, msgs),
+		spanCtx:  span.SpanContext(),
 	}
-	fmt.Println("Messages queued for broadcast", len(msgs), "messages")
+	originLogger(ctx, "broadcast").WithField("msgs", len(msgs)).Debugln("messages queued for broadcast")
 
 	select {
 	case resp := <-respChan:
@@ -246,7 +256,7 @@ func (g *Global) StartRunnersAtStartUp(runHyperion func(ctx context.Context, g *
 	for _, runner := range runners {
 		err := runHyperion(context.Background(), g, uint64(runner["chainId"].(float64)))
 		if err != nil {
-			fmt.Println("Error running hyperion:", err)
+			loopLogger("runner").WithError(err).WithField(logs.FieldChainId, uint64(runner["chainId"].(float64))).Errorln("failed to run hyperion")
 		}
 	}
 }
@@ -298,19 +308,20 @@ func (g *Global) InitHeliosNetwork() (*helios.Network, error) {
 			TendermintRPC:    g.cfg.TendermintRPC,
 			GasPrice:         g.cfg.HeliosGasPrices,
 			Gas:              g.cfg.HeliosGas,
+			GasMultiplier:    g.cfg.HeliosGasMultiplier,
 			ValidatorAddress: heliosKeyring.Addr.String(),
 		}
 	)
 
-	ethKeyFromAddress, _, _, err := keys.InitEthereumAccountsManagerWithPrivateKey(&g.cfg.PrivateKey, 1)
+	ethKeyFromAddress, _, _, err := g.initEthereumAccountsManager(1)
 	if err != nil {
-		fmt.Println("Error initializing ethereum accounts manager:", err)
+		loopLogger("global").WithError(err).Errorln("failed to initialize the ethereum accounts manager")
 		return nil, err
 	}
 
-	heliosNetwork, err := helios.NewNetworkWithBroadcast(heliosKeyring, heliosNetworkCfg)
+	heliosNetwork, err := helios.NewNetworkWithBroadcast(g.runCtx, heliosKeyring, heliosNetworkCfg)
 	if err != nil {
-		fmt.Println("Error creating helios network:", err)
+		loopLogger("global").WithError(err).Errorln("failed to create the helios network")
 		return nil, err
 	}
 
@@ -320,6 +331,136 @@ func (g *Global) InitHeliosNetwork() (*helios.Network, error) {
 	return heliosNetwork, nil
 }
 
+// initEthereumAccountsManager returns the EVM account of the orchestrator. It is held by the
+// remote signer when one is configured, otherwise it is derived from the Helios private key.
+func (g *Global) initEthereumAccountsManager(chainId uint64) (gethcommon.Address, bind.SignerFn, keystore.PersonalSignFn, error) {
+	if len(g.cfg.EthRemoteSignerURL) == 0 {
+		return keys.InitEthereumAccountsManagerWithPrivateKey(&g.cfg.PrivateKey, chainId)
+	}
+
+	signer, err := g.getRemoteSigner()
+	if err != nil {
+		return gethcommon.Address{}, nil, nil, err
+	}
+	return keys.InitEthereumAccountsManagerWithRemoteSigner(signer, chainId)
+}
+
+// getRemoteSigner returns the remote signer of the orchestrator key, created on first use.
+// It has its own lock as the upcheck can take seconds.
+func (g *Global) getRemoteSigner() (*remotesigner.Web3Signer, error) {
+	g.remoteSignerMu.Lock()
+	defer g.remoteSignerMu.Unlock()
+
+	if g.remoteSigner != nil {
+		return g.remoteSigner, nil
+	}
+
+	signer, err := remotesigner.NewWeb3Signer(remotesigner.Config{
+		URL:            g.cfg.EthRemoteSignerURL,
+		Address:        g.cfg.EthRemoteSignerAddress,
+		CACertFile:     g.cfg.EthRemoteSignerCACert,
+		ClientCertFile: g.cfg.EthRemoteSignerClientCert,
+		ClientKeyFile:  g.cfg.EthRemoteSignerClientKey,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init remote signer")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := signer.Upcheck(ctx); err != nil {
+		return nil, errors.Wrap(err, "remote signer upcheck failed")
+	}
+
+	g.remoteSigner = signer
+	return g.remoteSigner, nil
+}
+
+// getRelayerWallet returns the wallet paying the relay gas on chainId, as configured in the chain
+// settings with either a keystore file or a remote signer. It returns nil when none is configured
+// and the orchestrator key pays for the relayed transactions.
+func (g *Global) getRelayerWallet(chainId uint64, settings map[string]interface{}) (*ethereum.RelayerWallet, error) {
+	keystoreFile, _ := settings["relayer_keystore_file"].(string)
+	passwordFile, _ := settings["relayer_keystore_password_file"].(string)
+	remoteSignerURL, _ := settings["relayer_remote_signer_url"].(string)
+	remoteSignerAddress, _ := settings["relayer_remote_signer_address"].(string)
+
+	if keystoreFile == "" && remoteSignerURL == "" {
+		return nil, nil
+	}
+	if keystoreFile != "" && remoteSignerURL != "" {
+		return nil, fmt.Errorf("both relayer_keystore_file and relayer_remote_signer_url are set for chainId: %d", chainId)
+	}
+
+	cacheKey := fmt.Sprintf("%d|%s|%s|%s", chainId, keystoreFile, remoteSignerURL, remoteSignerAddress)
+
+	g.mu.Lock()
+	wallet, ok := g.relayerWallets[cacheKey]
+	g.mu.Unlock()
+	if ok {
+		return wallet, nil
+	}
+
+	// the wallet is resolved outside the lock, the upcheck of a remote signer takes up to
+	// 10s and would block every other operation of the global meanwhile
+	var (
+		fromAddr gethcommon.Address
+		signerFn bind.SignerFn
+		err      error
+	)
+
+	if keystoreFile != "" {
+		passphrase := ""
+		if passwordFile != "" {
+			passphraseBytes, err := os.ReadFile(passwordFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read relayer keystore password file")
+			}
+			passphrase = strings.TrimSpace(string(passphraseBytes))
+		}
+		fromAddr, signerFn, _, err = keys.InitEthereumAccountsManagerWithKeystoreFile(keystoreFile, passphrase, chainId)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load relayer keystore")
+		}
+	} else {
+		signer, err := remotesigner.NewWeb3Signer(remotesigner.Config{
+			URL:            remoteSignerURL,
+			Address:        remoteSignerAddress,
+			CACertFile:     g.cfg.EthRemoteSignerCACert,
+			ClientCertFile: g.cfg.EthRemoteSignerClientCert,
+			ClientKeyFile:  g.cfg.EthRemoteSignerClientKey,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to init relayer remote signer")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := signer.Upcheck(ctx); err != nil {
+			return nil, errors.Wrap(err, "relayer remote signer upcheck failed")
+		}
+
+		fromAddr, signerFn, _, err = keys.InitEthereumAccountsManagerWithRemoteSigner(signer, chainId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// a concurrent call may have resolved it meanwhile, the first one is kept
+	if wallet, ok := g.relayerWallets[cacheKey]; ok {
+		return wallet, nil
+	}
+	wallet = &ethereum.RelayerWallet{
+		FromAddr: fromAddr,
+		SignerFn: signerFn,
+	}
+	g.relayerWallets[cacheKey] = wallet
+
+	return wallet, nil
+}
+
 func (g *Global) GetAnonymousEVMNetwork(chainId uint64, rpc *rpcs.Rpc, options ...committer.EVMCommitterOption) (*ethereum.Network, error) {
 	ethKeyFromAddress, signerFn, personalSignFn, err := keys.InitEthereumAccountsManagerWithRandomKey(chainId)
 	if err != nil {
@@ -343,7 +484,7 @@ func (g *Global) GetAnonymousEVMNetworks(chainId uint64, rpcs []*rpcs.Rpc) ([]*e
 	for _, rpc := range rpcs {
 		ethNetwork, err := g.GetAnonymousEVMNetwork(chainId, rpc)
 		if err != nil {
-			fmt.Println("Error getting EVM network:", err, "for rpc:", rpc.Url)
+			loopLogger("rpcs").WithError(err).WithField(logs.FieldChainId, chainId).WithField("rpc", rpc.Url).Warningln("failed to get the EVM network")
 			continue
 		}
 		ethNetworks = append(ethNetworks, ethNetwork)
@@ -359,9 +500,9 @@ func (g *Global) GetEVMNetwork(counterpartyChainParams *hyperiontypes.Counterpar
 		return nil, err
 	}
 
-	ethKeyFromAddress, signerFn, personalSignFn, err := keys.InitEthereumAccountsManagerWithPrivateKey(&g.cfg.PrivateKey, counterpartyChainParams.BridgeChainId)
+	ethKeyFromAddress, signerFn, personalSignFn, err := g.initEthereumAccountsManager(counterpartyChainParams.BridgeChainId)
 	if err != nil {
-		fmt.Println("Error initializing ethereum accounts manager:", err)
+		loopLogger("global").WithError(err).WithField(logs.FieldChainId, counterpartyChainParams.BridgeChainId).Errorln("failed to initialize the ethereum accounts manager")
 		return nil, err
 	}
 
@@ -383,11 +524,18 @@ func (g *Global) GetEVMNetwork(counterpartyChainParams *hyperiontypes.Counterpar
 		options = append(options, committer.OptionGasLimit(uint64(gasLimit)))
 	}
 
+	relayerWallet, err := g.getRelayerWallet(counterpartyChainParams.BridgeChainId, settings)
+	if err != nil {
+		loopLogger("global").WithError(err).WithField(logs.FieldChainId, counterpartyChainParams.BridgeChainId).Errorln("failed to initialize the relayer wallet")
+		return nil, err
+	}
+
 	ethNetwork, err := ethereum.NewNetwork(hyperionContractAddr, ethKeyFromAddress, signerFn, personalSignFn, ethereum.NetworkConfig{
 		EthNodeRPC:            rpc,
 		GasPriceAdjustment:    g.cfg.EthGasPriceAdjustment,
 		MaxGasPrice:           g.cfg.EthMaxGasPrice,
 		PendingTxWaitDuration: g.cfg.PendingTxWaitDuration,
+		RelayerWallet:         relayerWallet,
 	}, options...)
 
 	if err != nil {
@@ -401,7 +549,7 @@ func (g *Global) GetEVMNetworks(counterpartyChainParams *hyperiontypes.Counterpa
 	for _, rpc := range rpcs {
 		ethNetwork, err := g.GetEVMNetwork(counterpartyChainParams, rpc)
 		if err != nil {
-			fmt.Println("Error getting EVM network:", err, "for rpc:", rpc.Url)
+			loopLogger("rpcs").WithError(err).WithField(logs.FieldChainId, counterpartyChainParams.BridgeChainId).WithField("rpc", rpc.Url).Warningln("failed to get the EVM network")
 			continue
 		}
 		ethNetworks = append(ethNetworks, ethNetwork)
@@ -477,7 +625,7 @@ func (g *Global) TestChainListRpcsAndSaveForChain(chainId uint64) ([]*rpcs.Rpc,
 	for _, ethNetwork := range ethNetworks {
 		ok := (*ethNetwork).TestRpc(context.Background())
 		if !ok {
-			fmt.Println("Error testing rpc:", (*ethNetwork).GetRpc().Url)
+			loopLogger("rpcs").WithField(logs.FieldChainId, chainId).WithField("rpc", (*ethNetwork).GetRpc().Url).Warningln("rpc test failed")
 			continue
 		}
 		rpc := (*ethNetwork).GetRpc()
@@ -666,19 +814,9 @@ func (g *Global) ProposeHyperionUpdate(title string, description string, counter
 		return 0, fmt.Errorf("helios network not initialized")
 	}
 
-	hash := sha256.Sum256([]byte(wrappers.HyperionBin))
-	hashString := hex.EncodeToString(hash[:])
-
-	contentI := map[string]interface{}{
-		"@type":                        "/helios.hyperion.v1.MsgUpdateChainSmartContract",
-		"chain_id":                     counterpartyChainParams.BridgeChainId,
-		"bridge_contract_address":      counterpartyChainParams.BridgeCounterpartyAddress,
-		"bridge_contract_start_height": counterpartyChainParams.BridgeContractStartHeight,
-		"contract_source_hash":         hashString,
-		"first_orchestrator_address":   g.ethKeyFromAddress.Hex(),
-	}
-	content, _ := json.Marshal(contentI)
-	proposalId, err := (*g.heliosNetwork).SendProposal(context.Background(), title, description, string(content), g.accAddress, math.NewInt(1000000000000000000))
+	content := g.hyperionUpdateProposalContent(counterpartyChainParams)
+	g.logProposalDiff(content)
+	proposalId, err := (*g.heliosNetwork).SendProposal(context.Background(), title, description, content, g.accAddress, math.NewInt(1000000000000000000))
 	if err != nil {
 		return 0, err
 	}
@@ -732,8 +870,17 @@ func (g *Global) CreateNewBlockchainProposal(title string, description string, c
 		return 0, fmt.Errorf("helios network not initialized")
 	}
 
-	hash := sha256.Sum256([]byte(wrappers.HyperionBin))
-	hashString := hex.EncodeToString(hash[:])
+	content := g.newBlockchainProposalContent(counterpartyChainParams)
+	g.logProposalDiff(content)
+	proposalId, err := (*g.heliosNetwork).SendProposal(context.Background(), title, description, content, g.accAddress, math.NewInt(1000000000000000000))
+	if err != nil {
+		return 0, err
+	}
+	return proposalId, nil
+}
+
+func (g *Global) newBlockchainProposalContent(counterpartyChainParams *hyperiontypes.CounterpartyChainParams) string {
+	hashString := wrappers.HyperionSourceHash()
 
 	contentI := map[string]interface{}{
 		"@type": "/helios.hyperion.v1.MsgAddCounterpartyChainParams",
@@ -794,13 +941,24 @@ func (g *Global) CreateNewBlockchainProposal(title string, description string, c
 			"paused":                     false,
 		},
 	}
-	fmt.Println("contentI: ", contentI)
+	loopLogger("global").WithField("content", contentI).Debugln("blockchain proposal content")
 	content, _ := json.Marshal(contentI)
-	proposalId, err := (*g.heliosNetwork).SendProposal(context.Background(), title, description, string(content), g.accAddress, math.NewInt(1000000000000000000))
-	if err != nil {
-		return 0, err
+	return string(content)
+}
+
+func (g *Global) hyperionUpdateProposalContent(counterpartyChainParams *hyperiontypes.CounterpartyChainParams) string {
+	hashString := wrappers.HyperionSourceHash()
+
+	contentI := map[string]interface{}{
+		"@type":                        "/helios.hyperion.v1.MsgUpdateChainSmartContract",
+		"chain_id":                     counterpartyChainParams.BridgeChainId,
+		"bridge_contract_address":      counterpartyChainParams.BridgeCounterpartyAddress,
+		"bridge_contract_start_height": counterpartyChainParams.BridgeContractStartHeight,
+		"contract_source_hash":         hashString,
+		"first_orchestrator_address":   g.ethKeyFromAddress.Hex(),
 	}
-	return proposalId, nil
+	content, _ := json.Marshal(contentI)
+	return string(content)
 }
 
 func (g *Global) DeployNewHyperionContract(chainId uint64) (gethcommon.Address, uint64, bool) {
@@ -810,7 +968,7 @@ func (g *Global) DeployNewHyperionContract(chainId uint64) (gethcommon.Address,
 	}
 	if len(rpcs) == 0 {
 		// setup rpcs
-		fmt.Println("No rpcs found for chainId:", chainId, "in global.DeployNewHyperionContract")
+		loopLogger("deployment").WithField(logs.FieldChainId, chainId).Warningln("no rpcs found to deploy the hyperion contract")
 		return gethcommon.Address{}, 0, false
 	}
 	heliosNetwork := g.GetHeliosNetwork()
@@ -818,9 +976,9 @@ func (g *Global) DeployNewHyperionContract(chainId uint64) (gethcommon.Address,
 		return gethcommon.Address{}, 0, false
 	}
 
-	ethKeyFromAddress, signerFn, _, err := keys.InitEthereumAccountsManagerWithPrivateKey(&g.cfg.PrivateKey, chainId)
+	ethKeyFromAddress, signerFn, _, err := g.initEthereumAccountsManager(chainId)
 	if err != nil {
-		fmt.Println("Error initializing ethereum accounts manager:", err)
+		loopLogger("deployment").WithError(err).WithField(logs.FieldChainId, chainId).Errorln("failed to initialize the ethereum accounts manager")
 		return gethcommon.Address{}, 0, false
 	}
 
@@ -834,13 +992,13 @@ func (g *Global) DeployNewHyperionContract(chainId uint64) (gethcommon.Address,
 	if settings["gas_limit"] != nil {
 		gasLimit = int(settings["gas_limit"].(float64))
 	}
-	fmt.Println("gasLimit:", gasLimit)
+	loopLogger("deployment").WithField(logs.FieldChainId, chainId).WithField("gas_limit", gasLimit).Debugln("deploying with gas limit")
 	options := []committer.EVMCommitterOption{
 		committer.OptionGasPriceFromString(gasPrice),
 		committer.OptionGasLimit(uint64(gasLimit)),
 	}
 
-	fmt.Println("Deploying Hyperion contract...", ethKeyFromAddress.Hex())
+	loopLogger("deployment").WithField(logs.FieldChainId, chainId).WithField("deployer", ethKeyFromAddress.Hex()).Infoln("deploying hyperion contract")
 	address, blockNumber, err, success := ethereum.DeployNewHyperionContract(g.ethKeyFromAddress, signerFn, ethereum.NetworkConfig{
 		EthNodeRPC:            rpcs[0],
 		GasPriceAdjustment:    g.cfg.EthGasPriceAdjustment,
@@ -848,7 +1006,7 @@ func (g *Global) DeployNewHyperionContract(chainId uint64) (gethcommon.Address,
 		PendingTxWaitDuration: g.cfg.PendingTxWaitDuration,
 	}, options...)
 	if err != nil {
-		fmt.Println("Error deploying Hyperion contract:", err)
+		loopLogger("deployment").WithError(err).WithField(logs.FieldChainId, chainId).Errorln("failed to deploy hyperion contract")
 		return gethcommon.Address{}, 0, false
 	}
 	if !success {
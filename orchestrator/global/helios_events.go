@@ -0,0 +1,93 @@
+package global
+
+import (
+	"context"
+	"time"
+
+	comettypes "github.com/cometbft/cometbft/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/tendermint"
+)
+
+const (
+	// heliosEventsStallTimeout is how long without a new block before subscribing again
+	heliosEventsStallTimeout = 1 * time.Minute
+	// heliosEventsFailoverCheck is how often the subscription checks it follows the active endpoint
+	heliosEventsFailoverCheck = 10 * time.Second
+
+	heliosEventsBaseDelay = 2 * time.Second
+	heliosEventsMaxDelay  = 1 * time.Minute
+)
+
+// runHeliosEvents follows the new blocks of the active Helios endpoint and wakes the loops
+// of the orchestrators on the Hyperion events of each block. The loops keep their tickers,
+// missing events only delays them.
+func (g *Global) runHeliosEvents(ctx context.Context) {
+	delay := heliosEventsBaseDelay
+
+	for ctx.Err() == nil {
+		endpoint := g.heliosNetwork.ActiveEndpoint().TendermintRPC
+
+		subscriptionCtx, cancel := context.WithCancel(ctx)
+		go g.cancelOnEndpointChange(subscriptionCtx, cancel, endpoint)
+
+		err := tendermint.SubscribeNewBlocks(subscriptionCtx, endpoint, heliosEventsStallTimeout, func(block comettypes.EventDataNewBlock) {
+			delay = heliosEventsBaseDelay
+			g.wakeOrchestrators(tendermint.NewBlockWakeups(block))
+		})
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			// the active endpoint changed, follow the new one right away
+			continue
+		}
+
+		loopLogger("helios_events").WithError(err).WithField("delay", delay.String()).Warningln("subscription failed, subscribing again")
+		if !sleepCtx(ctx, delay) {
+			return
+		}
+		delay = min(delay*2, heliosEventsMaxDelay)
+	}
+}
+
+func (g *Global) cancelOnEndpointChange(ctx context.Context, cancel context.CancelFunc, endpoint string) {
+	ticker := time.NewTicker(heliosEventsFailoverCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if g.heliosNetwork.ActiveEndpoint().TendermintRPC != endpoint {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (g *Global) wakeOrchestrators(wakeups []tendermint.Wakeup) {
+	if len(wakeups) == 0 {
+		return
+	}
+	for _, orchestrator := range g.GetOrchestrators() {
+		for _, wakeup := range wakeups {
+			orchestrator.Wake(wakeup)
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,20 @@
+package global
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
+)
+
+// QueryEventIndex returns a page of the Hyperion contract events indexed for the chain.
+func (g *Global) QueryEventIndex(chainId uint64, q indexer.Query) (indexer.Page, error) {
+	o, err := g.transferOrchestrator(chainId)
+	if err != nil {
+		return indexer.Page{}, err
+	}
+	index := o.GetIndex()
+	if index == nil {
+		return indexer.Page{}, errors.Errorf("no event index for chain %d", chainId)
+	}
+	return index.Query(q)
+}
@@ -0,0 +1,27 @@
+package global
+
+import (
+	"context"
+
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/outbox"
+)
+
+// loopLogger returns the logger of a process of Global, its level can be adjusted at
+// runtime under the name of the loop.
+func loopLogger(loop string) log.Logger {
+	return log.WithField(logs.FieldLoop, loop)
+}
+
+// originLogger returns the logger of a process of Global acting for the orchestrator loop
+// that tagged ctx, its lines are filtered with the level of that chain.
+func originLogger(ctx context.Context, loop string) log.Logger {
+	origin := outbox.OriginFromContext(ctx)
+	logger := loopLogger(loop).WithField("origin", origin.Loop)
+	if origin.ChainId != 0 {
+		logger = logger.WithField(logs.FieldChainId, origin.ChainId)
+	}
+	return logger
+}
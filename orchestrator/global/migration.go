@@ -0,0 +1,610 @@
+package global
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+// Steps of the migration of a chain to a new Hyperion contract, in order. The stage of a
+// migration is the last step completed.
+const (
+	MigrationStageNone              = ""
+	MigrationStageDepositsPaused    = "deposits_paused"
+	MigrationStageWithdrawalPaused  = "withdrawals_paused"
+	MigrationStageBalancesSnapshot  = "balances_snapshot"
+	MigrationStageContractDeployed  = "contract_deployed"
+	MigrationStageTokensSwept       = "tokens_swept"
+	MigrationStageBalancesVerified  = "balances_verified"
+	MigrationStageWithdrawalResumed = "withdrawals_resumed"
+)
+
+var migrationStages = []string{
+	MigrationStageDepositsPaused,
+	MigrationStageWithdrawalPaused,
+	MigrationStageBalancesSnapshot,
+	MigrationStageContractDeployed,
+	MigrationStageTokensSwept,
+	MigrationStageBalancesVerified,
+	MigrationStageWithdrawalResumed,
+}
+
+const migrationTokensPageSize = 100
+
+// MigrationRequest holds the inputs of a migration. The chain name and block time of the
+// update proposal default to the live params.
+type MigrationRequest struct {
+	ChainId                      uint64 `json:"chain_id"`
+	Title                        string `json:"title"`
+	Description                  string `json:"description"`
+	BridgeChainName              string `json:"bridge_chain_name"`
+	AverageCounterpartyBlockTime uint64 `json:"average_counterparty_block_time"`
+}
+
+// MigrationToken is a token held by the old contract, swept to the new contract.
+type MigrationToken struct {
+	Denom         string `json:"denom"`
+	Symbol        string `json:"symbol"`
+	Contract      string `json:"contract"`
+	BalanceBefore string `json:"balance_before"`
+	Swept         bool   `json:"swept"`
+	SweepTxHash   string `json:"sweep_tx_hash,omitempty"`
+	BalanceAfter  string `json:"balance_after,omitempty"`
+	BalanceNew    string `json:"balance_new,omitempty"`
+	Verified      bool   `json:"verified"`
+}
+
+// MigrationState is the persisted state of the migration of a chain.
+type MigrationState struct {
+	Request           MigrationRequest  `json:"request"`
+	Stage             string            `json:"stage"`
+	OldContract       string            `json:"old_contract"`
+	NewContract       string            `json:"new_contract,omitempty"`
+	ProposalId        uint64            `json:"proposal_id,omitempty"`
+	Tokens            []*MigrationToken `json:"tokens"`
+	TxHashes          map[string]string `json:"tx_hashes"`
+	LastError         string            `json:"last_error,omitempty"`
+	StartedAt         time.Time         `json:"started_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	CompletedStagesAt map[string]string `json:"completed_stages_at"`
+}
+
+func (s *MigrationState) Done() bool {
+	return s.Stage == MigrationStageWithdrawalResumed
+}
+
+// MigrationPlan is the dry run of a migration: the steps left and the current state of
+// the old contract.
+type MigrationPlan struct {
+	ChainId           uint64            `json:"chain_id"`
+	OldContract       string            `json:"old_contract"`
+	Stage             string            `json:"stage"`
+	Steps             []string          `json:"steps"`
+	DepositsPaused    bool              `json:"deposits_paused"`
+	WithdrawalsPaused bool              `json:"withdrawals_paused"`
+	DeploymentStage   string            `json:"deployment_stage"`
+	Tokens            []*MigrationToken `json:"tokens"`
+}
+
+func (g *Global) GetMigration(chainId uint64) (*MigrationState, error) {
+	state := &MigrationState{}
+	found, err := storage.GetMigrationState(chainId, state)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no migration started for chain %d", chainId)
+	}
+	return state, nil
+}
+
+func (g *Global) ResetMigration(chainId uint64) error {
+	if !g.claimMigration(chainId) {
+		return fmt.Errorf("migration of chain %d is running", chainId)
+	}
+	defer g.releaseMigration(chainId)
+	return storage.RemoveMigrationState(chainId)
+}
+
+// claimMigration marks the migration of the chain as running, it returns false when
+// another call already runs it so that no step is sent twice.
+func (g *Global) claimMigration(chainId uint64) bool {
+	g.migratingMu.Lock()
+	defer g.migratingMu.Unlock()
+	if g.migrating[chainId] {
+		return false
+	}
+	g.migrating[chainId] = true
+	return true
+}
+
+func (g *Global) releaseMigration(chainId uint64) {
+	g.migratingMu.Lock()
+	delete(g.migrating, chainId)
+	g.migratingMu.Unlock()
+}
+
+// PlanMigration returns the steps a migration of the chain would run, from the last
+// completed stage, without sending any tx.
+func (g *Global) PlanMigration(ctx context.Context, chainId uint64) (*MigrationPlan, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, fmt.Errorf("helios network not initialized")
+	}
+
+	state := &MigrationState{}
+	found, err := storage.GetMigrationState(chainId, state)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := heliosNetwork.GetCounterpartyChainParamsByChainId(ctx, chainId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get counterparty chain params of chain %d", chainId)
+	}
+	if !found {
+		state.OldContract = params.BridgeCounterpartyAddress
+	}
+
+	plan := &MigrationPlan{
+		ChainId:           chainId,
+		OldContract:       state.OldContract,
+		Stage:             state.Stage,
+		Steps:             remainingMigrationSteps(state.Stage),
+		WithdrawalsPaused: params.Paused,
+	}
+
+	oldNetwork, err := g.oldContractNetwork(state)
+	if err != nil {
+		return nil, err
+	}
+	if plan.DepositsPaused, err = (*oldNetwork).IsDepositPaused(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to get deposit pause of the old contract")
+	}
+
+	deployment := &DeploymentState{}
+	if found, err := storage.GetDeploymentState(chainId, deployment); err == nil && found {
+		plan.DeploymentStage = deployment.Stage
+	}
+
+	if found && len(state.Tokens) > 0 {
+		plan.Tokens = state.Tokens
+	} else if plan.Tokens, err = g.snapshotMigrationTokens(ctx, oldNetwork, state); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func remainingMigrationSteps(stage string) []string {
+	if stage == MigrationStageNone {
+		return migrationStages
+	}
+	for i, s := range migrationStages {
+		if s == stage {
+			return migrationStages[i+1:]
+		}
+	}
+	return []string{}
+}
+
+// RunMigration starts the migration of the chain to a new Hyperion contract, or resumes it
+// from the last completed stage. Each stage is checkpointed, each swept token as well.
+func (g *Global) RunMigration(ctx context.Context, request *MigrationRequest) (*MigrationState, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, fmt.Errorf("helios network not initialized")
+	}
+
+	if !g.claimMigration(request.ChainId) {
+		return nil, fmt.Errorf("migration of chain %d is already running", request.ChainId)
+	}
+	defer g.releaseMigration(request.ChainId)
+
+	state := &MigrationState{}
+	found, err := storage.GetMigrationState(request.ChainId, state)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		params, err := heliosNetwork.GetCounterpartyChainParamsByChainId(ctx, request.ChainId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get counterparty chain params of chain %d", request.ChainId)
+		}
+		if request.BridgeChainName == "" {
+			request.BridgeChainName = params.BridgeChainName
+		}
+		if request.AverageCounterpartyBlockTime == 0 {
+			request.AverageCounterpartyBlockTime = params.AverageCounterpartyBlockTime
+		}
+
+		// a completed deployment of the chain is the contract we migrate from
+		deployment := &DeploymentState{}
+		if found, err := storage.GetDeploymentState(request.ChainId, deployment); err == nil && found && deployment.Done() {
+			if err := storage.RemoveDeploymentState(request.ChainId); err != nil {
+				return nil, err
+			}
+		}
+
+		state = &MigrationState{
+			Request:           *request,
+			OldContract:       params.BridgeCounterpartyAddress,
+			Tokens:            []*MigrationToken{},
+			TxHashes:          map[string]string{},
+			StartedAt:         time.Now(),
+			CompletedStagesAt: map[string]string{},
+		}
+		if err := g.saveMigration(state); err != nil {
+			return nil, err
+		}
+	}
+
+	oldNetwork, err := g.oldContractNetwork(state)
+	if err != nil {
+		return state, err
+	}
+	chain := &liveMigrationChain{g: g, chainId: state.Request.ChainId, oldNetwork: oldNetwork}
+	if err := advanceMigration(ctx, chain, state, g.saveMigration); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// advanceMigration runs the steps following the current stage until the migration is
+// done, the state is saved after each step.
+func advanceMigration(ctx context.Context, chain migrationChain, state *MigrationState, save func(*MigrationState) error) error {
+	for !state.Done() {
+		if err := runMigrationStep(ctx, chain, state, save); err != nil {
+			state.LastError = err.Error()
+			save(state)
+			return errors.Wrapf(err, "migration of chain %d failed after stage %q", state.Request.ChainId, state.Stage)
+		}
+		state.LastError = ""
+		state.CompletedStagesAt[state.Stage] = time.Now().Format(time.RFC3339)
+		if err := save(state); err != nil {
+			return err
+		}
+		loopLogger("migration").WithField(logs.FieldChainId, state.Request.ChainId).WithField("stage", state.Stage).Infoln("migration reached stage")
+	}
+	return nil
+}
+
+func (g *Global) saveMigration(state *MigrationState) error {
+	state.UpdatedAt = time.Now()
+	return storage.SaveMigrationState(state.Request.ChainId, state)
+}
+
+// oldContractNetwork returns the target network bound to the contract we migrate from,
+// the live params point to the new contract once the update proposal passes.
+func (g *Global) oldContractNetwork(state *MigrationState) (*ethereum.Network, error) {
+	targetNetworks, err := g.InitTargetNetworks(&hyperiontypes.CounterpartyChainParams{
+		HyperionId:                state.Request.ChainId,
+		BridgeChainId:             state.Request.ChainId,
+		BridgeCounterpartyAddress: state.OldContract,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(targetNetworks) == 0 {
+		return nil, fmt.Errorf("no target networks found for chainId: %d", state.Request.ChainId)
+	}
+	return targetNetworks[0], nil
+}
+
+// migrationChain is what the migration acts on: the old contract on the counterparty
+// chain and the Hyperion module on Helios.
+type migrationChain interface {
+	depositsPaused(ctx context.Context) (bool, error)
+	pauseDeposits(ctx context.Context) (string, error)
+	withdrawalsPaused(ctx context.Context) (bool, error)
+	setWithdrawalsPaused(ctx context.Context, paused bool) (string, error)
+	// liveContract returns the contract of the chain in the Helios params
+	liveContract(ctx context.Context) (string, error)
+	snapshotTokens(ctx context.Context, state *MigrationState) ([]*MigrationToken, error)
+	// deployContract runs the deployment pipeline, it returns the new contract and the
+	// update proposal once submitted
+	deployContract(ctx context.Context, state *MigrationState) (string, uint64, error)
+	tokenBalance(ctx context.Context, tokenContract string, holder string) (*big.Int, error)
+	// sweepToken moves the amount from the old contract to the destination and waits for
+	// the receipt, the tx hash is returned with the error of a reverted tx
+	sweepToken(ctx context.Context, tokenContract string, amount *big.Int, destination string) (string, error)
+}
+
+// runMigrationStep completes the step following the current stage.
+func runMigrationStep(ctx context.Context, chain migrationChain, state *MigrationState, save func(*MigrationState) error) error {
+	switch state.Stage {
+	case MigrationStageNone:
+		paused, err := chain.depositsPaused(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get deposit pause of the old contract")
+		}
+		if !paused {
+			hash, err := chain.pauseDeposits(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to pause deposits of the old contract")
+			}
+			state.TxHashes[MigrationStageDepositsPaused] = hash
+		}
+		state.Stage = MigrationStageDepositsPaused
+
+	case MigrationStageDepositsPaused:
+		paused, err := chain.withdrawalsPaused(ctx)
+		if err != nil {
+			return err
+		}
+		if !paused {
+			hash, err := chain.setWithdrawalsPaused(ctx, true)
+			if err != nil {
+				return errors.Wrap(err, "failed to pause withdrawals")
+			}
+			state.TxHashes[MigrationStageWithdrawalPaused] = hash
+		}
+		state.Stage = MigrationStageWithdrawalPaused
+
+	case MigrationStageWithdrawalPaused:
+		tokens, err := chain.snapshotTokens(ctx, state)
+		if err != nil {
+			return err
+		}
+		state.Tokens = tokens
+		state.Stage = MigrationStageBalancesSnapshot
+
+	case MigrationStageBalancesSnapshot:
+		newContract, proposalId, err := chain.deployContract(ctx, state)
+		if newContract != "" {
+			state.NewContract = newContract
+			state.ProposalId = proposalId
+		}
+		if err != nil {
+			return err
+		}
+		state.Stage = MigrationStageContractDeployed
+
+	case MigrationStageContractDeployed:
+		if state.NewContract == "" {
+			return fmt.Errorf("no new contract to sweep the tokens to")
+		}
+		for _, token := range state.Tokens {
+			if token.Swept {
+				continue
+			}
+			// the balance left is swept, a sweep mined before a crash leaves nothing to send
+			balance, err := chain.tokenBalance(ctx, token.Contract, state.OldContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get balance of %s", token.Denom)
+			}
+			if balance.Sign() > 0 {
+				hash, err := chain.sweepToken(ctx, token.Contract, balance, state.NewContract)
+				if hash != "" {
+					token.SweepTxHash = hash
+				}
+				if err != nil {
+					// not marked swept, the next run sends it again
+					return errors.Wrapf(err, "failed to sweep %s", token.Denom)
+				}
+			}
+			token.Swept = true
+			// persist each token so that a failure on the next one does not sweep it again
+			if err := save(state); err != nil {
+				return err
+			}
+		}
+		state.Stage = MigrationStageTokensSwept
+
+	case MigrationStageTokensSwept:
+		mismatches := 0
+		resweep := false
+		for _, token := range state.Tokens {
+			oldBalance, err := chain.tokenBalance(ctx, token.Contract, state.OldContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get balance of %s", token.Denom)
+			}
+			newBalance, err := chain.tokenBalance(ctx, token.Contract, state.NewContract)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get balance of %s", token.Denom)
+			}
+			balanceBefore, ok := new(big.Int).SetString(token.BalanceBefore, 10)
+			if !ok {
+				return fmt.Errorf("invalid snapshot balance %q of %s", token.BalanceBefore, token.Denom)
+			}
+			token.BalanceAfter = oldBalance.String()
+			token.BalanceNew = newBalance.String()
+			token.Verified = oldBalance.Sign() == 0 && newBalance.Cmp(balanceBefore) >= 0
+			if !token.Verified {
+				mismatches++
+			}
+			if oldBalance.Sign() > 0 {
+				token.Swept = false
+				resweep = true
+			}
+		}
+		if mismatches > 0 {
+			if resweep {
+				// the tokens left in the old contract are swept again by the next run
+				state.Stage = MigrationStageContractDeployed
+			}
+			return fmt.Errorf("%d tokens not moved from the old contract %s to the new contract %s", mismatches, state.OldContract, state.NewContract)
+		}
+		state.Stage = MigrationStageBalancesVerified
+
+	case MigrationStageBalancesVerified:
+		live, err := chain.liveContract(ctx)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(live, state.NewContract) {
+			return fmt.Errorf("new contract %s is not live on Helios yet, waiting for proposal %d", state.NewContract, state.ProposalId)
+		}
+		paused, err := chain.withdrawalsPaused(ctx)
+		if err != nil {
+			return err
+		}
+		if paused {
+			hash, err := chain.setWithdrawalsPaused(ctx, false)
+			if err != nil {
+				return errors.Wrap(err, "failed to resume withdrawals")
+			}
+			state.TxHashes[MigrationStageWithdrawalResumed] = hash
+		}
+		state.Stage = MigrationStageWithdrawalResumed
+
+	default:
+		return fmt.Errorf("unknown migration stage %q", state.Stage)
+	}
+
+	return nil
+}
+
+// liveMigrationChain runs the migration against the old contract and Helios.
+type liveMigrationChain struct {
+	g          *Global
+	chainId    uint64
+	oldNetwork *ethereum.Network
+}
+
+func (c *liveMigrationChain) depositsPaused(ctx context.Context) (bool, error) {
+	return (*c.oldNetwork).IsDepositPaused(ctx)
+}
+
+func (c *liveMigrationChain) pauseDeposits(ctx context.Context) (string, error) {
+	hash, err := (*c.oldNetwork).PauseOrUnpauseDeposit(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+func (c *liveMigrationChain) withdrawalsPaused(ctx context.Context) (bool, error) {
+	params, err := c.g.GetHeliosNetwork().GetCounterpartyChainParamsByChainId(ctx, c.chainId)
+	if err != nil {
+		return false, err
+	}
+	return params.Paused, nil
+}
+
+func (c *liveMigrationChain) setWithdrawalsPaused(ctx context.Context, paused bool) (string, error) {
+	msg, err := c.g.GetHeliosNetwork().PauseOrUnpauseHyperionWithdrawalMsg(ctx, c.chainId, paused)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.g.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
+	if err != nil {
+		return "", err
+	}
+	return resp.TxHash, nil
+}
+
+func (c *liveMigrationChain) liveContract(ctx context.Context) (string, error) {
+	params, err := c.g.GetHeliosNetwork().GetCounterpartyChainParamsByChainId(ctx, c.chainId)
+	if err != nil {
+		return "", err
+	}
+	return params.BridgeCounterpartyAddress, nil
+}
+
+func (c *liveMigrationChain) snapshotTokens(ctx context.Context, state *MigrationState) ([]*MigrationToken, error) {
+	return c.g.snapshotMigrationTokens(ctx, c.oldNetwork, state)
+}
+
+func (c *liveMigrationChain) deployContract(ctx context.Context, state *MigrationState) (string, uint64, error) {
+	deployment, err := c.g.RunDeployment(ctx, &DeploymentRequest{
+		ChainId:                      state.Request.ChainId,
+		Title:                        state.Request.Title,
+		Description:                  state.Request.Description,
+		BridgeChainName:              state.Request.BridgeChainName,
+		AverageCounterpartyBlockTime: state.Request.AverageCounterpartyBlockTime,
+	})
+	if deployment == nil {
+		return "", 0, err
+	}
+	return deployment.HyperionAddress, deployment.ProposalId, err
+}
+
+func (c *liveMigrationChain) tokenBalance(ctx context.Context, tokenContract string, holder string) (*big.Int, error) {
+	return (*c.oldNetwork).TokenBalance(ctx, gethcommon.HexToAddress(tokenContract), gethcommon.HexToAddress(holder))
+}
+
+func (c *liveMigrationChain) sweepToken(ctx context.Context, tokenContract string, amount *big.Int, destination string) (string, error) {
+	pendingBatchNonce, err := c.g.PendingBatchNonce(ctx, c.chainId, tokenContract)
+	if err != nil {
+		return "", err
+	}
+	network := *c.oldNetwork
+	hash, err := network.SendClaimTokensOfOldContract(ctx, c.chainId, tokenContract, amount, pendingBatchNonce, gethcommon.HexToAddress(destination), network.FromAddress(), network.GetPersonalSignFn())
+	if hash == nil {
+		return "", err
+	}
+	return hash.Hex(), err
+}
+
+// PendingBatchNonce returns the highest nonce of the batches of the token pending on
+// Helios, 0 when there is none.
+func (g *Global) PendingBatchNonce(ctx context.Context, chainId uint64, tokenContract string) (uint64, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return 0, errors.New("helios network not initialized")
+	}
+	batches, err := heliosNetwork.LatestTransactionBatches(ctx, chainId)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get pending batches")
+	}
+	nonce := uint64(0)
+	for _, batch := range batches {
+		if strings.EqualFold(batch.TokenContract, tokenContract) && batch.BatchNonce > nonce {
+			nonce = batch.BatchNonce
+		}
+	}
+	return nonce, nil
+}
+
+// snapshotMigrationTokens lists the tokens of the chain registered on Helios with the
+// balance of the old contract.
+func (g *Global) snapshotMigrationTokens(ctx context.Context, oldNetwork *ethereum.Network, state *MigrationState) ([]*MigrationToken, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	chainId := state.Request.ChainId
+
+	tokens := make([]*MigrationToken, 0)
+	for page := uint64(1); ; page++ {
+		list, total, err := heliosNetwork.QueryGetListTokens(ctx, chainId, page, migrationTokensPageSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get tokens")
+		}
+
+		for _, token := range list {
+			if token.Metadata == nil {
+				continue
+			}
+			for _, chainMetadata := range token.Metadata.ChainsMetadatas {
+				if chainMetadata.ChainId != chainId {
+					continue
+				}
+				balance, err := (*oldNetwork).TokenBalance(ctx, gethcommon.HexToAddress(chainMetadata.ContractAddress), gethcommon.HexToAddress(state.OldContract))
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to get balance of %s", token.Metadata.Base)
+				}
+				tokens = append(tokens, &MigrationToken{
+					Denom:         token.Metadata.Base,
+					Symbol:        chainMetadata.Symbol,
+					Contract:      gethcommon.HexToAddress(chainMetadata.ContractAddress).Hex(),
+					BalanceBefore: balance.String(),
+				})
+			}
+		}
+
+		if len(list) == 0 || page*migrationTokensPageSize >= total {
+			break
+		}
+	}
+	return tokens, nil
+}
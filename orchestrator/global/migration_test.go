@@ -0,0 +1,217 @@
+package global
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOldContract = "0x00000000000000000000000000000000000000a1"
+	testNewContract = "0x00000000000000000000000000000000000000b2"
+	testTokenA      = "0x00000000000000000000000000000000000000c3"
+	testTokenB      = "0x00000000000000000000000000000000000000d4"
+)
+
+type fakeMigrationChain struct {
+	depositsPausedFlag    bool
+	withdrawalsPausedFlag bool
+	live                  string
+	// balances of each token by holder
+	balances map[string]map[string]*big.Int
+	// sweeps of the tokens listed revert
+	reverts map[string]bool
+	sweeps  map[string]int
+	// the part of a sweep lost on the way to the destination
+	leak *big.Int
+}
+
+func newFakeMigrationChain() *fakeMigrationChain {
+	return &fakeMigrationChain{
+		live: testOldContract,
+		balances: map[string]map[string]*big.Int{
+			testTokenA: {testOldContract: big.NewInt(100)},
+			testTokenB: {testOldContract: big.NewInt(250)},
+		},
+		reverts: map[string]bool{},
+		sweeps:  map[string]int{},
+	}
+}
+
+func (c *fakeMigrationChain) depositsPaused(ctx context.Context) (bool, error) {
+	return c.depositsPausedFlag, nil
+}
+
+func (c *fakeMigrationChain) pauseDeposits(ctx context.Context) (string, error) {
+	c.depositsPausedFlag = true
+	return "0xpause", nil
+}
+
+func (c *fakeMigrationChain) withdrawalsPaused(ctx context.Context) (bool, error) {
+	return c.withdrawalsPausedFlag, nil
+}
+
+func (c *fakeMigrationChain) setWithdrawalsPaused(ctx context.Context, paused bool) (string, error) {
+	c.withdrawalsPausedFlag = paused
+	return fmt.Sprintf("PAUSE_%t", paused), nil
+}
+
+func (c *fakeMigrationChain) liveContract(ctx context.Context) (string, error) {
+	return c.live, nil
+}
+
+func (c *fakeMigrationChain) snapshotTokens(ctx context.Context, state *MigrationState) ([]*MigrationToken, error) {
+	tokens := make([]*MigrationToken, 0)
+	for _, contract := range []string{testTokenA, testTokenB} {
+		tokens = append(tokens, &MigrationToken{
+			Denom:         contract,
+			Contract:      contract,
+			BalanceBefore: c.balance(contract, state.OldContract).String(),
+		})
+	}
+	return tokens, nil
+}
+
+func (c *fakeMigrationChain) deployContract(ctx context.Context, state *MigrationState) (string, uint64, error) {
+	return testNewContract, 7, nil
+}
+
+func (c *fakeMigrationChain) tokenBalance(ctx context.Context, tokenContract string, holder string) (*big.Int, error) {
+	return c.balance(tokenContract, holder), nil
+}
+
+func (c *fakeMigrationChain) sweepToken(ctx context.Context, tokenContract string, amount *big.Int, destination string) (string, error) {
+	c.sweeps[tokenContract]++
+	hash := fmt.Sprintf("SWEEP_%s_%d", tokenContract, c.sweeps[tokenContract])
+	if c.reverts[tokenContract] {
+		return hash, errors.New("transaction failed")
+	}
+	received := new(big.Int).Set(amount)
+	if c.leak != nil {
+		received.Sub(received, c.leak)
+	}
+	c.balances[tokenContract][testOldContract] = new(big.Int).Sub(c.balance(tokenContract, testOldContract), amount)
+	c.balances[tokenContract][destination] = new(big.Int).Add(c.balance(tokenContract, destination), received)
+	return hash, nil
+}
+
+func (c *fakeMigrationChain) balance(tokenContract string, holder string) *big.Int {
+	if balance, ok := c.balances[tokenContract][holder]; ok {
+		return balance
+	}
+	return big.NewInt(0)
+}
+
+func newTestMigrationState() *MigrationState {
+	return &MigrationState{
+		Request:           MigrationRequest{ChainId: 11155111},
+		OldContract:       testOldContract,
+		Tokens:            []*MigrationToken{},
+		TxHashes:          map[string]string{},
+		CompletedStagesAt: map[string]string{},
+	}
+}
+
+func noSave(*MigrationState) error { return nil }
+
+func TestMigrationRunsEveryStage(t *testing.T) {
+	chain := newFakeMigrationChain()
+	state := newTestMigrationState()
+
+	// the update proposal has not passed yet
+	err := advanceMigration(context.Background(), chain, state, noSave)
+	require.Error(t, err)
+	assert.Equal(t, MigrationStageBalancesVerified, state.Stage)
+	assert.True(t, chain.withdrawalsPausedFlag)
+	assert.True(t, chain.depositsPausedFlag)
+
+	chain.live = testNewContract
+	require.NoError(t, advanceMigration(context.Background(), chain, state, noSave))
+	assert.True(t, state.Done())
+	assert.False(t, chain.withdrawalsPausedFlag)
+	assert.Equal(t, "PAUSE_false", state.TxHashes[MigrationStageWithdrawalResumed])
+	assert.Equal(t, testNewContract, state.NewContract)
+	assert.Equal(t, uint64(7), state.ProposalId)
+
+	assert.Equal(t, int64(100), chain.balance(testTokenA, testNewContract).Int64())
+	assert.Equal(t, int64(250), chain.balance(testTokenB, testNewContract).Int64())
+	for _, token := range state.Tokens {
+		assert.True(t, token.Swept)
+		assert.True(t, token.Verified)
+		assert.Equal(t, "0", token.BalanceAfter)
+		assert.Equal(t, token.BalanceBefore, token.BalanceNew)
+		assert.NotEmpty(t, token.SweepTxHash)
+	}
+}
+
+func TestMigrationResumesRevertedSweep(t *testing.T) {
+	chain := newFakeMigrationChain()
+	chain.reverts[testTokenB] = true
+	state := newTestMigrationState()
+
+	saves := 0
+	save := func(*MigrationState) error {
+		saves++
+		return nil
+	}
+
+	err := advanceMigration(context.Background(), chain, state, save)
+	require.Error(t, err)
+	assert.Equal(t, MigrationStageContractDeployed, state.Stage)
+	assert.True(t, state.Tokens[0].Swept)
+	assert.False(t, state.Tokens[1].Swept)
+	assert.Equal(t, "SWEEP_"+testTokenB+"_1", state.Tokens[1].SweepTxHash)
+	assert.NotEmpty(t, state.LastError)
+	assert.True(t, saves > 0)
+
+	// the next run only sends the reverted sweep again
+	chain.reverts[testTokenB] = false
+	chain.live = testNewContract
+	require.NoError(t, advanceMigration(context.Background(), chain, state, save))
+	assert.True(t, state.Done())
+	assert.Empty(t, state.LastError)
+	assert.Equal(t, 1, chain.sweeps[testTokenA])
+	assert.Equal(t, 2, chain.sweeps[testTokenB])
+	assert.Equal(t, int64(250), chain.balance(testTokenB, testNewContract).Int64())
+}
+
+func TestMigrationVerifiesNewContractBalance(t *testing.T) {
+	chain := newFakeMigrationChain()
+	chain.leak = big.NewInt(1)
+	state := newTestMigrationState()
+
+	err := advanceMigration(context.Background(), chain, state, noSave)
+	require.Error(t, err)
+	assert.Equal(t, MigrationStageTokensSwept, state.Stage)
+	for _, token := range state.Tokens {
+		assert.False(t, token.Verified)
+		assert.Equal(t, "0", token.BalanceAfter)
+	}
+	assert.Equal(t, "99", state.Tokens[0].BalanceNew)
+}
+
+func TestMigrationSweepsAgainTokensLeftInOldContract(t *testing.T) {
+	chain := newFakeMigrationChain()
+	state := newTestMigrationState()
+	state.Stage = MigrationStageTokensSwept
+	state.NewContract = testNewContract
+	state.Tokens = []*MigrationToken{
+		{Denom: testTokenA, Contract: testTokenA, BalanceBefore: "100", Swept: true},
+	}
+
+	// the sweep was recorded but the old contract still holds the tokens
+	err := advanceMigration(context.Background(), chain, state, noSave)
+	require.Error(t, err)
+	assert.Equal(t, MigrationStageContractDeployed, state.Stage)
+	assert.False(t, state.Tokens[0].Swept)
+
+	chain.live = testNewContract
+	require.NoError(t, advanceMigration(context.Background(), chain, state, noSave))
+	assert.Equal(t, 1, chain.sweeps[testTokenA])
+	assert.True(t, state.Tokens[0].Verified)
+}
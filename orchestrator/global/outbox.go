@@ -0,0 +1,157 @@
+package global
+
+import (
+	"context"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	log "github.com/xlab/suplog"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/outbox"
+)
+
+// maxOutboxReplayAge is the age after which a journaled message is not replayed anymore,
+// the loops will have rebuilt it from the chain state long before.
+const maxOutboxReplayAge = 24 * time.Hour
+
+func (g *Global) loadOutbox() *outbox.Outbox {
+	path, err := outbox.DefaultPath()
+	if err != nil {
+		loopLogger("outbox").WithError(err).Errorln("failed to load outbox")
+		return nil
+	}
+	o, err := outbox.New(path, g.heliosNetwork.Codec())
+	if err != nil {
+		loopLogger("outbox").WithError(err).Errorln("failed to load outbox")
+		return nil
+	}
+	return o
+}
+
+// GetOutbox returns the journal of the messages waiting to be broadcast, nil until the
+// Helios network is initialized.
+func (g *Global) GetOutbox() *outbox.Outbox {
+	if g.heliosBroadcastManager == nil {
+		return nil
+	}
+	return g.heliosBroadcastManager.outbox
+}
+
+// journalMsgs records the msgs in the outbox under the chain and loop found in ctx.
+func (g *Global) journalMsgs(ctx context.Context, msgs []sdk.Msg) string {
+	o := g.GetOutbox()
+	if o == nil {
+		return ""
+	}
+	id, err := o.Add(outbox.OriginFromContext(ctx), msgs)
+	if err != nil {
+		originLogger(ctx, "outbox").WithError(err).Errorln("failed to journal messages in outbox")
+		return ""
+	}
+	return id
+}
+
+// replayOutbox queues again the messages of the orchestrator loops that were not broadcast
+// before the last stop. Messages already known on chain, or too old, are dropped instead.
+func (g *Global) replayOutbox(ctx context.Context) {
+	o := g.GetOutbox()
+	if o == nil {
+		return
+	}
+
+	for _, entry := range o.Pending() {
+		// actions requested through the API are not replayed behind the operator's back
+		if entry.Origin.Loop == outbox.LoopAPI || time.Since(entry.QueuedAt) > maxOutboxReplayAge || g.isAlreadyOnChain(ctx, entry.Msgs) {
+			outboxEntryLogger(entry).Infoln("dropping stale outbox entry")
+			o.Remove(entry.ID)
+			continue
+		}
+
+		outboxEntryLogger(entry).WithField("msgs", len(entry.Msgs)).Infoln("replaying outbox entry")
+		// nobody waits for the result, the channels are buffered so the broadcaster never blocks
+		g.heliosBroadcastManager.messageQueue <- queuedMessage{
+			msgs:     entry.Msgs,
+			respChan: make(chan *sdk.TxResponse, 1),
+			errChan:  make(chan error, 1),
+			outboxID: entry.ID,
+		}
+	}
+}
+
+// isAlreadyOnChain returns true when every msg is already reflected in the Helios state:
+// the confirm is stored or the claim nonce was already submitted by us. Msgs that cannot
+// be checked are assumed missing.
+func (g *Global) isAlreadyOnChain(ctx context.Context, msgs []sdk.Msg) bool {
+	helios := g.heliosNetwork
+	if helios == nil {
+		return false
+	}
+
+	lastClaimNonces := make(map[uint64]uint64)
+	lastClaimNonce := func(hyperionId uint64, orchestrator string) (uint64, bool) {
+		if nonce, ok := lastClaimNonces[hyperionId]; ok {
+			return nonce, true
+		}
+		addr, err := sdk.AccAddressFromBech32(orchestrator)
+		if err != nil {
+			return 0, false
+		}
+		claim, err := helios.LastClaimEventByAddr(ctx, hyperionId, addr)
+		if err != nil || claim == nil {
+			return 0, false
+		}
+		lastClaimNonces[hyperionId] = claim.EthereumEventNonce
+		return claim.EthereumEventNonce, true
+	}
+
+	claimDone := func(hyperionId uint64, orchestrator string, eventNonce uint64) bool {
+		nonce, ok := lastClaimNonce(hyperionId, orchestrator)
+		return ok && nonce >= eventNonce
+	}
+
+	for _, msg := range msgs {
+		done := false
+		switch m := msg.(type) {
+		case *hyperiontypes.MsgValsetConfirm:
+			confirms, err := helios.AllValsetConfirms(ctx, m.HyperionId, m.Nonce)
+			if err == nil {
+				for _, confirm := range confirms {
+					if confirm.Orchestrator == m.Orchestrator {
+						done = true
+						break
+					}
+				}
+			}
+		case *hyperiontypes.MsgConfirmBatch:
+			confirms, err := helios.TransactionBatchSignatures(ctx, m.HyperionId, m.Nonce, gethcommon.HexToAddress(m.TokenContract))
+			if err == nil {
+				for _, confirm := range confirms {
+					if confirm.Orchestrator == m.Orchestrator {
+						done = true
+						break
+					}
+				}
+			}
+		case *hyperiontypes.MsgDepositClaim:
+			done = claimDone(m.HyperionId, m.Orchestrator, m.EventNonce)
+		case *hyperiontypes.MsgWithdrawClaim:
+			done = claimDone(m.HyperionId, m.Orchestrator, m.EventNonce)
+		case *hyperiontypes.MsgValsetUpdatedClaim:
+			done = claimDone(m.HyperionId, m.Orchestrator, m.EventNonce)
+		case *hyperiontypes.MsgERC20DeployedClaim:
+			done = claimDone(m.HyperionId, m.Orchestrator, m.EventNonce)
+		}
+		if !done {
+			return false
+		}
+	}
+
+	return len(msgs) > 0
+}
+
+func outboxEntryLogger(entry outbox.PendingEntry) log.Logger {
+	return originLogger(outbox.WithOrigin(context.Background(), entry.Origin.ChainId, entry.Origin.Loop), "outbox").WithField("entry", entry.ID)
+}
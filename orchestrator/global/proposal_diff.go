@@ -0,0 +1,126 @@
+package global
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/gov"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/paramsdiff"
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+// DiffProposal returns, for each msg of the proposal modifying the counterparty chain
+// params, the diff against the live params of the chain.
+func (g *Global) DiffProposal(ctx context.Context, proposalId uint64) ([]*paramsdiff.Diff, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, errors.New("helios network not initialized")
+	}
+
+	proposal, err := heliosNetwork.GetProposal(ctx, proposalId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get proposal %d", proposalId)
+	}
+
+	cdc := heliosNetwork.Codec()
+	diffs := make([]*paramsdiff.Diff, 0)
+	for _, proposalMsg := range gov.DecodeProposalMsgs(cdc, proposal) {
+		msg, err := proposalMsg.Decode(cdc)
+		if err != nil {
+			continue
+		}
+		if _, ok := paramsdiff.ChainIdOf(msg); !ok {
+			continue
+		}
+		diff, err := g.diffMsg(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// PreviewNewBlockchainProposal returns the params that CreateNewBlockchainProposal would
+// propose, as a diff against the live params.
+func (g *Global) PreviewNewBlockchainProposal(ctx context.Context, counterpartyChainParams *hyperiontypes.CounterpartyChainParams) (*paramsdiff.Diff, error) {
+	return g.diffProposalContent(ctx, g.newBlockchainProposalContent(counterpartyChainParams))
+}
+
+// PreviewHyperionUpdate returns the diff of the params that ProposeHyperionUpdate would
+// propose.
+func (g *Global) PreviewHyperionUpdate(ctx context.Context, counterpartyChainParams *hyperiontypes.CounterpartyChainParams) (*paramsdiff.Diff, error) {
+	return g.diffProposalContent(ctx, g.hyperionUpdateProposalContent(counterpartyChainParams))
+}
+
+// diffProposalContent decodes the msg of a Hyperion proposal content, as sent by
+// SendProposal, and diffs it against the live params.
+func (g *Global) diffProposalContent(ctx context.Context, content string) (*paramsdiff.Diff, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, errors.New("helios network not initialized")
+	}
+
+	var msg sdk.Msg
+	if err := heliosNetwork.Codec().UnmarshalInterfaceJSON([]byte(content), &msg); err != nil {
+		return nil, errors.Wrap(err, "failed to decode proposal msg")
+	}
+	return g.diffMsg(ctx, msg)
+}
+
+func (g *Global) diffMsg(ctx context.Context, msg sdk.Msg) (*paramsdiff.Diff, error) {
+	chainId, _ := paramsdiff.ChainIdOf(msg)
+	current, err := g.currentChainParams(ctx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	return paramsdiff.DiffMsg(current, msg)
+}
+
+// currentChainParams returns the live params of the chain, nil when the chain is not
+// registered yet.
+func (g *Global) currentChainParams(ctx context.Context, chainId uint64) (*hyperiontypes.CounterpartyChainParams, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+
+	hyperionParams, err := heliosNetwork.HyperionParams(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hyperion params")
+	}
+	registered := false
+	for _, counterpartyChainParams := range hyperionParams.CounterpartyChainParams {
+		registered = registered || counterpartyChainParams.BridgeChainId == chainId
+	}
+	if !registered {
+		return nil, nil
+	}
+
+	current, err := heliosNetwork.GetCounterpartyChainParamsByChainId(ctx, chainId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get counterparty chain params of chain %d", chainId)
+	}
+	return current, nil
+}
+
+// logProposalDiff prints the diff of the proposal we are about to send, it does not block
+// the proposal.
+func (g *Global) logProposalDiff(content string) {
+	diff, err := g.diffProposalContent(context.Background(), content)
+	if err != nil {
+		loopLogger("proposal_diff").WithError(err).Warningln("failed to diff proposal against the live params")
+		return
+	}
+	logger := loopLogger("proposal_diff").WithField(logs.FieldChainId, diff.ChainId).WithField("msg_type", diff.MsgType)
+	logger.WithField("risky", diff.Risky).Infoln("proposal diff")
+	for _, change := range diff.Changes {
+		fields := log.Fields{"field": change.Field, "current": change.Current, "proposed": change.Proposed}
+		if change.Risky {
+			logger.WithFields(fields).WithField("reason", change.Reason).Warningln("risky change")
+		} else {
+			logger.WithFields(fields).Infoln("change")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package global
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
+)
+
+// statusStreamInterval is how often the states of the orchestrators are compared to push
+// the ones that changed, the loop statuses are pushed as they change
+const statusStreamInterval = 1 * time.Second
+
+// GetStatusStream returns the hub of the live status stream of every chain.
+func (g *Global) GetStatusStream() *stream.Hub {
+	return g.statusStream
+}
+
+// runStatusStream pushes the HyperionState of a chain each time it changed. The loop
+// timestamps move on every run, they are left out of the comparison so that the replay
+// history is not filled with states differing only by them.
+func (g *Global) runStatusStream(ctx context.Context) {
+	ticker := time.NewTicker(statusStreamInterval)
+	defer ticker.Stop()
+
+	last := make(map[uint64]string)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		seen := make(map[uint64]bool)
+		for chainId, orchestrator := range g.GetOrchestrators() {
+			seen[chainId] = true
+			state, err := json.Marshal(orchestrator.HyperionState)
+			if err != nil {
+				continue
+			}
+			digest, err := stateDigest(state)
+			if err != nil || digest == last[chainId] {
+				continue
+			}
+			last[chainId] = digest
+			g.statusStream.Publish(stream.Event{ChainId: chainId, Type: stream.EventState, Data: json.RawMessage(state)})
+		}
+		for chainId := range last {
+			if !seen[chainId] {
+				delete(last, chainId)
+			}
+		}
+	}
+}
+
+// stateDigest returns the marshalled state without its timestamp fields.
+func stateDigest(state []byte) (string, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(state, &fields); err != nil {
+		return "", err
+	}
+	for name := range fields {
+		if strings.HasSuffix(name, "Timestamp") {
+			delete(fields, name)
+		}
+	}
+	// the keys of a map are marshalled sorted
+	digest, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(digest), nil
+}
@@ -0,0 +1,557 @@
+package global
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	cosmostypes "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/hyperion"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/tendermint"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/transfers"
+	hyperionevents "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
+)
+
+// transferSearchLimit is the number of Helios txs read when searching the events of a transfer
+const transferSearchLimit = 20
+
+// TransferQuery identifies a transfer by one of its hashes or ids.
+type TransferQuery struct {
+	// ChainId of the counterparty chain, read from the MsgSendToChain when zero and the
+	// transfer is looked up by its Helios tx
+	ChainId uint64
+	// TxHash is the deposit tx on the counterparty chain when it starts with 0x, the
+	// MsgSendToChain tx on Helios otherwise
+	TxHash string
+	// EventNonce of the SendToHelios event of an inbound transfer
+	EventNonce uint64
+	// TxId of the outgoing transfer of an outbound transfer
+	TxId uint64
+}
+
+// TrackTransfer rebuilds the lifecycle of the transfers matching the query. A tx holding
+// several deposits or MsgSendToChain returns one lifecycle per transfer.
+func (g *Global) TrackTransfer(ctx context.Context, q TransferQuery) ([]*transfers.Lifecycle, error) {
+	heliosNetwork := g.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return nil, errors.New("helios network not initialized")
+	}
+
+	switch {
+	case strings.HasPrefix(q.TxHash, "0x"):
+		o, err := g.transferOrchestrator(q.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		return trackDepositTx(ctx, o, heliosNetwork, gethcommon.HexToHash(q.TxHash))
+	case q.TxHash != "":
+		return g.trackSendToChainTx(ctx, heliosNetwork, q)
+	case q.EventNonce != 0:
+		o, err := g.transferOrchestrator(q.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		lifecycle, err := trackDepositNonce(ctx, o, heliosNetwork, q.EventNonce)
+		if err != nil {
+			return nil, err
+		}
+		return []*transfers.Lifecycle{lifecycle}, nil
+	case q.TxId != 0:
+		o, err := g.transferOrchestrator(q.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		lifecycle, err := trackOutgoingTx(ctx, o, heliosNetwork, q.TxId)
+		if err != nil {
+			return nil, err
+		}
+		return []*transfers.Lifecycle{lifecycle}, nil
+	}
+	return nil, errors.New("a tx hash, an event nonce or a tx id is required")
+}
+
+// transferOrchestrator is the orchestrator of the chain, its Ethereum network and
+// orchestrator address are used to read the counterparty side of the transfers.
+func (g *Global) transferOrchestrator(chainId uint64) (*orchestrator.Orchestrator, error) {
+	if chainId == 0 {
+		return nil, errors.New("chain id is required")
+	}
+	o := g.GetOrchestrator(chainId)
+	if o == nil {
+		return nil, errors.Errorf("no orchestrator running for chain %d", chainId)
+	}
+	return o, nil
+}
+
+func trackDepositTx(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, txHash gethcommon.Hash) ([]*transfers.Lifecycle, error) {
+	deposits, _, err := o.GetEthereum().GetSendToHeliosEventsOfTx(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(deposits) == 0 {
+		return nil, errors.Errorf("no SendToHelios event in tx %s", txHash.Hex())
+	}
+
+	lifecycles := make([]*transfers.Lifecycle, 0, len(deposits))
+	for _, deposit := range deposits {
+		facts, err := inboundFacts(ctx, o, heliosNetwork, deposit.EventNonce.Uint64(), deposit)
+		if err != nil {
+			return nil, err
+		}
+		lifecycles = append(lifecycles, transfers.BuildInbound(facts, time.Now()))
+	}
+	return lifecycles, nil
+}
+
+// trackDepositNonce looks for the deposit of the nonce in the blocks the oracle scans by
+// default, the deposit step is unknown when it is older.
+func trackDepositNonce(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, eventNonce uint64) (*transfers.Lifecycle, error) {
+	cfg := o.GetConfig()
+	latest, err := o.GetEthereum().GetHeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest ethereum header")
+	}
+	latestHeight := latest.Number.Uint64()
+	startHeight := uint64(0)
+	if blocksToSearch := chainSettingUint(cfg.ChainId, "oracle_eth_default_blocks_to_search", 2000); latestHeight > blocksToSearch {
+		startHeight = latestHeight - blocksToSearch
+	}
+
+	events, err := o.GetEthereum().GetSendToHeliosEvents(startHeight, latestHeight)
+	if err != nil {
+		return nil, err
+	}
+	var deposit *hyperionevents.HyperionSendToHeliosEvent
+	for _, event := range events {
+		if event.EventNonce.Uint64() == eventNonce {
+			deposit = event
+			break
+		}
+	}
+
+	facts, err := inboundFacts(ctx, o, heliosNetwork, eventNonce, deposit)
+	if err != nil {
+		return nil, err
+	}
+	return transfers.BuildInbound(facts, time.Now()), nil
+}
+
+func inboundFacts(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, eventNonce uint64, deposit *hyperionevents.HyperionSendToHeliosEvent) (*transfers.InboundFacts, error) {
+	cfg := o.GetConfig()
+	facts := &transfers.InboundFacts{
+		ChainId:           cfg.ChainId,
+		EventNonce:        eventNonce,
+		ConfirmationDelay: chainSettingUint(cfg.ChainId, "oracle_block_confirmation_delay", 4),
+	}
+
+	latest, err := o.GetEthereum().GetHeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest ethereum header")
+	}
+	facts.LatestHeight = latest.Number.Uint64()
+
+	lastClaim, err := heliosNetwork.LastClaimEventByAddr(ctx, cfg.HyperionId, cfg.CosmosAddr)
+	if err != nil {
+		return nil, err
+	}
+	if lastClaim != nil {
+		facts.LastClaimedNonce = lastClaim.EthereumEventNonce
+	}
+
+	facts.LastObservedNonce, err = heliosNetwork.QueryGetLastObservedEventNonce(ctx, cfg.HyperionId)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped, err := heliosNetwork.QueryGetAllSkippedTxs(ctx, cfg.HyperionId)
+	if err != nil && !errors.Is(err, hyperion.ErrNotFound) {
+		return nil, err
+	}
+	for _, skippedNonce := range skipped {
+		if skippedNonce.Nonce == eventNonce {
+			facts.Skipped = true
+		}
+	}
+
+	if deposit == nil {
+		return facts, nil
+	}
+
+	facts.Deposit = &transfers.Deposit{
+		EventNonce:    eventNonce,
+		TxHash:        deposit.Raw.TxHash.Hex(),
+		BlockHeight:   deposit.Raw.BlockNumber,
+		Sender:        deposit.Sender.Hex(),
+		Receiver:      cosmostypes.AccAddress(deposit.Destination[12:32]).String(),
+		TokenContract: deposit.TokenContract.Hex(),
+		Amount:        deposit.Amount.String(),
+	}
+	if header, err := o.GetEthereum().GetHeaderByNumber(ctx, new(big.Int).SetUint64(deposit.Raw.BlockNumber)); err == nil {
+		facts.Deposit.Time = time.Unix(int64(header.Time), 0)
+	}
+
+	// the attestation is keyed by the hash of the claim, which leaves the orchestrator out
+	msg, err := heliosNetwork.SendDepositClaimMsg(ctx, cfg.HyperionId, deposit, "")
+	if err != nil {
+		return nil, err
+	}
+	claim, ok := msg.(*hyperiontypes.MsgDepositClaim)
+	if !ok {
+		return facts, nil
+	}
+	attestation, err := heliosNetwork.Attestation(ctx, cfg.HyperionId, eventNonce, claim.ClaimHash())
+	if errors.Is(err, hyperion.ErrNotFound) {
+		return facts, nil
+	} else if err != nil {
+		return nil, err
+	}
+	facts.Attestation = &transfers.Attestation{
+		Observed: attestation.Observed,
+		Votes:    len(attestation.Votes),
+		Height:   attestation.Height,
+		Time:     heliosBlockTime(ctx, heliosNetwork, attestation.Height),
+	}
+	return facts, nil
+}
+
+func (g *Global) trackSendToChainTx(ctx context.Context, heliosNetwork *helios.Network, q TransferQuery) ([]*transfers.Lifecycle, error) {
+	txHash := strings.ToUpper(q.TxHash)
+	resp, err := heliosNetwork.GetTx(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	sendToChainTypeURL := cosmostypes.MsgTypeURL(&hyperiontypes.MsgSendToChain{})
+	msgs := make([]*hyperiontypes.MsgSendToChain, 0)
+	if resp.Tx != nil && resp.Tx.Body != nil {
+		for _, anyMsg := range resp.Tx.Body.Messages {
+			if anyMsg.TypeUrl != sendToChainTypeURL {
+				continue
+			}
+			msg := &hyperiontypes.MsgSendToChain{}
+			if err := msg.Unmarshal(anyMsg.Value); err != nil {
+				return nil, errors.Wrap(err, "failed to decode MsgSendToChain")
+			}
+			if q.ChainId == 0 || msg.DestChainId == q.ChainId {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		return nil, errors.Errorf("no MsgSendToChain in tx %s", txHash)
+	}
+
+	chainId := msgs[0].DestChainId
+	o, err := g.transferOrchestrator(chainId)
+	if err != nil {
+		return nil, err
+	}
+	sent := heliosTxOf(resp.TxResponse)
+
+	inBatches, unbatched, err := heliosNetwork.QueryGetAllPendingSendToChain(ctx, o.GetConfig().HyperionId)
+	if err != nil {
+		return nil, err
+	}
+	lifecycles := make([]*transfers.Lifecycle, 0, len(msgs))
+	for _, pending := range append(inBatches, unbatched...) {
+		if !strings.EqualFold(strings.TrimPrefix(pending.TxHash, "0x"), txHash) {
+			continue
+		}
+		lifecycle, err := outboundLifecycle(ctx, o, heliosNetwork, pending, sent)
+		if err != nil {
+			return nil, err
+		}
+		lifecycles = append(lifecycles, lifecycle)
+	}
+	if len(lifecycles) > 0 {
+		return lifecycles, nil
+	}
+
+	// the transfers left the Helios pool, their ids are read from the events of the tx
+	var txIds []uint64
+	if resp.TxResponse != nil && resp.TxResponse.Code == 0 {
+		txIds = tendermint.ParseSendToChainTxIds(resp.TxResponse.Events, o.GetConfig().HyperionId)
+	}
+	for i, msg := range msgs {
+		facts := &transfers.OutboundFacts{ChainId: msg.DestChainId, Sent: sent}
+		if i < len(txIds) {
+			if facts, err = departedOutboundFacts(ctx, o, heliosNetwork, txIds[i], sent); err != nil {
+				return nil, err
+			}
+		}
+		lifecycle := transfers.BuildOutbound(facts, time.Now())
+		lifecycle.Sender, lifecycle.Receiver, lifecycle.Amount = msg.Sender, msg.Dest, msg.Amount.String()
+		lifecycles = append(lifecycles, lifecycle)
+	}
+	return lifecycles, nil
+}
+
+func trackOutgoingTx(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, txId uint64) (*transfers.Lifecycle, error) {
+	inBatches, unbatched, err := heliosNetwork.QueryGetAllPendingSendToChain(ctx, o.GetConfig().HyperionId)
+	if err != nil {
+		return nil, err
+	}
+	for _, pending := range append(inBatches, unbatched...) {
+		if pending.Id != txId {
+			continue
+		}
+		var sent *transfers.HeliosTx
+		if pending.TxHash != "" {
+			if resp, err := heliosNetwork.GetTx(ctx, strings.ToUpper(strings.TrimPrefix(pending.TxHash, "0x"))); err == nil {
+				sent = heliosTxOf(resp.TxResponse)
+			}
+		}
+		return outboundLifecycle(ctx, o, heliosNetwork, pending, sent)
+	}
+
+	facts, err := departedOutboundFacts(ctx, o, heliosNetwork, txId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return transfers.BuildOutbound(facts, time.Now()), nil
+}
+
+func outboundLifecycle(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, pending *hyperiontypes.OutgoingTransferTx, sent *transfers.HeliosTx) (*transfers.Lifecycle, error) {
+	facts, err := outboundFacts(ctx, o, heliosNetwork, pending.Id, sent)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle := transfers.BuildOutbound(facts, time.Now())
+	lifecycle.Sender, lifecycle.Receiver = pending.Sender, pending.DestAddress
+	if pending.Token != nil {
+		lifecycle.TokenContract, lifecycle.Amount = pending.Token.Contract, pending.Token.Amount.String()
+	}
+	return lifecycle, nil
+}
+
+func outboundFacts(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, txId uint64, sent *transfers.HeliosTx) (*transfers.OutboundFacts, error) {
+	cfg := o.GetConfig()
+	facts := &transfers.OutboundFacts{ChainId: cfg.ChainId, TxId: txId, Sent: sent, Pending: true}
+
+	batches, err := heliosNetwork.QueryGetListOutgoingTxs(ctx, cfg.HyperionId)
+	if err != nil && !errors.Is(err, hyperion.ErrNotFound) {
+		return nil, err
+	}
+	var batch *hyperiontypes.OutgoingTxBatch
+	for _, outgoing := range batches {
+		for _, tx := range outgoing.Transactions {
+			if tx.Id == txId {
+				batch = outgoing
+			}
+		}
+	}
+	if batch == nil {
+		return facts, nil
+	}
+
+	facts.Batch = &transfers.Batch{
+		Nonce:         batch.BatchNonce,
+		TokenContract: batch.TokenContract,
+		Timeout:       batch.BatchTimeout,
+		Height:        batch.Block,
+		Time:          heliosBlockTime(ctx, heliosNetwork, batch.Block),
+	}
+	tokenContract := gethcommon.HexToAddress(batch.TokenContract)
+
+	confirms, err := heliosNetwork.TransactionBatchSignatures(ctx, cfg.HyperionId, batch.BatchNonce, tokenContract)
+	if err != nil && !errors.Is(err, hyperion.ErrNotFound) {
+		return nil, err
+	}
+	facts.Confirms = len(confirms)
+	for _, confirm := range confirms {
+		if confirm.Orchestrator == cfg.CosmosAddr.String() {
+			facts.Signed = true
+		}
+	}
+	if valset, err := heliosNetwork.CurrentValset(ctx, cfg.HyperionId); err == nil && valset != nil {
+		facts.Validators = len(valset.Members)
+	}
+
+	if err := batchExecutionFacts(ctx, o, facts); err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+// departedOutboundFacts looks for what became of a transfer out of the Helios pool: its
+// cancellation, or the last batch built with it and the execution of that batch. Neither
+// found leaves the facts without batch, the transfer is then reported unknown.
+func departedOutboundFacts(ctx context.Context, o *orchestrator.Orchestrator, heliosNetwork *helios.Network, txId uint64, sent *transfers.HeliosTx) (*transfers.OutboundFacts, error) {
+	cfg := o.GetConfig()
+	facts := &transfers.OutboundFacts{ChainId: cfg.ChainId, TxId: txId, Sent: sent}
+
+	cancels, err := heliosNetwork.SearchTxs(ctx, tendermint.CancelSendToChainQuery(txId), transferSearchLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search the cancellation of the transfer")
+	}
+	for _, tx := range cancels {
+		if tx.TxResult.Code == 0 && cancelsTransfer(tx.Tx, cfg.ChainId, txId) {
+			facts.Cancelled = &transfers.HeliosTx{
+				TxHash: strings.ToUpper(hex.EncodeToString(tx.Hash)),
+				Height: uint64(tx.Height),
+				Time:   heliosBlockTime(ctx, heliosNetwork, uint64(tx.Height)),
+			}
+			return facts, nil
+		}
+	}
+
+	batchTxs, err := heliosNetwork.SearchTxs(ctx, tendermint.OutgoingBatchQuery(txId), transferSearchLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search the batch of the transfer")
+	}
+	var batch *tendermint.OutgoingBatch
+	var batchHeight int64
+	for _, tx := range batchTxs {
+		if tx.TxResult.Code != 0 {
+			continue
+		}
+		for _, built := range tendermint.ParseOutgoingBatches(tx.TxResult.Events) {
+			if built.HyperionId != cfg.HyperionId || !slices.Contains(built.TxIds, txId) {
+				continue
+			}
+			// a timed out batch returns its txs to the pool, the last batch is the one that counts
+			if batch == nil || built.Nonce > batch.Nonce {
+				batch, batchHeight = &built, tx.Height
+			}
+		}
+	}
+	if batch == nil {
+		return facts, nil
+	}
+
+	tokenContract, found, err := heliosNetwork.QueryDenomToTokenAddress(ctx, cfg.HyperionId, batch.Denom)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return facts, nil
+	}
+	facts.Batch = &transfers.Batch{
+		Nonce:         batch.Nonce,
+		TokenContract: tokenContract.Hex(),
+		Timeout:       batch.Timeout,
+		Height:        uint64(batchHeight),
+		Time:          heliosBlockTime(ctx, heliosNetwork, uint64(batchHeight)),
+	}
+	if err := batchExecutionFacts(ctx, o, facts); err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+// cancelsTransfer tells whether the raw tx holds the MsgCancelSendToChain of the transfer.
+func cancelsTransfer(rawTx []byte, chainId uint64, txId uint64) bool {
+	raw := &txtypes.TxRaw{}
+	if err := raw.Unmarshal(rawTx); err != nil {
+		return false
+	}
+	body := &txtypes.TxBody{}
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return false
+	}
+	cancelTypeURL := cosmostypes.MsgTypeURL(&hyperiontypes.MsgCancelSendToChain{})
+	for _, anyMsg := range body.Messages {
+		if anyMsg.TypeUrl != cancelTypeURL {
+			continue
+		}
+		msg := &hyperiontypes.MsgCancelSendToChain{}
+		if err := msg.Unmarshal(anyMsg.Value); err == nil && msg.TransactionId == txId && msg.ChainId == chainId {
+			return true
+		}
+	}
+	return false
+}
+
+// batchExecutionFacts reads the execution of the batch of the facts on the counterparty
+// chain, and the relay tx of this orchestrator not mined yet.
+func batchExecutionFacts(ctx context.Context, o *orchestrator.Orchestrator, facts *transfers.OutboundFacts) error {
+	cfg := o.GetConfig()
+	batch := facts.Batch
+	tokenContract := gethcommon.HexToAddress(batch.TokenContract)
+
+	latest, err := o.GetEthereum().GetHeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest ethereum header")
+	}
+	facts.LatestHeight = latest.Number.Uint64()
+
+	contractNonce, err := o.GetEthereum().GetTxBatchNonce(ctx, tokenContract)
+	if err != nil {
+		return err
+	}
+	if contractNonce != nil {
+		facts.ContractBatchNonce = contractNonce.Uint64()
+	}
+
+	if facts.ContractBatchNonce >= batch.Nonce {
+		startHeight := uint64(0)
+		if blocksToSearch := chainSettingUint(cfg.ChainId, "oracle_eth_default_blocks_to_search", 2000); facts.LatestHeight > blocksToSearch {
+			startHeight = facts.LatestHeight - blocksToSearch
+		}
+		executions, err := o.GetEthereum().GetTransactionBatchExecutedEventsOfBatch(startHeight, facts.LatestHeight, batch.Nonce, tokenContract)
+		if err != nil {
+			return err
+		}
+		if len(executions) > 0 {
+			execution := executions[0]
+			facts.Execution = &transfers.Execution{TxHash: execution.Raw.TxHash.Hex(), BlockHeight: execution.Raw.BlockNumber}
+			if header, err := o.GetEthereum().GetHeaderByNumber(ctx, new(big.Int).SetUint64(execution.Raw.BlockNumber)); err == nil {
+				facts.Execution.Time = time.Unix(int64(header.Time), 0)
+			}
+		}
+	}
+
+	for _, tx := range o.PendingTxs() {
+		if tx.Kind == "batch" && tx.Nonce == batch.Nonce && strings.EqualFold(tx.TokenContract, batch.TokenContract) {
+			facts.Relay = &transfers.Relay{TxHash: tx.TxHash, SentAt: tx.SentAt}
+		}
+	}
+	return nil
+}
+
+func heliosTxOf(resp *cosmostypes.TxResponse) *transfers.HeliosTx {
+	if resp == nil {
+		return nil
+	}
+	tx := &transfers.HeliosTx{TxHash: resp.TxHash, Height: uint64(resp.Height), Code: resp.Code, RawLog: resp.RawLog}
+	if t, err := time.Parse(time.RFC3339, resp.Timestamp); err == nil {
+		tx.Time = t
+	}
+	return tx
+}
+
+// heliosBlockTime is the time of the Helios block, zero when it cannot be read.
+func heliosBlockTime(ctx context.Context, heliosNetwork *helios.Network, height uint64) time.Time {
+	if height == 0 {
+		return time.Time{}
+	}
+	block, err := heliosNetwork.GetBlock(ctx, int64(height))
+	if err != nil || block == nil || block.Block == nil {
+		return time.Time{}
+	}
+	return block.Block.Time
+}
+
+func chainSettingUint(chainId uint64, key string, defaultValue uint64) uint64 {
+	settings, err := storage.GetChainSettings(chainId)
+	if err != nil {
+		return defaultValue
+	}
+	value, ok := settings[key].(float64)
+	if !ok {
+		return defaultValue
+	}
+	return uint64(value)
+}
@@ -0,0 +1,22 @@
+package helios
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// switchConn is the gRPC connection the query clients are built on. A failover swaps the
+// connection underneath, each call loads the one in use.
+type switchConn struct {
+	conn atomic.Pointer[grpc.ClientConn]
+}
+
+func (c *switchConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.conn.Load().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (c *switchConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.conn.Load().NewStream(ctx, desc, method, opts...)
+}
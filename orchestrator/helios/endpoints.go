@@ -0,0 +1,199 @@
+package helios
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	comethttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/pkg/errors"
+)
+
+const (
+	// endpointHealthCheckInterval is the interval between two health checks of the Helios endpoints
+	endpointHealthCheckInterval = 15 * time.Second
+	endpointHealthCheckTimeout  = 5 * time.Second
+	// minEndpointCheckInterval rate limits the health checks triggered by failed calls
+	minEndpointCheckInterval = 5 * time.Second
+	// maxEndpointBlockLag is the number of blocks an endpoint can be behind the highest
+	// one before it is considered unhealthy
+	maxEndpointBlockLag = 5
+)
+
+// Endpoint is a Helios node, queried through gRPC and followed through Tendermint RPC.
+type Endpoint struct {
+	GRPC          string `json:"grpc"`
+	TendermintRPC string `json:"tendermint_rpc"`
+}
+
+// EndpointHealth is the result of the last health check of an endpoint.
+type EndpointHealth struct {
+	Endpoint   Endpoint      `json:"endpoint"`
+	Height     int64         `json:"height"`
+	CatchingUp bool          `json:"catching_up"`
+	Latency    time.Duration `json:"latency_ns"`
+	Error      string        `json:"error,omitempty"`
+	CheckedAt  time.Time     `json:"checked_at"`
+	Healthy    bool          `json:"healthy"`
+	Active     bool          `json:"active"`
+}
+
+// ParseEndpoints pairs the comma separated gRPC and Tendermint RPC endpoints by position.
+func ParseEndpoints(grpcList, tendermintList string) ([]Endpoint, error) {
+	grpcs := splitList(grpcList)
+	tendermints := splitList(tendermintList)
+	if len(grpcs) != len(tendermints) {
+		return nil, errors.Errorf("got %d Helios gRPC endpoints but %d Tendermint RPC endpoints", len(grpcs), len(tendermints))
+	}
+
+	endpoints := make([]Endpoint, 0, len(grpcs))
+	for i := range grpcs {
+		endpoints = append(endpoints, Endpoint{GRPC: grpcs[i], TendermintRPC: tendermints[i]})
+	}
+	return endpoints, nil
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// probeEndpoint queries the status of the Tendermint RPC of the endpoint.
+func probeEndpoint(ctx context.Context, endpoint Endpoint) EndpointHealth {
+	health := EndpointHealth{Endpoint: endpoint, CheckedAt: time.Now()}
+
+	ctx, cancelFn := context.WithTimeout(ctx, endpointHealthCheckTimeout)
+	defer cancelFn()
+
+	rpc, err := comethttp.New(endpoint.TendermintRPC, "/websocket")
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	start := time.Now()
+	status, err := rpc.Status(ctx)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Height = status.SyncInfo.LatestBlockHeight
+	health.CatchingUp = status.SyncInfo.CatchingUp
+	return health
+}
+
+// rankEndpoints marks the healthy endpoints and returns their indexes, healthiest first.
+// An endpoint is healthy when it answers, is not catching up and is at most
+// maxEndpointBlockLag blocks behind the highest endpoint.
+func rankEndpoints(healths []EndpointHealth) []int {
+	highest := int64(0)
+	for _, health := range healths {
+		if health.Error == "" && health.Height > highest {
+			highest = health.Height
+		}
+	}
+
+	ranked := make([]int, 0, len(healths))
+	for i := range healths {
+		healths[i].Healthy = healths[i].Error == "" && !healths[i].CatchingUp && highest-healths[i].Height <= maxEndpointBlockLag
+		if healths[i].Healthy {
+			ranked = append(ranked, i)
+		}
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return healths[ranked[a]].Latency < healths[ranked[b]].Latency
+	})
+	return ranked
+}
+
+// endpointSet holds the endpoints of a Network and the result of their last health check.
+// It is shared by the copies of the Network.
+type endpointSet struct {
+	endpoints []Endpoint
+
+	mu        sync.Mutex
+	index     int
+	health    []EndpointHealth
+	checkedAt time.Time
+}
+
+// check probes every endpoint and returns the index of the healthiest one, -1 if none
+// is healthy.
+func (s *endpointSet) check() int {
+	healths := make([]EndpointHealth, len(s.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range s.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint Endpoint) {
+			defer wg.Done()
+			healths[i] = probeEndpoint(context.Background(), endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	ranked := rankEndpoints(healths)
+
+	s.mu.Lock()
+	s.health = healths
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+
+	if len(ranked) == 0 {
+		return -1
+	}
+	return ranked[0]
+}
+
+func (s *endpointSet) lastChecked() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checkedAt
+}
+
+func (s *endpointSet) setActive(index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.index = index
+}
+
+func (s *endpointSet) activeIndex() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.index
+}
+
+func (s *endpointSet) active() Endpoint {
+	return s.endpoints[s.activeIndex()]
+}
+
+func (s *endpointSet) activeHealthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.index < len(s.health) && s.health[s.index].Healthy
+}
+
+func (s *endpointSet) healths() []EndpointHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	healths := make([]EndpointHealth, len(s.health))
+	copy(healths, s.health)
+	for i := range healths {
+		healths[i].Active = i == s.index
+	}
+	return healths
+}
@@ -0,0 +1,35 @@
+package helios
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints("tcp://a:9090, tcp://b:9090", "http://a:26657,http://b:26657")
+	assert.NoError(t, err)
+	assert.Equal(t, []Endpoint{
+		{GRPC: "tcp://a:9090", TendermintRPC: "http://a:26657"},
+		{GRPC: "tcp://b:9090", TendermintRPC: "http://b:26657"},
+	}, endpoints)
+
+	_, err = ParseEndpoints("tcp://a:9090,tcp://b:9090", "http://a:26657")
+	assert.Error(t, err)
+}
+
+func TestRankEndpoints(t *testing.T) {
+	healths := []EndpointHealth{
+		{Height: 100, Latency: 10 * time.Millisecond},
+		{Height: 90, Latency: 1 * time.Millisecond},
+		{Height: 101, Latency: 5 * time.Millisecond, CatchingUp: true},
+		{Error: "connection refused"},
+		{Height: 98, Latency: 20 * time.Millisecond},
+	}
+
+	// the lagging, catching up and unreachable endpoints are left out, the others are ranked by latency
+	assert.Equal(t, []int{0, 4}, rankEndpoints(healths))
+	assert.False(t, healths[1].Healthy)
+	assert.True(t, healths[4].Healthy)
+}
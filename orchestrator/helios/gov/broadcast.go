@@ -12,6 +12,7 @@ import (
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	govbasetypes "github.com/cosmos/cosmos-sdk/x/gov/types"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
@@ -24,16 +25,22 @@ type BClient interface {
 	VoteOnProposalWithOptionMsg(ctx context.Context, proposalId uint64, accountAddress sdk.AccAddress, voteOption govtypes.VoteOption) (sdk.Msg, error)
 }
 
-type broadcastClient struct {
-	chain.ChainClient
+// TxSender broadcasts the txs of the orchestrator account with the sequence shared by
+// every client of the account.
+type TxSender interface {
+	SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	ChainClient() chain.ChainClient
+}
 
+type broadcastClient struct {
+	sender  TxSender
 	svcTags metrics.Tags
 }
 
-func NewBroadcastClient(client chain.ChainClient) BClient {
+func NewBroadcastClient(sender TxSender) BClient {
 	return broadcastClient{
-		ChainClient: client,
-		svcTags:     metrics.Tags{"svc": "gov_broadcast"},
+		sender:  sender,
+		svcTags: metrics.Tags{"svc": "gov_broadcast"},
 	}
 }
 
@@ -70,7 +77,7 @@ func (c broadcastClient) SendProposal(ctx context.Context, title, description, m
 		Summary:  description,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.sender.SyncBroadcastMsg(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return 0, fmt.Errorf("broadcasting MsgSubmitProposal failed: %w", err)
@@ -105,7 +112,7 @@ func (c broadcastClient) VoteOnProposal(ctx context.Context, proposalId uint64,
 		Option:     govtypes.OptionYes,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.sender.SyncBroadcastMsg(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return fmt.Errorf("broadcasting MsgVote failed: %w", err)
@@ -129,7 +136,7 @@ func (c broadcastClient) VoteOnProposalWithOption(ctx context.Context, proposalI
 		Option:     voteOption,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.sender.SyncBroadcastMsg(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return fmt.Errorf("broadcasting MsgVote failed: %w", err)
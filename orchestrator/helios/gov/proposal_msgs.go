@@ -0,0 +1,65 @@
+package gov
+
+import (
+	"encoding/json"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/pkg/errors"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+// ProposalMsg is a msg executed by a proposal, with its JSON encoding including the
+// "@type" field. For the Hyperion proposals, submitted as legacy content, it is the msg
+// carried by the HyperionProposal and not the MsgExecLegacyContent wrapping it.
+type ProposalMsg struct {
+	TypeUrl string
+	JSON    []byte
+}
+
+// Decode returns the msg, its type must be registered in the codec.
+func (m ProposalMsg) Decode(cdc codec.Codec) (sdk.Msg, error) {
+	var msg sdk.Msg
+	if err := cdc.UnmarshalInterfaceJSON(m.JSON, &msg); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", m.TypeUrl)
+	}
+	return msg, nil
+}
+
+// DecodeProposalMsgs returns the msgs executed by the proposal, the msgs that cannot be
+// decoded are skipped.
+func DecodeProposalMsgs(cdc codec.Codec, proposal *govtypes.Proposal) []ProposalMsg {
+	execLegacyContentUrl := sdk.MsgTypeURL(&govtypes.MsgExecLegacyContent{})
+	hyperionProposalUrl := sdk.MsgTypeURL(&hyperiontypes.HyperionProposal{})
+
+	msgs := make([]ProposalMsg, 0, len(proposal.Messages))
+	for _, anyMsg := range proposal.Messages {
+		if anyMsg.TypeUrl == execLegacyContentUrl {
+			var exec govtypes.MsgExecLegacyContent
+			if err := cdc.Unmarshal(anyMsg.Value, &exec); err == nil && exec.Content != nil && exec.Content.TypeUrl == hyperionProposalUrl {
+				var content hyperiontypes.HyperionProposal
+				if err := cdc.Unmarshal(exec.Content.Value, &content); err != nil {
+					continue
+				}
+				var header struct {
+					Type string `json:"@type"`
+				}
+				if err := json.Unmarshal([]byte(content.Msg), &header); err != nil {
+					continue
+				}
+				msgs = append(msgs, ProposalMsg{TypeUrl: header.Type, JSON: []byte(content.Msg)})
+				continue
+			}
+		}
+
+		bz, err := cdc.MarshalJSON(anyMsg)
+		if err != nil {
+			msgs = append(msgs, ProposalMsg{TypeUrl: anyMsg.TypeUrl})
+			continue
+		}
+		msgs = append(msgs, ProposalMsg{TypeUrl: anyMsg.TypeUrl, JSON: bz})
+	}
+	return msgs
+}
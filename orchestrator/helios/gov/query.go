@@ -3,12 +3,15 @@ package gov
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/Helios-Chain-Labs/metrics"
 	query "github.com/cosmos/cosmos-sdk/types/query"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var ErrNotFound = errors.New("not found")
@@ -16,6 +19,7 @@ var ErrNotFound = errors.New("not found")
 type QClient interface {
 	GetProposal(ctx context.Context, proposalId uint64) (*govtypes.Proposal, error)
 	GetProposalsByPageAndSize(ctx context.Context, page int, size int) ([]*govtypes.Proposal, uint64, error)
+	HasVoted(ctx context.Context, proposalId uint64, voter string) (bool, error)
 }
 
 type queryClient struct {
@@ -64,3 +68,39 @@ func (c queryClient) GetProposalsByPageAndSize(ctx context.Context, page int, si
 	}
 	return response.Proposals, response.Pagination.Total, nil
 }
+
+// HasVoted returns true if the voter already voted on the proposal.
+func (c queryClient) HasVoted(ctx context.Context, proposalId uint64, voter string) (bool, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	response, err := c.QueryClient.Vote(ctx, &govtypes.QueryVoteRequest{
+		ProposalId: proposalId,
+		Voter:      voter,
+	})
+	if err != nil {
+		if isVoteNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return response.Vote != nil, nil
+}
+
+// isVoteNotFound reports whether the Vote query failed because the voter has not
+// voted. The gov module answers a missing vote with InvalidArgument rather than
+// NotFound, so that code is only accepted with its "not found" status message.
+func isVoteNotFound(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return true
+	case codes.InvalidArgument:
+		return strings.Contains(st.Message(), "not found")
+	}
+	return false
+}
@@ -10,6 +10,7 @@ import (
 	sdkmath "cosmossdk.io/math"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
@@ -32,6 +33,7 @@ type queuedMessage struct {
 
 type BroadcastClient interface {
 	GetTxCost(ctx context.Context, txHash string) (*big.Int, error)
+	GetTx(ctx context.Context, txHash string) (*txtypes.GetTxResponse, error)
 	SendValsetConfirm(ctx context.Context, hyperionId uint64, ethFrom gethcommon.Address, hyperionID gethcommon.Hash, signFn keystore.PersonalSignFn, valset *hyperiontypes.Valset) error
 	SendValsetConfirmMsg(ctx context.Context, hyperionId uint64, ethFrom gethcommon.Address, hyperionID gethcommon.Hash, signFn keystore.PersonalSignFn, valset *hyperiontypes.Valset) (sdk.Msg, error)
 	SendBatchConfirm(ctx context.Context, hyperionId uint64, ethFrom gethcommon.Address, hyperionID gethcommon.Hash, signFn keystore.PersonalSignFn, batch *hyperiontypes.OutgoingTxBatch) error
@@ -58,6 +60,7 @@ type BroadcastClient interface {
 	SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
 	// SyncBroadcastMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, error)
 	SyncBroadcastMsgsSimulate(ctx context.Context, msgs []sdk.Msg) error
+	ResyncSequence() error
 
 	SendSetOrchestratorAddresses(ctx context.Context, hyperionId uint64, ethAddress string) error
 	SendSetOrchestratorAddressesMsg(ctx context.Context, hyperionId uint64, ethAddress string) (sdk.Msg, error)
@@ -80,24 +83,20 @@ type BroadcastClient interface {
 }
 
 type broadcastClient struct {
-	chain.ChainClient
-
 	svcTags metrics.Tags
 
 	// messageQueue chan queuedMessage // Unbuffered channel for messages to be broadcast
 	// ticker       *time.Ticker
 
-	gasPrice string
-	gas      string
+	// sender broadcasts the txs of the orchestrator account, see tx_sender.go
+	sender *TxSender
 }
 
-func NewBroadcastClient(client chain.ChainClient, gasPrice string, gas string) BroadcastClient {
+func NewBroadcastClient(sender *TxSender) BroadcastClient {
 	broadcastClient := &broadcastClient{
-		ChainClient: client,
-		svcTags:     metrics.Tags{"svc": "hyperion_broadcast"},
+		svcTags: metrics.Tags{"svc": "hyperion_broadcast"},
 		// messageQueue: make(chan queuedMessage),
-		gasPrice: gasPrice,
-		gas:      gas,
+		sender: sender,
 	}
 
 	// go broadcastClient.runBroadcastLoop()
@@ -106,7 +105,7 @@ func NewBroadcastClient(client chain.ChainClient, gasPrice string, gas string) B
 }
 
 // func (c *broadcastClient) Reconnect() {
-// 	err := c.ChainClient.Reconnect(clientcommon.OptionGasPrices(c.gasPrice), clientcommon.OptionGas(c.gas))
+// 	err := c.client().Reconnect(clientcommon.OptionGasPrices(c.gasPrice), clientcommon.OptionGas(c.gas))
 // 	if err != nil {
 // 		log.WithError(err).Errorln("Reconnect failed")
 // 	}
@@ -164,7 +163,7 @@ func NewBroadcastClient(client chain.ChainClient, gasPrice string, gas string) B
 // 	start := time.Now()
 
 // 	// Broadcast the collected messages
-// 	resp, err := c.ChainClient.SyncBroadcastMsg(allMsgs...)
+// 	resp, err := c.client().SyncBroadcastMsg(allMsgs...)
 
 // 	// Send responses back to all waiting callers
 // 	for i := 0; i < len(allRespChans); i++ {
@@ -199,7 +198,7 @@ func (c *broadcastClient) SendValsetConfirm(ctx context.Context, hyperionId uint
 		return errors.Wrap(err, "broadcasting MsgValsetConfirm failed")
 	}
 
-	if err = c.ChainClient.QueueBroadcastMsg(msg); err != nil {
+	if err = c.queueMsg(msg); err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgValsetConfirm failed")
 	}
@@ -213,6 +212,10 @@ func (c *broadcastClient) SendValsetConfirmMsg(ctx context.Context, hyperionId u
 	defer doneFn()
 
 	confirmHash := hyperion.EncodeValsetConfirm(hyperionID, valset)
+	if err := checkValsetSigning(hyperionId, ethFrom, valset.Nonce, confirmHash); err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, err
+	}
 	signature, err := signerFn(ethFrom, confirmHash.Bytes())
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
@@ -236,7 +239,7 @@ func (c *broadcastClient) SendValsetConfirmMsg(ctx context.Context, hyperionId u
 	// -------------
 	msg := &hyperiontypes.MsgValsetConfirm{
 		HyperionId:   hyperionId,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 		EthAddress:   ethFrom.Hex(),
 		Nonce:        valset.Nonce,
 		Signature:    gethcommon.Bytes2Hex(signature),
@@ -250,6 +253,10 @@ func (c *broadcastClient) SendBatchConfirm(_ context.Context, hyperionId uint64,
 	defer doneFn()
 
 	confirmHash := hyperion.EncodeTxBatchConfirm(hyperionID, batch)
+	if err := checkBatchSigning(hyperionId, ethFrom, batch, confirmHash); err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return err
+	}
 	// log.Info("confirmHash: ", confirmHash, "batch: ", batch, "hyperionID: ", hyperionID, "ethFrom: ", ethFrom.Hex())
 	// log.Info("confirmHashLength: ", len(confirmHash.Bytes()))
 	signature, err := signerFn(ethFrom, confirmHash.Bytes())
@@ -271,7 +278,7 @@ func (c *broadcastClient) SendBatchConfirm(_ context.Context, hyperionId uint64,
 	// -------------
 	msg := &hyperiontypes.MsgConfirmBatch{
 		HyperionId:    hyperionId,
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		Nonce:         batch.BatchNonce,
 		Signature:     gethcommon.Bytes2Hex(signature),
 		EthSigner:     ethFrom.Hex(),
@@ -279,7 +286,7 @@ func (c *broadcastClient) SendBatchConfirm(_ context.Context, hyperionId uint64,
 	}
 	log.Info("start confirm batch, msg", msg)
 
-	if err = c.ChainClient.QueueBroadcastMsg(msg); err != nil {
+	if err = c.queueMsg(msg); err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgConfirmBatch failed")
 	}
@@ -293,6 +300,10 @@ func (c *broadcastClient) SendBatchConfirmSync(_ context.Context, hyperionId uin
 	defer doneFn()
 
 	confirmHash := hyperion.EncodeTxBatchConfirm(hyperionID, batch)
+	if err := checkBatchSigning(hyperionId, ethFrom, batch, confirmHash); err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return err
+	}
 	// log.Info("confirmHash: ", confirmHash, "batch: ", batch, "hyperionID: ", hyperionID, "ethFrom: ", ethFrom.Hex())
 	// log.Info("confirmHashLength: ", len(confirmHash.Bytes()))
 	signature, err := signerFn(ethFrom, confirmHash.Bytes())
@@ -314,7 +325,7 @@ func (c *broadcastClient) SendBatchConfirmSync(_ context.Context, hyperionId uin
 	// -------------
 	msg := &hyperiontypes.MsgConfirmBatch{
 		HyperionId:    hyperionId,
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		Nonce:         batch.BatchNonce,
 		Signature:     gethcommon.Bytes2Hex(signature),
 		EthSigner:     ethFrom.Hex(),
@@ -322,7 +333,7 @@ func (c *broadcastClient) SendBatchConfirmSync(_ context.Context, hyperionId uin
 	}
 	log.Info("start confirm batch, msg", msg)
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgConfirmBatch failed")
@@ -359,14 +370,14 @@ func (c *broadcastClient) SendToChain(ctx context.Context, chainId uint64, desti
 	// two layers of fees for the user
 	// -------------
 	msg := &hyperiontypes.MsgSendToChain{
-		Sender:      c.FromAddress().String(),
+		Sender:      c.client().FromAddress().String(),
 		DestChainId: chainId,
 		Dest:        destination.Hex(),
 		Amount:      amount,
 		BridgeFee:   fee, // TODO: use exactly that fee for transaction
 	}
 
-	if err := c.ChainClient.QueueBroadcastMsg(msg); err != nil {
+	if err := c.queueMsg(msg); err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgSendToChain failed")
 	}
@@ -391,9 +402,9 @@ func (c *broadcastClient) SendRequestBatch(ctx context.Context, hyperionId uint6
 	msg := &hyperiontypes.MsgRequestBatch{
 		HyperionId:   hyperionId,
 		Denom:        denom,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 	}
-	if err := c.ChainClient.QueueBroadcastMsg(msg); err != nil {
+	if err := c.queueMsg(msg); err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgRequestBatch failed")
 	}
@@ -405,7 +416,7 @@ func (c *broadcastClient) SendRequestBatchMsg(ctx context.Context, hyperionId ui
 	msg := &hyperiontypes.MsgRequestBatch{
 		HyperionId:   hyperionId,
 		Denom:        denom,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -414,7 +425,7 @@ func (c *broadcastClient) SendRequestBatchWithMinimumFeeMsg(ctx context.Context,
 	msg := &hyperiontypes.MsgRequestBatchWithMinimumFee{
 		HyperionId:      hyperionId,
 		Denom:           denom,
-		Orchestrator:    c.FromAddress().String(),
+		Orchestrator:    c.client().FromAddress().String(),
 		MinimumBatchFee: sdk.NewCoin(sdk.DefaultBondDenom, minimumBatchFee),
 		MinimumTxFee:    sdk.NewCoin(sdk.DefaultBondDenom, minimumTxFee),
 		TxIds:           txIds,
@@ -423,13 +434,26 @@ func (c *broadcastClient) SendRequestBatchWithMinimumFeeMsg(ctx context.Context,
 }
 
 func (c *broadcastClient) GetTxCost(ctx context.Context, txHash string) (*big.Int, error) {
-	tx, err := c.ChainClient.GetTx(ctx, txHash)
+	tx, err := c.client().GetTx(ctx, txHash)
 	if err != nil {
 		return nil, err
 	}
 	return tx.Tx.AuthInfo.Fee.Amount[0].Amount.BigInt(), nil
 }
 
+func (c *broadcastClient) GetTx(ctx context.Context, txHash string) (*txtypes.GetTxResponse, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	tx, err := c.client().GetTx(ctx, txHash)
+	if err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, errors.Wrapf(err, "failed to get tx %s", txHash)
+	}
+	return tx, nil
+}
+
 func (c *broadcastClient) SendSetOrchestratorAddresses(ctx context.Context, hyperionId uint64, ethAddress string) error {
 	metrics.ReportFuncCall(c.svcTags)
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
@@ -439,12 +463,12 @@ func (c *broadcastClient) SendSetOrchestratorAddresses(ctx context.Context, hype
 	// Permit to set the orchestrator address on the hyperion module
 	// -------------
 	msg := &hyperiontypes.MsgSetOrchestratorAddresses{
-		Sender:       c.FromAddress().String(),
+		Sender:       c.client().FromAddress().String(),
 		HyperionId:   hyperionId,
 		EthAddress:   ethAddress,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 	}
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgSetOrchestratorAddresses failed")
@@ -468,10 +492,10 @@ func (c *broadcastClient) SendSetOrchestratorAddresses(ctx context.Context, hype
 
 func (c *broadcastClient) SendSetOrchestratorAddressesMsg(ctx context.Context, hyperionId uint64, ethAddress string) (sdk.Msg, error) {
 	msg := &hyperiontypes.MsgSetOrchestratorAddresses{
-		Sender:       c.FromAddress().String(),
+		Sender:       c.client().FromAddress().String(),
 		HyperionId:   hyperionId,
 		EthAddress:   ethAddress,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -485,10 +509,10 @@ func (c *broadcastClient) SendSetOrchestratorAddressesWithFee(ctx context.Contex
 	// Permit to set the orchestrator address on the hyperion module
 	// -------------
 	msg := &hyperiontypes.MsgSetOrchestratorAddressesWithFee{
-		Sender:          c.FromAddress().String(),
+		Sender:          c.client().FromAddress().String(),
 		HyperionId:      hyperionId,
 		EthAddress:      ethAddress,
-		Orchestrator:    c.FromAddress().String(),
+		Orchestrator:    c.client().FromAddress().String(),
 		MinimumTxFee:    sdk.NewCoin(sdk.DefaultBondDenom, minimumfeePerTx),
 		MinimumBatchFee: sdk.NewCoin(sdk.DefaultBondDenom, minimumfeePerBatch),
 	}
@@ -498,10 +522,10 @@ func (c *broadcastClient) SendSetOrchestratorAddressesWithFee(ctx context.Contex
 		"ethAddress":         ethAddress,
 		"minimumfeePerTx":    minimumfeePerTx.String(),
 		"minimumfeePerBatch": minimumfeePerBatch.String(),
-		"sender":             c.FromAddress().String(),
+		"sender":             c.client().FromAddress().String(),
 	}).Infoln("Sending MsgSetOrchestratorAddressesWithFee")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgSetOrchestratorAddresses failed")
@@ -535,7 +559,7 @@ func (c *broadcastClient) SendUpdateOrchestratorAddressesFee(ctx context.Context
 	if err != nil {
 		return errors.Wrap(err, "broadcasting MsgUpdateOrchestratorAddressesFee failed")
 	}
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgUpdateOrchestratorAddressesFee failed")
@@ -562,7 +586,7 @@ func (c *broadcastClient) SendUpdateOrchestratorAddressesFeeMsg(ctx context.Cont
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 	msg := &hyperiontypes.MsgUpdateOrchestratorAddressesFee{
-		Sender:          c.FromAddress().String(),
+		Sender:          c.client().FromAddress().String(),
 		HyperionId:      hyperionId,
 		MinimumTxFee:    sdk.NewCoin(sdk.DefaultBondDenom, minimumfeePerTx),
 		MinimumBatchFee: sdk.NewCoin(sdk.DefaultBondDenom, minimumfeePerBatch),
@@ -579,11 +603,11 @@ func (c *broadcastClient) SendUnSetOrchestratorAddresses(ctx context.Context, hy
 	// Permit to unset the orchestrator address on the hyperion module
 	// -------------
 	msg := &hyperiontypes.MsgUnSetOrchestratorAddresses{
-		Sender:     c.FromAddress().String(),
+		Sender:     c.client().FromAddress().String(),
 		HyperionId: hyperionId,
 		EthAddress: ethAddress,
 	}
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgUnSetOrchestratorAddresses failed")
@@ -634,7 +658,7 @@ func (c *broadcastClient) SendDepositClaim(ctx context.Context, hyperionId uint6
 		Amount:         sdkmath.NewIntFromBigInt(deposit.Amount),
 		EthereumSender: deposit.Sender.Hex(),
 		CosmosReceiver: sdk.AccAddress(deposit.Destination[12:32]).String(),
-		Orchestrator:   c.ChainClient.FromAddress().String(),
+		Orchestrator:   c.client().FromAddress().String(),
 		Data:           deposit.Data,
 		TxHash:         deposit.Raw.TxHash.Hex(),
 		RpcUsed:        rpcUsedForObservation,
@@ -646,14 +670,14 @@ func (c *broadcastClient) SendDepositClaim(ctx context.Context, hyperionId uint6
 		"data":         deposit.Data,
 	}).Infoln(hyperionId, " - sending MsgDepositClaim")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting MsgDepositClaim failed")
 	}
 
 	// cost := big.NewInt(0)
-	// gasFee, err := c.ChainClient.GetGasFee()//client.HeaderByNumber(ctx, nil)
+	// gasFee, err := c.client().GetGasFee()//client.HeaderByNumber(ctx, nil)
 	// if err == nil {
 	// 	gasUsed := resp.TxResponse.GasUsed
 	// 	// calculate the cost of the transaction
@@ -703,7 +727,7 @@ func (c *broadcastClient) SendWithdrawalClaim(_ context.Context, hyperionId uint
 		BatchNonce:    withdrawal.BatchNonce.Uint64(),
 		BlockHeight:   withdrawal.Raw.BlockNumber,
 		TokenContract: withdrawal.Token.Hex(),
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		TxHash:        withdrawal.Raw.TxHash.Hex(),
 		RpcUsed:       rpcUsedForObservation,
 	}
@@ -713,7 +737,7 @@ func (c *broadcastClient) SendWithdrawalClaim(_ context.Context, hyperionId uint
 		"event_nonce":  msg.EventNonce,
 	}).Infoln(hyperionId, " - sending MsgWithdrawClaim")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting MsgWithdrawClaim failed")
@@ -747,7 +771,7 @@ func (c *broadcastClient) SendExternalDataClaim(ctx context.Context, hyperionId
 		TxNonce:                 nonce,
 		BlockHeight:             blockHeight,
 		ExternalContractAddress: externalContractAddress,
-		Orchestrator:            c.FromAddress().String(),
+		Orchestrator:            c.client().FromAddress().String(),
 		CallDataResult:          hex.EncodeToString(callData),
 		CallDataResultError:     hex.EncodeToString(callErr),
 		RpcUsed:                 rpcUsedForObservation,
@@ -759,7 +783,7 @@ func (c *broadcastClient) SendExternalDataClaim(ctx context.Context, hyperionId
 		"call_data": msg.CallDataResult,
 	}).Infoln(hyperionId, " - sending MsgExternalDataClaim")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting MsgExternalDataClaim failed")
@@ -807,7 +831,7 @@ func (c *broadcastClient) SendValsetClaim(ctx context.Context, hyperionId uint64
 		RewardAmount: sdkmath.NewIntFromBigInt(vs.RewardAmount),
 		RewardToken:  vs.RewardToken.Hex(),
 		Members:      members,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 		RpcUsed:      rpcUsedForObservation,
 	}
 
@@ -817,7 +841,7 @@ func (c *broadcastClient) SendValsetClaim(ctx context.Context, hyperionId uint64
 		"claim_hash":   gethcommon.Bytes2Hex(msg.ClaimHash()),
 	}).Infoln(hyperionId, " - sending MsgValsetUpdatedClaim")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting MsgValsetUpdatedClaim failed")
@@ -861,7 +885,7 @@ func (c *broadcastClient) SendERC20DeployedClaim(_ context.Context, hyperionId u
 		Name:          utils.SanitizeUTF8(erc20.Name),
 		Symbol:        utils.SanitizeUTF8(erc20.Symbol),
 		Decimals:      uint64(erc20.Decimals),
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		RpcUsed:       rpcUsedForObservation,
 	}
 
@@ -870,7 +894,7 @@ func (c *broadcastClient) SendERC20DeployedClaim(_ context.Context, hyperionId u
 		"event_height": msg.BlockHeight,
 	}).Infoln(hyperionId, " - sending MsgERC20DeployedClaim")
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting MsgERC20DeployedClaim failed")
@@ -893,12 +917,12 @@ func (c *broadcastClient) SendForceSetValsetAndLastObservedEventNonce(ctx contex
 	msg := &hyperiontypes.MsgForceSetValsetAndLastObservedEventNonce{
 		HyperionId:                      hyperionId,
 		Valset:                          valset,
-		Signer:                          c.FromAddress().String(),
+		Signer:                          c.client().FromAddress().String(),
 		LastObservedEventNonce:          nonce,
 		LastObservedEthereumBlockHeight: blockHeight,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgForceSetValset failed")
@@ -920,10 +944,10 @@ func (c *broadcastClient) SendCancelAllPendingOutTx(ctx context.Context, chainId
 
 	msg := &hyperiontypes.MsgCancelAllPendingOutgoingTxs{
 		ChainId: chainId,
-		Signer:  c.FromAddress().String(),
+		Signer:  c.client().FromAddress().String(),
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgCancelAllPendingOutTx failed")
@@ -943,11 +967,11 @@ func (c *broadcastClient) SendCancelPendingOutTxs(ctx context.Context, chainId u
 
 	msg := &hyperiontypes.MsgCancelPendingOutgoingTxs{
 		ChainId: chainId,
-		Signer:  c.FromAddress().String(),
+		Signer:  c.client().FromAddress().String(),
 		Count:   count,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgCancelAllPendingOutTx failed")
@@ -991,7 +1015,7 @@ func (c *broadcastClient) SendDepositClaimMsg(ctx context.Context, hyperionId ui
 		Amount:         sdkmath.NewIntFromBigInt(deposit.Amount),
 		EthereumSender: deposit.Sender.Hex(),
 		CosmosReceiver: sdk.AccAddress(deposit.Destination[12:32]).String(),
-		Orchestrator:   c.ChainClient.FromAddress().String(),
+		Orchestrator:   c.client().FromAddress().String(),
 		Data:           deposit.Data,
 		TxHash:         deposit.Raw.TxHash.Hex(),
 		RpcUsed:        rpcUsedForObservation,
@@ -1019,7 +1043,7 @@ func (c *broadcastClient) SendWithdrawalClaimMsg(_ context.Context, hyperionId u
 		BatchNonce:    withdrawal.BatchNonce.Uint64(),
 		BlockHeight:   withdrawal.Raw.BlockNumber,
 		TokenContract: withdrawal.Token.Hex(),
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		TxHash:        withdrawal.Raw.TxHash.Hex(),
 		RpcUsed:       rpcUsedForObservation,
 	}
@@ -1046,7 +1070,7 @@ func (c *broadcastClient) SendExternalDataClaimMsg(ctx context.Context, hyperion
 		TxNonce:                 nonce,
 		BlockHeight:             blockHeight,
 		ExternalContractAddress: externalContractAddress,
-		Orchestrator:            c.FromAddress().String(),
+		Orchestrator:            c.client().FromAddress().String(),
 		CallDataResult:          hex.EncodeToString(callData),
 		CallDataResultError:     hex.EncodeToString(callErr),
 		RpcUsed:                 rpcUsedForObservation,
@@ -1088,7 +1112,7 @@ func (c *broadcastClient) SendValsetClaimMsg(ctx context.Context, hyperionId uin
 		RewardAmount: sdkmath.NewIntFromBigInt(vs.RewardAmount),
 		RewardToken:  vs.RewardToken.Hex(),
 		Members:      members,
-		Orchestrator: c.FromAddress().String(),
+		Orchestrator: c.client().FromAddress().String(),
 		RpcUsed:      rpcUsedForObservation,
 	}
 	return msg, nil
@@ -1122,7 +1146,7 @@ func (c *broadcastClient) SendERC20DeployedClaimMsg(_ context.Context, hyperionI
 		Name:          utils.SanitizeUTF8(erc20.Name),
 		Symbol:        utils.SanitizeUTF8(erc20.Symbol),
 		Decimals:      uint64(erc20.Decimals),
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		RpcUsed:       rpcUsedForObservation,
 	}
 
@@ -1134,7 +1158,7 @@ func (c *broadcastClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, er
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msgs...)
+	resp, err := c.broadcastTx(msgs...)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return nil, errors.Wrap(err, "broadcasting Msgs failed")
@@ -1175,17 +1199,11 @@ func (c *broadcastClient) SyncBroadcastMsgsSimulate(ctx context.Context, msgs []
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 
-	resp, err := c.ChainClient.SimulateMsg(c.ClientContext(), msgs...)
-	if err != nil {
+	if err := c.sender.Simulate(msgs...); err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting Msgs failed")
 	}
 
-	if resp.GasInfo.GasUsed > resp.GasInfo.GasWanted {
-		metrics.ReportFuncError(c.svcTags)
-		return errors.New("gas used is greater than gas wanted")
-	}
-
 	return nil
 }
 
@@ -1200,10 +1218,10 @@ func (c *broadcastClient) UpdateChainSmartContract(ctx context.Context, chainId
 		BridgeContractStartHeight: bridgeContractStartHeight,
 		ContractSourceHash:        contractSourceCodeHash,
 		FirstOrchestratorAddress:  ethFrom.Hex(),
-		Signer:                    c.FromAddress().String(),
+		Signer:                    c.client().FromAddress().String(),
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.broadcastTx(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return errors.Wrap(err, "broadcasting MsgUpdateChainSmartContract failed")
@@ -1223,7 +1241,7 @@ func (c *broadcastClient) UpdateChainLogoMsg(ctx context.Context, chainId uint64
 	msg := &hyperiontypes.MsgUpdateChainLogo{
 		ChainId: chainId,
 		Logo:    logo,
-		Signer:  c.FromAddress().String(),
+		Signer:  c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -1234,6 +1252,10 @@ func (c *broadcastClient) SendBatchConfirmMsg(ctx context.Context, hyperionId ui
 	defer doneFn()
 
 	confirmHash := hyperion.EncodeTxBatchConfirm(hyperionID, batch)
+	if err := checkBatchSigning(hyperionId, ethFrom, batch, confirmHash); err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, err
+	}
 	// log.Info("confirmHash: ", confirmHash, "batch: ", batch, "hyperionID: ", hyperionID, "ethFrom: ", ethFrom.Hex())
 	// log.Info("confirmHashLength: ", len(confirmHash.Bytes()))
 	signature, err := signerFn(ethFrom, confirmHash.Bytes())
@@ -1255,7 +1277,7 @@ func (c *broadcastClient) SendBatchConfirmMsg(ctx context.Context, hyperionId ui
 	// -------------
 	msg := &hyperiontypes.MsgConfirmBatch{
 		HyperionId:    hyperionId,
-		Orchestrator:  c.FromAddress().String(),
+		Orchestrator:  c.client().FromAddress().String(),
 		Nonce:         batch.BatchNonce,
 		Signature:     gethcommon.Bytes2Hex(signature),
 		EthSigner:     ethFrom.Hex(),
@@ -1272,7 +1294,7 @@ func (c *broadcastClient) AddWhitelistedAddressMsg(ctx context.Context, chainId
 	msg := &hyperiontypes.MsgAddOneWhitelistedAddress{
 		HyperionId: chainId,
 		Address:    address,
-		Signer:     c.FromAddress().String(),
+		Signer:     c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -1283,7 +1305,7 @@ func (c *broadcastClient) PauseHyperionWithdrawalMsg(ctx context.Context, chainI
 	defer doneFn()
 	msg := &hyperiontypes.MsgPauseChain{
 		ChainId: chainId,
-		Signer:  c.FromAddress().String(),
+		Signer:  c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -1294,7 +1316,7 @@ func (c *broadcastClient) UnpauseHyperionWithdrawalMsg(ctx context.Context, chai
 	defer doneFn()
 	msg := &hyperiontypes.MsgUnpauseChain{
 		ChainId: chainId,
-		Signer:  c.FromAddress().String(),
+		Signer:  c.client().FromAddress().String(),
 	}
 	return msg, nil
 }
@@ -1312,7 +1334,7 @@ func (c *broadcastClient) MintTokensMsg(ctx context.Context, chainId uint64, tok
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 	msg := &hyperiontypes.MsgMintToken{
-		Signer:          c.FromAddress().String(),
+		Signer:          c.client().FromAddress().String(),
 		ChainId:         chainId,
 		TokenAddress:    gethcommon.HexToAddress(tokenAddress).Hex(),
 		Amount:          amount,
@@ -1320,3 +1342,29 @@ func (c *broadcastClient) MintTokensMsg(ctx context.Context, chainId uint64, tok
 	}
 	return msg, nil
 }
+
+// client returns the chain client of the endpoint in use.
+func (c *broadcastClient) client() chain.ChainClient {
+	return c.sender.ChainClient()
+}
+
+// broadcastTx sends the msgs with the shared sender of the account.
+func (c *broadcastClient) broadcastTx(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
+	return c.sender.SyncBroadcastMsg(msgs...)
+}
+
+// ResyncSequence reloads the sequence of the account from the chain.
+func (c *broadcastClient) ResyncSequence() error {
+	return c.sender.ResyncSequence()
+}
+
+// queueMsg sends a msg in the background, it goes through the shared sender like the other
+// txs of the account instead of the queue of the chain client and its own sequence.
+func (c *broadcastClient) queueMsg(msg sdk.Msg) error {
+	go func() {
+		if _, err := c.broadcastTx(msg); err != nil {
+			log.WithError(err).Warningln("failed to broadcast", sdk.MsgTypeURL(msg))
+		}
+	}()
+	return nil
+}
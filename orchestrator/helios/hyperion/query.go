@@ -11,6 +11,8 @@ import (
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	sdkmath "cosmossdk.io/math"
 	"github.com/Helios-Chain-Labs/metrics"
@@ -43,6 +45,7 @@ type QueryClient interface {
 	UnbatchedTokensWithFees(ctx context.Context, hyperionId uint64) ([]*hyperiontypes.BatchFees, error)
 	UnbatchedTokensWithMinimumFees(ctx context.Context, hyperionId uint64, minimumBatchFee sdkmath.Int, minimumTxFee sdkmath.Int) ([]*hyperiontypes.BatchFeesWithIds, error)
 	TransactionBatchSignatures(ctx context.Context, hyperionId uint64, nonce uint64, tokenContract gethcommon.Address) ([]*hyperiontypes.MsgConfirmBatch, error)
+	BatchRequestByNonce(ctx context.Context, hyperionId uint64, nonce uint64, tokenContract gethcommon.Address) (*hyperiontypes.OutgoingTxBatch, error)
 
 	QueryTokenAddressToDenom(ctx context.Context, hyperionId uint64, tokenAddress gethcommon.Address) (string, bool, error)
 	QueryDenomToTokenAddress(ctx context.Context, hyperionId uint64, denom string) (gethcommon.Address, bool, error)
@@ -53,6 +56,7 @@ type QueryClient interface {
 	QueryGetAllPendingSendToChain(ctx context.Context, chainId uint64) ([]*hyperiontypes.OutgoingTransferTx, []*hyperiontypes.OutgoingTransferTx, error)
 
 	QueryGetAllSkippedTxs(ctx context.Context, chainId uint64) ([]*hyperiontypes.SkippedNonceFullInfo, error)
+	Attestation(ctx context.Context, hyperionId uint64, nonce uint64, claimHash []byte) (*hyperiontypes.Attestation, error)
 
 	GetValidatorHyperionData(ctx context.Context, ethAddr gethcommon.Address) (*hyperiontypes.OrchestratorData, error)
 }
@@ -358,6 +362,34 @@ func (c queryClient) TransactionBatchSignatures(ctx context.Context, hyperionId
 	return resp.Confirms, nil
 }
 
+// BatchRequestByNonce returns the batch of the token with the given nonce, ErrNotFound
+// when Helios does not hold it.
+func (c queryClient) BatchRequestByNonce(ctx context.Context, hyperionId uint64, nonce uint64, tokenContract gethcommon.Address) (*hyperiontypes.OutgoingTxBatch, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	req := &hyperiontypes.QueryBatchRequestByNonceRequest{
+		HyperionId:      hyperionId,
+		Nonce:           nonce,
+		ContractAddress: tokenContract.Hex(),
+	}
+
+	resp, err := c.QueryClient.BatchRequestByNonce(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, errors.Wrap(err, "failed to query BatchRequestByNonce from daemon")
+	}
+
+	if resp == nil || resp.Batch == nil {
+		return nil, ErrNotFound
+	}
+
+	return resp.Batch, nil
+}
+
 func (c queryClient) LastClaimEventByAddr(ctx context.Context, hyperionId uint64, validatorAccountAddress cosmostypes.AccAddress) (*hyperiontypes.LastClaimEvent, error) {
 	log.Info("LastClaimEventByAddr", validatorAccountAddress)
 	metrics.ReportFuncCall(c.svcTags)
@@ -688,6 +720,32 @@ func (c queryClient) QueryGetAllSkippedTxs(ctx context.Context, chainId uint64)
 	return resp.SkippedNonces, nil
 }
 
+func (c queryClient) Attestation(ctx context.Context, hyperionId uint64, nonce uint64, claimHash []byte) (*hyperiontypes.Attestation, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	req := &hyperiontypes.QueryAttestationRequest{
+		Nonce:      nonce,
+		ClaimHash:  claimHash,
+		HyperionId: hyperionId,
+	}
+
+	resp, err := c.QueryClient.Attestation(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, errors.Wrap(err, "failed to query Attestation from daemon")
+	}
+
+	if resp == nil || resp.Attestation == nil {
+		return nil, ErrNotFound
+	}
+
+	return resp.Attestation, nil
+}
+
 func (c queryClient) GetValidatorHyperionData(ctx context.Context, ethAddr gethcommon.Address) (*hyperiontypes.OrchestratorData, error) {
 	metrics.ReportFuncCall(c.svcTags)
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
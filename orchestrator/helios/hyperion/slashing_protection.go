@@ -0,0 +1,30 @@
+package hyperion
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/slashingprotection"
+)
+
+// checkValsetSigning records the valset confirm payload in the slashing protection store
+// and fails if a different payload was already signed for the same nonce.
+func checkValsetSigning(hyperionId uint64, ethFrom gethcommon.Address, nonce uint64, confirmHash gethcommon.Hash) error {
+	store, err := slashingprotection.DefaultStore()
+	if err != nil {
+		return errors.Wrap(err, "slashing protection store unavailable, refusing to sign")
+	}
+	return store.CheckAndRecordValset(hyperionId, ethFrom, nonce, confirmHash)
+}
+
+// checkBatchSigning records the batch confirm payload in the slashing protection store
+// and fails if a different payload was already signed for the same token and nonce.
+func checkBatchSigning(hyperionId uint64, ethFrom gethcommon.Address, batch *hyperiontypes.OutgoingTxBatch, confirmHash gethcommon.Hash) error {
+	store, err := slashingprotection.DefaultStore()
+	if err != nil {
+		return errors.Wrap(err, "slashing protection store unavailable, refusing to sign")
+	}
+	return store.CheckAndRecordBatch(hyperionId, ethFrom, gethcommon.HexToAddress(batch.TokenContract), batch.BatchNonce, confirmHash)
+}
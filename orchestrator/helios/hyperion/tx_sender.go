@@ -0,0 +1,349 @@
+package hyperion
+
+import (
+	"context"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	feemarkettypes "github.com/Helios-Chain-Labs/sdk-go/chain/feemarket/types"
+	"github.com/Helios-Chain-Labs/sdk-go/client/chain"
+)
+
+const (
+	// defaultGasMultiplier is applied to the simulated gas when no multiplier is configured
+	defaultGasMultiplier = 1.3
+	// defaultFeeDenom is used when the configured gas prices do not name a denom
+	defaultFeeDenom = "ahelios"
+
+	gasPriceCacheDuration = 30 * time.Second
+
+	txInclusionTimeout = 40 * time.Second
+	txInclusionPoll    = 100 * time.Millisecond
+)
+
+var sequenceMismatchRegexp = regexp.MustCompile(`expected (\d+), got (\d+)`)
+
+// parseExpectedSequence extracts the sequence expected by the chain from an
+// "account sequence mismatch" error.
+func parseExpectedSequence(err error) (uint64, bool) {
+	if err == nil || !strings.Contains(err.Error(), "account sequence mismatch") {
+		return 0, false
+	}
+	matches := sequenceMismatchRegexp.FindStringSubmatch(err.Error())
+	if len(matches) != 3 {
+		return 0, false
+	}
+	sequence, parseErr := strconv.ParseUint(matches[1], 10, 64)
+	if parseErr != nil {
+		return 0, false
+	}
+	return sequence, true
+}
+
+// TxSender signs and broadcasts the txs of the orchestrator account with a locally tracked
+// sequence. It is shared by every client broadcasting from the account and kept across
+// reconnections, so no two txs are signed with the same sequence.
+type TxSender struct {
+	gasPrice      string
+	gas           string
+	gasMultiplier float64
+
+	clientMu sync.RWMutex
+	client   chain.ChainClient
+
+	seqMu     sync.Mutex
+	accNum    uint64
+	nextSeq   uint64
+	seqLoaded bool
+
+	gasPriceMu        sync.Mutex
+	cachedGasPrice    sdk.DecCoin
+	gasPriceUpdatedAt time.Time
+}
+
+func NewTxSender(client chain.ChainClient, gasPrice string, gas string, gasMultiplier float64) *TxSender {
+	if gasMultiplier <= 0 {
+		gasMultiplier = defaultGasMultiplier
+	}
+
+	return &TxSender{
+		client:        client,
+		gasPrice:      gasPrice,
+		gas:           gas,
+		gasMultiplier: gasMultiplier,
+	}
+}
+
+// SetChainClient switches the sender to the client of another endpoint, the tracked
+// sequence is kept.
+func (s *TxSender) SetChainClient(client chain.ChainClient) {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	s.client = client
+}
+
+// ChainClient returns the client of the endpoint in use, to be loaded on each call.
+func (s *TxSender) ChainClient() chain.ChainClient {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+	return s.client
+}
+
+// ResyncSequence reloads the account number and sequence of the orchestrator from the chain.
+// It is used instead of a full reconnection when a tx was refused for a sequence mismatch.
+func (s *TxSender) ResyncSequence() error {
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	return s.loadSequence()
+}
+
+// loadSequence must be called with seqMu held.
+func (s *TxSender) loadSequence() error {
+	clientCtx := s.ChainClient().ClientContext()
+	accNum, accSeq, err := clientCtx.AccountRetriever.GetAccountNumberSequence(clientCtx, clientCtx.GetFromAddress())
+	if err != nil {
+		return errors.Wrap(err, "failed to load account sequence")
+	}
+
+	s.accNum = accNum
+	s.nextSeq = accSeq
+	s.seqLoaded = true
+
+	return nil
+}
+
+// SyncBroadcastMsg simulates the msgs to size the gas, signs them with the locally tracked
+// sequence and waits for the tx to be included in a block. A sequence mismatch resyncs
+// the sequence and retries once. The sequence is only locked until the tx is accepted in
+// the mempool, the wait for the block is done without it.
+func (s *TxSender) SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
+	client := s.ChainClient()
+
+	resp, txBytes, err := s.submit(client, msgs...)
+	if err != nil || resp.TxResponse.Code != 0 {
+		return resp, err
+	}
+
+	return awaitTx(client, resp, txBytes)
+}
+
+// submit signs the msgs and broadcasts them in sync mode, it returns once CheckTx ran.
+func (s *TxSender) submit(client chain.ChainClient, msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, []byte, error) {
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	if !s.seqLoaded {
+		if err := s.loadSequence(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resp, txBytes, err := s.signAndBroadcast(client, msgs...)
+	if sequence, ok := s.sequenceMismatch(resp, err); ok {
+		log.WithField("expected", sequence).WithField("local", s.nextSeq).Warningln("account sequence mismatch, retrying with the sequence expected by Helios")
+		s.nextSeq = sequence
+		resp, txBytes, err = s.signAndBroadcast(client, msgs...)
+	}
+
+	return resp, txBytes, err
+}
+
+// sequenceMismatch tells whether the tx was refused because of its sequence and returns
+// the sequence to use instead.
+func (s *TxSender) sequenceMismatch(resp *txtypes.BroadcastTxResponse, err error) (uint64, bool) {
+	if err == nil && resp != nil && resp.TxResponse != nil && resp.TxResponse.Code != 0 {
+		err = errors.New(resp.TxResponse.RawLog)
+	}
+	if err == nil || !strings.Contains(err.Error(), "account sequence mismatch") {
+		return 0, false
+	}
+
+	if sequence, ok := parseExpectedSequence(err); ok {
+		return sequence, true
+	}
+	if resyncErr := s.loadSequence(); resyncErr != nil {
+		log.WithError(resyncErr).Warningln("failed to resync account sequence")
+		return 0, false
+	}
+	return s.nextSeq, true
+}
+
+// signAndBroadcast must be called with seqMu held. The local sequence is only advanced
+// once the tx passed CheckTx, a tx refused by the mempool did not consume it.
+func (s *TxSender) signAndBroadcast(client chain.ChainClient, msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, []byte, error) {
+	clientCtx := client.ClientContext()
+	txf := chain.NewTxFactory(clientCtx).
+		WithAccountNumber(s.accNum).
+		WithSequence(s.nextSeq)
+
+	gas, err := s.simulateGas(client, txf, msgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gasPrice := s.currentGasPrice(client)
+	txf = txf.
+		WithSimulateAndExecute(false).
+		WithGas(gas).
+		WithGasPrices(gasPrice.String())
+
+	txn, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build unsigned tx")
+	}
+	txn.SetFeeGranter(clientCtx.GetFeeGranterAddress())
+	if err := tx.Sign(context.Background(), txf, clientCtx.GetFromName(), txn, true); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to sign tx")
+	}
+	txBytes, err := clientCtx.TxConfig.TxEncoder()(txn.GetTx())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to encode tx")
+	}
+
+	// sync mode returns the CheckTx result, the tx is then in the mempool
+	resp, err := client.AsyncBroadcastSignedTx(txBytes)
+	if err != nil {
+		return resp, nil, err
+	}
+	if resp == nil || resp.TxResponse == nil {
+		return nil, nil, errors.New("empty broadcast response")
+	}
+	if resp.TxResponse.Code == 0 {
+		s.nextSeq++
+	}
+
+	return resp, txBytes, nil
+}
+
+// awaitTx polls the node until the tx accepted in the mempool is included in a block.
+func awaitTx(client chain.ChainClient, resp *txtypes.BroadcastTxResponse, txBytes []byte) (*txtypes.BroadcastTxResponse, error) {
+	clientCtx := client.ClientContext()
+	awaitCtx, cancelFn := context.WithTimeout(context.Background(), txInclusionTimeout)
+	defer cancelFn()
+
+	txHash, _ := hex.DecodeString(resp.TxResponse.TxHash)
+	ticker := time.NewTicker(txInclusionPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-awaitCtx.Done():
+			// the tx is in the mempool, its sequence is consumed even if we did not see it included
+			return nil, errors.Wrapf(chain.ErrTimedOut, "%s", resp.TxResponse.TxHash)
+		case <-ticker.C:
+			resultTx, err := clientCtx.Client.Tx(awaitCtx, txHash, false)
+			if err != nil {
+				if errRes := sdkclient.CheckCometError(err, txBytes); errRes != nil {
+					return &txtypes.BroadcastTxResponse{TxResponse: errRes}, err
+				}
+				continue
+			}
+			if resultTx.Height > 0 {
+				return &txtypes.BroadcastTxResponse{TxResponse: sdk.NewResponseResultTx(resultTx, resp.TxResponse.Tx, resp.TxResponse.Timestamp)}, nil
+			}
+		}
+	}
+}
+
+// Simulate runs the msgs against the current state with the next sequence, without
+// holding the sequence while the simulation runs.
+func (s *TxSender) Simulate(msgs ...sdk.Msg) error {
+	client := s.ChainClient()
+
+	s.seqMu.Lock()
+	if !s.seqLoaded {
+		if err := s.loadSequence(); err != nil {
+			s.seqMu.Unlock()
+			return err
+		}
+	}
+	accNum, sequence := s.accNum, s.nextSeq
+	s.seqMu.Unlock()
+
+	txf := chain.NewTxFactory(client.ClientContext()).
+		WithAccountNumber(accNum).
+		WithSequence(sequence)
+	_, err := s.simulateGas(client, txf, msgs...)
+	return err
+}
+
+// simulateGas returns the gas used by the msgs in a simulation, scaled by the gas
+// multiplier and capped by the configured gas limit.
+func (s *TxSender) simulateGas(client chain.ChainClient, txf tx.Factory, msgs ...sdk.Msg) (uint64, error) {
+	simTxBytes, err := txf.BuildSimTx(msgs...)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to build sim tx")
+	}
+
+	simRes, err := txtypes.NewServiceClient(client.QueryClient()).Simulate(context.Background(), &txtypes.SimulateRequest{TxBytes: simTxBytes})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to simulate tx")
+	}
+
+	gas := uint64(s.gasMultiplier * float64(simRes.GasInfo.GasUsed))
+	if maxGas, err := strconv.ParseUint(s.gas, 10, 64); err == nil && maxGas > 0 && gas > maxGas {
+		return 0, errors.Errorf("tx needs %d gas, more than the configured limit of %d", gas, maxGas)
+	}
+
+	return gas, nil
+}
+
+// currentGasPrice returns the minimum gas price accepted by Helios, the highest of the
+// feemarket min gas price and base fee. The configured gas prices are used when the
+// feemarket cannot be queried.
+func (s *TxSender) currentGasPrice(client chain.ChainClient) sdk.DecCoin {
+	fallback := s.fallbackGasPrice()
+
+	s.gasPriceMu.Lock()
+	defer s.gasPriceMu.Unlock()
+
+	if time.Since(s.gasPriceUpdatedAt) < gasPriceCacheDuration && s.cachedGasPrice.IsValid() {
+		return s.cachedGasPrice
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	res, err := feemarkettypes.NewQueryClient(client.QueryClient()).Params(ctx, &feemarkettypes.QueryParamsRequest{})
+	if err != nil {
+		log.WithError(err).Warningln("failed to query feemarket params, using the configured gas prices")
+		return fallback
+	}
+
+	price := sdkmath.LegacyZeroDec()
+	if !res.Params.MinGasPrice.IsNil() {
+		price = res.Params.MinGasPrice
+	}
+	if !res.Params.NoBaseFee && !res.Params.BaseFee.IsNil() && res.Params.BaseFee.GT(price) {
+		price = res.Params.BaseFee
+	}
+	if price.IsZero() {
+		price = fallback.Amount
+	}
+
+	s.cachedGasPrice = sdk.NewDecCoinFromDec(fallback.Denom, price)
+	s.gasPriceUpdatedAt = time.Now()
+
+	return s.cachedGasPrice
+}
+
+func (s *TxSender) fallbackGasPrice() sdk.DecCoin {
+	gasPrices, err := sdk.ParseDecCoins(s.gasPrice)
+	if err != nil || gasPrices.Empty() {
+		return sdk.NewDecCoinFromDec(defaultFeeDenom, sdkmath.LegacyZeroDec())
+	}
+	return gasPrices[0]
+}
@@ -0,0 +1,20 @@
+package hyperion
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpectedSequence(t *testing.T) {
+	sequence, ok := parseExpectedSequence(errors.New("account sequence mismatch, expected 42, got 40: incorrect account sequence"))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), sequence)
+
+	_, ok = parseExpectedSequence(errors.New("account sequence mismatch"))
+	assert.False(t, ok)
+
+	_, ok = parseExpectedSequence(errors.New("insufficient fees; expected 10, got 5"))
+	assert.False(t, ok)
+}
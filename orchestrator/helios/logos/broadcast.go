@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 
 	"github.com/Helios-Chain-Labs/metrics"
 	logostypes "github.com/Helios-Chain-Labs/sdk-go/chain/logos/types"
@@ -16,16 +17,22 @@ type BLogosClient interface {
 	StoreLogoMsg(ctx context.Context, logo string) (sdk.Msg, error)
 }
 
-type broadcastClient struct {
-	chain.ChainClient
+// TxSender broadcasts the txs of the orchestrator account with the sequence shared by
+// every client of the account.
+type TxSender interface {
+	SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	ChainClient() chain.ChainClient
+}
 
+type broadcastClient struct {
+	sender  TxSender
 	svcTags metrics.Tags
 }
 
-func NewBroadcastClient(client chain.ChainClient) BLogosClient {
+func NewBroadcastClient(sender TxSender) BLogosClient {
 	return broadcastClient{
-		ChainClient: client,
-		svcTags:     metrics.Tags{"svc": "logos_broadcast"},
+		sender:  sender,
+		svcTags: metrics.Tags{"svc": "logos_broadcast"},
 	}
 }
 
@@ -35,11 +42,11 @@ func (c broadcastClient) StoreLogo(ctx context.Context, logo string) (string, er
 	defer doneFn()
 
 	msg := &logostypes.MsgStoreLogoRequest{
-		Creator: c.FromAddress().String(),
+		Creator: c.sender.ChainClient().FromAddress().String(),
 		Data:    logo,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.sender.SyncBroadcastMsg(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return "", fmt.Errorf("broadcasting MsgStoreLogo failed: %w", err)
@@ -58,7 +65,7 @@ func (c broadcastClient) StoreLogoMsg(ctx context.Context, logo string) (sdk.Msg
 	defer doneFn()
 
 	msg := &logostypes.MsgStoreLogoRequest{
-		Creator: c.FromAddress().String(),
+		Creator: c.sender.ChainClient().FromAddress().String(),
 		Data:    logo,
 	}
 	return msg, nil
@@ -3,6 +3,7 @@ package helios
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/gov"
@@ -16,6 +17,7 @@ import (
 	"github.com/Helios-Chain-Labs/sdk-go/client/chain"
 	clientcommon "github.com/Helios-Chain-Labs/sdk-go/client/common"
 	comethttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
@@ -27,18 +29,38 @@ import (
 	"google.golang.org/grpc/connectivity"
 )
 
+// previousClientCloseDelay is how long the client of a previous endpoint is kept open after a failover
+const previousClientCloseDelay = 1 * time.Minute
+
 type NetworkConfig struct {
 	ChainID,
 	ValidatorAddress,
+	// HeliosGRPC and TendermintRPC are comma separated lists of endpoints, paired by position
 	HeliosGRPC,
 	TendermintRPC,
 	GasPrice string
 	Gas string
+	// GasMultiplier scales the gas used in simulation to set the gas of each tx
+	GasMultiplier float64
 }
 
+// Network holds the Helios clients. They are built once on connections that a failover
+// swaps underneath, so the Network is shared by pointer and never copied.
 type Network struct {
-	chainClient chain.ChainClient
-	cfg         NetworkConfig
+	cfg     NetworkConfig
+	keyring keyring.Keyring
+
+	// conn is the gRPC connection of the query clients
+	conn *switchConn
+	// sender broadcasts every tx of the orchestrator account and holds the chain client in
+	// use, it outlives reconnections
+	sender *hyperion.TxSender
+
+	// endpoints the network fails over between, nil when using the load balanced endpoints
+	endpoints *endpointSet
+	// connectMu serializes the reconnections and failovers
+	connectMu sync.Mutex
+
 	hyperion.QueryClient
 	gov.QClient
 	tendermint.Client
@@ -50,16 +72,56 @@ type Network struct {
 	logos.BLogosClient
 }
 
-func NewNetworkWithBroadcast(k keyring.Keyring, cfg NetworkConfig) (*Network, error) {
-	clientCfg := cfg.loadClientConfig()
+// NewNetworkWithBroadcast connects to Helios. The health checks of the custom endpoints
+// run until ctx is done.
+func NewNetworkWithBroadcast(ctx context.Context, k keyring.Keyring, cfg NetworkConfig) (*Network, error) {
 	logger := log.WithField("svc", "MAIN PROCESS")
 
-	logger.Infoln("New Client Context with chain", clientCfg.ChainId, " and Validator", cfg.ValidatorAddress)
+	n := &Network{
+		cfg:     cfg,
+		keyring: k,
+	}
 
-	clientCtx, err := chain.NewClientContext(clientCfg.ChainId, cfg.ValidatorAddress, k)
-	if err != nil {
+	if cfg.HeliosGRPC != "" && cfg.TendermintRPC != "" {
+		endpoints, err := ParseEndpoints(cfg.HeliosGRPC, cfg.TendermintRPC)
+		if err != nil {
+			return nil, err
+		}
+		n.endpoints = &endpointSet{endpoints: endpoints}
+
+		active := n.endpoints.check()
+		if active < 0 {
+			logger.Warningln("no healthy Helios endpoint, using the first one")
+			active = 0
+		}
+		n.endpoints.setActive(active)
+		logger.WithFields(log.Fields{"helios_grpc": endpoints[active].GRPC, "tendermint_rpc": endpoints[active].TendermintRPC}).Debugln("using custom endpoints for Helios")
+		if err := n.connect(customEndpoints(cfg, endpoints[active])); err != nil {
+			return nil, err
+		}
+
+		go n.runHealthChecks(ctx)
+		return n, nil
+	}
+
+	clientCfg := loadBalancedEndpoints(cfg)
+	logger.WithFields(log.Fields{"Helios_grpc": clientCfg.ChainGrpcEndpoint, "tendermint_rpc": clientCfg.TmEndpoint}).Debugln("using load balanced endpoints for Helios")
+	if err := n.connect(clientCfg); err != nil {
 		return nil, err
 	}
+	return n, nil
+}
+
+// connect builds the Helios clients against the given endpoints.
+func (n *Network) connect(clientCfg clientcommon.Network) error {
+	logger := log.WithField("svc", "MAIN PROCESS")
+
+	logger.Infoln("New Client Context with chain", clientCfg.ChainId, " and Validator", n.cfg.ValidatorAddress)
+
+	clientCtx, err := chain.NewClientContext(clientCfg.ChainId, n.cfg.ValidatorAddress, n.keyring)
+	if err != nil {
+		return err
+	}
 
 	logger.Infoln("Context OK")
 
@@ -71,7 +133,7 @@ func NewNetworkWithBroadcast(k keyring.Keyring, cfg NetworkConfig) (*Network, er
 
 	tmRPC, err := comethttp.New(clientCfg.TmEndpoint, "/websocket")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	logger.Infoln("RPC OK")
@@ -80,12 +142,12 @@ func NewNetworkWithBroadcast(k keyring.Keyring, cfg NetworkConfig) (*Network, er
 
 	logger.Infoln("WithClient OK")
 
-	logger.Infoln(fmt.Sprintf("GasPrice CONFIG %s", cfg.GasPrice))
-	logger.Infoln(fmt.Sprintf("GAS CONFIG %s", cfg.Gas))
+	logger.Infoln(fmt.Sprintf("GasPrice CONFIG %s", n.cfg.GasPrice))
+	logger.Infoln(fmt.Sprintf("GAS CONFIG %s", n.cfg.Gas))
 
-	chainClient, err := chain.NewChainClient(clientCtx, clientCfg, clientcommon.OptionGasPrices(cfg.GasPrice), clientcommon.OptionGas(cfg.Gas))
+	chainClient, err := chain.NewChainClient(clientCtx, clientCfg, clientcommon.OptionGasPrices(n.cfg.GasPrice), clientcommon.OptionGas(n.cfg.Gas))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	logger.Infoln("NewChainClient OK")
@@ -94,28 +156,61 @@ func NewNetworkWithBroadcast(k keyring.Keyring, cfg NetworkConfig) (*Network, er
 
 	conn := awaitConnection(chainClient, 1*time.Minute)
 	if conn == nil {
-		return nil, errors.New("failed to connect to chain")
+		return errors.New("failed to connect to chain")
 	}
 
 	logger.Infoln("CON OK")
 
-	return &Network{
-		chainClient:             chainClient,
-		cfg:                     cfg,
-		QueryClient:             hyperion.NewQueryClient(hyperiontypes.NewQueryClient(conn)),
-		QClient:                 gov.NewQueryClient(govtypes.NewQueryClient(conn)),
-		Client:                  tendermint.NewRPCClient(clientCfg.TmEndpoint), // websocket on rpc node
-		StakingQueryClient:      staking.NewQueryClient(stakingtypes.NewQueryClient(conn)),
-		QLogosClient:            logos.NewQueryClient(logostypes.NewQueryClient(conn)),
-		BroadcastClient:         hyperion.NewBroadcastClient(chainClient, cfg.GasPrice, cfg.Gas),
-		BClient:                 gov.NewBroadcastClient(chainClient),
-		SlashingBroadcastClient: slashing.NewBroadcastClient(chainClient),
-		BLogosClient:            logos.NewBroadcastClient(chainClient),
-	}, nil
+	if n.sender == nil {
+		n.conn = &switchConn{}
+		n.conn.conn.Store(conn)
+		n.sender = hyperion.NewTxSender(chainClient, n.cfg.GasPrice, n.cfg.Gas, n.cfg.GasMultiplier)
+
+		n.QueryClient = hyperion.NewQueryClient(hyperiontypes.NewQueryClient(n.conn))
+		n.QClient = gov.NewQueryClient(govtypes.NewQueryClient(n.conn))
+		n.Client = tendermint.NewRPCClient(clientCfg.TmEndpoint) // websocket on rpc node
+		n.StakingQueryClient = staking.NewQueryClient(stakingtypes.NewQueryClient(n.conn))
+		n.QLogosClient = logos.NewQueryClient(logostypes.NewQueryClient(n.conn))
+		n.BroadcastClient = hyperion.NewBroadcastClient(n.sender)
+		n.BClient = gov.NewBroadcastClient(n.sender)
+		n.SlashingBroadcastClient = slashing.NewBroadcastClient(n.sender)
+		n.BLogosClient = logos.NewBroadcastClient(n.sender)
+		return nil
+	}
+
+	if err := n.Client.SetNode(clientCfg.TmEndpoint); err != nil {
+		chainClient.Close()
+		return errors.Wrap(err, "failed to connect to the tendermint rpc")
+	}
+
+	previous := n.sender.ChainClient()
+	n.conn.conn.Store(conn)
+	n.sender.SetChainClient(chainClient)
+
+	// leave the in flight calls some time to complete before closing the previous client
+	go func() {
+		time.Sleep(previousClientCloseDelay)
+		previous.Close()
+	}()
+
+	return nil
 }
 
+// Reconnect switches to the healthiest endpoint, or reconnects to the active one when it
+// is still the healthiest.
 func (n *Network) Reconnect() {
-	err := n.chainClient.Reconnect(
+	n.connectMu.Lock()
+	defer n.connectMu.Unlock()
+
+	if n.endpoints != nil && len(n.endpoints.endpoints) > 1 {
+		if best := n.endpoints.check(); best >= 0 && best != n.endpoints.activeIndex() {
+			n.failover(best)
+			return
+		}
+	}
+
+	chainClient := n.sender.ChainClient()
+	err := chainClient.Reconnect(
 		clientcommon.OptionGasPrices(n.cfg.GasPrice),
 		clientcommon.OptionGas(n.cfg.Gas),
 	)
@@ -124,12 +219,81 @@ func (n *Network) Reconnect() {
 		return
 	}
 
-	conn := n.chainClient.QueryClient()
+	n.conn.conn.Store(chainClient.QueryClient())
+}
+
+// runHealthChecks keeps probing the endpoints until ctx is done and fails over as soon as
+// the active endpoint is no longer healthy.
+func (n *Network) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(endpointHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.failoverIfUnhealthy()
+		}
+	}
+}
+
+// FailoverIfUnhealthy checks the endpoints right away and fails over when the active one
+// is no longer healthy. It is meant to be called when a call failed to reach Helios.
+func (n *Network) FailoverIfUnhealthy() {
+	if n.endpoints == nil || len(n.endpoints.endpoints) < 2 {
+		return
+	}
+	if time.Since(n.endpoints.lastChecked()) < minEndpointCheckInterval {
+		return
+	}
+	n.failoverIfUnhealthy()
+}
+
+func (n *Network) failoverIfUnhealthy() {
+	n.connectMu.Lock()
+	defer n.connectMu.Unlock()
 
-	n.QueryClient = hyperion.NewQueryClient(hyperiontypes.NewQueryClient(conn))
-	n.QClient = gov.NewQueryClient(govtypes.NewQueryClient(conn))
-	n.StakingQueryClient = staking.NewQueryClient(stakingtypes.NewQueryClient(conn))
-	n.QLogosClient = logos.NewQueryClient(logostypes.NewQueryClient(conn))
+	best := n.endpoints.check()
+	if best < 0 || n.endpoints.activeHealthy() {
+		return
+	}
+	n.failover(best)
+}
+
+// failover must be called with connectMu held.
+func (n *Network) failover(index int) {
+	from := n.endpoints.active()
+	to := n.endpoints.endpoints[index]
+	log.WithFields(log.Fields{"from": from.GRPC, "to": to.GRPC}).Warningln("failing over to another Helios endpoint")
+
+	if err := n.connect(customEndpoints(n.cfg, to)); err != nil {
+		log.WithError(err).WithField("endpoint", to.GRPC).Errorln("failed to connect to Helios endpoint")
+		return
+	}
+	n.endpoints.setActive(index)
+}
+
+// ActiveEndpoint returns the endpoint currently used for queries and broadcasts.
+func (n *Network) ActiveEndpoint() Endpoint {
+	if n.endpoints == nil {
+		clientCfg := loadBalancedEndpoints(n.cfg)
+		return Endpoint{GRPC: clientCfg.ChainGrpcEndpoint, TendermintRPC: clientCfg.TmEndpoint}
+	}
+	return n.endpoints.active()
+}
+
+// EndpointsHealth returns the result of the last health check of every endpoint.
+func (n *Network) EndpointsHealth() []EndpointHealth {
+	if n.endpoints == nil {
+		return []EndpointHealth{}
+	}
+	return n.endpoints.healths()
+}
+
+// Codec returns the codec of the Helios client, it knows every msg type we broadcast.
+func (n *Network) Codec() codec.Codec {
+	return n.sender.ChainClient().ClientContext().Codec
 }
 
 func awaitConnection(client chain.ChainClient, timeout time.Duration) *grpc.ClientConn {
@@ -140,7 +304,7 @@ func awaitConnection(client chain.ChainClient, timeout time.Duration) *grpc.Clie
 	for {
 		select {
 		case <-ctx.Done():
-			logger.Fatalln("GRPC service wait timed out")
+			logger.Errorln("GRPC service wait timed out")
 			return nil
 		default:
 			grpcConn := client.QueryClient()
@@ -156,26 +320,13 @@ func awaitConnection(client chain.ChainClient, timeout time.Duration) *grpc.Clie
 	}
 }
 
-func (cfg NetworkConfig) loadClientConfig() clientcommon.Network {
-	logger := log.WithField("svc", "MAIN PROCESS")
-	if custom := cfg.HeliosGRPC != "" && cfg.TendermintRPC != ""; custom {
-		logger.WithFields(log.Fields{"helios_grpc": cfg.HeliosGRPC, "tendermint_rpc": cfg.TendermintRPC}).Debugln("using custom endpoints for Helios")
-		return customEndpoints(cfg)
-	}
-
-	c := loadBalancedEndpoints(cfg)
-	logger.WithFields(log.Fields{"Helios_grpc": c.ChainGrpcEndpoint, "tendermint_rpc": c.TmEndpoint}).Debugln("using load balanced endpoints for Helios")
-
-	return c
-}
-
-func customEndpoints(cfg NetworkConfig) clientcommon.Network {
+func customEndpoints(cfg NetworkConfig, endpoint Endpoint) clientcommon.Network {
 	c := clientcommon.LoadNetwork("devnet", "")
 	c.Name = "custom"
 	c.ChainId = cfg.ChainID
 	c.FeeDenom = "helios"
-	c.TmEndpoint = cfg.TendermintRPC
-	c.ChainGrpcEndpoint = cfg.HeliosGRPC
+	c.TmEndpoint = endpoint.TendermintRPC
+	c.ChainGrpcEndpoint = endpoint.GRPC
 	c.ExplorerGrpcEndpoint = ""
 	c.LcdEndpoint = ""
 	c.ExplorerGrpcEndpoint = ""
@@ -199,7 +350,7 @@ func loadBalancedEndpoints(cfg NetworkConfig) clientcommon.Network {
 	return clientcommon.LoadNetwork(networkName, "lb")
 }
 
-func HasRegisteredOrchestrator(n Network, hyperionId uint64, ethAddr gethcommon.Address) (cosmostypes.AccAddress, bool) {
+func HasRegisteredOrchestrator(n *Network, hyperionId uint64, ethAddr gethcommon.Address) (cosmostypes.AccAddress, bool) {
 	logger := log.WithField("svc", "MAIN PROCESS")
 	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelFn()
@@ -215,7 +366,7 @@ func HasRegisteredOrchestrator(n Network, hyperionId uint64, ethAddr gethcommon.
 	return validator, true
 }
 
-func GetListOfNetworksWhereRegistered(n Network, ethAddr gethcommon.Address) ([]uint64, bool) {
+func GetListOfNetworksWhereRegistered(n *Network, ethAddr gethcommon.Address) ([]uint64, bool) {
 	logger := log.WithField("svc", "MAIN PROCESS")
 	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelFn()
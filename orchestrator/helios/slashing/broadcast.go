@@ -8,6 +8,7 @@ import (
 	"github.com/Helios-Chain-Labs/sdk-go/client/chain"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 )
 
@@ -16,16 +17,22 @@ type SlashingBroadcastClient interface {
 	SendUnjailMsg(ctx context.Context, validatorAddress string) (sdk.Msg, error)
 }
 
-type broadcastClient struct {
-	chain.ChainClient
+// TxSender broadcasts the txs of the orchestrator account with the sequence shared by
+// every client of the account.
+type TxSender interface {
+	SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	ChainClient() chain.ChainClient
+}
 
+type broadcastClient struct {
+	sender  TxSender
 	svcTags metrics.Tags
 }
 
-func NewBroadcastClient(client chain.ChainClient) SlashingBroadcastClient {
+func NewBroadcastClient(sender TxSender) SlashingBroadcastClient {
 	return broadcastClient{
-		ChainClient: client,
-		svcTags:     metrics.Tags{"svc": "gov_broadcast"},
+		sender:  sender,
+		svcTags: metrics.Tags{"svc": "gov_broadcast"},
 	}
 }
 
@@ -38,7 +45,7 @@ func (c broadcastClient) SendUnjail(ctx context.Context, validatorAddress string
 		ValidatorAddr: validatorAddress,
 	}
 
-	resp, err := c.ChainClient.SyncBroadcastMsg(msg)
+	resp, err := c.sender.SyncBroadcastMsg(msg)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return fmt.Errorf("broadcasting MsgUnjail failed: %w", err)
@@ -3,6 +3,7 @@ package tendermint
 import (
 	"context"
 	"strings"
+	"sync/atomic"
 
 	"github.com/Helios-Chain-Labs/metrics"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
@@ -14,32 +15,48 @@ import (
 type Client interface {
 	GetBlock(ctx context.Context, height int64) (*comettypes.ResultBlock, error)
 	GetLatestBlockHeight(ctx context.Context) (int64, error)
+	GetSyncInfo(ctx context.Context) (*comettypes.SyncInfo, error)
 	GetTxs(ctx context.Context, block *comettypes.ResultBlock) ([]*comettypes.ResultTx, error)
 	GetValidatorSet(ctx context.Context, height int64) (*comettypes.ResultValidators, error)
+	SearchTxs(ctx context.Context, query string, limit int) ([]*comettypes.ResultTx, error)
+	// SetNode points the client to another node, the calls in flight complete on the previous one
+	SetNode(rpcNodeAddr string) error
 }
 
 type tmClient struct {
-	rpcClient rpcclient.Client
-	svcTags   metrics.Tags
+	rpc     atomic.Pointer[rpchttp.HTTP]
+	svcTags metrics.Tags
 }
 
 func NewRPCClient(rpcNodeAddr string) Client {
-	rpcClient, err := rpchttp.NewWithTimeout(rpcNodeAddr, "/websocket", 10)
-	if err != nil {
-		log.WithError(err).Fatalln("failed to init rpcClient")
-	}
-
-	return &tmClient{
-		rpcClient: rpcClient,
+	c := &tmClient{
 		svcTags: metrics.Tags{
 			"svc": string("tendermint"),
 		},
 	}
+	if err := c.SetNode(rpcNodeAddr); err != nil {
+		log.WithError(err).Fatalln("failed to init rpcClient")
+	}
+
+	return c
+}
+
+func (c *tmClient) SetNode(rpcNodeAddr string) error {
+	rpcClient, err := rpchttp.NewWithTimeout(rpcNodeAddr, "/websocket", 10)
+	if err != nil {
+		return err
+	}
+	c.rpc.Store(rpcClient)
+	return nil
+}
+
+func (c *tmClient) rpcClient() rpcclient.Client {
+	return c.rpc.Load()
 }
 
 // GetBlock queries for a block by height. An error is returned if the query fails.
 func (c *tmClient) GetBlock(ctx context.Context, height int64) (*comettypes.ResultBlock, error) {
-	return c.rpcClient.Block(ctx, &height)
+	return c.rpcClient().Block(ctx, &height)
 }
 
 // GetLatestBlockHeight returns the latest block height on the active chain.
@@ -49,7 +66,7 @@ func (c *tmClient) GetLatestBlockHeight(ctx context.Context) (int64, error) {
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 
-	status, err := c.rpcClient.Status(ctx)
+	status, err := c.rpcClient().Status(ctx)
 	if err != nil {
 		metrics.ReportFuncError(c.svcTags)
 		return -1, err
@@ -60,6 +77,21 @@ func (c *tmClient) GetLatestBlockHeight(ctx context.Context) (int64, error) {
 	return height, nil
 }
 
+// GetSyncInfo returns the latest block and catching up status of the node.
+func (c *tmClient) GetSyncInfo(ctx context.Context) (*comettypes.SyncInfo, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	status, err := c.rpcClient().Status(ctx)
+	if err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, err
+	}
+
+	return &status.SyncInfo, nil
+}
+
 // GetTxs queries for all the transactions in a block height.
 // It uses `Tx` RPC method to query for the transaction.
 func (c *tmClient) GetTxs(ctx context.Context, block *comettypes.ResultBlock) ([]*comettypes.ResultTx, error) {
@@ -69,7 +101,7 @@ func (c *tmClient) GetTxs(ctx context.Context, block *comettypes.ResultBlock) ([
 
 	txs := make([]*comettypes.ResultTx, 0, len(block.Block.Txs))
 	for _, tmTx := range block.Block.Txs {
-		tx, err := c.rpcClient.Tx(ctx, tmTx.Hash(), true)
+		tx, err := c.rpcClient().Tx(ctx, tmTx.Hash(), true)
 		if err != nil {
 			if strings.HasSuffix(err.Error(), "not found") {
 				metrics.ReportFuncError(c.svcTags)
@@ -93,5 +125,20 @@ func (c *tmClient) GetValidatorSet(ctx context.Context, height int64) (*comettyp
 	doneFn := metrics.ReportFuncTiming(c.svcTags)
 	defer doneFn()
 
-	return c.rpcClient.Validators(ctx, &height, nil, nil)
+	return c.rpcClient().Validators(ctx, &height, nil, nil)
+}
+
+// SearchTxs returns the latest txs matching the event query, newest first.
+func (c *tmClient) SearchTxs(ctx context.Context, query string, limit int) ([]*comettypes.ResultTx, error) {
+	metrics.ReportFuncCall(c.svcTags)
+	doneFn := metrics.ReportFuncTiming(c.svcTags)
+	defer doneFn()
+
+	page := 1
+	result, err := c.rpcClient().TxSearch(ctx, query, false, &page, &limit, "desc")
+	if err != nil {
+		metrics.ReportFuncError(c.svcTags)
+		return nil, err
+	}
+	return result.Txs, nil
 }
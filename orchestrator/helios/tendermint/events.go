@@ -0,0 +1,202 @@
+package tendermint
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	comettypes "github.com/cometbft/cometbft/types"
+	"github.com/pkg/errors"
+)
+
+// Kinds of wakeup raised by the Hyperion module events of a block.
+const (
+	WakeupValset        = "valset"
+	WakeupValsetConfirm = "valset_confirm"
+	WakeupBatch         = "batch"
+	WakeupBatchConfirm  = "batch_confirm"
+	WakeupSendToChain   = "send_to_chain"
+	WakeupExternalData  = "external_data"
+)
+
+// wakeupEventTypes maps the typed and legacy event types of the Hyperion module to a wakeup.
+var wakeupEventTypes = map[string]string{
+	"helios.hyperion.v1.EventValsetUpdateRequest": WakeupValset,
+	"multisig_update_request":                     WakeupValset,
+	"helios.hyperion.v1.EventValsetConfirm":       WakeupValsetConfirm,
+	"helios.hyperion.v1.EventOutgoingBatch":       WakeupBatch,
+	"outgoing_batch":                              WakeupBatch,
+	"helios.hyperion.v1.EventConfirmBatch":        WakeupBatchConfirm,
+	"helios.hyperion.v1.EventSendToChain":         WakeupSendToChain,
+}
+
+// Wakeup tells the loops of a Hyperion that something they act on happened on Helios.
+// HyperionId is zero when the event does not tell its Hyperion, every Hyperion is woken.
+type Wakeup struct {
+	Kind       string
+	HyperionId uint64
+}
+
+// NewBlockWakeups returns the wakeups raised by the events of the block and of its txs,
+// each wakeup once.
+func NewBlockWakeups(block comettypes.EventDataNewBlock) []Wakeup {
+	events := append([]abcitypes.Event{}, block.ResultFinalizeBlock.Events...)
+	for _, tx := range block.ResultFinalizeBlock.TxResults {
+		if tx != nil && tx.Code == abcitypes.CodeTypeOK {
+			events = append(events, tx.Events...)
+		}
+	}
+	return ParseWakeups(events)
+}
+
+// ParseWakeups returns the wakeups raised by the events, each wakeup once.
+func ParseWakeups(events []abcitypes.Event) []Wakeup {
+	wakeups := make([]Wakeup, 0)
+	seen := make(map[Wakeup]bool)
+	for _, event := range events {
+		kind, ok := wakeupEventTypes[event.Type]
+		if !ok && strings.Contains(strings.ToLower(strings.ReplaceAll(event.Type, "_", "")), "externaldata") {
+			kind, ok = WakeupExternalData, true
+		}
+		if !ok {
+			continue
+		}
+
+		wakeup := Wakeup{Kind: kind}
+		for _, attr := range event.Attributes {
+			if attr.Key != "hyperion_id" {
+				continue
+			}
+			// typed events hold JSON values, a uint64 is a quoted string
+			if id, err := strconv.ParseUint(strings.Trim(attr.Value, `"`), 10, 64); err == nil {
+				wakeup.HyperionId = id
+			}
+		}
+		if !seen[wakeup] {
+			seen[wakeup] = true
+			wakeups = append(wakeups, wakeup)
+		}
+	}
+	return wakeups
+}
+
+// OutgoingBatch is the EventOutgoingBatch emitted when a batch is built.
+type OutgoingBatch struct {
+	HyperionId uint64
+	Denom      string
+	Nonce      uint64
+	Timeout    uint64
+	TxIds      []uint64
+}
+
+// OutgoingBatchQuery matches the txs building a batch holding the outgoing tx.
+func OutgoingBatchQuery(txId uint64) string {
+	return fmt.Sprintf(`helios.hyperion.v1.EventOutgoingBatch.batch_tx_ids CONTAINS '"%d"'`, txId)
+}
+
+// CancelSendToChainQuery matches the txs cancelling the outgoing tx.
+func CancelSendToChainQuery(txId uint64) string {
+	return fmt.Sprintf(`helios.hyperion.v1.EventCancelSendToChain.outgoing_tx_id='"%d"'`, txId)
+}
+
+// ParseOutgoingBatches returns the batches built by the events.
+func ParseOutgoingBatches(events []abcitypes.Event) []OutgoingBatch {
+	batches := make([]OutgoingBatch, 0)
+	for _, event := range events {
+		if event.Type != "helios.hyperion.v1.EventOutgoingBatch" {
+			continue
+		}
+		batch := OutgoingBatch{}
+		for _, attr := range event.Attributes {
+			switch attr.Key {
+			case "hyperion_id":
+				batch.HyperionId = attributeUint(attr.Value)
+			case "denom":
+				batch.Denom = strings.Trim(attr.Value, `"`)
+			case "batch_nonce":
+				batch.Nonce = attributeUint(attr.Value)
+			case "batch_timeout":
+				batch.Timeout = attributeUint(attr.Value)
+			case "batch_tx_ids":
+				for _, value := range strings.Split(strings.Trim(attr.Value, "[]"), ",") {
+					if id, err := strconv.ParseUint(strings.Trim(strings.TrimSpace(value), `"`), 10, 64); err == nil {
+						batch.TxIds = append(batch.TxIds, id)
+					}
+				}
+			}
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// ParseSendToChainTxIds returns the outgoing tx ids of the EventSendToChain of the Hyperion.
+func ParseSendToChainTxIds(events []abcitypes.Event, hyperionId uint64) []uint64 {
+	txIds := make([]uint64, 0)
+	for _, event := range events {
+		if event.Type != "helios.hyperion.v1.EventSendToChain" {
+			continue
+		}
+		var eventHyperionId, txId uint64
+		for _, attr := range event.Attributes {
+			switch attr.Key {
+			case "hyperion_id":
+				eventHyperionId = attributeUint(attr.Value)
+			case "outgoing_tx_id":
+				txId = attributeUint(attr.Value)
+			}
+		}
+		if eventHyperionId == hyperionId && txId != 0 {
+			txIds = append(txIds, txId)
+		}
+	}
+	return txIds
+}
+
+// attributeUint reads a uint64 attribute, zero when it is not one. Typed events hold JSON
+// values, a uint64 is a quoted string.
+func attributeUint(value string) uint64 {
+	n, _ := strconv.ParseUint(strings.Trim(value, `"`), 10, 64)
+	return n
+}
+
+// SubscribeNewBlocks follows the new blocks of the node over its websocket until the
+// context is done. It returns an error when the subscription fails or when no block was
+// received for stallTimeout, the caller subscribes again.
+func SubscribeNewBlocks(ctx context.Context, rpcNodeAddr string, stallTimeout time.Duration, fn func(block comettypes.EventDataNewBlock)) error {
+	client, err := rpchttp.New(rpcNodeAddr, "/websocket")
+	if err != nil {
+		return errors.Wrap(err, "failed to init tendermint websocket client")
+	}
+	if err := client.Start(); err != nil {
+		return errors.Wrap(err, "failed to start tendermint websocket client")
+	}
+	defer client.Stop() //nolint:errcheck
+
+	subscribeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	blocks, err := client.Subscribe(subscribeCtx, "hyperion", comettypes.EventQueryNewBlock.String(), 16)
+	cancel()
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to new blocks")
+	}
+
+	stall := time.NewTimer(stallTimeout)
+	defer stall.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-stall.C:
+			return errors.Errorf("no new block for %s", stallTimeout)
+		case event := <-blocks:
+			stall.Reset(stallTimeout)
+			if block, ok := event.Data.(comettypes.EventDataNewBlock); ok {
+				fn(block)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package tendermint
+
+import (
+	"testing"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWakeups(t *testing.T) {
+	events := []abcitypes.Event{
+		{Type: "helios.hyperion.v1.EventOutgoingBatch", Attributes: []abcitypes.EventAttribute{{Key: "hyperion_id", Value: `"11155111"`}, {Key: "batch_nonce", Value: `"7"`}}},
+		{Type: "helios.hyperion.v1.EventOutgoingBatch", Attributes: []abcitypes.EventAttribute{{Key: "hyperion_id", Value: `"11155111"`}, {Key: "batch_nonce", Value: `"8"`}}},
+		{Type: "multisig_update_request"},
+		{Type: "helios.hyperion.v1.EventConfirmBatch", Attributes: []abcitypes.EventAttribute{{Key: "hyperion_id", Value: `"97"`}}},
+		{Type: "helios.hyperion.v1.EventOutgoingExternalDataTx"},
+		{Type: "transfer"},
+	}
+
+	assert.Equal(t, []Wakeup{
+		{Kind: WakeupBatch, HyperionId: 11155111},
+		{Kind: WakeupValset},
+		{Kind: WakeupBatchConfirm, HyperionId: 97},
+		{Kind: WakeupExternalData},
+	}, ParseWakeups(events))
+}
+
+func TestParseTransferEvents(t *testing.T) {
+	events := []abcitypes.Event{
+		{Type: "helios.hyperion.v1.EventOutgoingBatch", Attributes: []abcitypes.EventAttribute{
+			{Key: "hyperion_id", Value: `"11155111"`}, {Key: "denom", Value: `"ahelios"`}, {Key: "batch_nonce", Value: `"7"`},
+			{Key: "batch_timeout", Value: `"500"`}, {Key: "batch_tx_ids", Value: `["3","12"]`},
+		}},
+		{Type: "helios.hyperion.v1.EventSendToChain", Attributes: []abcitypes.EventAttribute{{Key: "hyperion_id", Value: `"11155111"`}, {Key: "outgoing_tx_id", Value: `"12"`}}},
+		{Type: "helios.hyperion.v1.EventSendToChain", Attributes: []abcitypes.EventAttribute{{Key: "hyperion_id", Value: `"97"`}, {Key: "outgoing_tx_id", Value: `"13"`}}},
+	}
+
+	assert.Equal(t, []OutgoingBatch{{HyperionId: 11155111, Denom: "ahelios", Nonce: 7, Timeout: 500, TxIds: []uint64{3, 12}}}, ParseOutgoingBatches(events))
+	assert.Equal(t, []uint64{12}, ParseSendToChainTxIds(events, 11155111))
+}
@@ -13,7 +13,7 @@ import (
 func TestnetForceUpdateValset(
 	ctx context.Context,
 	hyperionID int,
-	heliosNetwork Network,
+	heliosNetwork *Network,
 	ethNetwork ethereum.Network,
 ) error {
 	// check if the helios hyperion is not synchronized
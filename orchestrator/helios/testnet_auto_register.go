@@ -9,7 +9,7 @@ import (
 func RegisterValidator(
 	ctx context.Context,
 	hyperionID int,
-	heliosNetwork Network,
+	heliosNetwork *Network,
 	ethKeyFromAddress ethcmn.Address,
 ) error {
 	err := heliosNetwork.SendSetOrchestratorAddresses(ctx, uint64(hyperionID), ethKeyFromAddress.String())
@@ -22,7 +22,7 @@ func RegisterValidator(
 func UnRegisterValidator(
 	ctx context.Context,
 	hyperionID int,
-	heliosNetwork Network,
+	heliosNetwork *Network,
 	ethKeyFromAddress ethcmn.Address,
 ) error {
 	err := heliosNetwork.SendUnSetOrchestratorAddresses(ctx, uint64(hyperionID), ethKeyFromAddress.String())
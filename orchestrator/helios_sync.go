@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxHeliosBlockAge is the age of the latest Helios block above which the node is
+	// considered to be syncing, even when it does not report catching up
+	maxHeliosBlockAge = 2 * time.Minute
+	// heliosSyncCheckInterval is how long a sync check is reused by the loops of a chain
+	heliosSyncCheckInterval = 10 * time.Second
+
+	// HeliosSyncingStatus is shown by the loops waiting for the Helios node to sync
+	HeliosSyncingStatus = "helios node syncing"
+)
+
+var ErrHeliosSyncing = errors.New(HeliosSyncingStatus)
+
+type heliosSyncGate struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// checkHeliosSynced returns ErrHeliosSyncing while the connected Helios node is catching
+// up or its latest block is too old, in which case its state must not be acted upon.
+func (s *Orchestrator) checkHeliosSynced(ctx context.Context) error {
+	s.heliosSync.mu.Lock()
+	defer s.heliosSync.mu.Unlock()
+
+	if time.Since(s.heliosSync.checkedAt) < heliosSyncCheckInterval {
+		return s.heliosSync.err
+	}
+
+	wasSyncing := errors.Is(s.heliosSync.err, ErrHeliosSyncing)
+	s.heliosSync.err = s.queryHeliosSynced(ctx)
+	s.heliosSync.checkedAt = time.Now()
+
+	switch syncing := errors.Is(s.heliosSync.err, ErrHeliosSyncing); {
+	case syncing:
+		s.setStatus(LoopError, HeliosSyncingStatus)
+		if !wasSyncing {
+			s.logger.WithError(s.heliosSync.err).Warningln("Helios node is syncing, pausing claims and signatures")
+		}
+	case wasSyncing:
+		if s.HyperionState.ErrorStatus == HeliosSyncingStatus {
+			s.setStatus(LoopError, "okay")
+		}
+		s.logger.Infoln("Helios node synced, resuming claims and signatures")
+	}
+
+	return s.heliosSync.err
+}
+
+func (s *Orchestrator) queryHeliosSynced(ctx context.Context) error {
+	heliosNetwork := s.global.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return errors.New("helios network not initialized")
+	}
+
+	ctx, cancelFn := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelFn()
+
+	syncInfo, err := heliosNetwork.GetSyncInfo(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get helios sync status")
+	}
+
+	if syncInfo.CatchingUp {
+		return errors.Wrapf(ErrHeliosSyncing, "catching up at height %d", syncInfo.LatestBlockHeight)
+	}
+	if age := time.Since(syncInfo.LatestBlockTime); age > maxHeliosBlockAge {
+		return errors.Wrapf(ErrHeliosSyncing, "latest block %d is %s old", syncInfo.LatestBlockHeight, age.Truncate(time.Second))
+	}
+
+	return nil
+}
+
+// heliosSyncing tells whether a loop must skip its iteration because the Helios node is
+// syncing. A failing sync check does not block the loops, their own queries will fail.
+func (s *Orchestrator) heliosSyncing(ctx context.Context, loop string) bool {
+	err := s.checkHeliosSynced(ctx)
+	if err == nil {
+		return false
+	}
+	if !errors.Is(err, ErrHeliosSyncing) {
+		s.logger.WithError(err).WithField("loop", loop).Debugln("failed to check the Helios sync status")
+		return false
+	}
+
+	s.logger.WithField("loop", loop).Debugln("Helios node syncing, skipping iteration")
+	return true
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+const (
+	defaultIndexerLoopDur = 15 * time.Second
+
+	// maxIndexerStepsPerLoop bounds the scans of an iteration while the index catches up
+	maxIndexerStepsPerLoop = 100
+)
+
+// openIndex loads the event index of the chain. The loops scan the RPCs as before while
+// the index is not open or behind the blocks they read.
+func (s *Orchestrator) openIndex() error {
+	path, err := indexer.DefaultPath(s.cfg.ChainId)
+	if err != nil {
+		return err
+	}
+	startHeight := uint64(0)
+	if s.cfg.ChainParams != nil {
+		startHeight = s.cfg.ChainParams.BridgeContractStartHeight
+	}
+	index, err := indexer.Open(path, s.cfg.ChainId, s.ethereum.GetHyperionContractAddress(), startHeight)
+	if err != nil {
+		return err
+	}
+	s.index = index
+	return nil
+}
+
+// GetIndex returns the event index of the chain, nil until the orchestrator runs.
+func (s *Orchestrator) GetIndex() *indexer.Index {
+	return s.index
+}
+
+func (s *Orchestrator) runIndexer(ctx context.Context) error {
+	if s.index == nil {
+		return errors.New("event index is not open")
+	}
+	s.logger.WithField("loop_duration", defaultIndexerLoopDur.String()).Debugln("starting Indexer...")
+
+	return loops.RunLoop(ctx, s.ethereum, defaultIndexerLoopDur, func() error {
+		blocksToSearch := uint64(2000)
+		// the oracle claims the blocks below its confirmation delay only, the index holds
+		// no block that may still be reorganized
+		confirmations := uint64(4)
+		if settings, err := storage.GetChainSettings(s.cfg.ChainId); err == nil {
+			if value, ok := settings["oracle_eth_default_blocks_to_search"].(float64); ok && value >= 10 {
+				blocksToSearch = uint64(value)
+			}
+			if value, ok := settings["oracle_block_confirmation_delay"].(float64); ok && value >= 0 {
+				confirmations = uint64(value)
+			}
+		}
+
+		for i := 0; i < maxIndexerStepsPerLoop; i++ {
+			caughtUp, err := s.index.Sync(ctx, s.ethereum, blocksToSearch, confirmations)
+			if err != nil {
+				s.logger.WithError(err).WithField("cursor", s.index.Cursor()).Warningln("failed to index Hyperion events")
+				return err
+			}
+			if caughtUp {
+				return nil
+			}
+		}
+		s.logger.WithField("cursor", s.index.Cursor()).Infoln("event index catching up")
+		return nil
+	})
+}
+
+// indexedEthEvents returns the events of the blocks from the index, false when the index
+// does not cover them yet.
+func (s *Orchestrator) indexedEthEvents(startBlock, endBlock uint64) ([]event, bool) {
+	if s.index == nil || !s.index.Covers(startBlock, endBlock) {
+		return nil, false
+	}
+
+	indexedEvents, err := s.index.Events(startBlock, endBlock, indexer.KindSendToHelios, indexer.KindTransactionBatchExecuted, indexer.KindValsetUpdated, indexer.KindERC20Deployed)
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to read indexed events, scanning the RPC")
+		return nil, false
+	}
+
+	events := make([]event, 0)
+	for _, indexed := range indexedEvents {
+		ev, err := toEthEvent(indexed)
+		if err != nil {
+			s.logger.WithError(err).WithField("tx_hash", indexed.TxHash).Warningln("failed to decode indexed event, scanning the RPC")
+			return nil, false
+		}
+		events = append(events, ev)
+	}
+	return events, true
+}
+
+// toEthEvent rebuilds the oracle event of an indexed event.
+func toEthEvent(indexed *indexer.Event) (event, error) {
+	switch indexed.Kind {
+	case indexer.KindSendToHelios:
+		e, err := indexed.SendToHelios()
+		if err != nil {
+			return nil, err
+		}
+		ev := deposit(*e)
+		return &ev, nil
+	case indexer.KindTransactionBatchExecuted:
+		e, err := indexed.TransactionBatchExecuted()
+		if err != nil {
+			return nil, err
+		}
+		ev := withdrawal(*e)
+		return &ev, nil
+	case indexer.KindValsetUpdated:
+		e, err := indexed.ValsetUpdated()
+		if err != nil {
+			return nil, err
+		}
+		ev := valsetUpdate(*e)
+		return &ev, nil
+	case indexer.KindERC20Deployed:
+		e, err := indexed.ERC20Deployed()
+		if err != nil {
+			return nil, err
+		}
+		ev := erc20Deployment(*e)
+		return &ev, nil
+	}
+	return nil, errors.Errorf("%s is not claimed on Helios", indexed.Kind)
+}
@@ -0,0 +1,722 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	cosmostypes "github.com/cosmos/cosmos-sdk/types"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	hyperionevents "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
+)
+
+// Kinds of the indexed events, named after the events of the Hyperion contract.
+const (
+	KindSendToHelios             = "SendToHeliosEvent"
+	KindTransactionBatchExecuted = "TransactionBatchExecutedEvent"
+	KindValsetUpdated            = "ValsetUpdatedEvent"
+	KindERC20Deployed            = "ERC20DeployedEvent"
+)
+
+// maxCheckpoints is the number of indexed block hashes kept to detect the reorgs, a reorg
+// deeper than the oldest checkpoint rewinds the index to it.
+const maxCheckpoints = 128
+
+// Chain is the counterparty chain read by the indexer, ethereum.Network implements it.
+type Chain interface {
+	GetHeaderByNumber(ctx context.Context, number *big.Int) (*gethtypes.Header, error)
+	GetHyperionLogs(ctx context.Context, startBlock, endBlock uint64) ([]gethtypes.Log, error)
+}
+
+// Event is a log of the Hyperion contract with the fields it is queried by.
+type Event struct {
+	Kind        string `json:"kind"`
+	EventNonce  uint64 `json:"event_nonce,omitempty"`
+	BlockNumber uint64 `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+	TxHash      string `json:"tx_hash"`
+	LogIndex    uint   `json:"log_index"`
+
+	TokenContract string `json:"token_contract,omitempty"`
+	// Addresses are the accounts of the event: sender and receiver of a deposit,
+	// validators of a valset
+	Addresses   []string `json:"addresses,omitempty"`
+	Amount      string   `json:"amount,omitempty"`
+	BatchNonce  uint64   `json:"batch_nonce,omitempty"`
+	ValsetNonce uint64   `json:"valset_nonce,omitempty"`
+
+	// Log is kept to rebuild the events of the contract bindings
+	Log gethtypes.Log `json:"log"`
+}
+
+// Checkpoint is the hash of an indexed block.
+type Checkpoint struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+type state struct {
+	ChainId  uint64 `json:"chain_id"`
+	Contract string `json:"contract"`
+	// StartHeight is the first block indexed, the deployment block of the contract
+	StartHeight uint64 `json:"start_height"`
+	// Cursor is the last block indexed
+	Cursor      uint64       `json:"cursor"`
+	Checkpoints []Checkpoint `json:"checkpoints"`
+	// Size is the length of the event log up to the cursor, the bytes past it were
+	// appended by a scan interrupted before its state was saved
+	Size int64 `json:"size"`
+}
+
+// entry locates an event in the event log with the fields the loops look it up by.
+type entry struct {
+	kind        string
+	eventNonce  uint64
+	valsetNonce uint64
+	blockNumber uint64
+	offset      int64
+	length      int
+}
+
+// Index holds the events of the Hyperion contract of a chain, persisted in the local store.
+// The events are appended to a log, one JSON line each, and only their entries are kept
+// in memory; the state file holds the cursor and the checkpoints.
+type Index struct {
+	dir string
+
+	mu      sync.RWMutex
+	state   state
+	entries []entry
+}
+
+const (
+	stateFile  = "state.json"
+	eventsFile = "events.jsonl"
+)
+
+// DefaultPath returns ~/.heliades/hyperion/index/<chain_id>.
+func DefaultPath(chainId uint64) (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".heliades", "hyperion", "index", fmt.Sprint(chainId)), nil
+}
+
+// Open loads the index in dir. The index is started over from startHeight when it was
+// built for another contract, after a migration, or its event log was lost.
+func Open(dir string, chainId uint64, contract gethcommon.Address, startHeight uint64) (*Index, error) {
+	ix := &Index{dir: dir, entries: []entry{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to read event index")
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &ix.state); err != nil {
+			return nil, errors.Wrap(err, "failed to decode event index")
+		}
+	}
+
+	if strings.EqualFold(ix.state.Contract, contract.Hex()) && ix.state.ChainId == chainId {
+		if err := ix.resize(ix.state.Size); err != nil {
+			return nil, errors.Wrap(err, "failed to restore event index")
+		}
+		if err := ix.load(); err != nil {
+			return nil, errors.Wrap(err, "failed to load event index")
+		}
+		if appendedSize(ix.entries) == ix.state.Size {
+			return ix, nil
+		}
+	}
+
+	ix.state = state{
+		ChainId:     chainId,
+		Contract:    contract.Hex(),
+		StartHeight: startHeight,
+		Cursor:      below(startHeight),
+		Checkpoints: []Checkpoint{},
+	}
+	ix.entries = []entry{}
+	if err := ix.save(); err != nil {
+		return nil, errors.Wrap(err, "failed to save event index")
+	}
+	if err := ix.resize(0); err != nil {
+		return nil, errors.Wrap(err, "failed to reset event index")
+	}
+	return ix, nil
+}
+
+// load reads the entries of the event log.
+func (ix *Index) load() error {
+	file, err := os.Open(ix.logPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		event := &Event{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return errors.Wrapf(err, "failed to decode event at offset %d", offset)
+		}
+		ix.entries = append(ix.entries, newEntry(event, offset, len(line)))
+		offset += int64(len(line))
+	}
+}
+
+func newEntry(event *Event, offset int64, length int) entry {
+	return entry{
+		kind:        event.Kind,
+		eventNonce:  event.EventNonce,
+		valsetNonce: event.ValsetNonce,
+		blockNumber: event.BlockNumber,
+		offset:      offset,
+		length:      length,
+	}
+}
+
+// Cursor is the last block indexed.
+func (ix *Index) Cursor() uint64 {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.state.Cursor
+}
+
+// Covers tells whether every block of the range is indexed.
+func (ix *Index) Covers(startBlock, endBlock uint64) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return startBlock >= ix.state.StartHeight && endBlock <= ix.state.Cursor
+}
+
+// Sync rewinds the index past a reorg then indexes at most maxBlocks blocks, up to
+// confirmations blocks below the head so that the index only holds blocks the oracle may
+// claim. It returns true once the index reached the confirmed head of the chain.
+func (ix *Index) Sync(ctx context.Context, chain Chain, maxBlocks uint64, confirmations uint64) (bool, error) {
+	if err := ix.rewind(ctx, chain); err != nil {
+		return false, err
+	}
+
+	head, err := chain.GetHeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get latest ethereum header")
+	}
+	if head.Number.Uint64() <= confirmations {
+		return true, nil
+	}
+	latest := head.Number.Uint64() - confirmations
+
+	cursor := ix.Cursor()
+	if cursor >= latest {
+		return true, nil
+	}
+	endBlock := latest
+	if maxBlocks > 0 && endBlock > cursor+maxBlocks {
+		endBlock = cursor + maxBlocks
+	}
+
+	// the end header is read around the scan, the logs of a fork replaced meanwhile would
+	// be checkpointed under the hash of the new one and never rewound
+	endHeader, err := chain.GetHeaderByNumber(ctx, new(big.Int).SetUint64(endBlock))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get ethereum header")
+	}
+	logs, err := chain.GetHyperionLogs(ctx, cursor+1, endBlock)
+	if err != nil {
+		return false, err
+	}
+	events := make([]*Event, 0, len(logs))
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		if log.BlockNumber == endBlock && log.BlockHash != endHeader.Hash() {
+			return false, errors.Errorf("block %d was reorganized during the scan", endBlock)
+		}
+		events = append(events, decode(log))
+	}
+	scannedHeader, err := chain.GetHeaderByNumber(ctx, new(big.Int).SetUint64(endBlock))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get ethereum header")
+	}
+	if scannedHeader.Hash() != endHeader.Hash() {
+		return false, errors.Errorf("block %d was reorganized during the scan", endBlock)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ix.state.Cursor != cursor {
+		// rewound or synced meanwhile, the scan is dropped
+		return false, nil
+	}
+	entries, err := ix.append(events)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to append indexed events")
+	}
+	ix.entries = append(ix.entries, entries...)
+	ix.state.Size += appendedSize(entries)
+	ix.state.Cursor = endBlock
+	ix.state.Checkpoints = append(ix.state.Checkpoints, Checkpoint{Height: endBlock, Hash: endHeader.Hash().Hex()})
+	if len(ix.state.Checkpoints) > maxCheckpoints {
+		ix.state.Checkpoints = ix.state.Checkpoints[len(ix.state.Checkpoints)-maxCheckpoints:]
+	}
+	return endBlock == latest, ix.save()
+}
+
+// append writes the events at the end of the event log and returns their entries.
+func (ix *Index) append(events []*Event) ([]entry, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	entries := make([]entry, 0, len(events))
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, '\n')
+		entries = append(entries, newEntry(event, ix.state.Size+int64(buf.Len()), len(line)))
+		buf.Write(line)
+	}
+
+	file, err := os.OpenFile(ix.logPath(), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.WriteAt(buf.Bytes(), ix.state.Size); err != nil {
+		return nil, err
+	}
+	return entries, file.Sync()
+}
+
+// appendedSize is the length of the entries in the event log.
+func appendedSize(entries []entry) int64 {
+	size := int64(0)
+	for _, e := range entries {
+		size += int64(e.length)
+	}
+	return size
+}
+
+// rewind drops the blocks above the last checkpoint still on the chain.
+func (ix *Index) rewind(ctx context.Context, chain Chain) error {
+	ix.mu.RLock()
+	checkpoints := append([]Checkpoint{}, ix.state.Checkpoints...)
+	ix.mu.RUnlock()
+
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		checkpoint := checkpoints[i]
+		header, err := chain.GetHeaderByNumber(ctx, new(big.Int).SetUint64(checkpoint.Height))
+		if err != nil {
+			return errors.Wrap(err, "failed to get ethereum header")
+		}
+		if header.Hash().Hex() == checkpoint.Hash {
+			if i == len(checkpoints)-1 {
+				return nil
+			}
+			return ix.truncate(checkpoint.Height, i+1)
+		}
+	}
+	if len(checkpoints) == 0 {
+		return nil
+	}
+
+	// deeper than the checkpoints, start over below the oldest one
+	height := checkpoints[0].Height
+	if height > maxCheckpoints {
+		height -= maxCheckpoints
+	} else {
+		height = 0
+	}
+	return ix.truncate(height, 0)
+}
+
+// truncate drops the events above height and the checkpoints from index keep.
+func (ix *Index) truncate(height uint64, keep int) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if height < ix.state.StartHeight {
+		height = below(ix.state.StartHeight)
+	}
+	// the events are appended in block order
+	n := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].blockNumber > height })
+	size := ix.state.Size
+	if n < len(ix.entries) {
+		size = ix.entries[n].offset
+	}
+
+	ix.entries = ix.entries[:n]
+	ix.state.Size = size
+	ix.state.Cursor = height
+	ix.state.Checkpoints = ix.state.Checkpoints[:keep]
+	// the state is saved first, a log longer than its size is cut on open
+	if err := ix.save(); err != nil {
+		return err
+	}
+	return ix.resize(size)
+}
+
+// resize cuts the event log to size, a shorter log is left as is.
+func (ix *Index) resize(size int64) error {
+	info, err := os.Stat(ix.logPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() <= size {
+		return nil
+	}
+	return os.Truncate(ix.logPath(), size)
+}
+
+// save writes the state file, the events are already in the log.
+func (ix *Index) save() error {
+	if err := os.MkdirAll(ix.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ix.state)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(ix.dir, stateFile)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (ix *Index) logPath() string {
+	return filepath.Join(ix.dir, eventsFile)
+}
+
+// read loads the events of the entries from the event log.
+func (ix *Index) read(entries []entry) ([]*Event, error) {
+	events := make([]*Event, 0, len(entries))
+	if len(entries) == 0 {
+		return events, nil
+	}
+
+	file, err := os.Open(ix.logPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open event log")
+	}
+	defer file.Close()
+
+	for _, e := range entries {
+		line := make([]byte, e.length)
+		if _, err := file.ReadAt(line, e.offset); err != nil {
+			return nil, errors.Wrapf(err, "failed to read event at offset %d", e.offset)
+		}
+		event := &Event{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode event at offset %d", e.offset)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Query selects indexed events, every field is optional.
+type Query struct {
+	Kind      string
+	Address   string
+	Token     string
+	Nonce     uint64
+	FromBlock uint64
+	ToBlock   uint64
+	// Page starts at 1, the newest events come first
+	Page int
+	Size int
+}
+
+// Page is a page of the events matching a query, Total counts every match.
+type Page struct {
+	Events []*Event `json:"events"`
+	Total  int      `json:"total"`
+	Cursor uint64   `json:"cursor"`
+}
+
+// Query returns a page of the events matching q.
+func (ix *Index) Query(q Query) (Page, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	candidates := make([]entry, 0)
+	for i := len(ix.entries) - 1; i >= 0; i-- {
+		if e := ix.entries[i]; q.matchesEntry(e) {
+			candidates = append(candidates, e)
+		}
+	}
+	matches := make([]*Event, 0)
+	if q.Token != "" || q.Address != "" {
+		// the token and the addresses are only in the log
+		events, err := ix.read(candidates)
+		if err != nil {
+			return Page{}, err
+		}
+		for _, event := range events {
+			if q.matches(event) {
+				matches = append(matches, event)
+			}
+		}
+		candidates = nil
+	}
+
+	total := len(matches) + len(candidates)
+	page := Page{Events: []*Event{}, Total: total, Cursor: ix.state.Cursor}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Size <= 0 {
+		q.Size = 50
+	}
+	start := (q.Page - 1) * q.Size
+	if start >= total {
+		return page, nil
+	}
+	end := start + q.Size
+	if end > total {
+		end = total
+	}
+	if len(candidates) == 0 {
+		page.Events = matches[start:end]
+		return page, nil
+	}
+	events, err := ix.read(candidates[start:end])
+	if err != nil {
+		return Page{}, err
+	}
+	page.Events = events
+	return page, nil
+}
+
+func (q Query) matchesEntry(e entry) bool {
+	if q.Kind != "" && e.kind != q.Kind {
+		return false
+	}
+	if q.Nonce != 0 && e.eventNonce != q.Nonce {
+		return false
+	}
+	if q.FromBlock != 0 && e.blockNumber < q.FromBlock {
+		return false
+	}
+	if q.ToBlock != 0 && e.blockNumber > q.ToBlock {
+		return false
+	}
+	return true
+}
+
+func (q Query) matches(event *Event) bool {
+	if q.Token != "" && !strings.EqualFold(event.TokenContract, q.Token) {
+		return false
+	}
+	if q.Address != "" {
+		found := false
+		for _, address := range event.Addresses {
+			if strings.EqualFold(address, q.Address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Events returns the events of the kinds in the blocks, oldest first. No kind returns them all.
+func (ix *Index) Events(startBlock, endBlock uint64, kinds ...string) ([]*Event, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// the entries are in block and log order
+	first := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].blockNumber >= startBlock })
+	selected := make([]entry, 0)
+	for _, e := range ix.entries[first:] {
+		if e.blockNumber > endBlock {
+			break
+		}
+		if len(kinds) > 0 && !contains(kinds, e.kind) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	return ix.read(selected)
+}
+
+// ByNonce returns the event of the event nonce, nil when it is not indexed.
+func (ix *Index) ByNonce(nonce uint64) (*Event, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	for _, e := range ix.entries {
+		if e.eventNonce == nonce && isClaimed(e.kind) {
+			return ix.readOne(e)
+		}
+	}
+	return nil, nil
+}
+
+// LatestValsetUpdated returns the ValsetUpdated event of the highest valset nonce, nil when
+// none is indexed.
+func (ix *Index) LatestValsetUpdated() (*Event, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var latest *entry
+	for i, e := range ix.entries {
+		if e.kind == KindValsetUpdated && (latest == nil || e.valsetNonce > latest.valsetNonce) {
+			latest = &ix.entries[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return ix.readOne(*latest)
+}
+
+func (ix *Index) readOne(e entry) (*Event, error) {
+	events, err := ix.read([]entry{e})
+	if err != nil {
+		return nil, err
+	}
+	return events[0], nil
+}
+
+// below is the block before height, the cursor of an index starting at height.
+func below(height uint64) uint64 {
+	if height == 0 {
+		return 0
+	}
+	return height - 1
+}
+
+func contains(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// isClaimed tells whether the events of the kind carry an event nonce claimed on Helios.
+func isClaimed(kind string) bool {
+	switch kind {
+	case KindSendToHelios, KindTransactionBatchExecuted, KindValsetUpdated, KindERC20Deployed:
+		return true
+	}
+	return false
+}
+
+var filterer, _ = hyperionevents.NewHyperionFilterer(gethcommon.Address{}, nil)
+
+// decode reads the fields of the log, the logs of events unknown to the bindings are
+// indexed with their raw log only.
+func decode(log gethtypes.Log) *Event {
+	event := &Event{
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		TxHash:      log.TxHash.Hex(),
+		LogIndex:    log.Index,
+		Log:         log,
+	}
+
+	if deposit, err := filterer.ParseSendToHeliosEvent(log); err == nil {
+		event.Kind = KindSendToHelios
+		event.EventNonce = deposit.EventNonce.Uint64()
+		event.TokenContract = deposit.TokenContract.Hex()
+		event.Amount = deposit.Amount.String()
+		receiver := deposit.Destination[12:32]
+		event.Addresses = []string{
+			deposit.Sender.Hex(),
+			gethcommon.BytesToAddress(receiver).Hex(),
+			cosmostypes.AccAddress(receiver).String(),
+		}
+	} else if withdrawal, err := filterer.ParseTransactionBatchExecutedEvent(log); err == nil {
+		event.Kind = KindTransactionBatchExecuted
+		event.EventNonce = withdrawal.EventNonce.Uint64()
+		event.BatchNonce = withdrawal.BatchNonce.Uint64()
+		event.TokenContract = withdrawal.Token.Hex()
+	} else if valset, err := filterer.ParseValsetUpdatedEvent(log); err == nil {
+		event.Kind = KindValsetUpdated
+		event.EventNonce = valset.EventNonce.Uint64()
+		event.ValsetNonce = valset.NewValsetNonce.Uint64()
+		event.TokenContract = valset.RewardToken.Hex()
+		event.Amount = valset.RewardAmount.String()
+		for _, validator := range valset.Validators {
+			event.Addresses = append(event.Addresses, validator.Hex())
+		}
+	} else if erc20, err := filterer.ParseERC20DeployedEvent(log); err == nil {
+		event.Kind = KindERC20Deployed
+		event.EventNonce = erc20.EventNonce.Uint64()
+		event.TokenContract = erc20.TokenContract.Hex()
+	} else if len(log.Topics) > 0 {
+		event.Kind = eventName(log.Topics[0])
+	}
+	return event
+}
+
+func eventName(topic gethcommon.Hash) string {
+	contractAbi, err := hyperionevents.HyperionMetaData.GetAbi()
+	if err != nil {
+		return topic.Hex()
+	}
+	if abiEvent, err := contractAbi.EventByID(topic); err == nil {
+		return abiEvent.Name
+	}
+	return topic.Hex()
+}
+
+// SendToHelios rebuilds the binding of a SendToHelios event.
+func (e *Event) SendToHelios() (*hyperionevents.HyperionSendToHeliosEvent, error) {
+	return filterer.ParseSendToHeliosEvent(e.Log)
+}
+
+// TransactionBatchExecuted rebuilds the binding of a TransactionBatchExecuted event.
+func (e *Event) TransactionBatchExecuted() (*hyperionevents.HyperionTransactionBatchExecutedEvent, error) {
+	return filterer.ParseTransactionBatchExecutedEvent(e.Log)
+}
+
+// ValsetUpdated rebuilds the binding of a ValsetUpdated event.
+func (e *Event) ValsetUpdated() (*hyperionevents.HyperionValsetUpdatedEvent, error) {
+	return filterer.ParseValsetUpdatedEvent(e.Log)
+}
+
+// ERC20Deployed rebuilds the binding of an ERC20Deployed event.
+func (e *Event) ERC20Deployed() (*hyperionevents.HyperionERC20DeployedEvent, error) {
+	return filterer.ParseERC20DeployedEvent(e.Log)
+}
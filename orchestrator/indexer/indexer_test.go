@@ -0,0 +1,229 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hyperionevents "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
+)
+
+var (
+	contract = gethcommon.HexToAddress("0x1000000000000000000000000000000000000001")
+	token    = gethcommon.HexToAddress("0x2000000000000000000000000000000000000002")
+	sender   = gethcommon.HexToAddress("0x3000000000000000000000000000000000000003")
+)
+
+type fakeChain struct {
+	head uint64
+	fork string
+	logs []gethtypes.Log
+	// forkOnScan replaces the fork while the logs are read
+	forkOnScan string
+}
+
+func (c *fakeChain) GetHeaderByNumber(_ context.Context, number *big.Int) (*gethtypes.Header, error) {
+	height := c.head
+	if number != nil {
+		height = number.Uint64()
+	}
+	return &gethtypes.Header{Number: new(big.Int).SetUint64(height), Extra: []byte(c.fork)}, nil
+}
+
+func (c *fakeChain) GetHyperionLogs(_ context.Context, startBlock, endBlock uint64) ([]gethtypes.Log, error) {
+	if c.forkOnScan != "" {
+		c.fork, c.forkOnScan = c.forkOnScan, ""
+	}
+	logs := make([]gethtypes.Log, 0)
+	for _, log := range c.logs {
+		if log.BlockNumber >= startBlock && log.BlockNumber <= endBlock {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func depositLog(t *testing.T, height, nonce uint64) gethtypes.Log {
+	contractAbi, err := hyperionevents.HyperionMetaData.GetAbi()
+	require.NoError(t, err)
+	abiEvent := contractAbi.Events[KindSendToHelios]
+	data, err := abiEvent.Inputs.NonIndexed().Pack(big.NewInt(1000), new(big.Int).SetUint64(nonce), "")
+	require.NoError(t, err)
+	return gethtypes.Log{
+		Address:     contract,
+		Topics:      []gethcommon.Hash{abiEvent.ID, gethcommon.BytesToHash(token.Bytes()), gethcommon.BytesToHash(sender.Bytes()), gethcommon.BytesToHash(sender.Bytes())},
+		Data:        data,
+		BlockNumber: height,
+		TxHash:      gethcommon.BigToHash(new(big.Int).SetUint64(nonce)),
+	}
+}
+
+func valsetLog(t *testing.T, height, nonce, valsetNonce uint64) gethtypes.Log {
+	contractAbi, err := hyperionevents.HyperionMetaData.GetAbi()
+	require.NoError(t, err)
+	abiEvent := contractAbi.Events[KindValsetUpdated]
+	data, err := abiEvent.Inputs.NonIndexed().Pack(new(big.Int).SetUint64(nonce), big.NewInt(0), token, []gethcommon.Address{sender}, []*big.Int{big.NewInt(100)})
+	require.NoError(t, err)
+	return gethtypes.Log{
+		Address:     contract,
+		Topics:      []gethcommon.Hash{abiEvent.ID, gethcommon.BigToHash(new(big.Int).SetUint64(valsetNonce))},
+		Data:        data,
+		BlockNumber: height,
+		TxHash:      gethcommon.BigToHash(new(big.Int).SetUint64(nonce)),
+	}
+}
+
+func TestIndexSync(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index")
+	chain := &fakeChain{head: 100, logs: []gethtypes.Log{depositLog(t, 20, 1), valsetLog(t, 40, 2, 5), depositLog(t, 80, 3)}}
+
+	index, err := Open(path, 1, contract, 10)
+	require.NoError(t, err)
+	assert.False(t, index.Covers(10, 10))
+
+	// catching up in steps of 50 blocks
+	caughtUp, err := index.Sync(ctx, chain, 50, 0)
+	require.NoError(t, err)
+	assert.False(t, caughtUp)
+	assert.Equal(t, uint64(59), index.Cursor())
+	caughtUp, err = index.Sync(ctx, chain, 50, 0)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+	assert.True(t, index.Covers(10, 100))
+	assert.False(t, index.Covers(10, 101))
+
+	// reloaded from the local store
+	index, err = Open(path, 1, contract, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), index.Cursor())
+	events, err := index.Events(10, 100)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	valset, err := index.ByNonce(2)
+	require.NoError(t, err)
+	require.NotNil(t, valset)
+	assert.Equal(t, KindValsetUpdated, valset.Kind)
+	latest, err := index.LatestValsetUpdated()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), latest.ValsetNonce)
+	valsetEvent, err := valset.ValsetUpdated()
+	require.NoError(t, err)
+	assert.Equal(t, []gethcommon.Address{sender}, valsetEvent.Validators)
+
+	indexedDeposit, err := index.ByNonce(3)
+	require.NoError(t, err)
+	deposit, err := indexedDeposit.SendToHelios()
+	require.NoError(t, err)
+	assert.Equal(t, token, deposit.TokenContract)
+
+	// the deposit block is reorged out
+	chain.fork = "b"
+	chain.logs = chain.logs[:2]
+	caughtUp, err = index.Sync(ctx, chain, 50, 0)
+	require.NoError(t, err)
+	assert.False(t, caughtUp)
+	assert.Equal(t, uint64(59), index.Cursor())
+	events, err = index.Events(10, 100)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// a scan interrupted before its state was saved is dropped on open
+	logPath := filepath.Join(path, eventsFile)
+	before, err := os.Stat(logPath)
+	require.NoError(t, err)
+	file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"kind":"SendToHeliosEvent","block_number":70}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	index, err = Open(path, 1, contract, 10)
+	require.NoError(t, err)
+	events, err = index.Events(10, 100)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+	caughtUp, err = index.Sync(ctx, chain, 50, 0)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+	events, err = index.Events(60, 100)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	after, err := os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, before.Size(), after.Size())
+
+	// another contract starts over
+	index, err = Open(path, 1, token, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), index.Cursor())
+	events, err = index.Events(0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestIndexQuery(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{head: 100, logs: []gethtypes.Log{depositLog(t, 20, 1), valsetLog(t, 40, 2, 5), depositLog(t, 80, 3)}}
+
+	index, err := Open(filepath.Join(t.TempDir(), "index"), 1, contract, 0)
+	require.NoError(t, err)
+	_, err = index.Sync(ctx, chain, 0, 0)
+	require.NoError(t, err)
+
+	// newest first
+	page, err := index.Query(Query{Address: sender.Hex(), Size: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, uint64(3), page.Events[0].EventNonce)
+
+	page, err = index.Query(Query{Kind: KindSendToHelios, Token: token.Hex(), Page: 2, Size: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, uint64(1), page.Events[0].EventNonce)
+
+	page, err = index.Query(Query{Nonce: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, KindValsetUpdated, page.Events[0].Kind)
+
+	page, err = index.Query(Query{FromBlock: 30, ToBlock: 50})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+
+	page, err = index.Query(Query{Page: 3, Size: 2})
+	require.NoError(t, err)
+	assert.Empty(t, page.Events)
+}
+
+func TestIndexSyncConfirmedBlocks(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{head: 100, logs: []gethtypes.Log{depositLog(t, 20, 1), depositLog(t, 98, 2)}}
+
+	index, err := Open(filepath.Join(t.TempDir(), "index"), 1, contract, 0)
+	require.NoError(t, err)
+
+	// the fork changes while the logs are read, the scan is dropped
+	chain.forkOnScan = "b"
+	_, err = index.Sync(ctx, chain, 0, 4)
+	require.Error(t, err)
+	assert.Equal(t, uint64(0), index.Cursor())
+
+	caughtUp, err := index.Sync(ctx, chain, 0, 4)
+	require.NoError(t, err)
+	assert.True(t, caughtUp)
+	assert.Equal(t, uint64(96), index.Cursor())
+	assert.False(t, index.Covers(0, 98))
+	events, err := index.Events(0, 100)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -10,12 +10,14 @@ import (
 	"syscall"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	terminal "golang.org/x/term"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/keystore"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/remotesigner"
 )
 
 var emptyEthAddress = ethcmn.Address{}
@@ -82,6 +84,60 @@ func InitEthereumAccountsManagerWithPrivateKey(
 	return ethAddressFromPk, txOpts.Signer, personalSignFn, nil
 }
 
+func InitEthereumAccountsManagerWithRemoteSigner(
+	signer *remotesigner.Web3Signer,
+	ethChainID uint64,
+) (
+	ethKeyFromAddress ethcmn.Address,
+	signerFn bind.SignerFn,
+	personalSignFn keystore.PersonalSignFn,
+	err error,
+) {
+	if signer == nil {
+		err = errors.New("remote signer not initialized")
+		return emptyEthAddress, nil, nil, err
+	}
+
+	return signer.Address(), signer.SignerFn(ethChainID), signer.PersonalSignFn(), nil
+}
+
+func InitEthereumAccountsManagerWithKeystoreFile(
+	keystoreFile string,
+	ethPassphrase string,
+	ethChainID uint64,
+) (
+	ethKeyFromAddress ethcmn.Address,
+	signerFn bind.SignerFn,
+	personalSignFn keystore.PersonalSignFn,
+	err error,
+) {
+	keyJSON, err := os.ReadFile(keystoreFile)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read keystore file")
+		return emptyEthAddress, nil, nil, err
+	}
+
+	key, err := gethkeystore.DecryptKey(keyJSON, ethPassphrase)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to decrypt keystore file %s", keystoreFile)
+		return emptyEthAddress, nil, nil, err
+	}
+
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key.PrivateKey, new(big.Int).SetUint64(ethChainID))
+	if err != nil {
+		err = errors.New("failed to init NewKeyedTransactorWithChainID")
+		return emptyEthAddress, nil, nil, err
+	}
+
+	personalSignFn, err = keystore.PrivateKeyPersonalSignFn(key.PrivateKey)
+	if err != nil {
+		err = errors.New("failed to init PrivateKeyPersonalSignFn")
+		return emptyEthAddress, nil, nil, err
+	}
+
+	return key.Address, txOpts.Signer, personalSignFn, nil
+}
+
 func InitEthereumAccountsManager(
 	ethChainID uint64,
 	ethKeystoreDir *string,
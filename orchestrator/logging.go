@@ -0,0 +1,20 @@
+package orchestrator
+
+import (
+	"strings"
+
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
+)
+
+// logEnabled tells whether the verbose logs of the loop are written: for the loops listed
+// in Config.EnabledLogs, and for the loops whose level was raised to debug at runtime.
+func (s *Orchestrator) logEnabled(loop string) bool {
+	for _, enabled := range strings.Split(s.cfg.EnabledLogs, ",") {
+		if strings.TrimSpace(enabled) == loop {
+			return true
+		}
+	}
+	return logs.Enabled(s.cfg.ChainId, loop, log.DebugLevel)
+}
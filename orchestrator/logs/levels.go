@@ -0,0 +1,126 @@
+package logs
+
+import (
+	"sort"
+	"sync"
+
+	log "github.com/xlab/suplog"
+)
+
+// LevelOverride is a log level set at runtime for a chain, a loop or a loop of a chain.
+// A zero ChainId matches every chain and an empty Loop matches every loop.
+type LevelOverride struct {
+	ChainId uint64 `json:"chain_id,omitempty"`
+	Loop    string `json:"loop,omitempty"`
+	Level   string `json:"level"`
+}
+
+type levelKey struct {
+	chainId uint64
+	loop    string
+}
+
+// Levels holds the default log level and the levels overridden per chain and loop.
+type Levels struct {
+	mu           sync.RWMutex
+	defaultLevel log.Level
+	overrides    map[levelKey]log.Level
+	onChange     func(max log.Level)
+}
+
+func NewLevels(defaultLevel log.Level) *Levels {
+	return &Levels{
+		defaultLevel: defaultLevel,
+		overrides:    make(map[levelKey]log.Level),
+	}
+}
+
+// Level returns the level of the loop of the chain, the most specific override wins:
+// chain and loop, then chain, then loop, then the default level.
+func (l *Levels) Level(chainId uint64, loop string) log.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, key := range []levelKey{{chainId, loop}, {chainId, ""}, {0, loop}} {
+		if key == (levelKey{}) {
+			continue
+		}
+		if level, ok := l.overrides[key]; ok {
+			return level
+		}
+	}
+	return l.defaultLevel
+}
+
+// Enabled tells whether an entry of the level is written for the loop of the chain.
+func (l *Levels) Enabled(chainId uint64, loop string, level log.Level) bool {
+	return level <= l.Level(chainId, loop)
+}
+
+func (l *Levels) Default() log.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.defaultLevel
+}
+
+func (l *Levels) SetDefault(level log.Level) {
+	l.mu.Lock()
+	l.defaultLevel = level
+	l.mu.Unlock()
+	l.changed()
+}
+
+// Set overrides the level of the loop of the chain.
+func (l *Levels) Set(chainId uint64, loop string, level log.Level) {
+	l.mu.Lock()
+	l.overrides[levelKey{chainId, loop}] = level
+	l.mu.Unlock()
+	l.changed()
+}
+
+// Reset removes the override of the loop of the chain, it falls back to a less specific one.
+func (l *Levels) Reset(chainId uint64, loop string) {
+	l.mu.Lock()
+	delete(l.overrides, levelKey{chainId, loop})
+	l.mu.Unlock()
+	l.changed()
+}
+
+// Overrides returns the levels overridden, sorted by chain and loop.
+func (l *Levels) Overrides() []LevelOverride {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	overrides := make([]LevelOverride, 0, len(l.overrides))
+	for key, level := range l.overrides {
+		overrides = append(overrides, LevelOverride{ChainId: key.chainId, Loop: key.loop, Level: level.String()})
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].ChainId != overrides[j].ChainId {
+			return overrides[i].ChainId < overrides[j].ChainId
+		}
+		return overrides[i].Loop < overrides[j].Loop
+	})
+	return overrides
+}
+
+// Max returns the most verbose level in use, the logger drops the entries above it before
+// they are built.
+func (l *Levels) Max() log.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	max := l.defaultLevel
+	for _, level := range l.overrides {
+		if level > max {
+			max = level
+		}
+	}
+	return max
+}
+
+func (l *Levels) changed() {
+	if l.onChange != nil {
+		l.onChange(l.Max())
+	}
+}
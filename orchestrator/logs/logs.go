@@ -0,0 +1,142 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	log "github.com/xlab/suplog"
+)
+
+// Fields of the structured log lines.
+const (
+	FieldChain   = "chain"
+	FieldChainId = "chain_id"
+	FieldLoop    = "loop"
+	FieldNonce   = "nonce"
+	FieldTxHash  = "tx_hash"
+)
+
+// Formats of the log lines written to the console, the store always holds JSON.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config of the process logs.
+type Config struct {
+	Level  log.Level
+	Format string
+	// Dir of the log store, empty disables it
+	Dir      string
+	MaxSize  int64
+	MaxFiles int
+}
+
+var (
+	levels = NewLevels(log.InfoLevel)
+	store  *Store
+)
+
+// DefaultDir returns ~/.heliades/hyperion/logs.
+func DefaultDir() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".heliades", "hyperion", "logs"), nil
+}
+
+// Setup writes the logs of the default logger as configured, filtered by the levels of
+// their chain and loop and copied to the log store.
+func Setup(cfg Config) error {
+	var console log.Formatter = jsonFormatter()
+	if cfg.Format == FormatText {
+		console = new(log.TextFormatter)
+	} else if cfg.Format != "" && cfg.Format != FormatJSON {
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	if cfg.Dir != "" {
+		s, err := OpenStore(cfg.Dir, cfg.MaxSize, cfg.MaxFiles)
+		if err != nil {
+			return err
+		}
+		store = s
+	}
+
+	levels.SetDefault(cfg.Level)
+	levels.onChange = log.DefaultLogger.SetLevel
+	log.DefaultLogger.SetFormatter(&formatter{console: console, store: store})
+	log.DefaultLogger.SetLevel(levels.Max())
+	return nil
+}
+
+// GetLevels returns the log levels of the process, they can be changed at runtime.
+func GetLevels() *Levels {
+	return levels
+}
+
+// Enabled tells whether an entry of the level is written for the loop of the chain.
+func Enabled(chainId uint64, loop string, level log.Level) bool {
+	return levels.Enabled(chainId, loop, level)
+}
+
+// GetStore returns the log store, nil when it is disabled.
+func GetStore() *Store {
+	return store
+}
+
+func jsonFormatter() *log.JSONFormatter {
+	return &log.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+}
+
+// formatter drops the entries above the level of their chain and loop, and copies the
+// others to the store.
+type formatter struct {
+	console log.Formatter
+	store   *Store
+	json    log.JSONFormatter
+}
+
+func (f *formatter) Format(entry *log.Entry) ([]byte, error) {
+	chainId := chainIdOf(entry.Data[FieldChainId])
+	loop, _ := entry.Data[FieldLoop].(string)
+	if !levels.Enabled(chainId, loop, entry.Level) {
+		return nil, nil
+	}
+
+	line, err := f.console.Format(entry)
+	if err != nil || f.store == nil {
+		return line, err
+	}
+
+	stored := line
+	if _, ok := f.console.(*log.JSONFormatter); !ok {
+		f.json.TimestampFormat = time.RFC3339Nano
+		if stored, err = f.json.Format(entry); err != nil {
+			return line, nil
+		}
+	}
+	if err := f.store.Write(stored); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write the log store:", err)
+	}
+	return line, nil
+}
+
+func chainIdOf(value interface{}) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int:
+		return uint64(v)
+	case float64:
+		return uint64(v)
+	case string:
+		id, _ := strconv.ParseUint(v, 10, 64)
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	log "github.com/xlab/suplog"
+)
+
+func TestLevels(t *testing.T) {
+	levels := NewLevels(log.InfoLevel)
+	levels.Set(0, "relayer", log.WarnLevel)
+	levels.Set(11155111, "", log.DebugLevel)
+	levels.Set(11155111, "relayer", log.ErrorLevel)
+
+	assert.Equal(t, log.InfoLevel, levels.Level(1, "oracle"))
+	assert.Equal(t, log.WarnLevel, levels.Level(1, "relayer"))
+	assert.Equal(t, log.DebugLevel, levels.Level(11155111, "oracle"))
+	assert.Equal(t, log.ErrorLevel, levels.Level(11155111, "relayer"))
+	assert.False(t, levels.Enabled(11155111, "relayer", log.WarnLevel))
+	assert.Equal(t, log.DebugLevel, levels.Max())
+
+	levels.Reset(11155111, "")
+	assert.Equal(t, log.InfoLevel, levels.Level(11155111, "oracle"))
+	assert.Len(t, levels.Overrides(), 2)
+}
+
+func TestStoreRotationAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(dir, 300, 3)
+	require.NoError(t, err)
+	defer store.Close()
+
+	for i := 0; i < 20; i++ {
+		level := "info"
+		if i%5 == 0 {
+			level = "error"
+		}
+		line := fmt.Sprintf(`{"level":%q,"msg":"batch %d sent","chain_id":%d,"loop":"relayer","time":"2026-01-01T00:00:%02dZ"}`+"\n", level, i, 1+i%2, i)
+		require.NoError(t, store.Write([]byte(line)))
+	}
+
+	// the oldest files were dropped
+	_, err = os.Stat(store.path(2))
+	assert.NoError(t, err)
+	_, err = os.Stat(store.path(3))
+	assert.True(t, os.IsNotExist(err))
+
+	records, err := store.Query(Query{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "batch 19 sent", records[1]["msg"])
+
+	records, err = store.Query(Query{ChainId: 2, Level: "error"})
+	require.NoError(t, err)
+	for _, record := range records {
+		assert.Equal(t, "error", record["level"])
+		assert.Equal(t, float64(2), record[FieldChainId])
+	}
+
+	records, err = store.Query(Query{Search: "BATCH 18"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+}
+
+func TestSetupFiltersByChainAndLoop(t *testing.T) {
+	require.NoError(t, Setup(Config{Level: log.InfoLevel, Format: FormatText, Dir: t.TempDir(), MaxSize: 1 << 20, MaxFiles: 2}))
+	defer GetStore().Close()
+
+	GetLevels().Set(5, "relayer", log.DebugLevel)
+	GetLevels().Set(0, "oracle", log.ErrorLevel)
+	defer GetLevels().Reset(5, "relayer")
+	defer GetLevels().Reset(0, "oracle")
+
+	log.WithFields(log.Fields{FieldChainId: uint64(5), FieldLoop: "relayer", FieldNonce: 12}).Debugln("relayer debug")
+	log.WithFields(log.Fields{FieldChainId: uint64(6), FieldLoop: "relayer"}).Debugln("dropped debug")
+	log.WithFields(log.Fields{FieldChainId: uint64(5), FieldLoop: "oracle"}).Warningln("dropped warning")
+	log.WithFields(log.Fields{FieldChainId: uint64(5), FieldLoop: "oracle"}).Errorln("oracle error")
+
+	records, err := GetStore().Query(Query{ChainId: 5})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "relayer debug", records[0]["msg"])
+	assert.Equal(t, float64(12), records[0][FieldNonce])
+	assert.Equal(t, "oracle error", records[1]["msg"])
+}
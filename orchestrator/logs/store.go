@@ -0,0 +1,204 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/xlab/suplog"
+)
+
+const storeFileName = "hyperion.log"
+
+// Store appends the JSON log lines to a file rotated once it reaches maxSize, the maxFiles
+// most recent files are kept: hyperion.log, hyperion.log.1, ... hyperion.log.<maxFiles-1>.
+type Store struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenStore opens the log store in dir, creating it when needed.
+func OpenStore(dir string, maxSize int64, maxFiles int) (*Store, error) {
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{dir: dir, maxSize: maxSize, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) path(index int) string {
+	if index == 0 {
+		return filepath.Join(s.dir, storeFileName)
+	}
+	return filepath.Join(s.dir, storeFileName+"."+strconv.Itoa(index))
+}
+
+func (s *Store) open() error {
+	file, err := os.OpenFile(s.path(0), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// Write appends a JSON line to the store.
+func (s *Store) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("log store is closed")
+	}
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *Store) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	os.Remove(s.path(s.maxFiles - 1))
+	for i := s.maxFiles - 2; i >= 0; i-- {
+		if err := os.Rename(s.path(i), s.path(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return s.open()
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Query filters the stored log lines, a zero field matches everything.
+type Query struct {
+	ChainId uint64
+	Loop    string
+	// Level is the least severe level returned, e.g. warning returns the warnings and errors
+	Level  string
+	Search string
+	Since  time.Time
+	// Limit is the number of most recent lines returned, the tail of the store
+	Limit int
+}
+
+// Record is a stored log line.
+type Record map[string]interface{}
+
+// Query returns the stored lines matching the query, oldest first.
+func (s *Store) Query(q Query) ([]Record, error) {
+	var maxLevel log.Level = log.TraceLevel
+	if q.Level != "" {
+		level, err := log.ParseLevel(q.Level)
+		if err != nil {
+			return nil, err
+		}
+		maxLevel = level
+	}
+	search := strings.ToLower(q.Search)
+
+	// the rotated files are read while the current one is written, the lock is only held
+	// to flush the view of the current file
+	s.mu.Lock()
+	if s.file != nil {
+		s.file.Sync()
+	}
+	s.mu.Unlock()
+
+	records := make([]Record, 0)
+	for i := s.maxFiles - 1; i >= 0; i-- {
+		file, err := os.Open(s.path(i))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if search != "" && !bytes.Contains(bytes.ToLower(line), []byte(search)) {
+				continue
+			}
+			record := Record{}
+			if err := json.Unmarshal(line, &record); err != nil {
+				continue
+			}
+			if !q.match(record, maxLevel) {
+				continue
+			}
+			records = append(records, record)
+			if q.Limit > 0 && len(records) > 2*q.Limit {
+				records = append(records[:0], records[len(records)-q.Limit:]...)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records, nil
+}
+
+func (q Query) match(record Record, maxLevel log.Level) bool {
+	if level, err := log.ParseLevel(fmt.Sprint(record["level"])); err == nil && level > maxLevel {
+		return false
+	}
+	if q.ChainId != 0 && chainIdOf(record[FieldChainId]) != q.ChainId {
+		return false
+	}
+	if q.Loop != "" && fmt.Sprint(record[FieldLoop]) != q.Loop {
+		return false
+	}
+	if !q.Since.IsZero() {
+		at, err := time.Parse(time.RFC3339, fmt.Sprint(record["time"]))
+		if err != nil || at.Before(q.Since) {
+			return false
+		}
+	}
+	return true
+}
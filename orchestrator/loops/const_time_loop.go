@@ -21,11 +21,19 @@ var ErrGracefulStop = errors.New("stop")
 // the waiting time between iteration decreases. A single iteration has a deadline and cannot run longer
 // than interval itself. There is a protection from panic which could crash adjacent loops.
 func RunLoop(ctx context.Context, ethereum ethereum.Network, interval time.Duration, fn func() error) (err error) {
+	return RunLoopWithWakeup(ctx, ethereum, interval, nil, fn)
+}
+
+// RunLoopWithWakeup is RunLoop also running the function as soon as wakeup receives, the
+// interval restarting from there.
+func RunLoopWithWakeup(ctx context.Context, ethereum ethereum.Network, interval time.Duration, wakeup <-chan struct{}, fn func() error) (err error) {
 	defer panicRecover(&err)
 
 	delayTimer := time.NewTimer(0)
 	for {
 		select {
+		case <-wakeup:
+			delayTimer.Reset(0)
 		case <-delayTimer.C:
 			var start = time.Now()
 			if fnErr := fn(); fnErr != nil {
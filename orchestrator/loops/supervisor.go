@@ -0,0 +1,199 @@
+package loops
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/xlab/suplog"
+)
+
+// Statuses of a supervised loop.
+const (
+	LoopRunning = "running"
+	LoopBackoff = "backoff"
+	// LoopBroken is a loop that crashed MaxCrashes times in a row, it is restarted once
+	// BreakDuration elapsed and broken again if it crashes on that attempt
+	LoopBroken  = "broken"
+	LoopStopped = "stopped"
+)
+
+// LoopState is the supervision state of a loop.
+type LoopState struct {
+	Name               string    `json:"name"`
+	Status             string    `json:"status"`
+	Crashes            int       `json:"crashes"`
+	ConsecutiveCrashes int       `json:"consecutive_crashes"`
+	LastError          string    `json:"last_error,omitempty"`
+	LastCrashAt        time.Time `json:"last_crash_at,omitempty"`
+	StartedAt          time.Time `json:"started_at"`
+	NextStartAt        time.Time `json:"next_start_at,omitempty"`
+}
+
+// Supervisor runs loops side by side and restarts only the loop that failed, with an
+// exponential backoff. Unlike ParanoidGroup a failed loop does not unblock Wait, the
+// other loops keep running.
+type Supervisor struct {
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxCrashes is the number of consecutive crashes that breaks a loop
+	MaxCrashes    int
+	BreakDuration time.Duration
+	// StableAfter is the run time after which a crash no longer counts as consecutive
+	StableAfter time.Duration
+
+	logger   log.Logger
+	onChange func([]LoopState)
+
+	mu     sync.Mutex
+	states map[string]*LoopState
+	order  []string
+	wg     sync.WaitGroup
+}
+
+// NewSupervisor returns a supervisor with the default backoff, onChange receives the
+// states of every loop after each change.
+func NewSupervisor(logger log.Logger, onChange func([]LoopState)) *Supervisor {
+	return &Supervisor{
+		BaseBackoff:   5 * time.Second,
+		MaxBackoff:    5 * time.Minute,
+		MaxCrashes:    5,
+		BreakDuration: 30 * time.Minute,
+		StableAfter:   10 * time.Minute,
+		logger:        logger,
+		onChange:      onChange,
+		states:        make(map[string]*LoopState),
+	}
+}
+
+// Go starts the loop under supervision. A loop returning an error or panicking is
+// restarted, a loop returning nil is stopped.
+func (s *Supervisor) Go(ctx context.Context, name string, fn func() error) {
+	s.mu.Lock()
+	if _, ok := s.states[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.states[name] = &LoopState{Name: name}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.supervise(ctx, name, fn)
+	}()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, name string, fn func() error) {
+	logger := s.logger.WithField("loop", name)
+
+	for {
+		start := time.Now()
+		s.update(name, func(state *LoopState) {
+			state.Status = LoopRunning
+			state.StartedAt = start
+			state.NextStartAt = time.Time{}
+		})
+
+		err := runRecovered(fn)
+		if err == nil || ctx.Err() != nil {
+			s.update(name, func(state *LoopState) { state.Status = LoopStopped })
+			return
+		}
+
+		var delay time.Duration
+		s.update(name, func(state *LoopState) {
+			delay = s.crash(state, err, time.Since(start), time.Now())
+		})
+
+		state := s.State(name)
+		if state.Status == LoopBroken {
+			logger.WithError(err).Errorf("loop crashed %d times in a row, broken until %s", state.ConsecutiveCrashes, state.NextStartAt.Format(time.RFC3339))
+		} else {
+			logger.WithError(err).Warningf("loop crashed, restarting in %s (#%d)", delay, state.ConsecutiveCrashes)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			s.update(name, func(state *LoopState) { state.Status = LoopStopped })
+			return
+		}
+	}
+}
+
+// crash records the crash of the loop and returns the delay before its restart.
+func (s *Supervisor) crash(state *LoopState, err error, ranFor time.Duration, now time.Time) time.Duration {
+	if ranFor >= s.StableAfter {
+		state.ConsecutiveCrashes = 0
+	}
+	state.Crashes++
+	state.ConsecutiveCrashes++
+	state.LastError = err.Error()
+	state.LastCrashAt = now
+
+	delay := s.backoff(state.ConsecutiveCrashes)
+	state.Status = LoopBackoff
+	if state.ConsecutiveCrashes >= s.MaxCrashes {
+		delay = s.BreakDuration
+		state.Status = LoopBroken
+	}
+	state.NextStartAt = now.Add(delay)
+	return delay
+}
+
+func (s *Supervisor) backoff(consecutiveCrashes int) time.Duration {
+	delay := s.BaseBackoff
+	for i := 1; i < consecutiveCrashes && delay < s.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.MaxBackoff)
+}
+
+func (s *Supervisor) update(name string, fn func(state *LoopState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.states[name])
+	// under the lock so that the snapshots are delivered in order
+	if s.onChange != nil {
+		s.onChange(s.snapshot())
+	}
+}
+
+func (s *Supervisor) snapshot() []LoopState {
+	states := make([]LoopState, 0, len(s.order))
+	for _, name := range s.order {
+		states = append(states, *s.states[name])
+	}
+	return states
+}
+
+// State returns the supervision state of the loop.
+func (s *Supervisor) State(name string) LoopState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[name]; ok {
+		return *state
+	}
+	return LoopState{Name: name}
+}
+
+// States returns the supervision states of every loop, in start order.
+func (s *Supervisor) States() []LoopState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// Wait blocks until every loop stopped, that is once the context is done or every loop
+// returned nil.
+func (s *Supervisor) Wait() error {
+	s.wg.Wait()
+	return nil
+}
+
+func runRecovered(fn func() error) (err error) {
+	defer panicRecover(&err)
+	return fn()
+}
@@ -0,0 +1,64 @@
+package loops
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	log "github.com/xlab/suplog"
+)
+
+func TestSupervisorRestartsFailedLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := NewSupervisor(log.WithField("test", "supervisor"), nil)
+	supervisor.BaseBackoff = time.Millisecond
+	supervisor.MaxBackoff = 4 * time.Millisecond
+	supervisor.MaxCrashes = 3
+	supervisor.BreakDuration = time.Hour
+
+	var runs, healthy atomic.Int32
+	supervisor.Go(ctx, "flaky", func() error {
+		if runs.Add(1) == 1 {
+			panic("boom")
+		}
+		return errors.New("failed")
+	})
+	supervisor.Go(ctx, "healthy", func() error {
+		healthy.Add(1)
+		<-ctx.Done()
+		return nil
+	})
+
+	assert.Eventually(t, func() bool { return supervisor.State("flaky").Status == LoopBroken }, time.Second, time.Millisecond)
+	flaky := supervisor.State("flaky")
+	assert.Equal(t, 3, flaky.Crashes)
+	assert.Equal(t, "failed", flaky.LastError)
+	// the other loop was never restarted
+	assert.Equal(t, int32(1), healthy.Load())
+	assert.Equal(t, LoopRunning, supervisor.State("healthy").Status)
+
+	cancel()
+	assert.NoError(t, supervisor.Wait())
+	for _, state := range supervisor.States() {
+		assert.Equal(t, LoopStopped, state.Status)
+	}
+}
+
+func TestSupervisorBackoff(t *testing.T) {
+	supervisor := NewSupervisor(log.WithField("test", "supervisor"), nil)
+	assert.Equal(t, 5*time.Second, supervisor.backoff(1))
+	assert.Equal(t, 40*time.Second, supervisor.backoff(4))
+	assert.Equal(t, 5*time.Minute, supervisor.backoff(20))
+
+	// a crash after a stable run starts a new streak
+	state := &LoopState{ConsecutiveCrashes: 4}
+	delay := supervisor.crash(state, errors.New("failed"), time.Hour, time.Unix(0, 0))
+	assert.Equal(t, 1, state.ConsecutiveCrashes)
+	assert.Equal(t, 5*time.Second, delay)
+	assert.Equal(t, LoopBackoff, state.Status)
+}
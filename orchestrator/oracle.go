@@ -11,10 +11,12 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
+	"go.opentelemetry.io/otel/attribute"
 
 	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
 	hyperionevents "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
 	"github.com/Helios-Chain-Labs/metrics"
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
@@ -45,7 +47,6 @@ func (s *Orchestrator) runOracle(ctx context.Context, lastObservedBlock uint64)
 		Orchestrator:            s,
 		lastObservedEthHeight:   lastObservedBlock,
 		lastResyncWithHelios:    time.Now(),
-		logEnabled:              strings.Contains(s.cfg.EnabledLogs, "oracle"),
 		missedEventsBlockHeight: 0,
 	}
 
@@ -62,15 +63,24 @@ func (s *Orchestrator) runOracle(ctx context.Context, lastObservedBlock uint64)
 			if s.HyperionState.OracleStatus == "running" {
 				continue
 			}
+			if s.heliosSyncing(ctx, "oracle") {
+				s.setStatus(LoopOracle, HeliosSyncingStatus)
+				continue
+			}
 
-			start := time.Now()
-			s.HyperionState.OracleStatus = "running"
-			if err := oracle.observeEthEvents(ctx); err != nil {
-				s.logger.WithError(err).Errorln("oracle function returned an error")
+			drained := !s.work("oracle", func() {
+				start := time.Now()
+				s.setStatus(LoopOracle, "running")
+				if err := oracle.observeEthEvents(ctx); err != nil {
+					s.logger.WithError(err).Errorln("oracle function returned an error")
+				}
+				s.setStatus(LoopOracle, "idle")
+				s.HyperionState.OracleLastExecutionFinishedTimestamp = uint64(start.Unix())
+				s.HyperionState.OracleNextExecutionTimestamp = uint64(start.Add(defaultLoopDur).Unix())
+			})
+			if drained {
+				s.setStatus(LoopOracle, DrainingStatus)
 			}
-			s.HyperionState.OracleStatus = "idle"
-			s.HyperionState.OracleLastExecutionFinishedTimestamp = uint64(start.Unix())
-			s.HyperionState.OracleNextExecutionTimestamp = uint64(start.Add(defaultLoopDur).Unix())
 		case <-ctx.Done():
 			return nil
 		}
@@ -81,19 +91,21 @@ type oracle struct {
 	*Orchestrator
 	lastResyncWithHelios    time.Time
 	lastObservedEthHeight   uint64
-	logEnabled              bool
 	missedEventsBlockHeight uint64
 }
 
 func (l *oracle) Log() log.Logger {
-	return l.logger.WithField("loop", "Oracle").WithField("chain", l.cfg.ChainName)
+	return l.logger.WithField("loop", LoopOracle).WithField("chain", l.cfg.ChainName)
 }
 
-func (l *oracle) observeEthEvents(ctx context.Context) error {
+func (l *oracle) observeEthEvents(ctx context.Context) (err error) {
 	metrics.ReportFuncCall(l.svcTags)
 	doneFn := metrics.ReportFuncTiming(l.svcTags)
 	defer doneFn()
 
+	ctx, span := l.startSpan(ctx, LoopOracle, "oracle.observeEthEvents")
+	defer func() { tracing.End(span, err) }()
+
 	settings, err := storage.GetChainSettings(l.cfg.ChainId)
 	if err != nil {
 		return errors.Wrap(err, "failed to get chain settings")
@@ -108,10 +120,10 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 	}
 
 	if defaultBlocksToSearch < 10 {
-		l.Orchestrator.HyperionState.ErrorStatus = "oracle_eth_default_blocks_to_search is less than 10, please increase the value"
+		l.Orchestrator.setStatus(LoopError, "oracle_eth_default_blocks_to_search is less than 10, please increase the value")
 		return errors.New("oracle_eth_default_blocks_to_search is less than 10, please increase the value")
 	} else if defaultBlocksToSearch >= 10 && l.Orchestrator.HyperionState.ErrorStatus == "oracle_eth_default_blocks_to_search is less than 10, please increase the value" {
-		l.Orchestrator.HyperionState.ErrorStatus = "okay"
+		l.Orchestrator.setStatus(LoopError, "okay")
 	}
 
 	ethBlockConfirmationDelay, ok := settings["oracle_block_confirmation_delay"].(float64)
@@ -130,7 +142,7 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 		l.Log().Infoln("oracle_max_claims_msg_per_bulk found in chain settings, using value", maxClaimsMsgPerBulk)
 	}
 
-	l.HyperionState.OracleStatus = "getting CurrentValset on Helios"
+	l.setStatus(LoopOracle, "getting CurrentValset on Helios")
 	// check if validator is in the active set since claims will fail otherwise
 	vs, err := l.GetHelios().CurrentValset(ctx, l.cfg.HyperionId)
 	if err != nil {
@@ -138,7 +150,7 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 		return err
 	}
 
-	l.HyperionState.OracleStatus = "getting LatestObservedHeight on Helios"
+	l.setStatus(LoopOracle, "getting LatestObservedHeight on Helios")
 	latestObservedHeight, err := l.GetHelios().QueryGetLastObservedEthereumBlockHeight(ctx, l.cfg.HyperionId)
 	if err != nil {
 		return errors.Wrap(err, "failed to get latest valsets on Helios")
@@ -147,7 +159,7 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 	// state
 	l.HyperionState.LastObservedHeight = latestObservedHeight.EthereumBlockHeight
 
-	l.HyperionState.OracleStatus = "checking if first time sync is needed"
+	l.setStatus(LoopOracle, "checking if first time sync is needed")
 	if latestObservedHeight.EthereumBlockHeight <= l.cfg.ChainParams.BridgeContractStartHeight && !l.firstTimeSync { // first time total sync needed
 		l.lastObservedEthHeight = l.cfg.ChainParams.BridgeContractStartHeight
 		l.Log().Info("First Time Hyperion total sync needed BridgeContractStartHeight: ", l.lastObservedEthHeight)
@@ -164,11 +176,11 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 	}
 
 	if !bonded {
-		l.HyperionState.OracleStatus = "validator not in active set, cannot make claims..."
+		l.setStatus(LoopOracle, "validator not in active set, cannot make claims...")
 		l.Log().WithFields(log.Fields{"latest_helios_block": vs.Height}).Warningln("validator not in active set, cannot make claims...")
 		_, err := l.GetHelios().GetLatestBlockHeight(ctx)
 		if err != nil {
-			l.HyperionState.OracleStatus = "Connected node is down, cannot make claims..."
+			l.setStatus(LoopOracle, "Connected node is down, cannot make claims...")
 			l.Log().WithError(err).Errorln("Connected node is down, cannot make claims...")
 			return err
 		}
@@ -218,7 +230,7 @@ func (l *oracle) observeEthEvents(ctx context.Context) error {
 		return nil
 	}
 
-	l.HyperionState.OracleStatus = "getting latest ethereum height"
+	l.setStatus(LoopOracle, "getting latest ethereum height")
 	latestHeight, err := l.getLatestEthHeight(ctx)
 	if err != nil {
 		l.Log().WithError(err).Errorln("failed to get latest " + l.cfg.ChainName + " height")
@@ -340,12 +352,12 @@ func (l *oracle) syncToTargetHeight(ctx context.Context, latestHeight uint64, ta
 		return err
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopOracle) {
 		l.Log().Infoln("lastObservedEventNonce: ", lastObservedEventNonce, " lastEventNonce: ", lastEventNonce)
 	}
 	l.Log().Infoln("events Before Filter", events)
 	newEvents := filterEvents(events, lastObservedEventNonce)
-	if l.logEnabled {
+	if l.logEnabled(LoopOracle) {
 		l.Log().Infoln("newEvents: ", newEvents)
 	}
 
@@ -355,7 +367,7 @@ func (l *oracle) syncToTargetHeight(ctx context.Context, latestHeight uint64, ta
 
 	if len(newEvents) == 0 {
 		// l.Log().Infoln("NO EVENTS DETECTED 0", l.ethereum.GetLastUsedRpc())
-		if l.logEnabled {
+		if l.logEnabled(LoopOracle) {
 			l.Log().WithFields(log.Fields{"last_observed_event_nonce": lastObservedEventNonce, "eth_block_start": l.lastObservedEthHeight, "eth_block_end": targetHeight}).Infoln("oracle no new events on " + l.cfg.ChainName)
 		}
 		l.lastObservedEthHeight = targetHeight
@@ -366,7 +378,7 @@ func (l *oracle) syncToTargetHeight(ctx context.Context, latestHeight uint64, ta
 		l.Log().Infoln("SOME EVENTS DETECTED %d", len(newEvents))
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopOracle) {
 		l.Log().WithFields(log.Fields{"event_helios_nonce": lastObservedEventNonce, "event_ethereum_nonce": newEvents[0].Nonce()}).Infoln("try oracle relay to helios")
 	}
 
@@ -399,12 +411,14 @@ func (l *oracle) syncToTargetHeight(ctx context.Context, latestHeight uint64, ta
 
 	l.missedEventsBlockHeight = 0
 
+	l.checkTripwires(ctx, newEvents)
+
 	if err := l.sendNewEventClaims(ctx, newEvents, maxClaimsMsgPerBulk); err != nil {
 		log.Info("err: ", err)
 		return err
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopOracle) {
 		l.Log().WithFields(log.Fields{"claims": len(newEvents), "eth_block_start": l.lastObservedEthHeight, "eth_block_end": latestHeight}).Infoln("sent new event claims to Helios")
 	}
 	l.lastObservedEthHeight = targetHeight
@@ -412,13 +426,28 @@ func (l *oracle) syncToTargetHeight(ctx context.Context, latestHeight uint64, ta
 	return nil
 }
 
-func (l *oracle) getEthEvents(ctx context.Context, startBlock, endBlock uint64, noncesResearched []uint64) ([]event, error) {
-	var events []event
+func (l *oracle) getEthEvents(ctx context.Context, startBlock, endBlock uint64, noncesResearched []uint64) (events []event, err error) {
+	ctx, span := l.startSpan(ctx, LoopOracle, "oracle.getEthEvents",
+		attribute.Int64("hyperion.start_block", int64(startBlock)),
+		attribute.Int64("hyperion.end_block", int64(endBlock)),
+	)
+	defer func() {
+		span.SetAttributes(tracing.AttrCount.Int(len(events)))
+		tracing.End(span, err)
+	}()
+
+	if indexed, ok := l.indexedEthEvents(startBlock, endBlock); ok {
+		span.SetAttributes(attribute.Bool("hyperion.indexed", true))
+		return indexed, nil
+	}
+
 	scanEthEventsFn := func() error {
 		events = nil // clear previous result in case a retry occurred
 		noncesFound := []uint64{}
 
+		_, rpcSpan := tracing.StartRPC(ctx, tracing.SystemEthereum, "GetSendToHeliosEvents")
 		depositEvents, err := l.ethereum.GetSendToHeliosEvents(startBlock, endBlock)
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			if strings.Contains(err.Error(), "limit exceeded") {
 				return errors.Wrap(err, "failed to get SendToHelios events - limit exceeded")
@@ -440,7 +469,9 @@ func (l *oracle) getEthEvents(ctx context.Context, startBlock, endBlock uint64,
 			return nil
 		}
 
+		_, rpcSpan = tracing.StartRPC(ctx, tracing.SystemEthereum, "GetTransactionBatchExecutedEvents")
 		withdrawalEvents, err := l.ethereum.GetTransactionBatchExecutedEvents(startBlock, endBlock)
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			return errors.Wrap(err, "failed to get TransactionBatchExecuted events")
 		}
@@ -459,7 +490,9 @@ func (l *oracle) getEthEvents(ctx context.Context, startBlock, endBlock uint64,
 			return nil
 		}
 
+		_, rpcSpan = tracing.StartRPC(ctx, tracing.SystemEthereum, "GetValsetUpdatedEvents")
 		valsetUpdateEvents, err := l.ethereum.GetValsetUpdatedEvents(startBlock, endBlock)
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			return errors.Wrap(err, "failed to get ValsetUpdated events")
 		}
@@ -478,7 +511,9 @@ func (l *oracle) getEthEvents(ctx context.Context, startBlock, endBlock uint64,
 			return nil
 		}
 
+		_, rpcSpan = tracing.StartRPC(ctx, tracing.SystemEthereum, "GetHyperionERC20DeployedEvents")
 		erc20DeploymentEvents, err := l.ethereum.GetHyperionERC20DeployedEvents(startBlock, endBlock)
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			return errors.Wrap(err, "failed to get ERC20Deployed events")
 		}
@@ -530,10 +565,15 @@ func (l *oracle) getLastClaimEvent(ctx context.Context) (*hyperiontypes.LastClai
 	return claim, nil
 }
 
-func (l *oracle) sendNewEventClaims(ctx context.Context, events []event, maxClaimsMsgPerBulk int) error {
+func (l *oracle) sendNewEventClaims(ctx context.Context, events []event, maxClaimsMsgPerBulk int) (err error) {
+	ctx, span := l.startSpan(ctx, LoopOracle, "oracle.sendNewEventClaims", tracing.AttrCount.Int(len(events)))
+	defer func() { tracing.End(span, err) }()
+
 	sendEventsFn := func() error {
 		// in case sending one of more claims fails, we reload the latest claimed nonce to filter processed events
-		lastClaim, err := l.GetHelios().LastClaimEventByAddr(ctx, l.cfg.HyperionId, l.cfg.CosmosAddr)
+		rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "LastClaimEventByAddr")
+		lastClaim, err := l.GetHelios().LastClaimEventByAddr(rpcCtx, l.cfg.HyperionId, l.cfg.CosmosAddr)
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			return err
 		}
@@ -554,9 +594,9 @@ func (l *oracle) sendNewEventClaims(ctx context.Context, events []event, maxClai
 
 			if len(msgs) >= maxClaimsMsgPerBulk {
 				log.Infoln("sending bulk of ", len(msgs), "claims messages")
-				l.Orchestrator.HyperionState.OracleStatus = "sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+				l.Orchestrator.setStatus(LoopOracle, "sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 
-				err = l.GetHelios().SyncBroadcastMsgsSimulate(ctx, msgs)
+				err = l.simulateMsgs(ctx, msgs)
 				if err != nil {
 					VerifyTxError(ctx, err.Error(), l.Orchestrator)
 					l.Log().WithError(err).Warningln("failed to simulate bulk of claims messages")
@@ -564,12 +604,13 @@ func (l *oracle) sendNewEventClaims(ctx context.Context, events []event, maxClai
 				}
 				resp, err := l.global.SyncBroadcastMsgs(ctx, msgs)
 				if err != nil {
-					l.Orchestrator.HyperionState.OracleStatus = "error sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+					l.Orchestrator.setStatus(LoopOracle, "error sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 					log.Errorln("error sending bulk of ", len(msgs), "claims messages", err)
 					return err
 				}
-				l.Orchestrator.HyperionState.OracleStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
-				cost, err := l.GetHelios().GetTxCost(ctx, resp.TxHash)
+				l.publishClaims(LoopOracle, len(msgs), resp)
+				l.Orchestrator.setStatus(LoopOracle, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
+				cost, err := l.getTxCost(ctx, resp.TxHash)
 				if err == nil {
 					storage.UpdateFeesFile(big.NewInt(0), "", cost, resp.TxHash, uint64(resp.Height), uint64(42000), "CLAIM")
 				}
@@ -580,27 +621,28 @@ func (l *oracle) sendNewEventClaims(ctx context.Context, events []event, maxClai
 
 		if len(msgs) > 0 {
 			log.Infoln("sending bulk of ", len(msgs), "claims messages")
-			l.Orchestrator.HyperionState.OracleStatus = "sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
-			err = l.GetHelios().SyncBroadcastMsgsSimulate(ctx, msgs)
+			l.Orchestrator.setStatus(LoopOracle, "sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
+			err = l.simulateMsgs(ctx, msgs)
 			if err != nil {
 				VerifyTxError(ctx, err.Error(), l.Orchestrator)
 				l.Log().WithError(err).Warningln("failed to simulate bulk of claims messages")
 				return err
 			} else {
-				l.Orchestrator.HyperionState.ErrorStatus = "okay"
+				l.Orchestrator.setStatus(LoopError, "okay")
 			}
 			resp, err := l.global.SyncBroadcastMsgs(ctx, msgs)
 			if err != nil {
-				l.Orchestrator.HyperionState.OracleStatus = "error sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+				l.Orchestrator.setStatus(LoopOracle, "error sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 				log.Errorln("error sending bulk of ", len(msgs), "claims messages", err)
 				return err
 			}
-			cost, err := l.GetHelios().GetTxCost(ctx, resp.TxHash)
+			l.publishClaims(LoopOracle, len(msgs), resp)
+			cost, err := l.getTxCost(ctx, resp.TxHash)
 			if err == nil {
-				l.Orchestrator.HyperionState.OracleStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
+				l.Orchestrator.setStatus(LoopOracle, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
 				storage.UpdateFeesFile(big.NewInt(0), "", cost, resp.TxHash, uint64(resp.Height), uint64(42000), "CLAIM")
 			}
-			l.Orchestrator.HyperionState.OracleStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
+			l.Orchestrator.setStatus(LoopOracle, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
 			time.Sleep(1100 * time.Millisecond)
 		}
 
@@ -633,7 +675,10 @@ func (l *oracle) autoResync(ctx context.Context) error {
 	return nil
 }
 
-func (l *oracle) prepareSendEthEventClaim(ctx context.Context, ev event) (cosmostypes.Msg, error) {
+func (l *oracle) prepareSendEthEventClaim(ctx context.Context, ev event) (msg cosmostypes.Msg, err error) {
+	ctx, span := tracing.Start(ctx, "oracle.prepareClaim", tracing.AttrNonce.Int64(int64(ev.Nonce())))
+	defer func() { tracing.End(span, err) }()
+
 	rpc := ""
 	switch e := ev.(type) {
 	case *deposit:
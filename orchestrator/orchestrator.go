@@ -2,7 +2,6 @@ package orchestrator
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"strings"
 	"time"
@@ -15,10 +14,15 @@ import (
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	log "github.com/xlab/suplog"
 
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/compliance"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/logs"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/outbox"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/utils"
 	"github.com/Helios-Chain-Labs/metrics"
 )
@@ -40,6 +44,8 @@ type Global interface {
 	ResetHeliosClient()
 	GetHeliosNetwork() *helios.Network
 	SyncBroadcastMsgs(ctx context.Context, msgs []sdk.Msg) (*sdk.TxResponse, error)
+	GetSanctionsList() *compliance.SanctionsList
+	GetStatusStream() *stream.Hub
 }
 
 type Config struct {
@@ -81,6 +87,9 @@ type HyperionState struct {
 	ERC20DeploymentCount int
 	SkippedRetriedCount  int
 	ExternalDataCount    int
+	SanctionsHitCount    int
+	HeldBatchCount       int
+	HeliosWakeupCount    int
 
 	BatchCreatorStatus  string
 	ExternalDataStatus  string
@@ -110,8 +119,12 @@ type HyperionState struct {
 	SkippedLastExecutionFinishedTimestamp       uint64
 	ValsetManagerLastExecutionFinishedTimestamp uint64
 
-	IsDepositPaused    bool
-	IsWithdrawalPaused bool
+	IsDepositPaused       bool
+	IsWithdrawalPaused    bool
+	CircuitBreakerTripped bool
+
+	// Loops is the supervision state of each loop, see startValidatorMode
+	Loops []loops.LoopState
 }
 
 type Orchestrator struct {
@@ -132,6 +145,13 @@ type Orchestrator struct {
 
 	HyperionState HyperionState
 
+	heliosSync    heliosSyncGate
+	participation *participationMonitor
+	breaker       *circuitBreaker
+	wakeups       wakeups
+	drain         *drainGate
+	index         *indexer.Index
+
 	CacheSymbol map[gethcommon.Address]string
 
 	Oracle *oracle
@@ -145,7 +165,8 @@ func NewOrchestrator(
 ) (*Orchestrator, error) {
 	o := &Orchestrator{
 		logger: log.WithFields(log.Fields{
-			"chain": cfg.ChainName,
+			logs.FieldChain:   cfg.ChainName,
+			logs.FieldChainId: cfg.ChainId,
 		}),
 		svcTags:       metrics.Tags{"svc": "hyperion_orchestrator"},
 		ethereum:      *eths[0],
@@ -159,6 +180,11 @@ func NewOrchestrator(
 		height:       0,
 		targetHeight: 0,
 
+		participation: &participationMonitor{records: make(map[string]*participationRecord)},
+		breaker:       newCircuitBreaker(cfg.ChainId),
+		wakeups:       newWakeups(),
+		drain:         newDrainGate(),
+
 		HyperionState: HyperionState{
 			HyperionID:             cfg.HyperionId,
 			Height:                 0,
@@ -177,6 +203,9 @@ func NewOrchestrator(
 			ERC20DeploymentCount: 0,
 			SkippedRetriedCount:  0,
 			ExternalDataCount:    0,
+			SanctionsHitCount:    0,
+			HeldBatchCount:       0,
+			HeliosWakeupCount:    0,
 
 			BatchCreatorStatus:  "idle",
 			ExternalDataStatus:  "idle",
@@ -208,6 +237,8 @@ func NewOrchestrator(
 
 			IsDepositPaused:    false,
 			IsWithdrawalPaused: false,
+
+			Loops: []loops.LoopState{},
 		},
 
 		CacheSymbol: make(map[gethcommon.Address]string),
@@ -240,8 +271,8 @@ func (s *Orchestrator) GetEthereum() ethereum.Network {
 	return s.ethereum
 }
 
-func (s *Orchestrator) GetHelios() helios.Network {
-	return *s.global.GetHeliosNetwork()
+func (s *Orchestrator) GetHelios() *helios.Network {
+	return s.global.GetHeliosNetwork()
 }
 
 func (s *Orchestrator) GetConfig() Config {
@@ -280,8 +311,6 @@ func (s *Orchestrator) GetStats() map[string]interface{} {
 func (s *Orchestrator) startValidatorMode(ctx context.Context) error {
 	s.logger.Infoln("running orchestrator in validator mode")
 
-	fmt.Println("startValidatorMode")
-
 	// update native balance
 	err := s.UpdateNativeBalance(ctx)
 	if err != nil {
@@ -327,6 +356,12 @@ func (s *Orchestrator) startValidatorMode(ctx context.Context) error {
 		ethereumBlockHeightWhereStart = latestObservedHeight.EthereumBlockHeight + 1
 	}
 
+	// resume from where the oracle stopped when the last run drained behind the start height
+	if cursor := s.resumeOracleCursor(); cursor > 0 && cursor < ethereumBlockHeightWhereStart {
+		s.logger.Infoln("resuming the oracle from the cursor of the last drain", cursor)
+		ethereumBlockHeightWhereStart = cursor
+	}
+
 	// check if deposit is paused
 	isDepositPaused, err := s.ethereum.IsDepositPaused(ctx)
 	if err != nil {
@@ -335,20 +370,33 @@ func (s *Orchestrator) startValidatorMode(ctx context.Context) error {
 	s.HyperionState.IsDepositPaused = isDepositPaused
 	s.HyperionState.IsWithdrawalPaused = s.cfg.ChainParams.Paused
 
-	var pg loops.ParanoidGroup
+	// the loops scan the RPCs as before when the index cannot be opened
+	if err := s.openIndex(); err != nil {
+		s.logger.WithError(err).Warningln("unable to open the event index")
+	}
+
+	// a failed loop is restarted alone, the others keep running
+	supervisor := loops.NewSupervisor(s.logger, s.setLoops)
+
+	if s.index != nil {
+		supervisor.Go(ctx, "indexer", func() error { return s.runIndexer(ctx) })
+	}
 
-	pg.Go(func() error { return s.runOracle(ctx, ethereumBlockHeightWhereStart) })
-	// pg.Go(func() error { return s.runSkipped(ctx) })
-	pg.Go(func() error { return s.runSigner(ctx, hyperionIDHash) })
-	pg.Go(func() error { return s.runBatchCreator(ctx) })
-	pg.Go(func() error { return s.runRelayer(ctx) })
-	pg.Go(func() error { return s.runUpdater(ctx) })
-	pg.Go(func() error { return s.runExternalData(ctx) })
+	supervisor.Go(ctx, "oracle", func() error {
+		return s.runOracle(outbox.WithOrigin(ctx, s.cfg.ChainId, "oracle"), ethereumBlockHeightWhereStart)
+	})
+	// supervisor.Go(ctx, "skipped", func() error { return s.runSkipped(ctx) })
+	supervisor.Go(ctx, "signer", func() error { return s.runSigner(outbox.WithOrigin(ctx, s.cfg.ChainId, "signer"), hyperionIDHash) })
+	supervisor.Go(ctx, "batch_creator", func() error { return s.runBatchCreator(outbox.WithOrigin(ctx, s.cfg.ChainId, "batch_creator")) })
+	supervisor.Go(ctx, "relayer", func() error { return s.runRelayer(outbox.WithOrigin(ctx, s.cfg.ChainId, "relayer")) })
+	supervisor.Go(ctx, "updater", func() error { return s.runUpdater(outbox.WithOrigin(ctx, s.cfg.ChainId, "updater")) })
+	supervisor.Go(ctx, "external_data", func() error { return s.runExternalData(outbox.WithOrigin(ctx, s.cfg.ChainId, "external_data")) })
+	supervisor.Go(ctx, "participation_monitor", func() error { return s.runParticipationMonitor(ctx) })
 	if s.cfg.RelayValsets {
-		pg.Go(func() error { return s.runValsetManager(ctx) })
+		supervisor.Go(ctx, "valset_manager", func() error { return s.runValsetManager(outbox.WithOrigin(ctx, s.cfg.ChainId, "valset_manager")) })
 	}
 
-	return pg.Wait()
+	return supervisor.Wait()
 }
 
 // startRelayerMode runs orchestrator processes that only relay specific
@@ -359,14 +407,14 @@ func (s *Orchestrator) startValidatorMode(ctx context.Context) error {
 
 // 	var pg loops.ParanoidGroup
 
-// 	pg.Go(func() error { return s.runBatchCreator(ctx) })
-// 	pg.Go(func() error { return s.runRelayer(ctx) })
-// 	pg.Go(func() error { return s.runUpdater(ctx) })
+// 	pg.Go(func() error { return s.runBatchCreator(outbox.WithOrigin(ctx, s.cfg.ChainId, "batch_creator")) })
+// 	pg.Go(func() error { return s.runRelayer(outbox.WithOrigin(ctx, s.cfg.ChainId, "relayer")) })
+// 	pg.Go(func() error { return s.runUpdater(outbox.WithOrigin(ctx, s.cfg.ChainId, "updater")) })
 
 // 	return pg.Wait()
 // }
 
-func (s *Orchestrator) getLastClaimBlockHeight(ctx context.Context, helios helios.Network) (uint64, error) {
+func (s *Orchestrator) getLastClaimBlockHeight(ctx context.Context, helios *helios.Network) (uint64, error) {
 	claim, err := helios.LastClaimEventByAddr(ctx, s.cfg.HyperionId, s.cfg.CosmosAddr)
 	if err != nil {
 		s.logger.Info("SSSSS", "err", err)
@@ -430,10 +478,22 @@ func (s *Orchestrator) UpdateNativeBalance(ctx context.Context) error {
 }
 
 func (s *Orchestrator) ResetHeliosClient() {
-	fmt.Println("ResetHeliosClient called")
+	s.logger.Debugln("resetting the Helios client")
 	s.global.ResetHeliosClient()
 }
 
+// ResyncHeliosSequence reloads the account sequence of the Helios broadcaster, it replaces
+// a full reconnection when a tx was refused for a sequence mismatch.
+func (s *Orchestrator) ResyncHeliosSequence() {
+	heliosNetwork := s.global.GetHeliosNetwork()
+	if heliosNetwork == nil {
+		return
+	}
+	if err := heliosNetwork.ResyncSequence(); err != nil {
+		s.logger.Error("Error resyncing helios account sequence", "error", err)
+	}
+}
+
 func (s *Orchestrator) ResetEthereum() {
 	targetNetworks, err := s.global.InitTargetNetworks(s.cfg.ChainParams)
 	if err != nil {
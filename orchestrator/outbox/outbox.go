@@ -0,0 +1,302 @@
+package outbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// LoopAPI is the origin of messages queued by the API server rather than an orchestrator loop.
+const LoopAPI = "api"
+
+type originKey struct{}
+
+// Origin identifies the chain and the orchestrator loop that queued a message.
+type Origin struct {
+	ChainId uint64
+	Loop    string
+}
+
+// WithOrigin tags the context of an orchestrator loop, messages broadcast with it are
+// journaled under this chain and loop.
+func WithOrigin(ctx context.Context, chainId uint64, loop string) context.Context {
+	return context.WithValue(ctx, originKey{}, Origin{ChainId: chainId, Loop: loop})
+}
+
+func OriginFromContext(ctx context.Context) Origin {
+	if origin, ok := ctx.Value(originKey{}).(Origin); ok {
+		return origin
+	}
+	return Origin{Loop: LoopAPI}
+}
+
+// Entry is a journaled group of msgs queued for a single broadcast.
+type Entry struct {
+	ID       string            `json:"id"`
+	ChainId  uint64            `json:"chain_id"`
+	Loop     string            `json:"loop"`
+	Msgs     []json.RawMessage `json:"msgs"`
+	QueuedAt int64             `json:"queued_at"`
+	Attempts int               `json:"attempts"`
+}
+
+// PendingEntry is a journaled entry with its msgs decoded, ready to be replayed.
+type PendingEntry struct {
+	ID       string
+	Origin   Origin
+	Msgs     []sdk.Msg
+	QueuedAt time.Time
+}
+
+// Outbox journals the msgs queued for broadcast to Helios until they are included in a
+// tx or rejected, so that a restart does not lose pending confirms and claims.
+type Outbox struct {
+	path string
+	cdc  codec.Codec
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+// DefaultPath returns ~/.heliades/hyperion/outbox.json.
+func DefaultPath() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homePath, ".heliades", "hyperion", "outbox.json"), nil
+}
+
+// New loads the journal at path. The codec must know every msg type that is queued.
+func New(path string, cdc codec.Codec) (*Outbox, error) {
+	o := &Outbox{
+		path:    path,
+		cdc:     cdc,
+		entries: make(map[string]*Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return o, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read outbox")
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to decode outbox")
+	}
+	for _, entry := range entries {
+		o.entries[entry.ID] = entry
+	}
+
+	return o, nil
+}
+
+// Add journals the msgs and returns the id of their entry. Queuing the same msgs again,
+// as the loops do when they retry, reuses the existing entry.
+func (o *Outbox) Add(origin Origin, msgs []sdk.Msg) (string, error) {
+	encoded := make([]json.RawMessage, 0, len(msgs))
+	hasher := sha256.New()
+	for _, msg := range msgs {
+		bz, err := o.cdc.MarshalInterfaceJSON(msg)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to encode %s", sdk.MsgTypeURL(msg))
+		}
+		encoded = append(encoded, bz)
+		hasher.Write(bz)
+	}
+	id := hex.EncodeToString(hasher.Sum(nil))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if entry, ok := o.entries[id]; ok {
+		entry.Attempts++
+		return id, o.save()
+	}
+
+	o.entries[id] = &Entry{
+		ID:       id,
+		ChainId:  origin.ChainId,
+		Loop:     origin.Loop,
+		Msgs:     encoded,
+		QueuedAt: time.Now().Unix(),
+		Attempts: 1,
+	}
+	if err := o.save(); err != nil {
+		delete(o.entries, id)
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Remove drops an entry once its msgs were included in a tx or rejected by Helios.
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.entries[id]; !ok {
+		return nil
+	}
+	delete(o.entries, id)
+	return o.save()
+}
+
+// Pending returns the journaled entries, oldest first. Entries that can no longer be
+// decoded are dropped.
+func (o *Outbox) Pending() []PendingEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := make([]PendingEntry, 0, len(o.entries))
+	dropped := false
+	for id, entry := range o.entries {
+		msgs := make([]sdk.Msg, 0, len(entry.Msgs))
+		for _, bz := range entry.Msgs {
+			var msg sdk.Msg
+			if err := o.cdc.UnmarshalInterfaceJSON(bz, &msg); err != nil {
+				msgs = nil
+				break
+			}
+			msgs = append(msgs, msg)
+		}
+		if msgs == nil {
+			delete(o.entries, id)
+			dropped = true
+			continue
+		}
+		pending = append(pending, PendingEntry{
+			ID:       entry.ID,
+			Origin:   Origin{ChainId: entry.ChainId, Loop: entry.Loop},
+			Msgs:     msgs,
+			QueuedAt: time.Unix(entry.QueuedAt, 0),
+		})
+	}
+	if dropped {
+		o.save()
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].QueuedAt.Before(pending[j].QueuedAt)
+	})
+
+	return pending
+}
+
+// Stats returns the depth of the outbox and the age of its oldest entry, in total and
+// per chain and loop.
+func (o *Outbox) Stats() map[string]interface{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	oldest := int64(0)
+	msgsCount := 0
+	byOrigin := make(map[string]map[string]interface{})
+	for _, entry := range o.entries {
+		age := now.Unix() - entry.QueuedAt
+		if age > oldest {
+			oldest = age
+		}
+		msgsCount += len(entry.Msgs)
+
+		key := fmt.Sprintf("%d/%s", entry.ChainId, entry.Loop)
+		origin, ok := byOrigin[key]
+		if !ok {
+			origin = map[string]interface{}{
+				"chain_id":           entry.ChainId,
+				"loop":               entry.Loop,
+				"depth":              0,
+				"oldest_age_seconds": int64(0),
+				"max_attempts":       0,
+			}
+			byOrigin[key] = origin
+		}
+		origin["depth"] = origin["depth"].(int) + 1
+		if age > origin["oldest_age_seconds"].(int64) {
+			origin["oldest_age_seconds"] = age
+		}
+		if entry.Attempts > origin["max_attempts"].(int) {
+			origin["max_attempts"] = entry.Attempts
+		}
+	}
+
+	origins := make([]map[string]interface{}, 0, len(byOrigin))
+	for _, origin := range byOrigin {
+		origins = append(origins, origin)
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		return origins[i]["oldest_age_seconds"].(int64) > origins[j]["oldest_age_seconds"].(int64)
+	})
+
+	return map[string]interface{}{
+		"depth":              len(o.entries),
+		"msgs":               msgsCount,
+		"oldest_age_seconds": oldest,
+		"origins":            origins,
+	}
+}
+
+// ChainStats returns the depth and oldest entry age for a single chain.
+func (o *Outbox) ChainStats(chainId uint64) (int, int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	depth := 0
+	oldest := int64(0)
+	now := time.Now().Unix()
+	for _, entry := range o.entries {
+		if entry.ChainId != chainId {
+			continue
+		}
+		depth++
+		if age := now - entry.QueuedAt; age > oldest {
+			oldest = age
+		}
+	}
+	return depth, oldest
+}
+
+// save writes the journal to a temporary file and renames it so that a crash never
+// leaves a truncated outbox behind.
+func (o *Outbox) save() error {
+	entries := make([]*Entry, 0, len(o.entries))
+	for _, entry := range o.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].QueuedAt < entries[j].QueuedAt
+	})
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create outbox dir")
+	}
+
+	tmpPath := o.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return errors.Wrap(err, "failed to write outbox")
+	}
+	if err := os.Rename(tmpPath, o.path); err != nil {
+		return errors.Wrap(err, "failed to write outbox")
+	}
+
+	return nil
+}
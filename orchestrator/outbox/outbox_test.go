@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+func newTestCodec() codec.Codec {
+	registry := codectypes.NewInterfaceRegistry()
+	hyperiontypes.RegisterInterfaces(registry)
+	return codec.NewProtoCodec(registry)
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	cdc := newTestCodec()
+
+	o, err := New(path, cdc)
+	assert.NoError(t, err)
+
+	ctx := WithOrigin(context.Background(), 42, "signer")
+	msgs := []sdk.Msg{&hyperiontypes.MsgValsetConfirm{HyperionId: 1, Nonce: 7, Orchestrator: "orchestrator"}}
+
+	id, err := o.Add(OriginFromContext(ctx), msgs)
+	assert.NoError(t, err)
+	// a retry of the same msgs reuses the entry
+	sameId, err := o.Add(OriginFromContext(ctx), msgs)
+	assert.NoError(t, err)
+	assert.Equal(t, id, sameId)
+
+	depth, _ := o.ChainStats(42)
+	assert.Equal(t, 1, depth)
+
+	reopened, err := New(path, cdc)
+	assert.NoError(t, err)
+	pending := reopened.Pending()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, Origin{ChainId: 42, Loop: "signer"}, pending[0].Origin)
+	confirm, ok := pending[0].Msgs[0].(*hyperiontypes.MsgValsetConfirm)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), confirm.Nonce)
+
+	assert.NoError(t, reopened.Remove(id))
+	reopened, err = New(path, cdc)
+	assert.NoError(t, err)
+	assert.Len(t, reopened.Pending(), 0)
+
+	assert.Equal(t, Origin{Loop: LoopAPI}, OriginFromContext(context.Background()))
+}
@@ -0,0 +1,210 @@
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	log "github.com/xlab/suplog"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ratelimit"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+const (
+	outflowStageSign  = "sign"
+	outflowStageRelay = "relay"
+)
+
+// outflowLedgerMu serializes the signer and relayer of all chains on the ledger files.
+var outflowLedgerMu sync.Mutex
+
+// OutflowLimits returns the outflow_limits chain setting of the chain.
+func OutflowLimits(chainId uint64) ([]ratelimit.Limit, error) {
+	settings, err := storage.GetChainSettings(chainId)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.ParseLimits(settings["outflow_limits"])
+}
+
+// OutflowLedger returns the batches counted against the outflow limits of the chain and
+// the held batches.
+func OutflowLedger(chainId uint64) (*ratelimit.Ledger, error) {
+	outflowLedgerMu.Lock()
+	defer outflowLedgerMu.Unlock()
+
+	ledger := &ratelimit.Ledger{Outflows: []ratelimit.Outflow{}, Holds: []*ratelimit.Hold{}}
+	if _, err := storage.GetOutflowLedger(chainId, ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// ApproveHeldBatch lets a batch held by the outflow limits be signed and relayed, it is
+// counted against the limits once it goes through.
+func ApproveHeldBatch(chainId uint64, tokenContract string, batchNonce uint64) (*ratelimit.Ledger, error) {
+	outflowLedgerMu.Lock()
+	defer outflowLedgerMu.Unlock()
+
+	ledger := &ratelimit.Ledger{Outflows: []ratelimit.Outflow{}, Holds: []*ratelimit.Hold{}}
+	if _, err := storage.GetOutflowLedger(chainId, ledger); err != nil {
+		return nil, err
+	}
+	if err := ledger.Approve(tokenContract, batchNonce, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := storage.SaveOutflowLedger(chainId, ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// admitBatch counts the batch against the outflow limits of its token, it returns false
+// when the batch must not be signed or relayed at the given stage.
+func (s *Orchestrator) admitBatch(ctx context.Context, batch *hyperiontypes.OutgoingTxBatch, stage string) bool {
+	limits, err := OutflowLimits(s.cfg.ChainId)
+	if err != nil {
+		s.logger.WithError(err).Warningln("invalid outflow_limits in chain settings, batches are held")
+		return false
+	}
+	if len(limits) == 0 {
+		return true
+	}
+
+	tokenContract := gethcommon.HexToAddress(batch.TokenContract)
+	decimals, err := s.ethereum.TokenDecimals(ctx, tokenContract)
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to get decimals of", batch.TokenContract, "batch", batch.BatchNonce, "not admitted")
+		return false
+	}
+
+	amount := new(big.Float).Quo(new(big.Float).SetInt(batchOutflow(batch).BigInt()), new(big.Float).SetFloat64(math.Pow10(int(decimals))))
+	outflow := ratelimit.Outflow{TokenContract: tokenContract.Hex(), BatchNonce: batch.BatchNonce}
+	outflow.Amount, _ = amount.Float64()
+
+	usdKnown := false
+	if ratelimit.NeedsUSD(limits, outflow.TokenContract) && s.priceFeed != nil {
+		if price, err := s.priceFeed.QueryUSDPrice(tokenContract); err == nil && price > 0 {
+			outflow.USD = outflow.Amount * price
+			usdKnown = true
+		}
+	}
+
+	outflowLedgerMu.Lock()
+	ledger := &ratelimit.Ledger{Outflows: []ratelimit.Outflow{}, Holds: []*ratelimit.Hold{}}
+	if _, err := storage.GetOutflowLedger(s.cfg.ChainId, ledger); err != nil {
+		outflowLedgerMu.Unlock()
+		s.logger.WithError(err).Warningln("failed to load outflow ledger, batch", batch.BatchNonce, "not admitted")
+		return false
+	}
+	now := time.Now()
+	admitted, reason := ledger.Admit(limits, outflow, usdKnown, stage, now)
+	ledger.Prune(limits, now)
+	err = storage.SaveOutflowLedger(s.cfg.ChainId, ledger)
+	held := len(ledger.Pending())
+	outflowLedgerMu.Unlock()
+
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to save outflow ledger, batch", batch.BatchNonce, "not admitted")
+		return false
+	}
+
+	s.HyperionState.HeldBatchCount = held
+	if !admitted {
+		s.logger.WithFields(log.Fields{
+			"batch_nonce":    batch.BatchNonce,
+			"token_contract": batch.TokenContract,
+			"amount":         outflow.Amount,
+			"stage":          stage,
+			"reason":         reason,
+		}).Warningln("batch held by outflow limits, waiting for operator approval")
+	}
+	return admitted
+}
+
+// settleOutflows drops the holds of the batches that left Helios and releases the outflow
+// of the batches that will never execute. The pending batches are read before the last
+// executed nonces: a batch gone from Helios once executed is then seen executed.
+func (s *Orchestrator) settleOutflows(ctx context.Context) error {
+	ledger, err := OutflowLedger(s.cfg.ChainId)
+	if err != nil {
+		return err
+	}
+	if ledger.Empty() {
+		return nil
+	}
+
+	batches, err := s.GetHelios().LatestTransactionBatches(ctx, s.cfg.HyperionId)
+	if err != nil {
+		return err
+	}
+	pending := make([]ratelimit.Batch, 0, len(batches))
+	for _, batch := range batches {
+		pending = append(pending, ratelimit.Batch{TokenContract: batch.TokenContract, BatchNonce: batch.BatchNonce})
+	}
+
+	lastExecuted := make(map[string]uint64)
+	for _, outflow := range ledger.Outflows {
+		tokenContract := gethcommon.HexToAddress(outflow.TokenContract)
+		if _, ok := lastExecuted[tokenContract.Hex()]; ok {
+			continue
+		}
+		nonce, err := s.ethereum.GetTxBatchNonce(ctx, tokenContract)
+		if err != nil {
+			return err
+		}
+		lastExecuted[tokenContract.Hex()] = nonce.Uint64()
+	}
+
+	outflowLedgerMu.Lock()
+	defer outflowLedgerMu.Unlock()
+	// reloaded, the batches admitted or held meanwhile were pending after the batches were read
+	known := ledger
+	ledger = &ratelimit.Ledger{Outflows: []ratelimit.Outflow{}, Holds: []*ratelimit.Hold{}}
+	if _, err := storage.GetOutflowLedger(s.cfg.ChainId, ledger); err != nil {
+		return err
+	}
+	for _, outflow := range ledger.Outflows {
+		if !known.Counted(outflow.TokenContract, outflow.BatchNonce) {
+			pending = append(pending, ratelimit.Batch{TokenContract: outflow.TokenContract, BatchNonce: outflow.BatchNonce})
+		}
+	}
+	for _, hold := range ledger.Holds {
+		if known.Held(hold.TokenContract, hold.BatchNonce) == nil {
+			pending = append(pending, ratelimit.Batch{TokenContract: hold.TokenContract, BatchNonce: hold.BatchNonce})
+		}
+	}
+	ledger.Settle(pending, lastExecuted)
+	if err := storage.SaveOutflowLedger(s.cfg.ChainId, ledger); err != nil {
+		return err
+	}
+	s.HyperionState.HeldBatchCount = len(ledger.Pending())
+	return nil
+}
+
+// batchOutflow sums the amounts and fees paid out by the batch in its token.
+func batchOutflow(batch *hyperiontypes.OutgoingTxBatch) sdkmath.Int {
+	total := sdkmath.ZeroInt()
+	for _, tx := range batch.Transactions {
+		if tx.Token != nil {
+			total = total.Add(tx.Token.Amount)
+		}
+		if tx.Fee != nil && (tx.Fee.Contract == "" || strings.EqualFold(tx.Fee.Contract, batch.TokenContract)) {
+			total = total.Add(tx.Fee.Amount)
+		}
+	}
+	return total
+}
+
+func heldBatchStatus(batch *hyperiontypes.OutgoingTxBatch, symbol string) string {
+	return "batch " + strconv.Itoa(int(batch.BatchNonce)) + " " + symbol + " held (outflow limit)"
+}
@@ -0,0 +1,317 @@
+package paramsdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+// Change is a field of the counterparty chain params modified by a msg.
+type Change struct {
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+	Risky    bool   `json:"risky"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Diff is the change of the counterparty chain params of a chain applied by a msg.
+type Diff struct {
+	MsgType string `json:"msg_type"`
+	ChainId uint64 `json:"chain_id"`
+	// NewChain is true when the chain has no params yet, every field is then a change
+	NewChain bool     `json:"new_chain"`
+	Changes  []Change `json:"changes"`
+	Risky    bool     `json:"risky"`
+}
+
+// ChainIdOf returns the chain whose params the msg modifies, false when the msg does not
+// modify the counterparty chain params.
+func ChainIdOf(msg sdk.Msg) (uint64, bool) {
+	switch m := msg.(type) {
+	case *hyperiontypes.MsgAddCounterpartyChainParams:
+		if m.CounterpartyChainParams == nil {
+			return 0, false
+		}
+		return m.CounterpartyChainParams.BridgeChainId, true
+	case *hyperiontypes.MsgUpdateChainSmartContract:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUpdateAverageCounterpartyBlockTime:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUpdateAverageBlockTime:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUpdateOutTxTimeout:
+		return m.ChainId, true
+	case *hyperiontypes.MsgSetMinCallExternalDataGas:
+		return m.ChainId, true
+	case *hyperiontypes.MsgSetValsetReward:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUpdateChainName:
+		return m.ChainId, true
+	case *hyperiontypes.MsgChangeInitializer:
+		return m.ChainId, true
+	case *hyperiontypes.MsgPauseChain:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUnpauseChain:
+		return m.ChainId, true
+	case *hyperiontypes.MsgUpdateDefaultToken:
+		return m.ChainId, true
+	case *hyperiontypes.MsgRemoveTokenFromChain:
+		return m.ChainId, true
+	}
+	return 0, false
+}
+
+// Apply returns the params of the chain once the msg is executed. current is nil when the
+// chain has no params yet, it is not modified.
+func Apply(current *hyperiontypes.CounterpartyChainParams, msg sdk.Msg) (*hyperiontypes.CounterpartyChainParams, error) {
+	if m, ok := msg.(*hyperiontypes.MsgAddCounterpartyChainParams); ok {
+		if m.CounterpartyChainParams == nil {
+			return nil, fmt.Errorf("MsgAddCounterpartyChainParams without params")
+		}
+		return m.CounterpartyChainParams, nil
+	}
+
+	chainId, ok := ChainIdOf(msg)
+	if !ok {
+		return nil, fmt.Errorf("%s does not modify the counterparty chain params", sdk.MsgTypeURL(msg))
+	}
+	if current == nil {
+		return nil, fmt.Errorf("chain %d has no counterparty chain params", chainId)
+	}
+
+	proposed := *current
+	proposed.DefaultTokens = append([]*hyperiontypes.TokenAddressToDenomWithGenesisInfos{}, current.DefaultTokens...)
+
+	switch m := msg.(type) {
+	case *hyperiontypes.MsgUpdateChainSmartContract:
+		proposed.BridgeCounterpartyAddress = m.BridgeContractAddress
+		proposed.BridgeContractStartHeight = m.BridgeContractStartHeight
+		proposed.ContractSourceHash = m.ContractSourceHash
+		if m.FirstOrchestratorAddress != "" {
+			proposed.Initializer = m.FirstOrchestratorAddress
+		}
+	case *hyperiontypes.MsgUpdateAverageCounterpartyBlockTime:
+		proposed.AverageCounterpartyBlockTime = m.AverageCounterpartyBlockTime
+	case *hyperiontypes.MsgUpdateAverageBlockTime:
+		proposed.AverageBlockTime = m.AverageBlockTime
+	case *hyperiontypes.MsgUpdateOutTxTimeout:
+		proposed.TargetOutgoingTxTimeout = m.TargetOutgoingTxTimeout
+		proposed.TargetBatchTimeout = m.TargetBatchTimeout
+	case *hyperiontypes.MsgSetMinCallExternalDataGas:
+		proposed.MinCallExternalDataGas = m.MinCallExternalDataGas
+	case *hyperiontypes.MsgSetValsetReward:
+		proposed.ValsetReward = sdk.Coin{Denom: m.TokenAddress, Amount: m.Amount}
+	case *hyperiontypes.MsgUpdateChainName:
+		proposed.BridgeChainName = m.Name
+	case *hyperiontypes.MsgChangeInitializer:
+		proposed.Initializer = m.NewInitializer
+	case *hyperiontypes.MsgPauseChain:
+		proposed.Paused = true
+	case *hyperiontypes.MsgUnpauseChain:
+		proposed.Paused = false
+	case *hyperiontypes.MsgUpdateDefaultToken:
+		token := &hyperiontypes.TokenAddressToDenomWithGenesisInfos{
+			TokenAddressToDenom: &hyperiontypes.TokenAddressToDenom{
+				TokenAddress:       m.TokenAddress,
+				Denom:              m.Denom,
+				Symbol:             m.Symbol,
+				Decimals:           m.Decimals,
+				IsCosmosOriginated: m.IsCosmosOriginated,
+				IsConcensusToken:   m.IsConcensusToken,
+			},
+		}
+		replaced := false
+		for i, existing := range proposed.DefaultTokens {
+			if existing.TokenAddressToDenom != nil && existing.TokenAddressToDenom.Denom == m.Denom {
+				token.DefaultHolders = existing.DefaultHolders
+				token.Logo = existing.Logo
+				proposed.DefaultTokens[i] = token
+				replaced = true
+			}
+		}
+		if !replaced {
+			proposed.DefaultTokens = append(proposed.DefaultTokens, token)
+		}
+	case *hyperiontypes.MsgRemoveTokenFromChain:
+		tokens := make([]*hyperiontypes.TokenAddressToDenomWithGenesisInfos, 0, len(proposed.DefaultTokens))
+		for _, existing := range proposed.DefaultTokens {
+			if existing.TokenAddressToDenom == nil || existing.TokenAddressToDenom.Denom != m.Denom {
+				tokens = append(tokens, existing)
+			}
+		}
+		proposed.DefaultTokens = tokens
+	}
+
+	return &proposed, nil
+}
+
+// DiffMsg returns the diff of the params of the chain applied by the msg.
+func DiffMsg(current *hyperiontypes.CounterpartyChainParams, msg sdk.Msg) (*Diff, error) {
+	chainId, ok := ChainIdOf(msg)
+	if !ok {
+		return nil, fmt.Errorf("%s does not modify the counterparty chain params", sdk.MsgTypeURL(msg))
+	}
+	proposed, err := Apply(current, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{
+		MsgType:  sdk.MsgTypeURL(msg),
+		ChainId:  chainId,
+		NewChain: current == nil,
+		Changes:  Compare(current, proposed),
+	}
+	for _, change := range diff.Changes {
+		diff.Risky = diff.Risky || change.Risky
+	}
+	return diff, nil
+}
+
+// Compare returns the fields that differ between the current and proposed params, current
+// is nil for a new chain. A change is risky when it moves the contract the orchestrators
+// follow, rewinds its start height or remaps a token.
+func Compare(current, proposed *hyperiontypes.CounterpartyChainParams) []Change {
+	if current == nil {
+		current = &hyperiontypes.CounterpartyChainParams{}
+	}
+	isNew := current.BridgeChainId == 0
+
+	changes := make([]Change, 0)
+	add := func(field string, currentValue, proposedValue interface{}) *Change {
+		c, p := format(currentValue), format(proposedValue)
+		if c == p {
+			return nil
+		}
+		changes = append(changes, Change{Field: field, Current: c, Proposed: p})
+		return &changes[len(changes)-1]
+	}
+
+	add("hyperion_id", current.HyperionId, proposed.HyperionId)
+	add("bridge_chain_id", current.BridgeChainId, proposed.BridgeChainId)
+	add("bridge_chain_name", current.BridgeChainName, proposed.BridgeChainName)
+	add("bridge_chain_type", current.BridgeChainType, proposed.BridgeChainType)
+
+	if change := add("bridge_counterparty_address", current.BridgeCounterpartyAddress, proposed.BridgeCounterpartyAddress); change != nil && !isNew {
+		change.Risky = true
+		change.Reason = "the orchestrators will follow another contract"
+	}
+	if change := add("bridge_contract_start_height", current.BridgeContractStartHeight, proposed.BridgeContractStartHeight); change != nil && !isNew && proposed.BridgeContractStartHeight < current.BridgeContractStartHeight {
+		change.Risky = true
+		change.Reason = "start height rewind, already observed events could be replayed"
+	}
+	if change := add("contract_source_hash", current.ContractSourceHash, proposed.ContractSourceHash); change != nil && !isNew {
+		change.Risky = true
+		change.Reason = "the contract bytecode changes"
+	}
+	if change := add("initializer", current.Initializer, proposed.Initializer); change != nil && !isNew {
+		change.Risky = true
+		change.Reason = "the contract initializer changes"
+	}
+
+	add("average_block_time", current.AverageBlockTime, proposed.AverageBlockTime)
+	add("average_counterparty_block_time", current.AverageCounterpartyBlockTime, proposed.AverageCounterpartyBlockTime)
+	add("target_batch_timeout", current.TargetBatchTimeout, proposed.TargetBatchTimeout)
+	add("target_outgoing_tx_timeout", current.TargetOutgoingTxTimeout, proposed.TargetOutgoingTxTimeout)
+	add("signed_valsets_window", current.SignedValsetsWindow, proposed.SignedValsetsWindow)
+	add("signed_batches_window", current.SignedBatchesWindow, proposed.SignedBatchesWindow)
+	add("signed_claims_window", current.SignedClaimsWindow, proposed.SignedClaimsWindow)
+	add("unbond_slashing_valsets_window", current.UnbondSlashingValsetsWindow, proposed.UnbondSlashingValsetsWindow)
+
+	add("valset_reward", coinString(current.ValsetReward), coinString(proposed.ValsetReward))
+	add("min_call_external_data_gas", current.MinCallExternalDataGas, proposed.MinCallExternalDataGas)
+	add("slash_fraction_valset", decString(current.SlashFractionValset), decString(proposed.SlashFractionValset))
+	add("slash_fraction_batch", decString(current.SlashFractionBatch), decString(proposed.SlashFractionBatch))
+	add("slash_fraction_claim", decString(current.SlashFractionClaim), decString(proposed.SlashFractionClaim))
+	add("slash_fraction_conflicting_claim", decString(current.SlashFractionConflictingClaim), decString(proposed.SlashFractionConflictingClaim))
+	add("slash_fraction_bad_eth_signature", decString(current.SlashFractionBadEthSignature), decString(proposed.SlashFractionBadEthSignature))
+
+	add("offset_valset_nonce", current.OffsetValsetNonce, proposed.OffsetValsetNonce)
+	add("paused", current.Paused, proposed.Paused)
+
+	changes = append(changes, compareTokens(current.DefaultTokens, proposed.DefaultTokens, isNew)...)
+	return changes
+}
+
+// compareTokens diffs the default tokens by denom, a denom moved to another token address
+// is risky.
+func compareTokens(current, proposed []*hyperiontypes.TokenAddressToDenomWithGenesisInfos, isNew bool) []Change {
+	currentByDenom := tokensByDenom(current)
+	proposedByDenom := tokensByDenom(proposed)
+
+	denoms := make([]string, 0, len(currentByDenom)+len(proposedByDenom))
+	for denom := range currentByDenom {
+		denoms = append(denoms, denom)
+	}
+	for denom := range proposedByDenom {
+		if _, ok := currentByDenom[denom]; !ok {
+			denoms = append(denoms, denom)
+		}
+	}
+	sort.Strings(denoms)
+
+	changes := make([]Change, 0)
+	for _, denom := range denoms {
+		c, p := currentByDenom[denom], proposedByDenom[denom]
+		cs, ps := tokenString(c), tokenString(p)
+		if cs == ps {
+			continue
+		}
+		change := Change{Field: "default_tokens[" + denom + "]", Current: cs, Proposed: ps}
+		if !isNew && c != nil && p != nil && !strings.EqualFold(c.TokenAddress, p.TokenAddress) {
+			change.Risky = true
+			change.Reason = "the denom is mapped to another token address"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func tokensByDenom(tokens []*hyperiontypes.TokenAddressToDenomWithGenesisInfos) map[string]*hyperiontypes.TokenAddressToDenom {
+	byDenom := make(map[string]*hyperiontypes.TokenAddressToDenom, len(tokens))
+	for _, token := range tokens {
+		if token != nil && token.TokenAddressToDenom != nil {
+			byDenom[token.TokenAddressToDenom.Denom] = token.TokenAddressToDenom
+		}
+	}
+	return byDenom
+}
+
+func tokenString(token *hyperiontypes.TokenAddressToDenom) string {
+	if token == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s decimals=%d cosmos_originated=%t consensus=%t", token.TokenAddress, token.Symbol, token.Decimals, token.IsCosmosOriginated, token.IsConcensusToken)
+}
+
+func coinString(coin sdk.Coin) string {
+	if coin.Amount.IsNil() {
+		return coin.Denom
+	}
+	return coin.Amount.String() + coin.Denom
+}
+
+func decString(dec math.LegacyDec) string {
+	if dec.IsNil() {
+		return ""
+	}
+	return dec.String()
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case uint64:
+		if v == 0 {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,104 @@
+package paramsdiff
+
+import (
+	"testing"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+func liveParams() *hyperiontypes.CounterpartyChainParams {
+	return &hyperiontypes.CounterpartyChainParams{
+		HyperionId:                   11155111,
+		BridgeChainId:                11155111,
+		BridgeChainName:              "Sepolia",
+		BridgeCounterpartyAddress:    "0x1111111111111111111111111111111111111111",
+		BridgeContractStartHeight:    5000,
+		AverageCounterpartyBlockTime: 12000,
+		DefaultTokens: []*hyperiontypes.TokenAddressToDenomWithGenesisInfos{
+			{TokenAddressToDenom: &hyperiontypes.TokenAddressToDenom{Denom: "ahelios", TokenAddress: "0xaaaa", Symbol: "HLS", Decimals: 18}},
+		},
+	}
+}
+
+func findChange(diff *Diff, field string) *Change {
+	for i := range diff.Changes {
+		if diff.Changes[i].Field == field {
+			return &diff.Changes[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffMsg(t *testing.T) {
+	t.Run("block time update is not risky", func(t *testing.T) {
+		diff, err := DiffMsg(liveParams(), &hyperiontypes.MsgUpdateAverageCounterpartyBlockTime{ChainId: 11155111, AverageCounterpartyBlockTime: 2000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diff.Changes) != 1 || diff.Changes[0].Field != "average_counterparty_block_time" || diff.Risky {
+			t.Fatalf("unexpected diff %+v", diff)
+		}
+		if diff.Changes[0].Current != "12000" || diff.Changes[0].Proposed != "2000" {
+			t.Fatalf("unexpected change %+v", diff.Changes[0])
+		}
+	})
+
+	t.Run("contract update rewinding the start height is risky", func(t *testing.T) {
+		diff, err := DiffMsg(liveParams(), &hyperiontypes.MsgUpdateChainSmartContract{
+			ChainId:                   11155111,
+			BridgeContractAddress:     "0x2222222222222222222222222222222222222222",
+			BridgeContractStartHeight: 4000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.Risky {
+			t.Fatal("expected a risky diff")
+		}
+		for _, field := range []string{"bridge_counterparty_address", "bridge_contract_start_height"} {
+			if change := findChange(diff, field); change == nil || !change.Risky {
+				t.Fatalf("expected %s to be a risky change, got %+v", field, change)
+			}
+		}
+	})
+
+	t.Run("start height moving forward is not risky", func(t *testing.T) {
+		diff, err := DiffMsg(liveParams(), &hyperiontypes.MsgUpdateChainSmartContract{
+			ChainId:                   11155111,
+			BridgeContractAddress:     "0x1111111111111111111111111111111111111111",
+			BridgeContractStartHeight: 6000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff.Risky {
+			t.Fatalf("unexpected risky diff %+v", diff)
+		}
+	})
+
+	t.Run("token remapped to another address is risky", func(t *testing.T) {
+		diff, err := DiffMsg(liveParams(), &hyperiontypes.MsgUpdateDefaultToken{ChainId: 11155111, Denom: "ahelios", TokenAddress: "0xbbbb", Symbol: "HLS", Decimals: 18})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if change := findChange(diff, "default_tokens[ahelios]"); change == nil || !change.Risky {
+			t.Fatalf("expected a risky token change, got %+v", diff.Changes)
+		}
+	})
+
+	t.Run("new chain is not risky", func(t *testing.T) {
+		diff, err := DiffMsg(nil, &hyperiontypes.MsgAddCounterpartyChainParams{CounterpartyChainParams: liveParams()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.NewChain || diff.Risky || findChange(diff, "bridge_counterparty_address") == nil {
+			t.Fatalf("unexpected diff %+v", diff)
+		}
+	})
+
+	t.Run("msg not touching the params", func(t *testing.T) {
+		if _, err := DiffMsg(liveParams(), &hyperiontypes.MsgAddOneWhitelistedAddress{}); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
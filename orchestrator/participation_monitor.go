@@ -0,0 +1,337 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
+	"github.com/Helios-Chain-Labs/metrics"
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+)
+
+const (
+	defaultParticipationMonitorLoopDur = 1 * time.Minute
+
+	// the monitor warns once less than this fraction of a signing window is left
+	participationRiskFraction = 4
+
+	// maxParticipationRecords bounds the confirmations kept in the participation report
+	maxParticipationRecords = 200
+)
+
+// participationRecord follows a valset or batch from its creation on Helios until we
+// confirmed it.
+type participationRecord struct {
+	Kind          string    `json:"kind"`
+	Nonce         uint64    `json:"nonce"`
+	TokenContract string    `json:"token_contract,omitempty"`
+	CreatedHeight uint64    `json:"created_height"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	Confirmed     bool      `json:"confirmed"`
+	// ConfirmedHeight is the Helios height at which the monitor first saw our confirmation
+	ConfirmedHeight uint64 `json:"confirmed_height,omitempty"`
+	// ConfirmBlocks is only measured for the records seen before they were confirmed
+	ConfirmBlocks   uint64 `json:"confirm_blocks,omitempty"`
+	confirmMeasured bool
+	Missed          bool `json:"missed"`
+	AtRisk          bool `json:"at_risk"`
+	warned          bool
+}
+
+func (r *participationRecord) key() string {
+	return fmt.Sprintf("%s/%s/%d", r.Kind, strings.ToLower(r.TokenContract), r.Nonce)
+}
+
+// participationMonitor tracks the valsets and batches we confirmed against those created
+// on Helios, and the claims we still owe, to warn before the signing windows run out.
+type participationMonitor struct {
+	mu sync.Mutex
+
+	records map[string]*participationRecord
+	height  uint64
+
+	lastObservedEventNonce uint64
+	lastClaimedEventNonce  uint64
+	// missingClaimsSinceHeight is the Helios height at which our claims fell behind
+	missingClaimsSinceHeight uint64
+	claimsWarned             bool
+
+	updatedAt time.Time
+}
+
+func (s *Orchestrator) runParticipationMonitor(ctx context.Context) error {
+	monitor := s.participation
+	s.logger.WithField("loop_duration", defaultParticipationMonitorLoopDur.String()).Debugln("starting ParticipationMonitor...")
+
+	return loops.RunLoop(ctx, s.ethereum, defaultParticipationMonitorLoopDur, func() error {
+		if s.heliosSyncing(ctx, "participation_monitor") {
+			return nil
+		}
+		return s.updateParticipation(ctx, monitor)
+	})
+}
+
+func (s *Orchestrator) updateParticipation(ctx context.Context, monitor *participationMonitor) error {
+	metrics.ReportFuncCall(s.svcTags)
+	doneFn := metrics.ReportFuncTiming(s.svcTags)
+	defer doneFn()
+
+	helios := s.GetHelios()
+	params := s.chainParams()
+
+	latestHeight, err := helios.GetLatestBlockHeight(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest helios block height")
+	}
+	height := uint64(latestHeight)
+
+	valsets, err := helios.LatestValsets(ctx, s.cfg.HyperionId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest valsets")
+	}
+	valsetConfirmed := make(map[uint64]bool, len(valsets))
+	for _, valset := range valsets {
+		confirms, err := helios.AllValsetConfirms(ctx, s.cfg.HyperionId, valset.Nonce)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get confirms of valset %d", valset.Nonce)
+		}
+		valsetConfirmed[valset.Nonce] = s.hasValsetConfirm(confirms)
+	}
+
+	batches, err := helios.LatestTransactionBatches(ctx, s.cfg.HyperionId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest batches")
+	}
+	batchConfirmed := make(map[*hyperiontypes.OutgoingTxBatch]bool, len(batches))
+	for _, batch := range batches {
+		confirms, err := helios.TransactionBatchSignatures(ctx, s.cfg.HyperionId, batch.BatchNonce, gethcommon.HexToAddress(batch.TokenContract))
+		if err != nil {
+			return errors.Wrapf(err, "failed to get confirms of batch %d", batch.BatchNonce)
+		}
+		batchConfirmed[batch] = s.hasBatchConfirm(confirms)
+	}
+
+	lastObservedEventNonce, err := helios.QueryGetLastObservedEventNonce(ctx, s.cfg.HyperionId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get last observed event nonce")
+	}
+	lastClaim, err := helios.LastClaimEventByAddr(ctx, s.cfg.HyperionId, s.cfg.CosmosAddr)
+	if err != nil {
+		return errors.Wrap(err, "failed to get our last claim")
+	}
+
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+
+	monitor.height = height
+	monitor.updatedAt = time.Now()
+
+	for _, valset := range valsets {
+		record := monitor.track(&participationRecord{Kind: "valset", Nonce: valset.Nonce, CreatedHeight: valset.Height})
+		monitor.update(record, valsetConfirmed[valset.Nonce], height, params.SignedValsetsWindow, s.logger)
+	}
+	for _, batch := range batches {
+		record := monitor.track(&participationRecord{Kind: "batch", Nonce: batch.BatchNonce, TokenContract: batch.TokenContract, CreatedHeight: batch.Block})
+		monitor.update(record, batchConfirmed[batch], height, params.SignedBatchesWindow, s.logger)
+	}
+
+	monitor.lastObservedEventNonce = lastObservedEventNonce
+	monitor.lastClaimedEventNonce = lastClaim.EthereumEventNonce
+	if lastClaim.EthereumEventNonce >= lastObservedEventNonce {
+		monitor.missingClaimsSinceHeight = 0
+		monitor.claimsWarned = false
+	} else {
+		if monitor.missingClaimsSinceHeight == 0 {
+			monitor.missingClaimsSinceHeight = height
+		}
+		if window := params.SignedClaimsWindow; window > 0 && !monitor.claimsWarned && atRisk(monitor.missingClaimsSinceHeight, height, window) {
+			monitor.claimsWarned = true
+			s.logger.WithFields(log.Fields{
+				"last_observed_event_nonce": lastObservedEventNonce,
+				"last_claimed_event_nonce":  lastClaim.EthereumEventNonce,
+				"blocks_left":               blocksLeft(monitor.missingClaimsSinceHeight, height, window),
+			}).Warningln("claims missing for observed events, the signed claims window is running out")
+		}
+	}
+
+	monitor.prune()
+
+	return nil
+}
+
+func (s *Orchestrator) hasValsetConfirm(confirms []*hyperiontypes.MsgValsetConfirm) bool {
+	for _, confirm := range confirms {
+		if confirm.Orchestrator == s.cfg.CosmosAddr.String() || strings.EqualFold(confirm.EthAddress, s.cfg.EthereumAddr.Hex()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Orchestrator) hasBatchConfirm(confirms []*hyperiontypes.MsgConfirmBatch) bool {
+	for _, confirm := range confirms {
+		if confirm.Orchestrator == s.cfg.CosmosAddr.String() || strings.EqualFold(confirm.EthSigner, s.cfg.EthereumAddr.Hex()) {
+			return true
+		}
+	}
+	return false
+}
+
+// track returns the known record of the valset or batch, or starts tracking it.
+func (m *participationMonitor) track(record *participationRecord) *participationRecord {
+	if known, ok := m.records[record.key()]; ok {
+		return known
+	}
+	record.FirstSeenAt = m.updatedAt
+	m.records[record.key()] = record
+	return record
+}
+
+func (m *participationMonitor) update(record *participationRecord, confirmed bool, height uint64, window uint64, logger log.Logger) {
+	if record.Confirmed {
+		return
+	}
+	if confirmed {
+		record.Confirmed = true
+		record.AtRisk = false
+		// a record confirmed before the monitor first saw it was confirmed at an unknown height
+		if !record.FirstSeenAt.Equal(m.updatedAt) {
+			record.ConfirmedHeight = height
+			record.ConfirmBlocks = height - min(height, record.CreatedHeight)
+			record.confirmMeasured = true
+		}
+		return
+	}
+	if window == 0 {
+		return
+	}
+
+	record.AtRisk = atRisk(record.CreatedHeight, height, window)
+	record.Missed = height > record.CreatedHeight+window
+	if record.AtRisk && !record.warned {
+		record.warned = true
+		logger.WithFields(log.Fields{
+			"kind":           record.Kind,
+			"nonce":          record.Nonce,
+			"token_contract": record.TokenContract,
+			"blocks_left":    blocksLeft(record.CreatedHeight, height, window),
+		}).Warningln(fmt.Sprintf("unconfirmed %s, the signing window is running out", record.Kind))
+	}
+}
+
+// prune drops the oldest confirmed records once the report holds too many.
+func (m *participationMonitor) prune() {
+	if len(m.records) <= maxParticipationRecords {
+		return
+	}
+	records := m.sortedRecords()
+	for _, record := range records[maxParticipationRecords:] {
+		if record.Confirmed || record.Missed {
+			delete(m.records, record.key())
+		}
+	}
+}
+
+// sortedRecords returns the records, newest first.
+func (m *participationMonitor) sortedRecords() []*participationRecord {
+	records := make([]*participationRecord, 0, len(m.records))
+	for _, record := range m.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedHeight > records[j].CreatedHeight
+	})
+	return records
+}
+
+func (s *Orchestrator) chainParams() hyperiontypes.CounterpartyChainParams {
+	if s.cfg.ChainParams == nil {
+		return hyperiontypes.CounterpartyChainParams{}
+	}
+	return *s.cfg.ChainParams
+}
+
+// atRisk tells whether less than 1/participationRiskFraction of the window is left.
+func atRisk(since, height, window uint64) bool {
+	return blocksLeft(since, height, window) < int64(window/participationRiskFraction)
+}
+
+func blocksLeft(since, height, window uint64) int64 {
+	return int64(since+window) - int64(height)
+}
+
+// ParticipationReport returns, for the chain, the valsets and batches created on Helios
+// against those we confirmed, and the claims we still owe.
+func (s *Orchestrator) ParticipationReport() map[string]interface{} {
+	m := s.participation
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	params := s.chainParams()
+	summary := func(kind string, window uint64) map[string]interface{} {
+		created, confirmed, missed, atRisk, measured := 0, 0, 0, 0, 0
+		confirmBlocks := uint64(0)
+		for _, record := range m.records {
+			if record.Kind != kind {
+				continue
+			}
+			created++
+			switch {
+			case record.Confirmed:
+				confirmed++
+				if record.confirmMeasured {
+					measured++
+					confirmBlocks += record.ConfirmBlocks
+				}
+			case record.Missed:
+				missed++
+			case record.AtRisk:
+				atRisk++
+			}
+		}
+		averageConfirmBlocks := float64(0)
+		if measured > 0 {
+			averageConfirmBlocks = float64(confirmBlocks) / float64(measured)
+		}
+		return map[string]interface{}{
+			"created":                created,
+			"confirmed":              confirmed,
+			"missed":                 missed,
+			"at_risk":                atRisk,
+			"average_confirm_blocks": averageConfirmBlocks,
+			"signed_window":          window,
+		}
+	}
+
+	missingClaims := uint64(0)
+	if m.lastObservedEventNonce > m.lastClaimedEventNonce {
+		missingClaims = m.lastObservedEventNonce - m.lastClaimedEventNonce
+	}
+	claims := map[string]interface{}{
+		"last_observed_event_nonce": m.lastObservedEventNonce,
+		"last_claimed_event_nonce":  m.lastClaimedEventNonce,
+		"missing":                   missingClaims,
+		"missing_since_height":      m.missingClaimsSinceHeight,
+		"at_risk":                   m.missingClaimsSinceHeight != 0 && params.SignedClaimsWindow > 0 && atRisk(m.missingClaimsSinceHeight, m.height, params.SignedClaimsWindow),
+		"signed_window":             params.SignedClaimsWindow,
+	}
+
+	return map[string]interface{}{
+		"chain_id":      s.cfg.ChainId,
+		"chain_name":    s.cfg.ChainName,
+		"helios_height": m.height,
+		"updated_at":    m.updatedAt.Unix(),
+		"valsets":       summary("valset", params.SignedValsetsWindow),
+		"batches":       summary("batch", params.SignedBatchesWindow),
+		"claims":        claims,
+		"records":       m.sortedRecords(),
+	}
+}
@@ -0,0 +1,41 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	log "github.com/xlab/suplog"
+)
+
+func TestParticipationRecords(t *testing.T) {
+	monitor := &participationMonitor{records: make(map[string]*participationRecord)}
+	logger := log.WithField("test", "participation")
+
+	// seen unconfirmed, then confirmed: the latency is measured
+	monitor.updatedAt = time.Unix(100, 0)
+	record := monitor.track(&participationRecord{Kind: "valset", Nonce: 1, CreatedHeight: 1000})
+	monitor.update(record, false, 1010, 100, logger)
+	assert.False(t, record.AtRisk)
+
+	monitor.updatedAt = time.Unix(160, 0)
+	record = monitor.track(&participationRecord{Kind: "valset", Nonce: 1, CreatedHeight: 1000})
+	monitor.update(record, true, 1020, 100, logger)
+	assert.True(t, record.Confirmed)
+	assert.True(t, record.confirmMeasured)
+	assert.Equal(t, uint64(20), record.ConfirmBlocks)
+
+	// already confirmed when first seen: the latency is unknown
+	late := monitor.track(&participationRecord{Kind: "batch", Nonce: 7, CreatedHeight: 900})
+	monitor.update(late, true, 1020, 100, logger)
+	assert.True(t, late.Confirmed)
+	assert.False(t, late.confirmMeasured)
+
+	// unconfirmed with less than a quarter of the window left, then past the window
+	risky := monitor.track(&participationRecord{Kind: "batch", Nonce: 8, CreatedHeight: 940})
+	monitor.update(risky, false, 1020, 100, logger)
+	assert.True(t, risky.AtRisk)
+	assert.False(t, risky.Missed)
+	monitor.update(risky, false, 1041, 100, logger)
+	assert.True(t, risky.Missed)
+}
@@ -0,0 +1,293 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AnyToken is the token contract of a limit applying to every token of the chain, each
+// token being counted separately.
+const AnyToken = "*"
+
+// Limit caps the outflow of a token over a rolling window. A zero cap is not enforced.
+type Limit struct {
+	TokenContract string  `json:"token_contract"`
+	WindowHours   float64 `json:"window_hours"`
+	// MaxAmount is counted in token units, the raw amount divided by 10^decimals
+	MaxAmount float64 `json:"max_amount,omitempty"`
+	MaxUSD    float64 `json:"max_usd,omitempty"`
+}
+
+func (l Limit) Validate() error {
+	if l.TokenContract == "" {
+		return errors.New("token_contract is required, use * for every token")
+	}
+	if l.WindowHours <= 0 {
+		return errors.Errorf("window_hours of %s must be positive", l.TokenContract)
+	}
+	if l.MaxAmount < 0 || l.MaxUSD < 0 {
+		return errors.Errorf("caps of %s must not be negative", l.TokenContract)
+	}
+	return nil
+}
+
+func (l Limit) matches(tokenContract string) bool {
+	return l.TokenContract == AnyToken || strings.EqualFold(l.TokenContract, tokenContract)
+}
+
+func (l Limit) window() time.Duration {
+	return time.Duration(l.WindowHours * float64(time.Hour))
+}
+
+// ParseLimits decodes the outflow_limits chain setting.
+func ParseLimits(setting interface{}) ([]Limit, error) {
+	if setting == nil {
+		return []Limit{}, nil
+	}
+	bz, err := json.Marshal(setting)
+	if err != nil {
+		return nil, err
+	}
+	limits := []Limit{}
+	if err := json.Unmarshal(bz, &limits); err != nil {
+		return nil, errors.Wrap(err, "outflow_limits must be a list of {token_contract, window_hours, max_amount, max_usd}")
+	}
+	for _, limit := range limits {
+		if err := limit.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return limits, nil
+}
+
+// NeedsUSD tells whether a limit of the token is counted in USD.
+func NeedsUSD(limits []Limit, tokenContract string) bool {
+	for _, limit := range limits {
+		if limit.matches(tokenContract) && limit.MaxUSD > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Outflow is a batch counted against the limits of its token.
+type Outflow struct {
+	TokenContract string    `json:"token_contract"`
+	BatchNonce    uint64    `json:"batch_nonce"`
+	Amount        float64   `json:"amount"`
+	USD           float64   `json:"usd"`
+	At            time.Time `json:"at"`
+	// Approved is set for a batch the operator let through over a cap
+	Approved bool `json:"approved,omitempty"`
+}
+
+// Hold is a batch exceeding a cap, it is neither signed nor relayed until approved.
+type Hold struct {
+	TokenContract string    `json:"token_contract"`
+	BatchNonce    uint64    `json:"batch_nonce"`
+	Amount        float64   `json:"amount"`
+	USD           float64   `json:"usd"`
+	Reason        string    `json:"reason"`
+	Stage         string    `json:"stage"`
+	HeldAt        time.Time `json:"held_at"`
+	Approved      bool      `json:"approved"`
+	ApprovedAt    time.Time `json:"approved_at,omitempty"`
+}
+
+// Ledger is the persisted outflow history of a chain with its holds.
+type Ledger struct {
+	Outflows []Outflow `json:"outflows"`
+	Holds    []*Hold   `json:"holds"`
+}
+
+func sameBatch(tokenA string, nonceA uint64, tokenB string, nonceB uint64) bool {
+	return nonceA == nonceB && strings.EqualFold(tokenA, tokenB)
+}
+
+// Counted tells whether the batch is counted against the limits.
+func (l *Ledger) Counted(tokenContract string, batchNonce uint64) bool {
+	for _, outflow := range l.Outflows {
+		if sameBatch(outflow.TokenContract, outflow.BatchNonce, tokenContract, batchNonce) {
+			return true
+		}
+	}
+	return false
+}
+
+// Held returns the hold of the batch, nil when it is not held.
+func (l *Ledger) Held(tokenContract string, batchNonce uint64) *Hold {
+	for _, hold := range l.Holds {
+		if sameBatch(hold.TokenContract, hold.BatchNonce, tokenContract, batchNonce) {
+			return hold
+		}
+	}
+	return nil
+}
+
+// Admit counts the batch against the limits of its token. A batch already counted, or
+// approved by the operator, is admitted; a batch exceeding a cap is held and the reason
+// returned. usdKnown is false when the USD value of the batch could not be priced, a USD
+// cap then holds the batch.
+func (l *Ledger) Admit(limits []Limit, outflow Outflow, usdKnown bool, stage string, now time.Time) (bool, string) {
+	if l.Counted(outflow.TokenContract, outflow.BatchNonce) {
+		return true, ""
+	}
+
+	hold := l.Held(outflow.TokenContract, outflow.BatchNonce)
+	if hold != nil && hold.Approved {
+		outflow.Approved = true
+		outflow.At = now
+		l.Outflows = append(l.Outflows, outflow)
+		return true, ""
+	}
+
+	reason := l.exceeded(limits, outflow, usdKnown, now)
+	if reason == "" {
+		outflow.At = now
+		l.Outflows = append(l.Outflows, outflow)
+		return true, ""
+	}
+
+	if hold == nil {
+		l.Holds = append(l.Holds, &Hold{
+			TokenContract: outflow.TokenContract,
+			BatchNonce:    outflow.BatchNonce,
+			Amount:        outflow.Amount,
+			USD:           outflow.USD,
+			Reason:        reason,
+			Stage:         stage,
+			HeldAt:        now,
+		})
+	}
+	return false, reason
+}
+
+func (l *Ledger) exceeded(limits []Limit, outflow Outflow, usdKnown bool, now time.Time) string {
+	for _, limit := range limits {
+		if !limit.matches(outflow.TokenContract) {
+			continue
+		}
+
+		since := now.Add(-limit.window())
+		amount, usd := outflow.Amount, outflow.USD
+		for _, counted := range l.Outflows {
+			if strings.EqualFold(counted.TokenContract, outflow.TokenContract) && counted.At.After(since) {
+				amount += counted.Amount
+				usd += counted.USD
+			}
+		}
+
+		if limit.MaxAmount > 0 && amount > limit.MaxAmount {
+			return fmt.Sprintf("%g tokens over %gh exceeds the cap of %g", amount, limit.WindowHours, limit.MaxAmount)
+		}
+		if limit.MaxUSD > 0 {
+			if !usdKnown {
+				return fmt.Sprintf("no USD price to check the cap of %g USD over %gh", limit.MaxUSD, limit.WindowHours)
+			}
+			if usd > limit.MaxUSD {
+				return fmt.Sprintf("%.2f USD over %gh exceeds the cap of %g USD", usd, limit.WindowHours, limit.MaxUSD)
+			}
+		}
+	}
+	return ""
+}
+
+// Approve lets a held batch through on its next sign or relay attempt.
+func (l *Ledger) Approve(tokenContract string, batchNonce uint64, now time.Time) error {
+	hold := l.Held(tokenContract, batchNonce)
+	if hold == nil {
+		return errors.Errorf("batch %d of %s is not held", batchNonce, tokenContract)
+	}
+	hold.Approved = true
+	hold.ApprovedAt = now
+	return nil
+}
+
+// Pending returns the holds not approved yet.
+func (l *Ledger) Pending() []*Hold {
+	pending := make([]*Hold, 0)
+	for _, hold := range l.Holds {
+		if !hold.Approved {
+			pending = append(pending, hold)
+		}
+	}
+	return pending
+}
+
+// Batch is a batch of a token by its nonce.
+type Batch struct {
+	TokenContract string
+	BatchNonce    uint64
+}
+
+// Empty tells whether the ledger counts no outflow and holds no batch.
+func (l *Ledger) Empty() bool {
+	return len(l.Outflows) == 0 && len(l.Holds) == 0
+}
+
+// Settle drops the holds of the batches no longer pending on Helios, timed out or
+// rebatched, and releases the outflows of the batches that left Helios without being
+// executed: above lastExecuted, the last batch nonce executed on the chain of each token.
+// An outflow of a token missing from lastExecuted is kept.
+func (l *Ledger) Settle(pending []Batch, lastExecuted map[string]uint64) {
+	isPending := func(tokenContract string, batchNonce uint64) bool {
+		for _, batch := range pending {
+			if sameBatch(batch.TokenContract, batch.BatchNonce, tokenContract, batchNonce) {
+				return true
+			}
+		}
+		return false
+	}
+	executed := make(map[string]uint64, len(lastExecuted))
+	for tokenContract, nonce := range lastExecuted {
+		executed[strings.ToLower(tokenContract)] = nonce
+	}
+
+	holds := make([]*Hold, 0, len(l.Holds))
+	for _, hold := range l.Holds {
+		if isPending(hold.TokenContract, hold.BatchNonce) {
+			holds = append(holds, hold)
+		}
+	}
+	l.Holds = holds
+
+	outflows := make([]Outflow, 0, len(l.Outflows))
+	for _, outflow := range l.Outflows {
+		lastNonce, known := executed[strings.ToLower(outflow.TokenContract)]
+		if known && outflow.BatchNonce > lastNonce && !isPending(outflow.TokenContract, outflow.BatchNonce) {
+			continue
+		}
+		outflows = append(outflows, outflow)
+	}
+	l.Outflows = outflows
+}
+
+// Prune drops the outflows older than the longest window, and the approved holds once
+// their batch was counted.
+func (l *Ledger) Prune(limits []Limit, now time.Time) {
+	longest := time.Duration(0)
+	for _, limit := range limits {
+		longest = max(longest, limit.window())
+	}
+
+	holds := make([]*Hold, 0, len(l.Holds))
+	for _, hold := range l.Holds {
+		if hold.Approved && l.Counted(hold.TokenContract, hold.BatchNonce) {
+			continue
+		}
+		holds = append(holds, hold)
+	}
+	l.Holds = holds
+
+	outflows := make([]Outflow, 0, len(l.Outflows))
+	for _, outflow := range l.Outflows {
+		if outflow.At.After(now.Add(-longest)) {
+			outflows = append(outflows, outflow)
+		}
+	}
+	l.Outflows = outflows
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmit(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	limits := []Limit{
+		{TokenContract: "0xAAAA", WindowHours: 24, MaxAmount: 1000},
+		{TokenContract: AnyToken, WindowHours: 1, MaxUSD: 5000},
+	}
+	ledger := &Ledger{}
+
+	admitted, _ := ledger.Admit(limits, Outflow{TokenContract: "0xaaaa", BatchNonce: 1, Amount: 600, USD: 600}, true, "sign", now)
+	assert.True(t, admitted)
+	// a batch is counted once, at sign or relay
+	admitted, _ = ledger.Admit(limits, Outflow{TokenContract: "0xaaaa", BatchNonce: 1, Amount: 600, USD: 600}, true, "relay", now)
+	assert.True(t, admitted)
+	assert.Len(t, ledger.Outflows, 1)
+
+	admitted, reason := ledger.Admit(limits, Outflow{TokenContract: "0xaaaa", BatchNonce: 2, Amount: 500, USD: 500}, true, "sign", now.Add(time.Hour))
+	assert.False(t, admitted)
+	assert.Contains(t, reason, "1100 tokens")
+	assert.Len(t, ledger.Pending(), 1)
+
+	// the window rolled over
+	admitted, _ = ledger.Admit(limits, Outflow{TokenContract: "0xaaaa", BatchNonce: 3, Amount: 500, USD: 500}, true, "sign", now.Add(25*time.Hour))
+	assert.True(t, admitted)
+
+	// the USD cap applies to every token, an unpriced batch is held
+	admitted, _ = ledger.Admit(limits, Outflow{TokenContract: "0xbbbb", BatchNonce: 1, Amount: 1, USD: 6000}, true, "relay", now)
+	assert.False(t, admitted)
+	admitted, reason = ledger.Admit(limits, Outflow{TokenContract: "0xbbbb", BatchNonce: 2, Amount: 1}, false, "relay", now)
+	assert.False(t, admitted)
+	assert.Contains(t, reason, "no USD price")
+
+	assert.NoError(t, ledger.Approve("0xAAAA", 2, now.Add(2*time.Hour)))
+	assert.Error(t, ledger.Approve("0xaaaa", 9, now))
+	admitted, _ = ledger.Admit(limits, Outflow{TokenContract: "0xaaaa", BatchNonce: 2, Amount: 500, USD: 500}, true, "sign", now.Add(2*time.Hour))
+	assert.True(t, admitted)
+	assert.Len(t, ledger.Pending(), 2)
+
+	ledger.Prune(limits, now.Add(26*time.Hour))
+	assert.Len(t, ledger.Outflows, 1)
+	assert.Len(t, ledger.Holds, 2)
+}
+
+func TestSettle(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	ledger := &Ledger{
+		Outflows: []Outflow{
+			{TokenContract: "0xaaaa", BatchNonce: 1, Amount: 100, At: now},
+			{TokenContract: "0xaaaa", BatchNonce: 3, Amount: 100, At: now},
+			{TokenContract: "0xaaaa", BatchNonce: 4, Amount: 100, At: now},
+			{TokenContract: "0xbbbb", BatchNonce: 9, Amount: 100, At: now},
+		},
+		Holds: []*Hold{
+			{TokenContract: "0xaaaa", BatchNonce: 5, HeldAt: now},
+			{TokenContract: "0xaaaa", BatchNonce: 6, HeldAt: now},
+		},
+	}
+
+	// batch 1 executed, batch 3 timed out, batch 4 is pending and the held batch 6 was rebatched
+	ledger.Settle([]Batch{{TokenContract: "0xAAAA", BatchNonce: 4}, {TokenContract: "0xAAAA", BatchNonce: 5}}, map[string]uint64{"0xAAAA": 2})
+	nonces := make([]uint64, 0)
+	for _, outflow := range ledger.Outflows {
+		nonces = append(nonces, outflow.BatchNonce)
+	}
+	assert.Equal(t, []uint64{1, 4, 9}, nonces)
+	assert.Len(t, ledger.Holds, 1)
+	assert.Equal(t, uint64(5), ledger.Holds[0].BatchNonce)
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits([]interface{}{map[string]interface{}{"token_contract": "*", "window_hours": 24.0, "max_usd": 100000.0}})
+	assert.NoError(t, err)
+	assert.Equal(t, []Limit{{TokenContract: AnyToken, WindowHours: 24, MaxUSD: 100000}}, limits)
+	assert.True(t, NeedsUSD(limits, "0xaaaa"))
+
+	_, err = ParseLimits([]interface{}{map[string]interface{}{"token_contract": "0xaaaa"}})
+	assert.Error(t, err)
+}
@@ -2,7 +2,6 @@ package orchestrator
 
 import (
 	"context"
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,10 +11,13 @@ import (
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/util"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
 	"github.com/Helios-Chain-Labs/metrics"
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 )
@@ -31,7 +33,6 @@ func (s *Orchestrator) runRelayer(ctx context.Context) error {
 
 	r := relayer{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "relayer"),
 	}
 	s.logger.WithFields(log.Fields{"loop_duration": defaultRelayerLoopDur.String(), "relay_token_batches": r.cfg.RelayBatches, "relay_validator_sets": s.cfg.RelayValsets}).Debugln("starting Relayer...")
 
@@ -40,48 +41,61 @@ func (s *Orchestrator) runRelayer(ctx context.Context) error {
 	defer ticker.Stop()
 
 	// Run first iteration immediately
-	if err := r.relay(ctx); err != nil {
-		s.logger.WithError(err).Errorln("relay function returned an error")
-	}
+	s.work("relayer", func() {
+		if err := r.relay(ctx); err != nil {
+			s.logger.WithError(err).Errorln("relay function returned an error")
+		}
+	})
 
 	for {
+		// the ticker is a fallback to the Helios events waking the relayer
 		select {
 		case <-ticker.C:
-			if s.HyperionState.RelayerStatus == "running" {
-				continue
-			}
+		case <-s.wakeups.relayer:
+			ticker.Reset(defaultRelayerLoopDur)
+		case <-ctx.Done():
+			return nil
+		}
+
+		if s.HyperionState.RelayerStatus == "running" {
+			continue
+		}
 
+		drained := !s.work("relayer", func() {
 			start := time.Now()
-			s.HyperionState.RelayerStatus = "running"
+			s.setStatus(LoopRelayer, "running")
 			if err := r.relay(ctx); err != nil {
 				s.logger.WithError(err).Errorln("relay function returned an error")
 			}
-			s.HyperionState.RelayerStatus = "idle"
+			s.setStatus(LoopRelayer, "idle")
 			s.HyperionState.RelayerLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 			s.HyperionState.RelayerNextExecutionTimestamp = uint64(start.Add(defaultRelayerLoopDur).Unix())
-		case <-ctx.Done():
-			return nil
+		})
+		if drained {
+			s.setStatus(LoopRelayer, DrainingStatus)
 		}
 	}
 }
 
 type relayer struct {
 	*Orchestrator
-	logEnabled bool
 }
 
 func (l *relayer) Log() log.Logger {
-	return l.logger.WithField("loop", "Relayer")
+	return l.logger.WithField("loop", LoopRelayer)
 }
 
-func (l *relayer) relay(ctx context.Context) error {
+func (l *relayer) relay(ctx context.Context) (err error) {
 	metrics.ReportFuncCall(l.svcTags)
 	doneFn := metrics.ReportFuncTiming(l.svcTags)
 	defer doneFn()
 
+	ctx, span := l.startSpan(ctx, LoopRelayer, "relayer.relay")
+	defer func() { tracing.End(span, err) }()
+
 	var pg loops.ParanoidGroup
 
-	if l.logEnabled {
+	if l.logEnabled(LoopRelayer) {
 		l.Log().Info("relaying getLatestEthValset")
 	}
 
@@ -128,12 +142,17 @@ type BatchAndSigs struct {
 	Sigs  []*hyperiontypes.MsgConfirmBatch
 }
 
-func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyperiontypes.Valset) (bool, error) {
+func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyperiontypes.Valset) (_ bool, err error) {
 	metrics.ReportFuncCall(l.svcTags)
 	doneFn := metrics.ReportFuncTiming(l.svcTags)
 	defer doneFn()
 
-	latestEthHeight, err := l.ethereum.GetHeaderByNumber(ctx, nil)
+	ctx, span := l.startSpan(ctx, LoopRelayer, "relayer.relayBatches")
+	defer func() { tracing.End(span, err) }()
+
+	rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemEthereum, "GetHeaderByNumber")
+	latestEthHeight, err := l.ethereum.GetHeaderByNumber(rpcCtx, nil)
+	tracing.End(rpcSpan, err)
 	if err != nil {
 		l.Log().Info("failed to get latest "+l.cfg.ChainName+" height", err)
 		return false, err
@@ -141,7 +160,9 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 
 	maxHeightTimeout := uint64(latestEthHeight.Number.Uint64() + 10)
 
-	batchesInHelios, err := l.GetHelios().LatestTransactionBatchesWithOptions(ctx, l.cfg.HyperionId, l.cfg.CosmosAddr.String(), 0, maxHeightTimeout, "", true)
+	rpcCtx, rpcSpan = tracing.StartRPC(ctx, tracing.SystemHelios, "LatestTransactionBatchesWithOptions")
+	batchesInHelios, err := l.GetHelios().LatestTransactionBatchesWithOptions(rpcCtx, l.cfg.HyperionId, l.cfg.CosmosAddr.String(), 0, maxHeightTimeout, "", true)
+	tracing.End(rpcSpan, err)
 
 	if err != nil {
 		l.Log().Info("failed to get latest transaction batches", err)
@@ -193,7 +214,9 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 		if _, ok := mapLatestBatchNonce[tokenContract]; ok {
 			continue
 		}
-		latestBatchNonce, err := l.ethereum.GetTxBatchNonce(ctx, gethcommon.HexToAddress(tokenContract))
+		rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemEthereum, "GetTxBatchNonce")
+		latestBatchNonce, err := l.ethereum.GetTxBatchNonce(rpcCtx, gethcommon.HexToAddress(tokenContract))
+		tracing.End(rpcSpan, err)
 		if err != nil {
 			continue
 		}
@@ -228,6 +251,15 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 		packSigned := make([]*BatchAndSigs, 0)
 
 		for _, batch := range batchs {
+			if l.screenBatch(batch, sanctionsStageRelay) {
+				l.Log().WithFields(log.Fields{"batch_nonce": batch.BatchNonce, "token_contract": batch.TokenContract}).Warningln("not relaying batch with sanctioned recipient")
+				continue
+			}
+			// the following batches of the token wait too, relaying them would invalidate the held one
+			if !l.admitBatch(ctx, batch, outflowStageRelay) {
+				l.Orchestrator.setStatus(LoopRelayer, heldBatchStatus(batch, batch.TokenContract))
+				break
+			}
 			packSigned = append(packSigned, &BatchAndSigs{Batch: batch, Sigs: nil})
 		}
 
@@ -255,6 +287,11 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 	stopGrp := make(map[string]bool, 0)
 	retryGrp := make(map[string]bool, 0)
 	for {
+		// the batches sent are waited for, no new batch is sent once draining
+		if l.Draining() {
+			return true, nil
+		}
+
 		// take 1 of each grp
 		batchToRelay := make([]*BatchAndSigs, 0)
 
@@ -264,7 +301,9 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 			}
 			batchAndSig := batchAndSigs[0]
 
-			sigs, err := l.GetHelios().TransactionBatchSignatures(ctx, l.cfg.HyperionId, batchAndSig.Batch.BatchNonce, gethcommon.HexToAddress(batchAndSig.Batch.TokenContract))
+			rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "TransactionBatchSignatures", tracing.AttrNonce.Int64(int64(batchAndSig.Batch.BatchNonce)))
+			sigs, err := l.GetHelios().TransactionBatchSignatures(rpcCtx, l.cfg.HyperionId, batchAndSig.Batch.BatchNonce, gethcommon.HexToAddress(batchAndSig.Batch.TokenContract))
+			tracing.End(rpcSpan, err)
 			if err != nil {
 				l.Log().WithError(err).Warningln("failed to get transaction batch signatures")
 				continue
@@ -279,31 +318,45 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 
 		// send tx
 		for _, batchAndSig := range batchToRelay {
+			if l.Draining() {
+				break
+			}
 
 			symbol, ok := l.CacheSymbol[gethcommon.HexToAddress(batchAndSig.Batch.TokenContract)]
 			if !ok {
 				symbol = batchAndSig.Batch.TokenContract
 			}
 
-			l.Orchestrator.HyperionState.RelayerStatus = "sending batch " + strconv.Itoa(int(batchAndSig.Batch.BatchNonce)) + " - " + symbol
-			fmt.Println("sending batch ", batchAndSig.Batch.BatchNonce, " - ", symbol)
-			txData, err := l.ethereum.PrepareTransactionBatch(ctx, latestEthValset, batchAndSig.Batch, batchAndSig.Sigs)
+			batchLog := l.Log().WithFields(log.Fields{"nonce": batchAndSig.Batch.BatchNonce, "token_contract": batchAndSig.Batch.TokenContract, "symbol": symbol})
+			batchLog.Infoln("sending batch")
+			l.Orchestrator.setStatus(LoopRelayer, "sending batch "+strconv.Itoa(int(batchAndSig.Batch.BatchNonce))+" - "+symbol)
+			batchCtx, batchSpan := tracing.Start(ctx, "relayer.relayBatch",
+				tracing.AttrNonce.Int64(int64(batchAndSig.Batch.BatchNonce)),
+				attribute.String("hyperion.token_contract", batchAndSig.Batch.TokenContract),
+				tracing.AttrCount.Int(len(batchAndSig.Batch.Transactions)),
+			)
+
+			stepCtx, stepSpan := tracing.Start(batchCtx, "relayer.prepare")
+			txData, err := l.ethereum.PrepareTransactionBatch(stepCtx, latestEthValset, batchAndSig.Batch, batchAndSig.Sigs)
+			tracing.End(stepSpan, err)
 			if err != nil {
-				fmt.Println("error preparing batch ", err)
+				tracing.End(batchSpan, err)
 				stopGrp[batchAndSig.Batch.TokenContract] = true
-				l.Orchestrator.HyperionState.RelayerStatus = "error preparing batch " + symbol
-				l.Log().WithError(err).Warningln("failed to prepare outgoing tx batch")
+				l.Orchestrator.setStatus(LoopRelayer, "error preparing batch "+symbol)
+				batchLog.WithError(err).Warningln("failed to prepare outgoing tx batch")
 				time.Sleep(2 * time.Second)
 				continue
 			}
-			ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+			ctxWithTimeout, cancel := context.WithTimeout(batchCtx, 30*time.Second)
 			defer cancel()
-			txHash, cost, err := l.ethereum.SendPreparedTx(ctxWithTimeout, txData)
+			stepCtx, stepSpan = tracing.Start(ctxWithTimeout, "relayer.send")
+			txHash, cost, err := l.ethereum.SendPreparedTx(stepCtx, txData)
+			tracing.End(stepSpan, err)
 			if err != nil {
-				fmt.Println("error sending batch ", err)
+				tracing.End(batchSpan, err)
 				stopGrp[batchAndSig.Batch.TokenContract] = true
-				l.Orchestrator.HyperionState.RelayerStatus = "error sending batch " + symbol
-				l.Log().WithError(err).Warningln("failed to send outgoing tx batch")
+				l.Orchestrator.setStatus(LoopRelayer, "error sending batch "+symbol)
+				batchLog.WithError(err).Warningln("failed to send outgoing tx batch")
 				time.Sleep(2 * time.Second)
 				if strings.Contains(err.Error(), "invalid nonce") {
 					l.Orchestrator.ResetEthereum()
@@ -311,22 +364,35 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 				}
 				continue
 			}
-			fmt.Println("txHash: ", txHash.Hex())
-			l.Orchestrator.HyperionState.RelayerStatus = "waiting batch " + strconv.Itoa(int(batchAndSig.Batch.BatchNonce)) + " - " + symbol + " for transaction to be mined"
+			batchSpan.SetAttributes(tracing.AttrTxHash.String(txHash.Hex()))
+			l.trackTx("batch", batchAndSig.Batch.BatchNonce, batchAndSig.Batch.TokenContract, *txHash)
+			l.Orchestrator.setStatus(LoopRelayer, "waiting batch "+strconv.Itoa(int(batchAndSig.Batch.BatchNonce))+" - "+symbol+" for transaction to be mined")
+			stepCtx, stepSpan = tracing.Start(batchCtx, "relayer.wait", tracing.AttrTxHash.String(txHash.Hex()))
 			time.Sleep(5 * time.Second) // wait for transaction to in pool on multiple nodes
-			ctxWithTimeout2, cancel2 := context.WithTimeout(ctx, 5*time.Minute)
+			ctxWithTimeout2, cancel2 := context.WithTimeout(stepCtx, 5*time.Minute)
 			defer cancel2()
 			_, blockNumber, err := l.ethereum.WaitForTransaction(ctxWithTimeout2, *txHash)
+			tracing.End(stepSpan, err)
+			tracing.End(batchSpan, err)
+			l.untrackTx(*txHash)
 			if err != nil {
 				stopGrp[batchAndSig.Batch.TokenContract] = true
-				l.Orchestrator.HyperionState.RelayerStatus = "error waiting for transaction " + symbol
-				l.Log().WithError(err).Warningln("failed to wait for transaction")
+				l.Orchestrator.setStatus(LoopRelayer, "error waiting for transaction "+symbol)
+				batchLog.WithError(err).WithField("tx_hash", txHash.Hex()).Warningln("failed to wait for transaction")
 				l.Orchestrator.RotateRpc()
 				time.Sleep(2 * time.Second)
 				continue
 			}
-			l.Orchestrator.HyperionState.RelayerStatus = "batch sent " + strconv.Itoa(int(batchAndSig.Batch.BatchNonce)) + " - " + symbol + " - block number: " + strconv.Itoa(int(blockNumber))
+			l.Orchestrator.setStatus(LoopRelayer, "batch sent "+strconv.Itoa(int(batchAndSig.Batch.BatchNonce))+" - "+symbol+" - block number: "+strconv.Itoa(int(blockNumber)))
 			l.HyperionState.OutBridgedTxCount += len(batchAndSig.Batch.Transactions)
+			l.publish(stream.EventRelay, LoopRelayer, "batch "+strconv.Itoa(int(batchAndSig.Batch.BatchNonce))+" - "+symbol+" relayed", map[string]interface{}{
+				"kind":          "batch",
+				"nonce":         batchAndSig.Batch.BatchNonce,
+				"tokenContract": batchAndSig.Batch.TokenContract,
+				"txCount":       len(batchAndSig.Batch.Transactions),
+				"txHash":        txHash.Hex(),
+				"blockNumber":   blockNumber,
+			})
 
 			totalFees := sdkmath.NewInt(0)
 			for _, tx := range batchAndSig.Batch.Transactions {
@@ -336,7 +402,7 @@ func (l *relayer) relayBatchsOptimised(ctx context.Context, latestEthValset *hyp
 			storage.UpdateFeesFile(totalFees.BigInt(), batchAndSig.Batch.TokenContract, cost, txHash.Hex(), latestEthHeight.Number.Uint64(), l.cfg.ChainId, "BATCH")
 			///////
 
-			l.Log().WithField("tx_hash", txHash.Hex()).Infoln("sent outgoing tx batch to " + l.cfg.ChainName)
+			batchLog.WithField("tx_hash", txHash.Hex()).WithField("block_number", blockNumber).Infoln("sent outgoing tx batch to " + l.cfg.ChainName)
 		}
 
 		for tokenContract, batchAndSigs := range grpOfSignedBatchs {
@@ -373,16 +439,18 @@ type BatchToSign struct {
 // The other (and far worse) way a disagreement here could occur is if validators are colluding to steal
 // funds from the Hyperion contract and have submitted a hijacking update. If slashing for off Cosmos chain
 // Ethereum signatures is implemented you would put that handler here.
-func checkIfValsetsDiffer(cosmosValset, ethereumValset *hyperiontypes.Valset) {
+// The returned error describes a disagreement that is not a bootstrapping or syncing case,
+// it trips the valset mismatch tripwire of the circuit breaker.
+func checkIfValsetsDiffer(cosmosValset, ethereumValset *hyperiontypes.Valset) error {
 	if cosmosValset == nil && ethereumValset.Nonce == 0 {
 		// bootstrapping case
-		return
+		return nil
 	} else if cosmosValset == nil {
 		log.WithField(
 			"eth_valset_nonce",
 			ethereumValset.Nonce,
 		).Errorln("Cosmos does not have a valset for nonce from Ethereum chain. Maybe not synced yet?")
-		return
+		return nil
 	}
 
 	if cosmosValset.Nonce != ethereumValset.Nonce {
@@ -390,7 +458,7 @@ func checkIfValsetsDiffer(cosmosValset, ethereumValset *hyperiontypes.Valset) {
 			"cosmos_valset_nonce": cosmosValset.Nonce,
 			"eth_valset_nonce":    ethereumValset.Nonce,
 		}).Errorln("Cosmos does have a wrong valset nonce, differs from Ethereum chain. Possible bridge hijacking!")
-		return
+		return errors.Errorf("valset nonce %d on Helios differs from nonce %d on the contract", cosmosValset.Nonce, ethereumValset.Nonce)
 	}
 
 	if len(cosmosValset.Members) != len(ethereumValset.Members) {
@@ -398,20 +466,19 @@ func checkIfValsetsDiffer(cosmosValset, ethereumValset *hyperiontypes.Valset) {
 			"cosmos_valset": len(cosmosValset.Members),
 			"eth_valset":    len(ethereumValset.Members),
 		}).Errorln("Cosmos and Ethereum Valsets have different length. Possible bridge hijacking!")
-		return
+		return errors.Errorf("valset %d has %d members on Helios and %d on the contract", cosmosValset.Nonce, len(cosmosValset.Members), len(ethereumValset.Members))
 	}
 
 	BridgeValidators(cosmosValset.Members).Sort()
 	BridgeValidators(ethereumValset.Members).Sort()
 
 	for idx, member := range cosmosValset.Members {
-		if ethereumValset.Members[idx].EthereumAddress != member.EthereumAddress {
-			log.Errorln("Valsets are different, a sorting error?")
-		}
-		if ethereumValset.Members[idx].Power != member.Power {
+		if !strings.EqualFold(ethereumValset.Members[idx].EthereumAddress, member.EthereumAddress) || ethereumValset.Members[idx].Power != member.Power {
 			log.Errorln("Valsets are different, a sorting error?")
+			return errors.Errorf("valset %d member %s (power %d) on Helios differs from %s (power %d) on the contract", cosmosValset.Nonce, member.EthereumAddress, member.Power, ethereumValset.Members[idx].EthereumAddress, ethereumValset.Members[idx].Power)
 		}
 	}
+	return nil
 }
 
 type BridgeValidators []*hyperiontypes.BridgeValidator
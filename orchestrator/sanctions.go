@@ -0,0 +1,75 @@
+package orchestrator
+
+import (
+	log "github.com/xlab/suplog"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/compliance"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+const (
+	sanctionsStageSign  = "sign"
+	sanctionsStageRelay = "relay"
+)
+
+func (s *Orchestrator) GetSanctionsPolicy() compliance.Policy {
+	settings, err := storage.GetChainSettings(s.cfg.ChainId)
+	if err != nil {
+		return compliance.DefaultPolicy
+	}
+	policyStr, ok := settings["sanctions_policy"].(string)
+	if !ok {
+		return compliance.DefaultPolicy
+	}
+	policy, err := compliance.ParsePolicy(policyStr)
+	if err != nil {
+		s.logger.WithError(err).Warningln("invalid sanctions_policy in chain settings, using default", compliance.DefaultPolicy)
+		return compliance.DefaultPolicy
+	}
+	return policy
+}
+
+// screenBatch checks the batch recipients against the sanctions list, records any hit
+// and returns true if the configured policy forbids the given stage (sign or relay).
+func (s *Orchestrator) screenBatch(batch *hyperiontypes.OutgoingTxBatch, stage string) bool {
+	sanctionsList := s.global.GetSanctionsList()
+	if sanctionsList == nil {
+		return false
+	}
+
+	hits := sanctionsList.ScreenBatch(batch)
+	if len(hits) == 0 {
+		return false
+	}
+
+	policy := s.GetSanctionsPolicy()
+	blocked := false
+	switch stage {
+	case sanctionsStageSign:
+		blocked = policy.BlocksSigning()
+	case sanctionsStageRelay:
+		blocked = policy.BlocksRelaying()
+	}
+
+	recorded, err := storage.RecordSanctionsHit(s.cfg.ChainId, batch.BatchNonce, batch.TokenContract, hits, string(policy), stage, blocked)
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to record sanctions hit")
+	}
+	if !recorded {
+		return blocked
+	}
+	s.HyperionState.SanctionsHitCount++
+
+	s.logger.WithFields(log.Fields{
+		"batch_nonce":    batch.BatchNonce,
+		"token_contract": batch.TokenContract,
+		"addresses":      hits,
+		"policy":         policy,
+		"stage":          stage,
+		"blocked":        blocked,
+	}).Warningln("batch pays out to sanctioned address")
+
+	return blocked
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/ethereum/hyperion"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+)
+
+const (
+	signatureTypeValset = "valset"
+	signatureTypeBatch  = "batch"
+)
+
+// archiveValsetSignature keeps the valset confirmation we produced so it can be
+// re-verified later in case of a dispute, even when its broadcast fails.
+func (l *signer) archiveValsetSignature(valset *hyperiontypes.Valset, msg *hyperiontypes.MsgValsetConfirm) {
+	message := hyperion.EncodeValsetConfirmMessage(l.hyperionID, valset)
+	l.archiveSignature(signatureTypeValset, valset.Nonce, "", message, msg.Signature)
+}
+
+// archiveBatchSignature keeps the batch confirmation we produced so it can be
+// re-verified later in case of a dispute, even when its broadcast fails.
+func (l *signer) archiveBatchSignature(batch *hyperiontypes.OutgoingTxBatch, msg *hyperiontypes.MsgConfirmBatch) {
+	message := hyperion.EncodeTxBatchConfirmMessage(l.hyperionID, batch)
+	l.archiveSignature(signatureTypeBatch, batch.BatchNonce, batch.TokenContract, message, msg.Signature)
+}
+
+// attachSignatureTxHash records the Helios tx that carried an archived signature.
+func (l *signer) attachSignatureTxHash(kind string, nonce uint64, signature string, heliosTxHash string) {
+	if err := storage.SetSignatureTxHash(l.cfg.ChainId, kind, nonce, signature, heliosTxHash); err != nil {
+		l.Log().WithError(err).Warningln("failed to attach the tx hash of", kind, "signature", nonce)
+	}
+}
+
+func (l *signer) archiveSignature(kind string, nonce uint64, tokenContract string, message []byte, signature string) {
+	err := storage.RecordSignature(
+		l.cfg.ChainId,
+		l.cfg.HyperionId,
+		kind,
+		nonce,
+		tokenContract,
+		l.cfg.EthereumAddr.Hex(),
+		gethcommon.Bytes2Hex(message),
+		crypto.Keccak256Hash(message).Hex(),
+		signature,
+		"",
+	)
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to archive", kind, "signature", nonce)
+	}
+}
@@ -1,18 +1,19 @@
 package orchestrator
 
 import (
+	"cmp"
 	"context"
-	"fmt"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	log "github.com/xlab/suplog"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
 	"github.com/Helios-Chain-Labs/metrics"
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
 )
@@ -24,7 +25,6 @@ func (s *Orchestrator) runSigner(ctx context.Context, hyperionID gethcommon.Hash
 	signer := signer{
 		Orchestrator: s,
 		hyperionID:   hyperionID,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "signer"),
 	}
 
 	s.logger.WithField("loop_duration", defaultLoopDur.String()).Debugln("starting Signer...")
@@ -34,27 +34,46 @@ func (s *Orchestrator) runSigner(ctx context.Context, hyperionID gethcommon.Hash
 	defer ticker.Stop()
 
 	// Run first iteration immediately
-	if err := signer.sign(ctx); err != nil {
-		s.logger.WithError(err).Errorln("signer function returned an error")
+	if s.heliosSyncing(ctx, "signer") {
+		s.setStatus(LoopSigner, HeliosSyncingStatus)
+	} else {
+		s.work("signer", func() {
+			if err := signer.sign(ctx); err != nil {
+				s.logger.WithError(err).Errorln("signer function returned an error")
+			}
+		})
 	}
 
 	for {
+		// the ticker is a fallback to the Helios events waking the signer
 		select {
 		case <-ticker.C:
-			if s.HyperionState.SignerStatus == "running" {
-				continue
-			}
+		case <-s.wakeups.signer:
+			ticker.Reset(defaultLoopDur)
+		case <-ctx.Done():
+			return nil
+		}
+
+		if s.HyperionState.SignerStatus == "running" {
+			continue
+		}
+		if s.heliosSyncing(ctx, "signer") {
+			s.setStatus(LoopSigner, HeliosSyncingStatus)
+			continue
+		}
 
+		drained := !s.work("signer", func() {
 			start := time.Now()
-			s.HyperionState.SignerStatus = "running"
+			s.setStatus(LoopSigner, "running")
 			if err := signer.sign(ctx); err != nil {
 				s.logger.WithError(err).Errorln("signer function returned an error")
 			}
-			s.HyperionState.SignerStatus = "idle"
+			s.setStatus(LoopSigner, "idle")
 			s.HyperionState.SignerLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 			s.HyperionState.SignerNextExecutionTimestamp = uint64(start.Add(defaultLoopDur).Unix())
-		case <-ctx.Done():
-			return nil
+		})
+		if drained {
+			s.setStatus(LoopSigner, DrainingStatus)
 		}
 	}
 }
@@ -62,35 +81,40 @@ func (s *Orchestrator) runSigner(ctx context.Context, hyperionID gethcommon.Hash
 type signer struct {
 	*Orchestrator
 	hyperionID gethcommon.Hash
-	logEnabled bool
 }
 
 func (l *signer) Log() log.Logger {
-	return l.logger.WithField("loop", "Signer")
+	return l.logger.WithField("loop", LoopSigner)
 }
 
-func (l *signer) sign(ctx context.Context) error {
+func (l *signer) sign(ctx context.Context) (err error) {
 	metrics.ReportFuncCall(l.svcTags)
 	doneFn := metrics.ReportFuncTiming(l.svcTags)
 	defer doneFn()
 
+	ctx, span := l.startSpan(ctx, LoopSigner, "signer.sign")
+	defer func() { tracing.End(span, err) }()
+
 	l.Log().Debugln("signing")
-	l.Orchestrator.HyperionState.SignerStatus = "signing validator sets"
+	l.Orchestrator.setStatus(LoopSigner, "signing validator sets")
 	if err := l.signValidatorSets(ctx); err != nil {
-		l.Orchestrator.HyperionState.SignerStatus = "error signing validator sets"
+		l.Orchestrator.setStatus(LoopSigner, "error signing validator sets")
 		return err
 	}
 	l.Log().Debugln("signing validator sets done")
 
-	noncesPushed := []uint64{}
+	if err := l.settleOutflows(ctx); err != nil {
+		l.Log().WithError(err).Warningln("failed to settle the outflow ledger")
+	}
+
+	pass := &batchPass{}
 	for i := 0; i < 50; i++ {
-		l.Orchestrator.HyperionState.SignerStatus = "signing new batch"
-		hasPushedABatch, noncePushed, err := l.signNewBatch(ctx, noncesPushed)
+		l.Orchestrator.setStatus(LoopSigner, "signing new batch")
+		hasPushedABatch, err := l.signNewBatch(ctx, pass)
 		if err != nil {
-			l.Orchestrator.HyperionState.SignerStatus = "error signing new batch"
+			l.Orchestrator.setStatus(LoopSigner, "error signing new batch")
 			return err
 		}
-		noncesPushed = append(noncesPushed, noncePushed)
 		if !hasPushedABatch {
 			break
 		}
@@ -100,10 +124,16 @@ func (l *signer) sign(ctx context.Context) error {
 	return nil
 }
 
-func (l *signer) signValidatorSets(ctx context.Context) error {
+func (l *signer) signValidatorSets(ctx context.Context) (err error) {
+	ctx, span := l.startSpan(ctx, LoopSigner, "signer.signValidatorSets")
+	defer func() { tracing.End(span, err) }()
+
 	var valsets []*hyperiontypes.Valset
 	fn := func() error {
-		valsets, _ = l.GetHelios().OldestUnsignedValsets(ctx, l.cfg.HyperionId, l.cfg.CosmosAddr)
+		rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "OldestUnsignedValsets")
+		var err error
+		valsets, err = l.GetHelios().OldestUnsignedValsets(rpcCtx, l.cfg.HyperionId, l.cfg.CosmosAddr)
+		tracing.End(rpcSpan, err)
 		return nil
 	}
 
@@ -116,34 +146,45 @@ func (l *signer) signValidatorSets(ctx context.Context) error {
 	}
 
 	for _, vs := range valsets {
-		if err := l.retry(ctx, func() error {
+		if err := l.retry(ctx, func() (err error) {
+			ctx, span := tracing.Start(ctx, "signer.signValset", tracing.AttrNonce.Int64(int64(vs.Nonce)))
+			defer func() { tracing.End(span, err) }()
+
 			l.Log().Infoln("signing valset", vs.Nonce)
 
 			msg, err := l.GetHelios().SendValsetConfirmMsg(ctx, l.cfg.HyperionId, l.cfg.EthereumAddr, l.hyperionID, l.ethereum.GetPersonalSignFn(), vs)
 			if err != nil {
 				return errors.Wrap(err, "failed to send valset confirm message")
 			}
+			confirm, isConfirm := msg.(*hyperiontypes.MsgValsetConfirm)
+			if isConfirm {
+				l.archiveValsetSignature(vs, confirm)
+			}
 
-			err = l.GetHelios().SyncBroadcastMsgsSimulate(ctx, []sdk.Msg{msg})
+			err = l.simulateMsgs(ctx, []sdk.Msg{msg})
 			if err != nil {
 				VerifyTxError(ctx, err.Error(), l.Orchestrator)
-				fmt.Println("failed to simulate valset confirm message", err.Error())
+				l.Log().WithError(err).WithField("nonce", vs.Nonce).Warningln("failed to simulate valset confirm message")
 				return errors.Wrap(err, "failed to simulate valset confirm message")
 			}
 
-			_, err = l.global.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
+			resp, err := l.global.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
 			if err != nil {
-				fmt.Println("failed to broadcast valset confirm message", err.Error())
+				l.Log().WithError(err).WithField("nonce", vs.Nonce).Warningln("failed to broadcast valset confirm message")
 				return errors.Wrap(err, "failed to broadcast valset confirm message")
 			}
 
+			if isConfirm && resp != nil {
+				l.attachSignatureTxHash(signatureTypeValset, vs.Nonce, confirm.Signature, resp.TxHash)
+			}
+
 			return nil
 		}); err != nil {
-			fmt.Println("signing valset failed", err.Error())
+			l.Log().WithError(err).WithField("nonce", vs.Nonce).Errorln("signing valset failed")
 			return err
 		}
 
-		if l.logEnabled {
+		if l.logEnabled(LoopSigner) {
 			l.Log().WithFields(log.Fields{"valset_nonce": vs.Nonce, "validators": len(vs.Members)}).Infoln("confirmed valset update on Helios")
 		}
 	}
@@ -151,62 +192,155 @@ func (l *signer) signValidatorSets(ctx context.Context) error {
 	return nil
 }
 
-func (l *signer) signNewBatch(ctx context.Context, noncesPushed []uint64) (bool, uint64, error) {
+// batchPass holds the batches already handled by a signing pass, signed or refused, so
+// the batches behind a refused one are still signed. A batch held by the outflow limits
+// holds the later batches of its token, as the relayer does, the other tokens go on.
+type batchPass struct {
+	handled    []uint64
+	heldTokens map[string]bool
+}
+
+func (p *batchPass) skips(batch *hyperiontypes.OutgoingTxBatch) bool {
+	return slices.Contains(p.handled, batch.BatchNonce) || p.heldTokens[gethcommon.HexToAddress(batch.TokenContract).Hex()]
+}
+
+func (p *batchPass) hold(batch *hyperiontypes.OutgoingTxBatch) {
+	if p.heldTokens == nil {
+		p.heldTokens = make(map[string]bool)
+	}
+	p.heldTokens[gethcommon.HexToAddress(batch.TokenContract).Hex()] = true
+}
+
+func (l *signer) signNewBatch(ctx context.Context, pass *batchPass) (_ bool, err error) {
+	ctx, span := l.startSpan(ctx, LoopSigner, "signer.signNewBatch")
+	defer func() { tracing.End(span, err) }()
+
 	var oldestUnsignedBatch *hyperiontypes.OutgoingTxBatch
 	getBatchFn := func() error {
-		tmpOldestUnsignedBatch, _ := l.GetHelios().OldestUnsignedTransactionBatch(ctx, l.cfg.HyperionId, l.cfg.CosmosAddr)
-		if tmpOldestUnsignedBatch != nil && tmpOldestUnsignedBatch.HyperionId == l.cfg.HyperionId && !slices.Contains(noncesPushed, tmpOldestUnsignedBatch.BatchNonce) {
+		rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "OldestUnsignedTransactionBatch")
+		tmpOldestUnsignedBatch, err := l.GetHelios().OldestUnsignedTransactionBatch(rpcCtx, l.cfg.HyperionId, l.cfg.CosmosAddr)
+		tracing.End(rpcSpan, err)
+		if tmpOldestUnsignedBatch != nil && tmpOldestUnsignedBatch.HyperionId == l.cfg.HyperionId {
 			oldestUnsignedBatch = tmpOldestUnsignedBatch
 		}
 		return nil
 	}
 
 	if err := l.retry(ctx, getBatchFn); err != nil {
-		return false, 0, err
+		return false, err
+	}
+
+	// Helios keeps returning a batch we did not sign, the next ones are looked up
+	if oldestUnsignedBatch != nil && pass.skips(oldestUnsignedBatch) {
+		if oldestUnsignedBatch, err = l.nextUnsignedBatch(ctx, pass); err != nil {
+			return false, err
+		}
 	}
 
 	if oldestUnsignedBatch == nil {
-		if l.logEnabled {
+		if l.logEnabled(LoopSigner) {
 			l.Log().Infoln("no token batch to confirm")
 		}
-		return false, 0, nil
+		return false, nil
 	}
+	pass.handled = append(pass.handled, oldestUnsignedBatch.BatchNonce)
+
+	span.SetAttributes(tracing.AttrNonce.Int64(int64(oldestUnsignedBatch.BatchNonce)), attribute.String("hyperion.token_contract", oldestUnsignedBatch.TokenContract))
 
 	symbol, ok := l.Orchestrator.CacheSymbol[gethcommon.HexToAddress(oldestUnsignedBatch.TokenContract)]
 	if !ok {
 		symbol = oldestUnsignedBatch.TokenContract
 	}
 
-	l.Orchestrator.HyperionState.SignerStatus = "signing batch " + strconv.Itoa(int(oldestUnsignedBatch.BatchNonce)) + " " + symbol
+	if l.screenBatch(oldestUnsignedBatch, sanctionsStageSign) {
+		l.Orchestrator.setStatus(LoopSigner, "batch "+strconv.Itoa(int(oldestUnsignedBatch.BatchNonce))+" "+symbol+" refused (sanctioned recipient)")
+		return true, nil
+	}
+
+	if !l.admitBatch(ctx, oldestUnsignedBatch, outflowStageSign) {
+		pass.hold(oldestUnsignedBatch)
+		l.Orchestrator.setStatus(LoopSigner, heldBatchStatus(oldestUnsignedBatch, symbol))
+		return true, nil
+	}
+
+	l.Orchestrator.setStatus(LoopSigner, "signing batch "+strconv.Itoa(int(oldestUnsignedBatch.BatchNonce))+" "+symbol)
 
 	msg, err := l.GetHelios().SendBatchConfirmMsg(ctx, l.cfg.HyperionId, l.cfg.EthereumAddr, l.hyperionID, l.ethereum.GetPersonalSignFn(), oldestUnsignedBatch)
 	if err != nil {
-		return false, 0, errors.Wrap(err, "failed to send batch confirm message")
+		return false, errors.Wrap(err, "failed to send batch confirm message")
+	}
+	confirm, isConfirm := msg.(*hyperiontypes.MsgConfirmBatch)
+	if isConfirm {
+		l.archiveBatchSignature(oldestUnsignedBatch, confirm)
 	}
 
-	err = l.GetHelios().SyncBroadcastMsgsSimulate(ctx, []sdk.Msg{msg})
+	err = l.simulateMsgs(ctx, []sdk.Msg{msg})
 	if err != nil {
 		VerifyTxError(ctx, err.Error(), l.Orchestrator)
-		fmt.Println("failed to simulate sign batch confirm message", err.Error())
-		return false, 0, err
+		l.Log().WithError(err).WithField("nonce", oldestUnsignedBatch.BatchNonce).Warningln("failed to simulate batch confirm message")
+		return false, err
 	} else {
-		l.Orchestrator.HyperionState.ErrorStatus = "okay"
+		l.Orchestrator.setStatus(LoopError, "okay")
 	}
 
-	_, err = l.global.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
+	resp, err := l.global.SyncBroadcastMsgs(ctx, []sdk.Msg{msg})
 	if err != nil {
-		fmt.Println("failed to sign batch confirm message", err.Error())
-		return false, 0, errors.Wrap(err, "failed to broadcast batch confirm message")
+		l.Log().WithError(err).WithField("nonce", oldestUnsignedBatch.BatchNonce).Warningln("failed to broadcast batch confirm message")
+		return false, errors.Wrap(err, "failed to broadcast batch confirm message")
+	}
+
+	if isConfirm && resp != nil {
+		l.attachSignatureTxHash(signatureTypeBatch, oldestUnsignedBatch.BatchNonce, confirm.Signature, resp.TxHash)
 	}
 
 	if err != nil {
-		l.Orchestrator.HyperionState.SignerStatus = "error signing batch " + strconv.Itoa(int(oldestUnsignedBatch.BatchNonce)) + " " + symbol
-		return false, 0, err
+		l.Orchestrator.setStatus(LoopSigner, "error signing batch "+strconv.Itoa(int(oldestUnsignedBatch.BatchNonce))+" "+symbol)
+		return false, err
 	}
 
-	l.Orchestrator.HyperionState.SignerStatus = "batch " + strconv.Itoa(int(oldestUnsignedBatch.BatchNonce)) + " " + symbol + " signed"
+	l.Orchestrator.setStatus(LoopSigner, "batch "+strconv.Itoa(int(oldestUnsignedBatch.BatchNonce))+" "+symbol+" signed")
 
 	l.Log().WithFields(log.Fields{"token_contract": oldestUnsignedBatch.TokenContract, "batch_nonce": oldestUnsignedBatch.BatchNonce, "txs": len(oldestUnsignedBatch.Transactions)}).Infoln("confirmed batch on Helios")
 
-	return true, oldestUnsignedBatch.BatchNonce, nil
+	return true, nil
+}
+
+// nextUnsignedBatch returns the oldest pending batch of the chain not handled by the pass
+// and not signed by us yet.
+func (l *signer) nextUnsignedBatch(ctx context.Context, pass *batchPass) (*hyperiontypes.OutgoingTxBatch, error) {
+	var batches []*hyperiontypes.OutgoingTxBatch
+	if err := l.retry(ctx, func() (err error) {
+		rpcCtx, rpcSpan := tracing.StartRPC(ctx, tracing.SystemHelios, "LatestTransactionBatches")
+		batches, err = l.GetHelios().LatestTransactionBatches(rpcCtx, l.cfg.HyperionId)
+		tracing.End(rpcSpan, err)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get pending batches")
+	}
+
+	slices.SortFunc(batches, func(a, b *hyperiontypes.OutgoingTxBatch) int {
+		return cmp.Compare(a.BatchNonce, b.BatchNonce)
+	})
+
+	for _, batch := range batches {
+		if batch.HyperionId != l.cfg.HyperionId || pass.skips(batch) {
+			continue
+		}
+		var confirms []*hyperiontypes.MsgConfirmBatch
+		if err := l.retry(ctx, func() (err error) {
+			confirms, err = l.GetHelios().TransactionBatchSignatures(ctx, l.cfg.HyperionId, batch.BatchNonce, gethcommon.HexToAddress(batch.TokenContract))
+			return err
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to get batch signatures")
+		}
+		signed := slices.ContainsFunc(confirms, func(confirm *hyperiontypes.MsgConfirmBatch) bool {
+			return confirm.Orchestrator == l.cfg.CosmosAddr.String()
+		})
+		if !signed {
+			return batch, nil
+		}
+		// signed already, no need to look at it again in this pass
+		pass.handled = append(pass.handled, batch.BatchNonce)
+	}
+	return nil, nil
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"math/big"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
@@ -21,7 +20,6 @@ const (
 func (s *Orchestrator) runSkipped(ctx context.Context) error {
 	skipped := skipped{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "skipped"),
 	}
 	s.logger.WithField("loop_duration", defaultSkippedLoopDur.String()).Debugln("starting Skipped...")
 
@@ -31,9 +29,9 @@ func (s *Orchestrator) runSkipped(ctx context.Context) error {
 		}
 
 		start := time.Now()
-		s.HyperionState.SkippedStatus = "running"
+		s.setStatus(LoopSkipped, "running")
 		err := skipped.Run(ctx)
-		s.HyperionState.SkippedStatus = "idle"
+		s.setStatus(LoopSkipped, "idle")
 		s.HyperionState.SkippedLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 		s.HyperionState.SkippedNextExecutionTimestamp = uint64(start.Add(defaultSkippedLoopDur).Unix())
 		return err
@@ -42,11 +40,10 @@ func (s *Orchestrator) runSkipped(ctx context.Context) error {
 
 type skipped struct {
 	*Orchestrator
-	logEnabled bool
 }
 
 func (l *updater) Log() log.Logger {
-	return l.logger.WithField("loop", "Updater")
+	return l.logger.WithField("loop", LoopUpdater)
 }
 
 func (l *skipped) Run(ctx context.Context) error {
@@ -92,12 +89,15 @@ func (l *skipped) Run(ctx context.Context) error {
 			continue
 		}
 
-		l.HyperionState.SkippedStatus = "getting events for nonce " + strconv.FormatUint(skippedNonce.Nonce, 10)
+		l.setStatus(LoopSkipped, "getting events for nonce "+strconv.FormatUint(skippedNonce.Nonce, 10))
 
-		events, err := l.Orchestrator.Oracle.getEthEvents(ctx, skippedNonce.StartHeight, skippedNonce.EndHeight, []uint64{skippedNonce.Nonce})
-		if err != nil {
-			log.WithError(err).Errorln("failed to get events on " + l.cfg.ChainName)
-			return err
+		events, indexed := l.indexedEventOfNonce(skippedNonce.Nonce)
+		if !indexed {
+			events, err = l.Orchestrator.Oracle.getEthEvents(ctx, skippedNonce.StartHeight, skippedNonce.EndHeight, []uint64{skippedNonce.Nonce})
+			if err != nil {
+				log.WithError(err).Errorln("failed to get events on " + l.cfg.ChainName)
+				return err
+			}
 		}
 		var eventsToSend []event
 		for _, event := range events {
@@ -113,7 +113,7 @@ func (l *skipped) Run(ctx context.Context) error {
 			continue
 		}
 
-		l.HyperionState.SkippedStatus = "sending events for nonce " + strconv.FormatUint(skippedNonce.Nonce, 10)
+		l.setStatus(LoopSkipped, "sending events for nonce "+strconv.FormatUint(skippedNonce.Nonce, 10))
 
 		if err := l.sendNewEventClaimsWithoutFilter(ctx, eventsToSend, int(maxClaimsMsgPerBulk)); err != nil {
 			log.Info("err: ", err)
@@ -126,6 +126,23 @@ func (l *skipped) Run(ctx context.Context) error {
 	return nil
 }
 
+// indexedEventOfNonce returns the event of the nonce from the event index, false when the
+// index does not hold it.
+func (l *skipped) indexedEventOfNonce(nonce uint64) ([]event, bool) {
+	if l.index == nil {
+		return nil, false
+	}
+	indexed, err := l.index.ByNonce(nonce)
+	if err != nil || indexed == nil {
+		return nil, false
+	}
+	ev, err := toEthEvent(indexed)
+	if err != nil {
+		return nil, false
+	}
+	return []event{ev}, true
+}
+
 func (l *skipped) sendNewEventClaimsWithoutFilter(ctx context.Context, newEvents []event, maxClaimsMsgPerBulk int) error {
 	sendEventsFn := func() error {
 
@@ -144,9 +161,9 @@ func (l *skipped) sendNewEventClaimsWithoutFilter(ctx context.Context, newEvents
 
 			if len(msgs) >= maxClaimsMsgPerBulk {
 				log.Infoln("sending bulk of ", len(msgs), "claims messages")
-				l.Orchestrator.HyperionState.SkippedStatus = "sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+				l.Orchestrator.setStatus(LoopSkipped, "sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 
-				err = l.GetHelios().SyncBroadcastMsgsSimulate(ctx, msgs)
+				err = l.simulateMsgs(ctx, msgs)
 				if err != nil {
 					VerifyTxError(ctx, err.Error(), l.Orchestrator)
 					log.WithError(err).Warningln("failed to simulate bulk of claims messages")
@@ -154,13 +171,14 @@ func (l *skipped) sendNewEventClaimsWithoutFilter(ctx context.Context, newEvents
 				}
 				resp, err := l.global.SyncBroadcastMsgs(ctx, msgs)
 				if err != nil {
-					l.Orchestrator.HyperionState.SkippedStatus = "error sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+					l.Orchestrator.setStatus(LoopSkipped, "error sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 					log.Errorln("error sending bulk of ", len(msgs), "claims messages", err)
 					return err
 				}
+				l.publishClaims(LoopSkipped, len(msgs), resp)
 				l.Orchestrator.HyperionState.SkippedRetriedCount += len(msgs)
-				l.Orchestrator.HyperionState.SkippedStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
-				cost, err := l.GetHelios().GetTxCost(ctx, resp.TxHash)
+				l.Orchestrator.setStatus(LoopSkipped, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
+				cost, err := l.getTxCost(ctx, resp.TxHash)
 				if err == nil {
 					storage.UpdateFeesFile(big.NewInt(0), "", cost, resp.TxHash, uint64(resp.Height), uint64(42000), "CLAIM")
 				}
@@ -171,8 +189,8 @@ func (l *skipped) sendNewEventClaimsWithoutFilter(ctx context.Context, newEvents
 
 		if len(msgs) > 0 {
 			log.Infoln("sending bulk of ", len(msgs), "claims messages")
-			l.Orchestrator.HyperionState.SkippedStatus = "sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
-			err := l.GetHelios().SyncBroadcastMsgsSimulate(ctx, msgs)
+			l.Orchestrator.setStatus(LoopSkipped, "sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
+			err := l.simulateMsgs(ctx, msgs)
 			if err != nil {
 				VerifyTxError(ctx, err.Error(), l.Orchestrator)
 				log.WithError(err).Warningln("failed to simulate bulk of claims messages")
@@ -180,16 +198,17 @@ func (l *skipped) sendNewEventClaimsWithoutFilter(ctx context.Context, newEvents
 			}
 			resp, err := l.global.SyncBroadcastMsgs(ctx, msgs)
 			if err != nil {
-				l.Orchestrator.HyperionState.SkippedStatus = "error sending bulk of " + strconv.Itoa(len(msgs)) + " claims messages"
+				l.Orchestrator.setStatus(LoopSkipped, "error sending bulk of "+strconv.Itoa(len(msgs))+" claims messages")
 				log.Errorln("error sending bulk of ", len(msgs), "claims messages", err)
 				return err
 			}
-			cost, err := l.GetHelios().GetTxCost(ctx, resp.TxHash)
+			l.publishClaims(LoopSkipped, len(msgs), resp)
+			cost, err := l.getTxCost(ctx, resp.TxHash)
 			if err == nil {
-				l.Orchestrator.HyperionState.SkippedStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
+				l.Orchestrator.setStatus(LoopSkipped, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
 				storage.UpdateFeesFile(big.NewInt(0), "", cost, resp.TxHash, uint64(resp.Height), uint64(42000), "CLAIM")
 			}
-			l.Orchestrator.HyperionState.SkippedStatus = "bulk of " + strconv.Itoa(len(msgs)) + " claims messages sent"
+			l.Orchestrator.setStatus(LoopSkipped, "bulk of "+strconv.Itoa(len(msgs))+" claims messages sent")
 			time.Sleep(1100 * time.Millisecond)
 		}
 
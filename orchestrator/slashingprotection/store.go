@@ -0,0 +1,412 @@
+package slashingprotection
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// InterchangeFormatVersion is the version of the import/export format.
+const InterchangeFormatVersion = "1"
+
+var ErrConflictingSignature = errors.New("slashing protection: refusing to sign a conflicting payload")
+
+// Interchange is the import/export format of the slashing protection store, used to move
+// a validator orchestrator from one host to another without losing its signing history.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []InterchangeSigner `json:"data"`
+}
+
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+}
+
+type InterchangeSigner struct {
+	EthAddress    string         `json:"eth_address"`
+	HyperionId    string         `json:"hyperion_id"`
+	SignedValsets []SignedValset `json:"signed_valsets"`
+	SignedBatches []SignedBatch  `json:"signed_batches"`
+}
+
+type SignedValset struct {
+	Nonce       string `json:"nonce"`
+	SigningRoot string `json:"signing_root"`
+}
+
+type SignedBatch struct {
+	TokenContract string `json:"token_contract"`
+	Nonce         string `json:"nonce"`
+	SigningRoot   string `json:"signing_root"`
+}
+
+type signerKey struct {
+	ethAddress gethcommon.Address
+	hyperionId uint64
+}
+
+type batchKey struct {
+	tokenContract gethcommon.Address
+	nonce         uint64
+}
+
+type signerHistory struct {
+	valsets map[uint64]gethcommon.Hash
+	batches map[batchKey]gethcommon.Hash
+}
+
+// Store remembers the hash of every valset and batch confirmation payload signed by the
+// orchestrator and refuses to sign a different payload for an already signed nonce.
+// Records are written to disk before the signature is produced: each one is appended to a
+// journal next to the store file, which is folded into the store file every
+// compactAfter records, so a signature does not rewrite the whole history.
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	signers map[signerKey]*signerHistory
+	// journaled is the number of records in the journal
+	journaled int
+}
+
+// compactAfter is the number of journal records folded into the store file at once.
+const compactAfter = 1024
+
+var (
+	defaultStore    *Store
+	defaultStoreErr error
+	defaultOnce     sync.Once
+)
+
+// DefaultStore returns the store kept in ~/.heliades/hyperion/slashing_protection.json.
+func DefaultStore() (*Store, error) {
+	defaultOnce.Do(func() {
+		homePath, err := os.UserHomeDir()
+		if err != nil {
+			defaultStoreErr = err
+			return
+		}
+		defaultStore, defaultStoreErr = NewStore(filepath.Join(homePath, ".heliades", "hyperion", "slashing_protection.json"))
+	})
+	return defaultStore, defaultStoreErr
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		signers: make(map[signerKey]*signerHistory),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to read slashing protection store")
+	}
+	if err == nil {
+		var interchange Interchange
+		if err := json.Unmarshal(data, &interchange); err != nil {
+			return nil, errors.Wrap(err, "failed to decode slashing protection store")
+		}
+		if err := s.merge(&interchange); err != nil {
+			return nil, errors.Wrap(err, "corrupted slashing protection store")
+		}
+	}
+
+	if err := s.replay(); err != nil {
+		return nil, errors.Wrap(err, "corrupted slashing protection journal")
+	}
+
+	return s, nil
+}
+
+func (s *Store) journalPath() string {
+	return s.path + ".journal"
+}
+
+// replay merges the records of the journal. A last line cut by a crash is cut off the
+// journal, its signature was never produced.
+func (s *Store) replay() error {
+	data, err := os.ReadFile(s.journalPath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	offset := 0
+	for i, line := range lines {
+		start := offset
+		offset += len(line) + 1
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var signer InterchangeSigner
+		if err := json.Unmarshal(line, &signer); err != nil {
+			if i == len(lines)-1 {
+				return os.Truncate(s.journalPath(), int64(start))
+			}
+			return errors.Wrapf(err, "invalid record on line %d", i+1)
+		}
+		if err := s.merge(&Interchange{Data: []InterchangeSigner{signer}}); err != nil {
+			return err
+		}
+		s.journaled++
+	}
+	return nil
+}
+
+// record appends a record to the journal, or folds the journal into the store file once
+// it holds compactAfter records.
+func (s *Store) record(signer InterchangeSigner) error {
+	if s.journaled+1 >= compactAfter {
+		return s.save()
+	}
+
+	line, err := json.Marshal(signer)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create slashing protection dir")
+	}
+	file, err := os.OpenFile(s.journalPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open slashing protection journal")
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write slashing protection journal")
+	}
+	if err := file.Sync(); err != nil {
+		return errors.Wrap(err, "failed to write slashing protection journal")
+	}
+	s.journaled++
+	return nil
+}
+
+// CheckAndRecordValset allows signing the valset confirm payload unless another payload was
+// already signed for the same nonce.
+func (s *Store) CheckAndRecordValset(hyperionId uint64, ethAddress gethcommon.Address, nonce uint64, signingRoot gethcommon.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.history(signerKey{ethAddress: ethAddress, hyperionId: hyperionId})
+	if previous, ok := history.valsets[nonce]; ok {
+		if previous == signingRoot {
+			return nil
+		}
+		return errors.Wrapf(ErrConflictingSignature, "valset nonce %d of hyperion %d already signed with root %s, got %s", nonce, hyperionId, previous.Hex(), signingRoot.Hex())
+	}
+
+	history.valsets[nonce] = signingRoot
+	record := InterchangeSigner{
+		EthAddress:    ethAddress.Hex(),
+		HyperionId:    strconv.FormatUint(hyperionId, 10),
+		SignedValsets: []SignedValset{{Nonce: strconv.FormatUint(nonce, 10), SigningRoot: signingRoot.Hex()}},
+	}
+	if err := s.record(record); err != nil {
+		delete(history.valsets, nonce)
+		return err
+	}
+
+	return nil
+}
+
+// CheckAndRecordBatch allows signing the batch confirm payload unless another payload was
+// already signed for the same token contract and batch nonce.
+func (s *Store) CheckAndRecordBatch(hyperionId uint64, ethAddress gethcommon.Address, tokenContract gethcommon.Address, nonce uint64, signingRoot gethcommon.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.history(signerKey{ethAddress: ethAddress, hyperionId: hyperionId})
+	key := batchKey{tokenContract: tokenContract, nonce: nonce}
+	if previous, ok := history.batches[key]; ok {
+		if previous == signingRoot {
+			return nil
+		}
+		return errors.Wrapf(ErrConflictingSignature, "batch nonce %d of token %s on hyperion %d already signed with root %s, got %s", nonce, tokenContract.Hex(), hyperionId, previous.Hex(), signingRoot.Hex())
+	}
+
+	history.batches[key] = signingRoot
+	record := InterchangeSigner{
+		EthAddress:    ethAddress.Hex(),
+		HyperionId:    strconv.FormatUint(hyperionId, 10),
+		SignedBatches: []SignedBatch{{TokenContract: tokenContract.Hex(), Nonce: strconv.FormatUint(nonce, 10), SigningRoot: signingRoot.Hex()}},
+	}
+	if err := s.record(record); err != nil {
+		delete(history.batches, key)
+		return err
+	}
+
+	return nil
+}
+
+// Export returns the whole signing history in the interchange format.
+func (s *Store) Export() *Interchange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.interchange()
+}
+
+// Import merges an interchange document into the store. The import is refused as a whole
+// if any record conflicts with the local history.
+func (s *Store) Import(interchange *Interchange) error {
+	if interchange.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return errors.Errorf("unsupported interchange format version %q", interchange.Metadata.InterchangeFormatVersion)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// merge into a copy so that a conflict leaves the store untouched
+	merged := &Store{path: s.path, signers: make(map[signerKey]*signerHistory)}
+	if err := merged.merge(s.interchange()); err != nil {
+		return err
+	}
+	if err := merged.merge(interchange); err != nil {
+		return err
+	}
+
+	previous := s.signers
+	s.signers = merged.signers
+	if err := s.save(); err != nil {
+		s.signers = previous
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) history(key signerKey) *signerHistory {
+	history, ok := s.signers[key]
+	if !ok {
+		history = &signerHistory{
+			valsets: make(map[uint64]gethcommon.Hash),
+			batches: make(map[batchKey]gethcommon.Hash),
+		}
+		s.signers[key] = history
+	}
+	return history
+}
+
+func (s *Store) merge(interchange *Interchange) error {
+	for _, signer := range interchange.Data {
+		if !gethcommon.IsHexAddress(signer.EthAddress) {
+			return errors.Errorf("invalid eth_address %q", signer.EthAddress)
+		}
+		hyperionId, err := strconv.ParseUint(signer.HyperionId, 10, 64)
+		if err != nil {
+			return errors.Errorf("invalid hyperion_id %q", signer.HyperionId)
+		}
+		history := s.history(signerKey{ethAddress: gethcommon.HexToAddress(signer.EthAddress), hyperionId: hyperionId})
+
+		for _, valset := range signer.SignedValsets {
+			nonce, root, err := parseRecord(valset.Nonce, valset.SigningRoot)
+			if err != nil {
+				return err
+			}
+			if previous, ok := history.valsets[nonce]; ok && previous != root {
+				return errors.Wrapf(ErrConflictingSignature, "valset nonce %d of hyperion %d signed with both %s and %s", nonce, hyperionId, previous.Hex(), root.Hex())
+			}
+			history.valsets[nonce] = root
+		}
+
+		for _, batch := range signer.SignedBatches {
+			if !gethcommon.IsHexAddress(batch.TokenContract) {
+				return errors.Errorf("invalid token_contract %q", batch.TokenContract)
+			}
+			nonce, root, err := parseRecord(batch.Nonce, batch.SigningRoot)
+			if err != nil {
+				return err
+			}
+			key := batchKey{tokenContract: gethcommon.HexToAddress(batch.TokenContract), nonce: nonce}
+			if previous, ok := history.batches[key]; ok && previous != root {
+				return errors.Wrapf(ErrConflictingSignature, "batch nonce %d of token %s on hyperion %d signed with both %s and %s", nonce, batch.TokenContract, hyperionId, previous.Hex(), root.Hex())
+			}
+			history.batches[key] = root
+		}
+	}
+
+	return nil
+}
+
+func parseRecord(nonceStr, rootStr string) (uint64, gethcommon.Hash, error) {
+	nonce, err := strconv.ParseUint(nonceStr, 10, 64)
+	if err != nil {
+		return 0, gethcommon.Hash{}, errors.Errorf("invalid nonce %q", nonceStr)
+	}
+	rootStr = strings.TrimPrefix(rootStr, "0x")
+	if len(rootStr) != 2*gethcommon.HashLength {
+		return 0, gethcommon.Hash{}, errors.Errorf("invalid signing_root %q", rootStr)
+	}
+	return nonce, gethcommon.HexToHash(rootStr), nil
+}
+
+func (s *Store) interchange() *Interchange {
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion},
+		Data:     make([]InterchangeSigner, 0, len(s.signers)),
+	}
+
+	for key, history := range s.signers {
+		signer := InterchangeSigner{
+			EthAddress:    key.ethAddress.Hex(),
+			HyperionId:    strconv.FormatUint(key.hyperionId, 10),
+			SignedValsets: make([]SignedValset, 0, len(history.valsets)),
+			SignedBatches: make([]SignedBatch, 0, len(history.batches)),
+		}
+		for nonce, root := range history.valsets {
+			signer.SignedValsets = append(signer.SignedValsets, SignedValset{
+				Nonce:       strconv.FormatUint(nonce, 10),
+				SigningRoot: root.Hex(),
+			})
+		}
+		for batch, root := range history.batches {
+			signer.SignedBatches = append(signer.SignedBatches, SignedBatch{
+				TokenContract: batch.tokenContract.Hex(),
+				Nonce:         strconv.FormatUint(batch.nonce, 10),
+				SigningRoot:   root.Hex(),
+			})
+		}
+		interchange.Data = append(interchange.Data, signer)
+	}
+
+	return interchange
+}
+
+// save writes the store to a temporary file and renames it so that a crash never leaves
+// a truncated history behind, then empties the journal folded into it. A crash in between
+// replays records already in the store file, which is harmless.
+func (s *Store) save() error {
+	data, err := json.Marshal(s.interchange())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create slashing protection dir")
+	}
+
+	tmpPath := fmt.Sprintf("%s.tmp", s.path)
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return errors.Wrap(err, "failed to write slashing protection store")
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return errors.Wrap(err, "failed to write slashing protection store")
+	}
+
+	// the records are in the store file, a journal left behind is emptied by the next save
+	if err := os.Remove(s.journalPath()); err == nil || os.IsNotExist(err) {
+		s.journaled = 0
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package slashingprotection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testSigner = gethcommon.HexToAddress("0x00000000000000000000000000000000000000a1")
+	testToken  = gethcommon.HexToAddress("0x00000000000000000000000000000000000000b2")
+)
+
+func TestRefusesConflictingSignatures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slashing_protection.json")
+
+	store, err := NewStore(path)
+	assert.NoError(t, err)
+
+	rootA := gethcommon.HexToHash("0x01")
+	rootB := gethcommon.HexToHash("0x02")
+
+	assert.NoError(t, store.CheckAndRecordValset(1, testSigner, 5, rootA))
+	// signing the same payload again is harmless
+	assert.NoError(t, store.CheckAndRecordValset(1, testSigner, 5, rootA))
+	err = store.CheckAndRecordValset(1, testSigner, 5, rootB)
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+	// another hyperion id has its own history
+	assert.NoError(t, store.CheckAndRecordValset(2, testSigner, 5, rootB))
+
+	assert.NoError(t, store.CheckAndRecordBatch(1, testSigner, testToken, 9, rootA))
+	err = store.CheckAndRecordBatch(1, testSigner, testToken, 9, rootB)
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+
+	// the history survives a restart
+	reopened, err := NewStore(path)
+	assert.NoError(t, err)
+	err = reopened.CheckAndRecordBatch(1, testSigner, testToken, 9, rootB)
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+}
+
+func TestReplaysJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slashing_protection.json")
+
+	store, err := NewStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.CheckAndRecordValset(1, testSigner, 5, gethcommon.HexToHash("0x01")))
+	assert.NoError(t, store.CheckAndRecordBatch(1, testSigner, testToken, 9, gethcommon.HexToHash("0x02")))
+
+	// the records are only appended to the journal
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// a record cut by a crash is dropped
+	file, err := os.OpenFile(path+".journal", os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"eth_address":"0x`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	reopened, err := NewStore(path)
+	assert.NoError(t, err)
+	err = reopened.CheckAndRecordBatch(1, testSigner, testToken, 9, gethcommon.HexToHash("0x03"))
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+	assert.NoError(t, reopened.CheckAndRecordValset(1, testSigner, 6, gethcommon.HexToHash("0x04")))
+	reopened, err = NewStore(path)
+	assert.NoError(t, err)
+	err = reopened.CheckAndRecordValset(1, testSigner, 6, gethcommon.HexToHash("0x05"))
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+
+	// folded into the store file on import
+	assert.NoError(t, reopened.Import(&Interchange{Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion}}))
+	_, err = os.Stat(path + ".journal")
+	assert.True(t, os.IsNotExist(err))
+	reopened, err = NewStore(path)
+	assert.NoError(t, err)
+	err = reopened.CheckAndRecordValset(1, testSigner, 5, gethcommon.HexToHash("0x03"))
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+}
+
+func TestImportExport(t *testing.T) {
+	source, err := NewStore(filepath.Join(t.TempDir(), "source.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, source.CheckAndRecordValset(1, testSigner, 5, gethcommon.HexToHash("0x01")))
+	assert.NoError(t, source.CheckAndRecordBatch(1, testSigner, testToken, 9, gethcommon.HexToHash("0x02")))
+
+	target, err := NewStore(filepath.Join(t.TempDir(), "target.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, target.CheckAndRecordValset(1, testSigner, 6, gethcommon.HexToHash("0x03")))
+	assert.NoError(t, target.Import(source.Export()))
+
+	err = target.CheckAndRecordBatch(1, testSigner, testToken, 9, gethcommon.HexToHash("0x04"))
+	assert.True(t, errors.Is(err, ErrConflictingSignature))
+
+	// a conflicting import is refused as a whole
+	conflicting := &Interchange{
+		Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion},
+		Data: []InterchangeSigner{{
+			EthAddress:    testSigner.Hex(),
+			HyperionId:    "1",
+			SignedValsets: []SignedValset{{Nonce: "7", SigningRoot: gethcommon.HexToHash("0x05").Hex()}, {Nonce: "6", SigningRoot: gethcommon.HexToHash("0x06").Hex()}},
+		}},
+	}
+	assert.Error(t, target.Import(conflicting))
+	assert.NoError(t, target.CheckAndRecordValset(1, testSigner, 7, gethcommon.HexToHash("0x07")))
+}
@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
+)
+
+// Loops whose status is shown in HyperionState, LoopError is the ErrorStatus of the chain.
+const (
+	LoopOracle        = "oracle"
+	LoopSigner        = "signer"
+	LoopBatchCreator  = "batch_creator"
+	LoopRelayer       = "relayer"
+	LoopUpdater       = "updater"
+	LoopExternalData  = "external_data"
+	LoopSkipped       = "skipped"
+	LoopValsetManager = "valset_manager"
+	LoopError         = "error"
+)
+
+func (s *Orchestrator) statusField(loop string) *string {
+	switch loop {
+	case LoopOracle:
+		return &s.HyperionState.OracleStatus
+	case LoopSigner:
+		return &s.HyperionState.SignerStatus
+	case LoopBatchCreator:
+		return &s.HyperionState.BatchCreatorStatus
+	case LoopRelayer:
+		return &s.HyperionState.RelayerStatus
+	case LoopUpdater:
+		return &s.HyperionState.UpdaterStatus
+	case LoopExternalData:
+		return &s.HyperionState.ExternalDataStatus
+	case LoopSkipped:
+		return &s.HyperionState.SkippedStatus
+	case LoopValsetManager:
+		return &s.HyperionState.ValsetManagerStatus
+	default:
+		return &s.HyperionState.ErrorStatus
+	}
+}
+
+// setStatus sets the status of the loop in HyperionState and pushes the transition to the
+// status stream, the short-lived statuses missed by get-stats are seen there.
+func (s *Orchestrator) setStatus(loop string, status string) {
+	field := s.statusField(loop)
+	if *field == status {
+		return
+	}
+	*field = status
+
+	if loop == LoopError && status != "okay" {
+		s.publish(stream.EventError, loop, status, nil)
+		return
+	}
+	s.publish(stream.EventStatus, loop, status, nil)
+}
+
+// publish pushes an event of the chain to the status stream.
+func (s *Orchestrator) publish(eventType string, loop string, message string, data interface{}) {
+	if s.global == nil {
+		return
+	}
+	hub := s.global.GetStatusStream()
+	if hub == nil {
+		return
+	}
+	hub.Publish(stream.Event{ChainId: s.cfg.ChainId, Type: eventType, Loop: loop, Message: message, Data: data})
+}
+
+// setLoops records the supervision states of the loops and pushes the ones that changed,
+// a crash is pushed as an error.
+func (s *Orchestrator) setLoops(states []loops.LoopState) {
+	previous := make(map[string]loops.LoopState, len(s.HyperionState.Loops))
+	for _, state := range s.HyperionState.Loops {
+		previous[state.Name] = state
+	}
+	s.HyperionState.Loops = states
+
+	for _, state := range states {
+		before, ok := previous[state.Name]
+		if ok && before.Status == state.Status && before.Crashes == state.Crashes {
+			continue
+		}
+		if state.Crashes > before.Crashes {
+			s.publish(stream.EventError, state.Name, state.LastError, state)
+		}
+		s.publish(stream.EventLoop, state.Name, state.Status, state)
+	}
+}
+
+// publishClaims pushes a bulk of claims broadcast to Helios.
+func (s *Orchestrator) publishClaims(loop string, count int, resp *sdk.TxResponse) {
+	if resp == nil {
+		return
+	}
+	s.publish(stream.EventClaim, loop, strconv.Itoa(count)+" claims sent", map[string]interface{}{
+		"count":  count,
+		"txHash": resp.TxHash,
+		"height": resp.Height,
+	})
+}
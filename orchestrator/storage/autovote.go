@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const maxAutoVotes = 1000
+
+var autoVotesMu sync.Mutex
+
+func getAutoVotesPath() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dirPath := filepath.Join(homePath, ".heliades", "hyperion")
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		os.MkdirAll(dirPath, 0755)
+	}
+
+	joinPath := filepath.Join(dirPath, "autovote_log.json")
+	if _, err := os.Stat(joinPath); os.IsNotExist(err) {
+		os.WriteFile(joinPath, []byte("[]"), 0644)
+	}
+
+	return joinPath, nil
+}
+
+func readAutoVotes(joinPath string) ([]map[string]interface{}, error) {
+	baseFile, err := os.ReadFile(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseFileArray []map[string]interface{}
+	json.Unmarshal(baseFile, &baseFileArray)
+
+	return baseFileArray, nil
+}
+
+// RecordAutoVote logs a vote cast by the auto-vote engine, or the error that prevented it.
+func RecordAutoVote(proposalId uint64, title string, rule string, option string, txHash string, voteErr string) error {
+	autoVotesMu.Lock()
+	defer autoVotesMu.Unlock()
+
+	joinPath, err := getAutoVotesPath()
+	if err != nil {
+		return err
+	}
+
+	baseFileArray, err := readAutoVotes(joinPath)
+	if err != nil {
+		return err
+	}
+
+	baseFileArray = append(baseFileArray, map[string]interface{}{
+		"proposal_id": proposalId,
+		"title":       title,
+		"rule":        rule,
+		"option":      option,
+		"tx_hash":     txHash,
+		"error":       voteErr,
+		"success":     voteErr == "",
+		"timestamp":   time.Now().Unix(),
+	})
+
+	if len(baseFileArray) > maxAutoVotes {
+		baseFileArray = baseFileArray[len(baseFileArray)-maxAutoVotes:]
+	}
+
+	jsonData, err := json.Marshal(baseFileArray)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(joinPath, jsonData, 0644)
+}
+
+// GetAutoVotes returns the votes logged by the auto-vote engine, newest first.
+func GetAutoVotes() ([]map[string]interface{}, error) {
+	autoVotesMu.Lock()
+	defer autoVotesMu.Unlock()
+
+	joinPath, err := getAutoVotesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	baseFileArray, err := readAutoVotes(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make([]map[string]interface{}, 0, len(baseFileArray))
+	for i := len(baseFileArray) - 1; i >= 0; i-- {
+		votes = append(votes, baseFileArray[i])
+	}
+	return votes, nil
+}
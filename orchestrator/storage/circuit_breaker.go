@@ -0,0 +1,21 @@
+package storage
+
+// GetCircuitBreakerState decodes the circuit breaker state of the chain into state, it
+// returns false when the breaker of the chain never tripped.
+func GetCircuitBreakerState(chainId uint64, state interface{}) (bool, error) {
+	return readChainState("circuit_breakers", chainId, state)
+}
+
+func SaveCircuitBreakerState(chainId uint64, state interface{}) error {
+	return saveChainState("circuit_breakers", chainId, state)
+}
+
+// GetDepositVolume decodes the deposit volume history of the chain into volume, it returns
+// false when no deposit was observed yet.
+func GetDepositVolume(chainId uint64, volume interface{}) (bool, error) {
+	return readChainState("deposit_volumes", chainId, volume)
+}
+
+func SaveDepositVolume(chainId uint64, volume interface{}) error {
+	return saveChainState("deposit_volumes", chainId, volume)
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var chainStatesMu sync.Mutex
+
+// getChainStatePath returns the state file of a chain for a resumable workflow, in
+// ~/.heliades/hyperion/<dirName>.
+func getChainStatePath(dirName string, chainId uint64) (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dirPath := filepath.Join(homePath, ".heliades", "hyperion", dirName)
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		os.MkdirAll(dirPath, 0755)
+	}
+
+	return filepath.Join(dirPath, fmt.Sprintf("%d.json", chainId)), nil
+}
+
+func readChainState(dirName string, chainId uint64, state interface{}) (bool, error) {
+	chainStatesMu.Lock()
+	defer chainStatesMu.Unlock()
+
+	joinPath, err := getChainStatePath(dirName, chainId)
+	if err != nil {
+		return false, err
+	}
+
+	baseFile, err := os.ReadFile(joinPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(baseFile, state); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveChainState replaces the state file atomically so that a crash never leaves a
+// truncated state behind.
+func saveChainState(dirName string, chainId uint64, state interface{}) error {
+	chainStatesMu.Lock()
+	defer chainStatesMu.Unlock()
+
+	joinPath, err := getChainStatePath(dirName, chainId)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := joinPath + ".tmp"
+	if err := os.WriteFile(tmpPath, jsonData, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, joinPath)
+}
+
+func removeChainState(dirName string, chainId uint64) error {
+	chainStatesMu.Lock()
+	defer chainStatesMu.Unlock()
+
+	joinPath, err := getChainStatePath(dirName, chainId)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(joinPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// GetDeploymentState decodes the deployment state of the chain into state, it returns
+// false when no deployment was started for the chain.
+func GetDeploymentState(chainId uint64, state interface{}) (bool, error) {
+	return readChainState("deployments", chainId, state)
+}
+
+// SaveDeploymentState persists the deployment state of the chain.
+func SaveDeploymentState(chainId uint64, state interface{}) error {
+	return saveChainState("deployments", chainId, state)
+}
+
+func RemoveDeploymentState(chainId uint64) error {
+	return removeChainState("deployments", chainId)
+}
+
+// GetMigrationState decodes the contract migration state of the chain into state, it
+// returns false when no migration was started for the chain.
+func GetMigrationState(chainId uint64, state interface{}) (bool, error) {
+	return readChainState("migrations", chainId, state)
+}
+
+// SaveMigrationState persists the contract migration state of the chain.
+func SaveMigrationState(chainId uint64, state interface{}) error {
+	return saveChainState("migrations", chainId, state)
+}
+
+func RemoveMigrationState(chainId uint64) error {
+	return removeChainState("migrations", chainId)
+}
@@ -0,0 +1,11 @@
+package storage
+
+// GetDrainState decodes the state persisted when the orchestrator of the chain last
+// stopped into state, it returns false when it never drained.
+func GetDrainState(chainId uint64, state interface{}) (bool, error) {
+	return readChainState("drains", chainId, state)
+}
+
+func SaveDrainState(chainId uint64, state interface{}) error {
+	return saveChainState("drains", chainId, state)
+}
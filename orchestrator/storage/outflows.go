@@ -0,0 +1,11 @@
+package storage
+
+// GetOutflowLedger decodes the outflow ledger of the chain into ledger, it returns false
+// when no batch was counted against the outflow limits of the chain yet.
+func GetOutflowLedger(chainId uint64, ledger interface{}) (bool, error) {
+	return readChainState("outflows", chainId, ledger)
+}
+
+func SaveOutflowLedger(chainId uint64, ledger interface{}) error {
+	return saveChainState("outflows", chainId, ledger)
+}
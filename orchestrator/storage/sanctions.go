@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const maxSanctionsHits = 1000
+
+var sanctionsHitsMu sync.Mutex
+
+func getSanctionsHitsPath() (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dirPath := filepath.Join(homePath, ".heliades", "hyperion")
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		os.MkdirAll(dirPath, 0755)
+	}
+
+	joinPath := filepath.Join(dirPath, "sanctions_hits.json")
+	if _, err := os.Stat(joinPath); os.IsNotExist(err) {
+		os.WriteFile(joinPath, []byte("[]"), 0644)
+	}
+
+	return joinPath, nil
+}
+
+func readSanctionsHits(joinPath string) ([]map[string]interface{}, error) {
+	baseFile, err := os.ReadFile(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseFileArray []map[string]interface{}
+	json.Unmarshal(baseFile, &baseFileArray)
+
+	return baseFileArray, nil
+}
+
+// RecordSanctionsHit stores a batch that pays out to at least one sanctioned address.
+// A hit for the same batch at the same stage (sign or relay) is only recorded once, the
+// returned bool is false when the hit was already known.
+func RecordSanctionsHit(chainId uint64, batchNonce uint64, tokenContract string, addresses []string, policy string, stage string, blocked bool) (bool, error) {
+	sanctionsHitsMu.Lock()
+	defer sanctionsHitsMu.Unlock()
+
+	joinPath, err := getSanctionsHitsPath()
+	if err != nil {
+		return false, err
+	}
+
+	baseFileArray, err := readSanctionsHits(joinPath)
+	if err != nil {
+		return false, err
+	}
+
+	for _, hit := range baseFileArray {
+		if hit["chain_id"] == float64(chainId) && hit["batch_nonce"] == float64(batchNonce) && hit["token_contract"] == tokenContract && hit["stage"] == stage {
+			return false, nil
+		}
+	}
+
+	baseFileArray = append(baseFileArray, map[string]interface{}{
+		"chain_id":       chainId,
+		"batch_nonce":    batchNonce,
+		"token_contract": tokenContract,
+		"addresses":      addresses,
+		"policy":         policy,
+		"stage":          stage,
+		"blocked":        blocked,
+		"timestamp":      time.Now().Unix(),
+	})
+
+	if len(baseFileArray) > maxSanctionsHits {
+		baseFileArray = baseFileArray[len(baseFileArray)-maxSanctionsHits:]
+	}
+
+	jsonData, err := json.Marshal(baseFileArray)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(joinPath, jsonData, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetSanctionsHits returns the recorded hits, newest first. A chainId of 0 returns all chains.
+func GetSanctionsHits(chainId uint64, onlyBlocked bool) ([]map[string]interface{}, error) {
+	sanctionsHitsMu.Lock()
+	defer sanctionsHitsMu.Unlock()
+
+	joinPath, err := getSanctionsHitsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	baseFileArray, err := readSanctionsHits(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]map[string]interface{}, 0)
+	for i := len(baseFileArray) - 1; i >= 0; i-- {
+		hit := baseFileArray[i]
+		if chainId != 0 && hit["chain_id"] != float64(chainId) {
+			continue
+		}
+		if onlyBlocked && hit["blocked"] != true {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var signaturesMu sync.Mutex
+
+// getSignaturesPath returns the archive file of a chain. The archive is never pruned so
+// each chain gets its own file in ~/.heliades/hyperion/signatures.
+func getSignaturesPath(chainId uint64) (string, error) {
+	homePath, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dirPath := filepath.Join(homePath, ".heliades", "hyperion", "signatures")
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		os.MkdirAll(dirPath, 0755)
+	}
+
+	joinPath := filepath.Join(dirPath, fmt.Sprintf("%d.json", chainId))
+	if _, err := os.Stat(joinPath); os.IsNotExist(err) {
+		os.WriteFile(joinPath, []byte("[]"), 0644)
+	}
+
+	return joinPath, nil
+}
+
+func readSignatures(joinPath string) ([]map[string]interface{}, error) {
+	baseFile, err := os.ReadFile(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseFileArray []map[string]interface{}
+	if err := json.Unmarshal(baseFile, &baseFileArray); err != nil {
+		return nil, err
+	}
+
+	return baseFileArray, nil
+}
+
+func writeSignatures(joinPath string, baseFileArray []map[string]interface{}) error {
+	jsonData, err := json.Marshal(baseFileArray)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(joinPath, jsonData, 0644)
+}
+
+func sameSignature(record map[string]interface{}, kind string, nonce uint64, signature string) bool {
+	return record["type"] == kind && record["nonce"] == float64(nonce) && record["signature"] == signature
+}
+
+// RecordSignature archives a valset or batch confirmation produced by the orchestrator
+// together with the encoded message that was signed and the Helios tx that carried it.
+// A signature produced again on a retry is archived once.
+func RecordSignature(chainId uint64, hyperionId uint64, kind string, nonce uint64, tokenContract string, ethSigner string, message string, messageHash string, signature string, heliosTxHash string) error {
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+
+	joinPath, err := getSignaturesPath(chainId)
+	if err != nil {
+		return err
+	}
+
+	baseFileArray, err := readSignatures(joinPath)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range baseFileArray {
+		if sameSignature(record, kind, nonce, signature) {
+			return nil
+		}
+	}
+
+	baseFileArray = append(baseFileArray, map[string]interface{}{
+		"chain_id":       chainId,
+		"hyperion_id":    hyperionId,
+		"type":           kind,
+		"nonce":          nonce,
+		"token_contract": tokenContract,
+		"eth_signer":     ethSigner,
+		"message":        message,
+		"message_hash":   messageHash,
+		"signature":      signature,
+		"helios_tx_hash": heliosTxHash,
+		"timestamp":      time.Now().Unix(),
+	})
+
+	return writeSignatures(joinPath, baseFileArray)
+}
+
+// SetSignatureTxHash attaches the Helios tx that carried an archived signature once it is
+// broadcast.
+func SetSignatureTxHash(chainId uint64, kind string, nonce uint64, signature string, heliosTxHash string) error {
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+
+	joinPath, err := getSignaturesPath(chainId)
+	if err != nil {
+		return err
+	}
+
+	baseFileArray, err := readSignatures(joinPath)
+	if err != nil {
+		return err
+	}
+
+	for i := len(baseFileArray) - 1; i >= 0; i-- {
+		if sameSignature(baseFileArray[i], kind, nonce, signature) {
+			baseFileArray[i]["helios_tx_hash"] = heliosTxHash
+			return writeSignatures(joinPath, baseFileArray)
+		}
+	}
+	return fmt.Errorf("%s signature %d not archived", kind, nonce)
+}
+
+// GetSignatures returns the archived signatures of a chain, newest first. An empty kind
+// returns both valsets and batches, a nonce of 0 returns every nonce.
+func GetSignatures(chainId uint64, kind string, nonce uint64, tokenContract string) ([]map[string]interface{}, error) {
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+
+	joinPath, err := getSignaturesPath(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	baseFileArray, err := readSignatures(joinPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]map[string]interface{}, 0)
+	for i := len(baseFileArray) - 1; i >= 0; i-- {
+		signature := baseFileArray[i]
+		if kind != "" && signature["type"] != kind {
+			continue
+		}
+		if nonce != 0 && signature["nonce"] != float64(nonce) {
+			continue
+		}
+		if tokenContract != "" {
+			if contract, _ := signature["token_contract"].(string); !strings.EqualFold(contract, tokenContract) {
+				continue
+			}
+		}
+		signatures = append(signatures, signature)
+	}
+
+	return signatures, nil
+}
@@ -507,6 +507,11 @@ var DefaultChainSettingsMap = map[string]interface{}{
 	"oracle_block_confirmation_delay":     float64(4),
 	"gas_limit":                           float64(5000000),
 	"oracle_max_claims_msg_per_bulk":      float64(50),
+	"sanctions_policy":                    "flag",
+	"relayer_keystore_file":               "",
+	"relayer_keystore_password_file":      "",
+	"relayer_remote_signer_url":           "",
+	"relayer_remote_signer_address":       "",
 }
 
 func GetChainSettings(chainId uint64) (map[string]interface{}, error) {
@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Types of the events pushed to the stream.
+const (
+	// EventState carries the HyperionState of a chain after it changed
+	EventState = "state"
+	// EventStatus is a loop status transition, e.g. "sending batch 12 - USDC"
+	EventStatus = "status"
+	// EventLoop is a change of the supervision state of a loop
+	EventLoop  = "loop"
+	EventRelay = "relay"
+	EventClaim = "claim"
+	EventError = "error"
+)
+
+const (
+	// DefaultHistory is the number of recent events kept for the replay
+	DefaultHistory = 1000
+
+	subscriberBuffer = 256
+)
+
+// Event is pushed to the subscribers of its chain.
+type Event struct {
+	Seq     uint64      `json:"seq"`
+	Time    time.Time   `json:"time"`
+	ChainId uint64      `json:"chain_id"`
+	Type    string      `json:"type"`
+	Loop    string      `json:"loop,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Filter selects the events of a subscription, an empty field matches everything.
+type Filter struct {
+	ChainIds []uint64
+	Types    []string
+}
+
+func (f Filter) match(event Event) bool {
+	if len(f.ChainIds) > 0 && !contains(f.ChainIds, event.ChainId) {
+		return false
+	}
+	if len(f.Types) > 0 && !contains(f.Types, event.Type) {
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives the events published after it was opened. C is closed when the
+// subscriber falls behind, it reconnects with the last seq it received to replay the gap.
+type Subscription struct {
+	C <-chan Event
+
+	ch     chan Event
+	filter Filter
+}
+
+// Hub fans the events of every orchestrator out to the stream subscribers and keeps the
+// most recent ones so that a client connecting late can replay them.
+type Hub struct {
+	mu      sync.Mutex
+	seq     uint64
+	history []Event
+	next    int
+	size    int
+	subs    map[*Subscription]struct{}
+}
+
+// NewHub returns a hub keeping the last history events.
+func NewHub(history int) *Hub {
+	return &Hub{
+		history: make([]Event, 0, history),
+		size:    history,
+		subs:    make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish numbers the event and pushes it to the matching subscribers, it never blocks on
+// a slow subscriber.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event.Seq = h.seq
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	if len(h.history) < h.size {
+		h.history = append(h.history, event)
+	} else if h.size > 0 {
+		h.history[h.next] = event
+		h.next = (h.next + 1) % h.size
+	}
+
+	for sub := range h.subs {
+		if !sub.filter.match(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe opens a subscription and returns the recent events matching the filter with
+// a seq above since, at most limit of them when limit is positive. No event is lost or
+// delivered twice between the replay and the subscription.
+func (h *Hub) Subscribe(filter Filter, since uint64, limit int) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	replay := make([]Event, 0)
+	for i := range h.history {
+		event := h.history[(h.next+i)%len(h.history)]
+		if event.Seq > since && filter.match(event) {
+			replay = append(replay, event)
+		}
+	}
+	if limit > 0 && len(replay) > limit {
+		replay = replay[len(replay)-limit:]
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+	h.subs[sub] = struct{}{}
+	return sub, replay
+}
+
+// Unsubscribe closes the subscription, it is a no-op when it was already closed.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
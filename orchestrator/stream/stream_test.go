@@ -0,0 +1,53 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubReplayAndFilter(t *testing.T) {
+	hub := NewHub(3)
+	for i := 0; i < 5; i++ {
+		hub.Publish(Event{ChainId: uint64(1 + i%2), Type: EventStatus})
+	}
+
+	// only the last 3 are kept, seq 3 to 5
+	_, replay := hub.Subscribe(Filter{}, 0, 0)
+	assert.Len(t, replay, 3)
+	assert.Equal(t, uint64(3), replay[0].Seq)
+	assert.Equal(t, uint64(5), replay[2].Seq)
+
+	_, replay = hub.Subscribe(Filter{ChainIds: []uint64{1}}, 3, 0)
+	assert.Len(t, replay, 1)
+	assert.Equal(t, uint64(5), replay[0].Seq)
+
+	_, replay = hub.Subscribe(Filter{}, 0, 1)
+	assert.Equal(t, uint64(5), replay[0].Seq)
+
+	sub, _ := hub.Subscribe(Filter{Types: []string{EventRelay}}, 0, 0)
+	hub.Publish(Event{ChainId: 1, Type: EventStatus})
+	hub.Publish(Event{ChainId: 2, Type: EventRelay})
+	event := <-sub.C
+	assert.Equal(t, uint64(7), event.Seq)
+	assert.Equal(t, EventRelay, event.Type)
+
+	hub.Unsubscribe(sub)
+	hub.Unsubscribe(sub)
+	_, ok := <-sub.C
+	assert.False(t, ok)
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(DefaultHistory)
+	sub, _ := hub.Subscribe(Filter{}, 0, 0)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		hub.Publish(Event{ChainId: 1, Type: EventStatus})
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
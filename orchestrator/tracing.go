@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/tracing"
+)
+
+// startSpan opens a span of the loop, tagged with the chain of the orchestrator.
+func (s *Orchestrator) startSpan(ctx context.Context, loop, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		tracing.AttrChain.String(s.cfg.ChainName),
+		tracing.AttrChainId.Int64(int64(s.cfg.ChainId)),
+		tracing.AttrLoop.String(loop),
+	)
+	return tracing.Start(ctx, name, attrs...)
+}
+
+// simulateMsgs simulates the Helios tx of the msgs before they are queued for broadcast.
+func (s *Orchestrator) simulateMsgs(ctx context.Context, msgs []sdk.Msg) error {
+	ctx, span := tracing.StartRPC(ctx, tracing.SystemHelios, "SyncBroadcastMsgsSimulate", tracing.AttrCount.Int(len(msgs)))
+	err := s.GetHelios().SyncBroadcastMsgsSimulate(ctx, msgs)
+	tracing.End(span, err)
+	return err
+}
+
+func (s *Orchestrator) getTxCost(ctx context.Context, txHash string) (*big.Int, error) {
+	ctx, span := tracing.StartRPC(ctx, tracing.SystemHelios, "GetTxCost", tracing.AttrTxHash.String(txHash))
+	cost, err := s.GetHelios().GetTxCost(ctx, txHash)
+	tracing.End(span, err)
+	return cost, err
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/version"
+)
+
+const instrumentationName = "github.com/Helios-Chain-Labs/hyperion/orchestrator"
+
+// Attributes of the spans.
+const (
+	AttrChain   = attribute.Key("hyperion.chain")
+	AttrChainId = attribute.Key("hyperion.chain_id")
+	AttrLoop    = attribute.Key("hyperion.loop")
+	AttrNonce   = attribute.Key("hyperion.nonce")
+	AttrTxHash  = attribute.Key("hyperion.tx_hash")
+	AttrCount   = attribute.Key("hyperion.count")
+)
+
+// Systems called by the RPC spans.
+const (
+	SystemEthereum = "ethereum"
+	SystemHelios   = "helios"
+)
+
+// Config of the trace export.
+type Config struct {
+	// Endpoint of the OTLP/HTTP collector, e.g. http://localhost:4318. When empty the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variables are used, and tracing is off without them.
+	Endpoint string
+	// SampleRatio of the traces kept, all of them when zero
+	SampleRatio float64
+}
+
+// Enabled tells whether the config exports the traces.
+func (c Config) Enabled() bool {
+	return c.Endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup exports the spans of the process to the OTLP collector of the config. The
+// returned func flushes the spans still buffered, it is a no-op when tracing is off.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	if !cfg.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := make([]otlptracehttp.Option, 0)
+	if cfg.Endpoint != "" {
+		endpoint := cfg.Endpoint
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("hyperion"),
+		semconv.ServiceVersion(version.AppVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start opens a span of the orchestrator, a child of the span of ctx if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartRPC opens the span of a call to the Ethereum or Helios RPCs, named after the
+// system and the method called, e.g. ethereum.GetSendToHeliosEvents.
+func StartRPC(ctx context.Context, system, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.RPCSystemKey.String(system), semconv.RPCMethod(method))
+	return tracer().Start(ctx, system+"."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// StartLinked opens a root span linked to the spans of the contexts given, it traces
+// the work done once for several callers, like a Helios tx bundling their msgs.
+func StartLinked(ctx context.Context, name string, links []trace.SpanContext, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanLinks := make([]trace.Link, 0, len(links))
+	for _, link := range links {
+		if link.IsValid() {
+			spanLinks = append(spanLinks, trace.Link{SpanContext: link})
+		}
+	}
+	return tracer().Start(ctx, name, trace.WithNewRoot(), trace.WithLinks(spanLinks...), trace.WithAttributes(attrs...))
+}
+
+// End closes the span, recording the error if the traced work failed.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector stands in for an OTLP/HTTP collector, it keeps the spans exported to it.
+type collector struct {
+	mu    sync.Mutex
+	spans map[string]*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || r.URL.Path != "/v1/traces" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	for _, resourceSpans := range req.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				c.spans[span.Name] = span
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+func TestSpansExportedOverOTLP(t *testing.T) {
+	c := &collector{spans: make(map[string]*tracepb.Span)}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	shutdown, err := Setup(context.Background(), Config{Endpoint: server.URL})
+	require.NoError(t, err)
+
+	ctx, claims := Start(context.Background(), "oracle.sendNewEventClaims", AttrChainId.Int64(11155111))
+	_, rpc := StartRPC(ctx, SystemHelios, "SyncBroadcastMsgsSimulate")
+	End(rpc, errors.New("out of gas"))
+	End(claims, nil)
+
+	_, bundle := StartLinked(context.Background(), "broadcast.bundle", []trace.SpanContext{claims.SpanContext(), {}})
+	End(bundle, nil)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.Len(t, c.spans, 3)
+
+	parent := c.spans["oracle.sendNewEventClaims"]
+	child := c.spans["helios.SyncBroadcastMsgsSimulate"]
+	require.NotNil(t, parent)
+	require.NotNil(t, child)
+	assert.Equal(t, parent.SpanId, child.ParentSpanId)
+	assert.Equal(t, parent.TraceId, child.TraceId)
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, child.Kind)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, child.Status.Code)
+
+	linked := c.spans["broadcast.bundle"]
+	require.NotNil(t, linked)
+	assert.Empty(t, linked.ParentSpanId)
+	require.Len(t, linked.Links, 1)
+	assert.Equal(t, parent.SpanId, linked.Links[0].SpanId)
+}
+
+func TestSetupDisabledWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(context.Background(), Config{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,390 @@
+package transfers
+
+import (
+	"fmt"
+	"time"
+)
+
+// Directions of a transfer.
+const (
+	// Inbound transfers are deposited on the counterparty chain and credited on Helios
+	Inbound = "inbound"
+	// Outbound transfers are sent from Helios and paid out by the counterparty chain
+	Outbound = "outbound"
+)
+
+// Stages of a transfer, in the order they complete.
+const (
+	// StageDeposited is the SendToHelios event emitted on the counterparty chain
+	StageDeposited = "deposited"
+	// StageConfirmed is reached once the deposit block has the confirmations the oracle waits for
+	StageConfirmed = "confirmed"
+	// StageClaimed is reached once the deposit is claimed on Helios
+	StageClaimed = "claimed"
+	// StageAttested is reached once the claims are observed and the tokens credited
+	StageAttested = "attested"
+
+	// StageSent is the MsgSendToChain included on Helios
+	StageSent = "sent"
+	// StageBatched is reached once the transfer is in an outgoing tx batch
+	StageBatched = "batched"
+	// StageSigned is reached once this orchestrator confirmed the batch
+	StageSigned = "signed"
+	// StageRelayed is reached once the batch is submitted to the counterparty chain
+	StageRelayed = "relayed"
+	// StageExecuted is the TransactionBatchExecuted event emitted on the counterparty chain
+	StageExecuted = "executed"
+)
+
+// Statuses of a step.
+const (
+	StatusDone    = "done"
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+	StatusUnknown = "unknown"
+)
+
+// States of a transfer.
+const (
+	StateCompleted  = "completed"
+	StateInProgress = "in_progress"
+	StateStuck      = "stuck"
+	StateFailed     = "failed"
+	StateCancelled  = "cancelled"
+	// StateUnknown is an outbound transfer out of the Helios pool whose cancellation and
+	// batch are not found
+	StateUnknown = "unknown"
+)
+
+// StuckAfter is how long a stage may stay pending after the previous one completed before
+// the transfer is reported stuck at it.
+var StuckAfter = map[string]time.Duration{
+	StageConfirmed: 30 * time.Minute,
+	StageClaimed:   15 * time.Minute,
+	StageAttested:  30 * time.Minute,
+	StageBatched:   1 * time.Hour,
+	StageSigned:    15 * time.Minute,
+	StageRelayed:   1 * time.Hour,
+	StageExecuted:  30 * time.Minute,
+}
+
+// Step is a stage of the lifecycle of a transfer.
+type Step struct {
+	Stage  string     `json:"stage"`
+	Status string     `json:"status"`
+	Time   *time.Time `json:"time,omitempty"`
+	Height uint64     `json:"height,omitempty"`
+	TxHash string     `json:"tx_hash,omitempty"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+// Lifecycle is the reconstructed path of a transfer and the stage it is at.
+type Lifecycle struct {
+	ChainId    uint64 `json:"chain_id"`
+	Direction  string `json:"direction"`
+	EventNonce uint64 `json:"event_nonce,omitempty"`
+	TxId       uint64 `json:"tx_id,omitempty"`
+	BatchNonce uint64 `json:"batch_nonce,omitempty"`
+
+	Sender        string `json:"sender,omitempty"`
+	Receiver      string `json:"receiver,omitempty"`
+	TokenContract string `json:"token_contract,omitempty"`
+	Amount        string `json:"amount,omitempty"`
+
+	Steps []*Step `json:"steps"`
+	State string  `json:"state"`
+	// Stage is the first stage not done, empty once the transfer completed
+	Stage string `json:"stage,omitempty"`
+	// StuckAt names the stage the transfer is stuck at or failed at
+	StuckAt string `json:"stuck_at,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Deposit is a SendToHelios event of the counterparty chain.
+type Deposit struct {
+	EventNonce    uint64
+	TxHash        string
+	BlockHeight   uint64
+	Time          time.Time
+	Sender        string
+	Receiver      string
+	TokenContract string
+	Amount        string
+}
+
+// Attestation is the state on Helios of the claims of an event.
+type Attestation struct {
+	Observed bool
+	Votes    int
+	Height   uint64
+	Time     time.Time
+}
+
+// InboundFacts are read from the counterparty chain and Helios to rebuild an inbound transfer.
+type InboundFacts struct {
+	ChainId    uint64
+	EventNonce uint64
+	// Deposit is nil when the transfer is looked up by its nonce
+	Deposit           *Deposit
+	LatestHeight      uint64
+	ConfirmationDelay uint64
+	// LastClaimedNonce is the last event nonce claimed by this orchestrator
+	LastClaimedNonce  uint64
+	LastObservedNonce uint64
+	// Skipped tells whether Helios skipped the nonce, it waits for the claims of the skipped loop
+	Skipped     bool
+	Attestation *Attestation
+}
+
+// HeliosTx is the Helios tx of a MsgSendToChain.
+type HeliosTx struct {
+	TxHash string
+	Height uint64
+	Time   time.Time
+	Code   uint32
+	RawLog string
+}
+
+// Batch is the outgoing tx batch of a transfer.
+type Batch struct {
+	Nonce         uint64
+	TokenContract string
+	// Timeout is the counterparty height after which the batch can no longer be executed
+	Timeout uint64
+	Height  uint64
+	Time    time.Time
+}
+
+// Relay is a batch relay tx sent by this orchestrator and not mined yet.
+type Relay struct {
+	TxHash string
+	SentAt time.Time
+}
+
+// Execution is the TransactionBatchExecuted event of a batch.
+type Execution struct {
+	TxHash      string
+	BlockHeight uint64
+	Time        time.Time
+}
+
+// OutboundFacts are read from Helios and the counterparty chain to rebuild an outbound transfer.
+type OutboundFacts struct {
+	ChainId uint64
+	TxId    uint64
+	// Sent is nil when the transfer is looked up by its tx id
+	Sent *HeliosTx
+	// Pending tells whether the transfer is still in the Helios pool, batched or not
+	Pending bool
+	// Cancelled is the Helios tx that cancelled a transfer out of the pool
+	Cancelled *HeliosTx
+	// Batch is the batch holding the transfer, for a transfer out of the pool the last
+	// batch built with it
+	Batch *Batch
+	// Confirms is the number of validators who confirmed the batch, Signed tells whether
+	// this orchestrator is one of them
+	Confirms   int
+	Validators int
+	Signed     bool
+	Relay      *Relay
+	// ContractBatchNonce is the last batch nonce executed by the contract for the token
+	ContractBatchNonce uint64
+	Execution          *Execution
+	LatestHeight       uint64
+}
+
+// BuildInbound rebuilds the lifecycle of an inbound transfer.
+func BuildInbound(facts *InboundFacts, now time.Time) *Lifecycle {
+	lifecycle := &Lifecycle{ChainId: facts.ChainId, Direction: Inbound, EventNonce: facts.EventNonce}
+	nonce := facts.EventNonce
+	observed := facts.LastObservedNonce >= nonce || (facts.Attestation != nil && facts.Attestation.Observed)
+	claimed := facts.LastClaimedNonce >= nonce || observed || (facts.Attestation != nil && facts.Attestation.Votes > 0)
+
+	deposited := &Step{Stage: StageDeposited, Status: StatusUnknown, Detail: "looked up by nonce, the deposit tx is unknown"}
+	confirmed := &Step{Stage: StageConfirmed, Status: StatusPending}
+	if deposit := facts.Deposit; deposit != nil {
+		lifecycle.Sender, lifecycle.Receiver = deposit.Sender, deposit.Receiver
+		lifecycle.TokenContract, lifecycle.Amount = deposit.TokenContract, deposit.Amount
+
+		deposited = &Step{Stage: StageDeposited, Status: StatusDone, Time: timeOf(deposit.Time), Height: deposit.BlockHeight, TxHash: deposit.TxHash}
+		confirmed.Height = deposit.BlockHeight + facts.ConfirmationDelay
+		if facts.LatestHeight >= confirmed.Height || claimed {
+			confirmed.Status = StatusDone
+		} else {
+			confirmations := uint64(0)
+			if facts.LatestHeight > deposit.BlockHeight {
+				confirmations = facts.LatestHeight - deposit.BlockHeight
+			}
+			confirmed.Detail = fmt.Sprintf("%d of %d confirmations", confirmations, facts.ConfirmationDelay)
+		}
+	} else if claimed {
+		confirmed.Status = StatusDone
+	} else {
+		confirmed.Status = StatusUnknown
+	}
+
+	claimedStep := &Step{Stage: StageClaimed, Status: StatusPending}
+	if claimed {
+		claimedStep.Status = StatusDone
+		if facts.LastClaimedNonce >= nonce {
+			claimedStep.Detail = "claimed by this orchestrator"
+		} else {
+			claimedStep.Detail = "not claimed by this orchestrator"
+		}
+	} else if facts.Skipped {
+		claimedStep.Detail = "nonce skipped on Helios, waiting for the claims of the skipped events"
+	}
+
+	attested := &Step{Stage: StageAttested, Status: StatusPending}
+	if attestation := facts.Attestation; attestation != nil {
+		attested.Detail = fmt.Sprintf("%d votes", attestation.Votes)
+		if attestation.Observed {
+			attested.Height, attested.Time = attestation.Height, timeOf(attestation.Time)
+		}
+	}
+	if observed {
+		attested.Status = StatusDone
+	}
+
+	lifecycle.Steps = []*Step{deposited, confirmed, claimedStep, attested}
+	lifecycle.resolve(now)
+	if facts.Skipped && lifecycle.Stage == StageClaimed {
+		lifecycle.State, lifecycle.StuckAt, lifecycle.Reason = StateStuck, StageClaimed, claimedStep.Detail
+	}
+	return lifecycle
+}
+
+// BuildOutbound rebuilds the lifecycle of an outbound transfer.
+func BuildOutbound(facts *OutboundFacts, now time.Time) *Lifecycle {
+	lifecycle := &Lifecycle{ChainId: facts.ChainId, Direction: Outbound, TxId: facts.TxId}
+
+	sent := &Step{Stage: StageSent, Status: StatusUnknown}
+	if tx := facts.Sent; tx != nil {
+		sent = &Step{Stage: StageSent, Status: StatusDone, Time: timeOf(tx.Time), Height: tx.Height, TxHash: tx.TxHash}
+		if tx.Code != 0 {
+			sent.Status, sent.Detail = StatusFailed, fmt.Sprintf("tx failed with code %d: %s", tx.Code, tx.RawLog)
+		}
+	} else if facts.Pending || facts.Cancelled != nil || facts.Batch != nil {
+		sent.Status = StatusDone
+	}
+
+	batched := &Step{Stage: StageBatched, Status: StatusPending}
+	signed := &Step{Stage: StageSigned, Status: StatusPending}
+	relayed := &Step{Stage: StageRelayed, Status: StatusPending}
+	executed := &Step{Stage: StageExecuted, Status: StatusPending}
+	lifecycle.Steps = []*Step{sent, batched, signed, relayed, executed}
+
+	if sent.Status != StatusDone {
+		lifecycle.resolve(now)
+		return lifecycle
+	}
+
+	if cancel := facts.Cancelled; cancel != nil {
+		// only a transfer not batched yet can be cancelled
+		batched.Status, batched.TxHash, batched.Height, batched.Time = StatusFailed, cancel.TxHash, cancel.Height, timeOf(cancel.Time)
+		batched.Detail = "cancelled by the sender, the tokens were refunded"
+		lifecycle.resolve(now)
+		lifecycle.State, lifecycle.StuckAt = StateCancelled, ""
+		return lifecycle
+	}
+
+	if !facts.Pending && facts.Batch == nil {
+		for _, step := range lifecycle.Steps[1:] {
+			step.Status, step.Detail = StatusUnknown, "the transfer left the Helios pool, neither its cancellation nor its batch was found"
+		}
+		lifecycle.resolve(now)
+		lifecycle.State, lifecycle.Stage, lifecycle.StuckAt, lifecycle.Reason = StateUnknown, StageBatched, "", batched.Detail
+		return lifecycle
+	}
+
+	batch := facts.Batch
+	if batch == nil {
+		batched.Detail = "waiting in the Helios pool for a batch"
+		lifecycle.resolve(now)
+		return lifecycle
+	}
+	lifecycle.BatchNonce, lifecycle.TokenContract = batch.Nonce, batch.TokenContract
+	batched.Status, batched.Height, batched.Time = StatusDone, batch.Height, timeOf(batch.Time)
+
+	executedOnChain := facts.ContractBatchNonce >= batch.Nonce || facts.Execution != nil
+	signed.Detail = fmt.Sprintf("%d of %d validators confirmed", facts.Confirms, facts.Validators)
+	if facts.Signed || executedOnChain {
+		signed.Status = StatusDone
+	}
+
+	if execution := facts.Execution; execution != nil {
+		relayed.Status, relayed.TxHash = StatusDone, execution.TxHash
+		executed.Status, executed.TxHash = StatusDone, execution.TxHash
+		executed.Height, executed.Time = execution.BlockHeight, timeOf(execution.Time)
+		if facts.Pending {
+			executed.Detail = "waiting for the claims of the execution on Helios"
+		}
+	} else if executedOnChain {
+		relayed.Status = StatusDone
+		executed.Status, executed.Detail = StatusDone, "executed by the contract, the event is out of the scanned blocks"
+	} else if relay := facts.Relay; relay != nil {
+		relayed.Status, relayed.TxHash, relayed.Time = StatusDone, relay.TxHash, timeOf(relay.SentAt)
+		executed.Detail = "relay tx not mined yet"
+	} else if batch.Timeout > 0 && facts.LatestHeight >= batch.Timeout {
+		relayed.Status = StatusFailed
+		relayed.Detail = fmt.Sprintf("batch timed out at height %d, its txs go back to the Helios pool", batch.Timeout)
+	}
+
+	lifecycle.resolve(now)
+	return lifecycle
+}
+
+// resolve sets the state of the lifecycle from its steps: the first step not done is the
+// stage of the transfer, and it is stuck there when it stayed pending for too long.
+func (l *Lifecycle) resolve(now time.Time) {
+	var since *time.Time
+	for _, step := range l.Steps {
+		switch step.Status {
+		case StatusDone:
+			if step.Time != nil {
+				since = step.Time
+			}
+			continue
+		case StatusUnknown:
+			// unknown steps are skipped when a later step is known
+			if l.laterDone(step) {
+				continue
+			}
+		case StatusFailed:
+			l.State, l.Stage, l.StuckAt, l.Reason = StateFailed, step.Stage, step.Stage, step.Detail
+			return
+		}
+
+		l.State, l.Stage = StateInProgress, step.Stage
+		if since != nil && now.Sub(*since) > StuckAfter[step.Stage] {
+			l.State, l.StuckAt = StateStuck, step.Stage
+			l.Reason = fmt.Sprintf("%s pending for %s", step.Stage, now.Sub(*since).Round(time.Second))
+			if step.Detail != "" {
+				l.Reason += ": " + step.Detail
+			}
+		}
+		return
+	}
+	l.State = StateCompleted
+}
+
+func (l *Lifecycle) laterDone(step *Step) bool {
+	later := false
+	for _, s := range l.Steps {
+		if s == step {
+			later = true
+			continue
+		}
+		if later && s.Status == StatusDone {
+			return true
+		}
+	}
+	return false
+}
+
+func timeOf(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,102 @@
+package transfers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInbound(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	deposit := &Deposit{EventNonce: 7, TxHash: "0xdeposit", BlockHeight: 100, Time: now.Add(-5 * time.Minute)}
+
+	// waiting for the confirmations of the deposit block
+	lifecycle := BuildInbound(&InboundFacts{EventNonce: 7, Deposit: deposit, LatestHeight: 102, ConfirmationDelay: 4, LastClaimedNonce: 6, LastObservedNonce: 6}, now)
+	assert.Equal(t, StateInProgress, lifecycle.State)
+	assert.Equal(t, StageConfirmed, lifecycle.Stage)
+	assert.Equal(t, "2 of 4 confirmations", lifecycle.Steps[1].Detail)
+
+	// claimed by this orchestrator, the attestation did not reach the vote threshold for too long
+	lifecycle = BuildInbound(&InboundFacts{
+		EventNonce: 7, Deposit: deposit, LatestHeight: 200, ConfirmationDelay: 4, LastClaimedNonce: 7, LastObservedNonce: 6,
+		Attestation: &Attestation{Votes: 1},
+	}, now.Add(time.Hour))
+	assert.Equal(t, StateStuck, lifecycle.State)
+	assert.Equal(t, StageAttested, lifecycle.StuckAt)
+	assert.Contains(t, lifecycle.Reason, "1 votes")
+
+	// observed
+	lifecycle = BuildInbound(&InboundFacts{
+		EventNonce: 7, Deposit: deposit, LatestHeight: 200, ConfirmationDelay: 4, LastClaimedNonce: 7, LastObservedNonce: 7,
+		Attestation: &Attestation{Observed: true, Votes: 3, Height: 50, Time: now},
+	}, now)
+	assert.Equal(t, StateCompleted, lifecycle.State)
+	assert.Empty(t, lifecycle.Stage)
+
+	// a skipped nonce is stuck right away
+	lifecycle = BuildInbound(&InboundFacts{EventNonce: 7, Deposit: deposit, LatestHeight: 200, ConfirmationDelay: 4, LastClaimedNonce: 6, LastObservedNonce: 6, Skipped: true}, now)
+	assert.Equal(t, StateStuck, lifecycle.State)
+	assert.Equal(t, StageClaimed, lifecycle.StuckAt)
+
+	// looked up by nonce, the deposit is unknown but the later steps are done
+	lifecycle = BuildInbound(&InboundFacts{EventNonce: 7, LastClaimedNonce: 7, LastObservedNonce: 7}, now)
+	assert.Equal(t, StatusUnknown, lifecycle.Steps[0].Status)
+	assert.Equal(t, StateCompleted, lifecycle.State)
+}
+
+func TestBuildOutbound(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	sent := &HeliosTx{TxHash: "ABCD", Height: 10, Time: now.Add(-10 * time.Minute)}
+
+	// waiting in the pool
+	lifecycle := BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Pending: true}, now)
+	assert.Equal(t, StateInProgress, lifecycle.State)
+	assert.Equal(t, StageBatched, lifecycle.Stage)
+
+	// a failed tx never entered the pool
+	lifecycle = BuildOutbound(&OutboundFacts{Sent: &HeliosTx{TxHash: "ABCD", Code: 5, RawLog: "insufficient funds"}}, now)
+	assert.Equal(t, StateFailed, lifecycle.State)
+	assert.Equal(t, StageSent, lifecycle.StuckAt)
+	assert.Contains(t, lifecycle.Reason, "insufficient funds")
+
+	// batched and signed, not relayed for too long
+	batch := &Batch{Nonce: 4, TokenContract: "0xtoken", Timeout: 500, Height: 12, Time: now.Add(-3 * time.Hour)}
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Pending: true, Batch: batch, Signed: true, Confirms: 2, Validators: 3, ContractBatchNonce: 3, LatestHeight: 400}, now)
+	assert.Equal(t, StateStuck, lifecycle.State)
+	assert.Equal(t, StageRelayed, lifecycle.StuckAt)
+	assert.Equal(t, uint64(4), lifecycle.BatchNonce)
+
+	// the batch timed out
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Pending: true, Batch: batch, Signed: true, ContractBatchNonce: 3, LatestHeight: 500}, now)
+	assert.Equal(t, StateFailed, lifecycle.State)
+	assert.Equal(t, StageRelayed, lifecycle.StuckAt)
+
+	// relay tx sent and not mined
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Pending: true, Batch: batch, Signed: true, ContractBatchNonce: 3, LatestHeight: 400, Relay: &Relay{TxHash: "0xrelay", SentAt: now.Add(-time.Minute)}}, now)
+	assert.Equal(t, StateInProgress, lifecycle.State)
+	assert.Equal(t, StageExecuted, lifecycle.Stage)
+
+	// executed on the counterparty chain, waiting for Helios to observe it
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Pending: true, Batch: batch, ContractBatchNonce: 4, Execution: &Execution{TxHash: "0xexec", BlockHeight: 450, Time: now}}, now)
+	assert.Equal(t, StateCompleted, lifecycle.State)
+	assert.Equal(t, "0xexec", lifecycle.Steps[4].TxHash)
+
+	// out of the pool, neither cancelled nor batched as far as Helios tells
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent}, now)
+	assert.Equal(t, StateUnknown, lifecycle.State)
+	assert.Equal(t, StatusUnknown, lifecycle.Steps[4].Status)
+
+	// out of the pool, cancelled by the sender
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Cancelled: &HeliosTx{TxHash: "CANCEL", Height: 20, Time: now}}, now)
+	assert.Equal(t, StateCancelled, lifecycle.State)
+	assert.Equal(t, StatusDone, lifecycle.Steps[0].Status)
+	assert.Equal(t, StatusFailed, lifecycle.Steps[1].Status)
+	assert.Equal(t, "CANCEL", lifecycle.Steps[1].TxHash)
+	assert.NotEqual(t, StatusDone, lifecycle.Steps[4].Status)
+
+	// out of the pool, its batch was executed
+	lifecycle = BuildOutbound(&OutboundFacts{TxId: 3, Sent: sent, Batch: batch, ContractBatchNonce: 4, Execution: &Execution{TxHash: "0xexec", BlockHeight: 450, Time: now}}, now)
+	assert.Equal(t, StateCompleted, lifecycle.State)
+	assert.Equal(t, uint64(4), lifecycle.BatchNonce)
+}
@@ -2,7 +2,6 @@ package orchestrator
 
 import (
 	"context"
-	"strings"
 	"time"
 
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
@@ -17,7 +16,6 @@ const (
 func (s *Orchestrator) runUpdater(ctx context.Context) error {
 	updater := updater{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "updater"),
 	}
 	s.logger.WithField("loop_duration", defaultUpdaterLoopDur.String()).Debugln("starting Updater...")
 
@@ -27,9 +25,9 @@ func (s *Orchestrator) runUpdater(ctx context.Context) error {
 		}
 
 		start := time.Now()
-		s.HyperionState.UpdaterStatus = "running"
+		s.setStatus(LoopUpdater, "running")
 		err := updater.Update(ctx)
-		s.HyperionState.UpdaterStatus = "idle"
+		s.setStatus(LoopUpdater, "idle")
 		s.HyperionState.UpdaterLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 		s.HyperionState.UpdaterNextExecutionTimestamp = uint64(start.Add(defaultUpdaterLoopDur).Unix())
 		return err
@@ -38,12 +36,11 @@ func (s *Orchestrator) runUpdater(ctx context.Context) error {
 
 type updater struct {
 	*Orchestrator
-	logEnabled bool
 }
 
 func (l *updater) Update(ctx context.Context) error {
 	l.logger.Info("Updating Updater...")
-	l.HyperionState.UpdaterStatus = "updating chain params"
+	l.setStatus(LoopUpdater, "updating chain params")
 	// update params of cfg
 	counterpartyChainParams, err := l.GetHelios().GetCounterpartyChainParamsByChainId(ctx, l.cfg.ChainId)
 	if err != nil {
@@ -51,7 +48,7 @@ func (l *updater) Update(ctx context.Context) error {
 	}
 	l.cfg.ChainParams = counterpartyChainParams
 
-	l.HyperionState.UpdaterStatus = "updating deposit pause status"
+	l.setStatus(LoopUpdater, "updating deposit pause status")
 	// update is deposit paused
 	isDepositPaused, err := l.ethereum.IsDepositPaused(ctx)
 	if err != nil {
@@ -59,24 +56,28 @@ func (l *updater) Update(ctx context.Context) error {
 	}
 	l.HyperionState.IsDepositPaused = isDepositPaused
 
-	l.HyperionState.UpdaterStatus = "updating withdrawal pause status"
+	l.setStatus(LoopUpdater, "updating withdrawal pause status")
 	// update is withdrawal paused
 	l.HyperionState.IsWithdrawalPaused = l.cfg.ChainParams.Paused
 
+	if breakerState, err := CircuitBreakerState(l.cfg.ChainId); err == nil {
+		l.HyperionState.CircuitBreakerTripped = breakerState.Tripped
+	}
+
 	// update native balance
-	l.HyperionState.UpdaterStatus = "updating native balance"
+	l.setStatus(LoopUpdater, "updating native balance")
 	err = l.Orchestrator.UpdateNativeBalance(ctx)
 	if err != nil {
 		return errors.Wrap(err, "unable to update native balance")
 	}
 
-	l.HyperionState.UpdaterStatus = "updating gas price"
+	l.setStatus(LoopUpdater, "updating gas price")
 	gasPrice, err := l.ethereum.GetGasPrice(ctx)
 	if err != nil {
 		return errors.Wrap(err, "unable to get gas price")
 	}
 	l.HyperionState.GasPrice = utils.FormatBigStringToFloat64(gasPrice.String(), 9) + " gwei"
-	l.HyperionState.UpdaterStatus = "idle"
+	l.setStatus(LoopUpdater, "idle")
 
 	l.logger.Info("Updater updated")
 	return nil
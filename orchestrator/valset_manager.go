@@ -2,16 +2,19 @@ package orchestrator
 
 import (
 	"context"
-	"fmt"
 	"math/big"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	sdkmath "cosmossdk.io/math"
 
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/breaker"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/indexer"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/loops"
 	"github.com/Helios-Chain-Labs/hyperion/orchestrator/storage"
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/stream"
 	hyperionevents "github.com/Helios-Chain-Labs/hyperion/solidity/wrappers/Hyperion.sol"
 	"github.com/Helios-Chain-Labs/metrics"
 	hyperiontypes "github.com/Helios-Chain-Labs/sdk-go/chain/hyperion/types"
@@ -29,7 +32,6 @@ const (
 func (s *Orchestrator) runValsetManager(ctx context.Context) error {
 	valsetManager := valsetManager{
 		Orchestrator: s,
-		logEnabled:   strings.Contains(s.cfg.EnabledLogs, "updater"),
 	}
 	s.logger.WithField("loop_duration", defaultValsetManagerLoopDur.String()).Debugln("starting ValsetManager...")
 	s.SetValsetManager(&valsetManager)
@@ -37,11 +39,16 @@ func (s *Orchestrator) runValsetManager(ctx context.Context) error {
 		if s.HyperionState.ValsetManagerStatus == "running" {
 			return nil
 		}
+		if !s.startWork("valset_manager") {
+			s.setStatus(LoopValsetManager, DrainingStatus)
+			return nil
+		}
+		defer s.finishWork("valset_manager")
 
 		start := time.Now()
-		s.HyperionState.ValsetManagerStatus = "running"
+		s.setStatus(LoopValsetManager, "running")
 		err := valsetManager.Process(ctx)
-		s.HyperionState.ValsetManagerStatus = "idle"
+		s.setStatus(LoopValsetManager, "idle")
 		s.HyperionState.ValsetManagerLastExecutionFinishedTimestamp = uint64(time.Now().Unix())
 		s.HyperionState.ValsetManagerNextExecutionTimestamp = uint64(start.Add(defaultValsetManagerLoopDur).Unix())
 
@@ -55,14 +62,13 @@ func (s *Orchestrator) runValsetManager(ctx context.Context) error {
 
 type valsetManager struct {
 	*Orchestrator
-	logEnabled       bool
 	synced           bool
 	consideredSynced bool
 	ethValset        *hyperiontypes.Valset
 }
 
 func (l *valsetManager) Log() log.Logger {
-	return l.logger.WithField("loop", "ValsetManager")
+	return l.logger.WithField("loop", LoopValsetManager)
 }
 
 func (l *valsetManager) IsValsetSynced(ctx context.Context) bool {
@@ -86,7 +92,7 @@ func (l *valsetManager) Process(ctx context.Context) error {
 		return err
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopValsetManager) {
 		l.Log().Info("relaying makeCheckpoint")
 	}
 
@@ -95,7 +101,7 @@ func (l *valsetManager) Process(ctx context.Context) error {
 		return err
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopValsetManager) {
 		l.Log().Info("relaying getLastValsetCheckpoint")
 	}
 
@@ -104,7 +110,7 @@ func (l *valsetManager) Process(ctx context.Context) error {
 		return err
 	}
 
-	if l.logEnabled {
+	if l.logEnabled(LoopValsetManager) {
 		l.Log().Info("relaying getLastValsetCheckpoint done")
 	}
 
@@ -115,13 +121,13 @@ func (l *valsetManager) Process(ctx context.Context) error {
 	}).Infoln("Relayer: checkpoints")
 
 	if heliosCheckpoint.Hex() != ethCheckpoint.Hex() {
-		if l.logEnabled {
+		if l.logEnabled(LoopValsetManager) {
 			l.Log().Infoln("relayer: checkpoint not synced yet waiting (rpc should be untrustable) ...")
 		}
 		l.synced = false
 		l.ethValset = nil
 	} else {
-		if l.logEnabled {
+		if l.logEnabled(LoopValsetManager) {
 			l.Log().Info("valset is synced")
 		}
 		l.synced = true
@@ -214,7 +220,7 @@ func (l *valsetManager) relayValset(ctx context.Context, latestEthValset *hyperi
 
 	shouldRelay := l.shouldRelayValset(ctx, latestConfirmedValset, latestEthValset)
 
-	if l.logEnabled {
+	if l.logEnabled(LoopValsetManager) {
 		l.Log().WithFields(log.Fields{"eth_nonce": latestEthValset.Nonce, "hls_nonce": latestConfirmedValset.Nonce, "sigs": len(confirmations), "should_relay": shouldRelay, "synched": latestEthValset.Nonce == latestConfirmedValset.Nonce}).Infoln("relayer try relay Valset")
 	}
 
@@ -223,39 +229,46 @@ func (l *valsetManager) relayValset(ctx context.Context, latestEthValset *hyperi
 		return nil
 	}
 
-	fmt.Println("Sending valset update to Ethereum", latestEthValset, latestConfirmedValset, confirmations)
+	l.Log().WithFields(log.Fields{"nonce": latestConfirmedValset.Nonce, "eth_nonce": latestEthValset.Nonce, "confirmations": len(confirmations)}).Infoln("sending valset update")
 
-	l.Orchestrator.HyperionState.ValsetManagerStatus = "sending valset update to " + l.cfg.ChainName
+	l.Orchestrator.setStatus(LoopValsetManager, "sending valset update to "+l.cfg.ChainName)
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	txHash, cost, err := l.ethereum.SendEthValsetUpdate(ctxWithTimeout, latestEthValset, latestConfirmedValset, confirmations)
 	if err != nil {
 
 		if strings.Contains(err.Error(), "insuffficient funds for gas") {
-			l.Orchestrator.HyperionState.ValsetManagerStatus = "insufficient funds for gas"
+			l.Orchestrator.setStatus(LoopValsetManager, "insufficient funds for gas")
 			return err
 		}
-		l.Orchestrator.HyperionState.ErrorStatus = "error sending valset update"
+		l.Orchestrator.setStatus(LoopError, "error sending valset update")
 		return err
 	}
 	if l.Orchestrator.HyperionState.ErrorStatus == "error sending valset update" {
-		l.Orchestrator.HyperionState.ErrorStatus = "okay"
+		l.Orchestrator.setStatus(LoopError, "okay")
 	}
 
 	ctxWithTimeout2, cancel2 := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel2()
-	l.Orchestrator.HyperionState.ValsetManagerStatus = "waiting for transaction to be mined"
+	l.Orchestrator.setStatus(LoopValsetManager, "waiting for transaction to be mined")
+	l.trackTx("valset", latestConfirmedValset.Nonce, "", *txHash)
 	_, _, err = l.ethereum.WaitForTransaction(ctxWithTimeout2, *txHash)
+	l.untrackTx(*txHash)
 	if err != nil {
-		l.Orchestrator.HyperionState.ErrorStatus = "error waiting for transaction (Hyperion updateValset)"
+		l.Orchestrator.setStatus(LoopError, "error waiting for transaction (Hyperion updateValset)")
 		l.Orchestrator.RotateRpc()
 		l.Log().WithError(err).WithField("tx_hash", txHash.Hex()).Errorln("Failed to wait for transaction (Hyperion updateValset)")
 		return err
 	}
 	if l.Orchestrator.HyperionState.ErrorStatus == "error waiting for transaction (Hyperion updateValset)" {
-		l.Orchestrator.HyperionState.ErrorStatus = "okay"
+		l.Orchestrator.setStatus(LoopError, "okay")
 	}
-	l.Orchestrator.HyperionState.ValsetManagerStatus = "valset update sent to " + l.cfg.ChainName
+	l.Orchestrator.setStatus(LoopValsetManager, "valset update sent to "+l.cfg.ChainName)
+	l.publish(stream.EventRelay, LoopValsetManager, "valset "+strconv.FormatUint(latestConfirmedValset.Nonce, 10)+" relayed", map[string]interface{}{
+		"kind":   "valset",
+		"nonce":  latestConfirmedValset.Nonce,
+		"txHash": txHash.Hex(),
+	})
 	storage.UpdateFeesFile(latestEthValset.RewardAmount.BigInt(), latestEthValset.RewardToken, cost, txHash.Hex(), latestEthValset.Height, l.cfg.ChainId, "VALSET")
 
 	l.Log().WithField("tx_hash", txHash.Hex()).Infoln("sent validator set update to Ethereum")
@@ -325,7 +338,7 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 		return nil, errors.Wrap(err, "failed to get latest valset nonce")
 	}
 
-	fmt.Println("latestEthereumValsetNonce", latestEthereumValsetNonce.Uint64())
+	l.Log().WithField("nonce", latestEthereumValsetNonce.Uint64()).Debugln("latest ethereum valset nonce")
 
 	cosmosValset, err := l.GetHelios().ValsetAt(ctx, l.cfg.HyperionId, latestEthereumValsetNonce.Uint64())
 	if err != nil {
@@ -333,13 +346,16 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 	}
 
 	if lastValsetUpdatedEventHeight.Uint64() > 0 {
-		valsetUpdatedEvents, err := l.ethereum.GetValsetUpdatedEventsAtSpecificBlock(lastValsetUpdatedEventHeight.Uint64())
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to filter past ValsetUpdated events")
+		valsetUpdatedEvents, indexed := l.indexedValsetUpdatedEvents(lastValsetUpdatedEventHeight.Uint64(), latestEthereumValsetNonce.Uint64())
+		if !indexed {
+			valsetUpdatedEvents, err = l.ethereum.GetValsetUpdatedEventsAtSpecificBlock(lastValsetUpdatedEventHeight.Uint64())
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to filter past ValsetUpdated events")
+			}
 		}
 
 		// manage case where the blockchain not manage correctly the block.number (like arbitrum)
-		if len(valsetUpdatedEvents) == 0 {
+		if !indexed && len(valsetUpdatedEvents) == 0 {
 			latestBlockHeader, err := l.ethereum.GetHeaderByNumber(ctx, nil)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to get latest block height")
@@ -349,7 +365,7 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to filter past ValsetUpdated events")
 			}
-			fmt.Println("[Warning] - GetValsetUpdatedEventsWithIndexedNonce : ", len(valsetUpdatedEvents))
+			l.Log().WithField("events", len(valsetUpdatedEvents)).Warningln("no ValsetUpdated event found with the indexed nonce")
 		}
 
 		// by default the lowest found valset goes first, we want the highest
@@ -359,14 +375,14 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 		sort.Sort(sort.Reverse(HyperionValsetUpdatedEvents(valsetUpdatedEvents)))
 
 		if len(valsetUpdatedEvents) == 0 { // return the cosmos valset if no event is found
-			fmt.Println("No valset on "+l.cfg.ChainName+" updated events found, returning cosmos valset", cosmosValset)
+			l.Log().WithField("nonce", cosmosValset.Nonce).Warningln("no ValsetUpdated event found, returning the Helios valset")
 			return cosmosValset, nil
 		}
 
 		// we take only the first event if we find any at all.
 		event := valsetUpdatedEvents[0]
 
-		if l.logEnabled {
+		if l.logEnabled(LoopValsetManager) {
 			l.Log().Info("found valset at block: ", event.Raw.BlockNumber, " with nonce: ", event.NewValsetNonce.Uint64())
 		}
 
@@ -384,8 +400,12 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 			})
 		}
 
-		if l.logEnabled {
-			checkIfValsetsDiffer(cosmosValset, valset)
+		if err := checkIfValsetsDiffer(cosmosValset, valset); err != nil {
+			if config, configErr := l.circuitBreakerConfig(); configErr == nil {
+				l.tripCircuitBreaker(ctx, config, breaker.TripwireValsetMismatch, err.Error())
+			} else {
+				l.Log().WithError(configErr).Warningln("failed to load circuit breaker config")
+			}
 		}
 
 		return valset, nil
@@ -397,6 +417,42 @@ func (l *valsetManager) findLatestValsetOnEth(ctx context.Context) (*hyperiontyp
 
 var ErrNotFound = errors.New("not found")
 
+// indexedValsetUpdatedEvents returns the ValsetUpdated events of the block from the event
+// index, or the event of the valset nonce when the chain reports another block number, like
+// arbitrum. It returns false when the index does not cover the block yet.
+func (l *valsetManager) indexedValsetUpdatedEvents(height uint64, valsetNonce uint64) ([]*hyperionevents.HyperionValsetUpdatedEvent, bool) {
+	if l.index == nil || !l.index.Covers(height, height) {
+		return nil, false
+	}
+
+	indexedEvents, err := l.index.Events(height, height, indexer.KindValsetUpdated)
+	if err != nil {
+		l.Log().WithError(err).Warningln("failed to read indexed ValsetUpdated events, scanning the RPC")
+		return nil, false
+	}
+	if len(indexedEvents) == 0 {
+		latest, err := l.index.LatestValsetUpdated()
+		if err != nil {
+			l.Log().WithError(err).Warningln("failed to read indexed ValsetUpdated events, scanning the RPC")
+			return nil, false
+		}
+		if latest != nil && latest.ValsetNonce == valsetNonce {
+			indexedEvents = append(indexedEvents, latest)
+		}
+	}
+
+	valsetUpdatedEvents := make([]*hyperionevents.HyperionValsetUpdatedEvent, 0, len(indexedEvents))
+	for _, indexed := range indexedEvents {
+		ev, err := indexed.ValsetUpdated()
+		if err != nil {
+			l.Log().WithError(err).Warningln("failed to decode indexed ValsetUpdated event, scanning the RPC")
+			return nil, false
+		}
+		valsetUpdatedEvents = append(valsetUpdatedEvents, ev)
+	}
+	return valsetUpdatedEvents, true
+}
+
 type HyperionValsetUpdatedEvents []*hyperionevents.HyperionValsetUpdatedEvent
 
 func (a HyperionValsetUpdatedEvents) Len() int { return len(a) }
@@ -7,8 +7,8 @@ import (
 
 func VerifyTxError(ctx context.Context, err string, orchestrator *Orchestrator) (bool, error) {
 	if strings.Contains(err, "account sequence mismatch") {
-		orchestrator.HyperionState.ErrorStatus = "Check Your Node - Maybe Jail or unsync"
-		orchestrator.ResetHeliosClient()
+		orchestrator.setStatus(LoopError, "Check Your Node - Maybe Jail or unsync")
+		orchestrator.ResyncHeliosSequence()
 	}
 	return true, nil
 }
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"github.com/Helios-Chain-Labs/hyperion/orchestrator/helios/tendermint"
+)
+
+// wakeups lets the Helios block events run a loop before its next tick. Each channel holds
+// at most one pending wakeup, the wakeups received while a loop runs are coalesced.
+type wakeups struct {
+	signer       chan struct{}
+	batchCreator chan struct{}
+	relayer      chan struct{}
+	externalData chan struct{}
+}
+
+func newWakeups() wakeups {
+	return wakeups{
+		signer:       make(chan struct{}, 1),
+		batchCreator: make(chan struct{}, 1),
+		relayer:      make(chan struct{}, 1),
+		externalData: make(chan struct{}, 1),
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// Wake runs the loops acting on the Helios event right away, their tickers are kept as a
+// fallback when the events are missed.
+func (s *Orchestrator) Wake(wakeup tendermint.Wakeup) {
+	if wakeup.HyperionId != 0 && wakeup.HyperionId != s.cfg.HyperionId {
+		return
+	}
+
+	switch wakeup.Kind {
+	case tendermint.WakeupValset, tendermint.WakeupBatch:
+		notify(s.wakeups.signer)
+	case tendermint.WakeupValsetConfirm, tendermint.WakeupBatchConfirm:
+		notify(s.wakeups.relayer)
+	case tendermint.WakeupSendToChain:
+		notify(s.wakeups.batchCreator)
+	case tendermint.WakeupExternalData:
+		notify(s.wakeups.externalData)
+	}
+	s.HyperionState.HeliosWakeupCount++
+}
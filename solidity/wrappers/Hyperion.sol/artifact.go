@@ -0,0 +1,66 @@
+package wrappers
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// compiledJSON is the solc standard JSON output the wrappers were generated from.
+//
+//go:embed compiled.json
+var compiledJSON []byte
+
+var (
+	artifactOnce    sync.Once
+	artifactRuntime []byte
+	artifactErr     error
+)
+
+// HyperionRuntimeBin returns the runtime bytecode of the Hyperion contract from the bundled
+// artifact, it is the code found at the address of a deployed contract. It fails when the
+// creation bytecode of the artifact is not HyperionBin.
+func HyperionRuntimeBin() ([]byte, error) {
+	artifactOnce.Do(func() {
+		var compiled struct {
+			Contracts map[string]map[string]struct {
+				Evm struct {
+					Bytecode struct {
+						Object string `json:"object"`
+					} `json:"bytecode"`
+					DeployedBytecode struct {
+						Object string `json:"object"`
+					} `json:"deployedBytecode"`
+				} `json:"evm"`
+			} `json:"contracts"`
+		}
+		if err := json.Unmarshal(compiledJSON, &compiled); err != nil {
+			artifactErr = errors.Wrap(err, "failed to decode the Hyperion artifact")
+			return
+		}
+
+		contract, ok := compiled.Contracts["Hyperion.sol"]["Hyperion"]
+		if !ok {
+			artifactErr = errors.New("Hyperion contract not found in the artifact")
+			return
+		}
+		if !strings.EqualFold(strings.TrimPrefix(contract.Evm.Bytecode.Object, "0x"), strings.TrimPrefix(HyperionBin, "0x")) {
+			artifactErr = errors.New("the Hyperion artifact does not match the bytecode of the wrapper")
+			return
+		}
+		artifactRuntime = common.FromHex(contract.Evm.DeployedBytecode.Object)
+	})
+	return artifactRuntime, artifactErr
+}
+
+// HyperionSourceHash returns the contract source hash of HyperionBin proposed to Helios.
+func HyperionSourceHash() string {
+	hash := sha256.Sum256([]byte(HyperionBin))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,16 @@
+package wrappers
+
+import "testing"
+
+func TestHyperionRuntimeBin(t *testing.T) {
+	runtime, err := HyperionRuntimeBin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runtime) == 0 {
+		t.Fatal("empty runtime bytecode")
+	}
+	if len(HyperionSourceHash()) != 64 {
+		t.Fatalf("unexpected source hash %s", HyperionSourceHash())
+	}
+}